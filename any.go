@@ -0,0 +1,119 @@
+package immutable
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// ErrCycle is returned by FromAnyE when a value refers back to one of its
+// own enclosing maps or slices.
+var ErrCycle = errors.New("immutable: cycle detected")
+
+// anyRef identifies a map or slice on the current conversion path. Slices are
+// keyed by length as well as address since reslicing shares the address.
+type anyRef struct {
+	ptr uintptr
+	len int
+}
+
+// FromAny returns an immutable deep copy of a dynamic Go value such as one
+// produced by decoding JSON into an any. Each map[string]any is converted to
+// a *Map[string, any] and each []any to a *List[any], recursively; all other
+// values are returned unchanged. FromAny panics if v contains a cycle; use
+// FromAnyE to receive an error instead.
+func FromAny(v any) any {
+	result, err := FromAnyE(v)
+	if err != nil {
+		panic(fmt.Sprintf("immutable.FromAny: %s", err))
+	}
+	return result
+}
+
+// FromAnyE is like FromAny but returns an error wrapping ErrCycle instead of
+// panicking if v contains a cycle.
+func FromAnyE(v any) (any, error) {
+	return fromAny(v, make(map[anyRef]struct{}))
+}
+
+func fromAny(v any, path map[anyRef]struct{}) (any, error) {
+	switch v := v.(type) {
+	case map[string]any:
+		if v == nil {
+			return v, nil
+		}
+		ref := anyRef{ptr: reflect.ValueOf(v).Pointer()}
+		if _, ok := path[ref]; ok {
+			return nil, ErrCycle
+		}
+		path[ref] = struct{}{}
+		defer delete(path, ref)
+
+		b := NewMapBuilder[string, any](nil)
+		for key, elem := range v {
+			converted, err := fromAny(elem, path)
+			if err != nil {
+				return nil, fmt.Errorf("key %q: %w", key, err)
+			}
+			b.Set(key, converted)
+		}
+		return b.Map(), nil
+
+	case []any:
+		if v == nil {
+			return v, nil
+		}
+		if len(v) > 0 {
+			ref := anyRef{ptr: reflect.ValueOf(v).Pointer(), len: len(v)}
+			if _, ok := path[ref]; ok {
+				return nil, ErrCycle
+			}
+			path[ref] = struct{}{}
+			defer delete(path, ref)
+		}
+
+		values := make([]any, len(v))
+		for i, elem := range v {
+			converted, err := fromAny(elem, path)
+			if err != nil {
+				return nil, fmt.Errorf("index %d: %w", i, err)
+			}
+			values[i] = converted
+		}
+		return NewList[any]().appendSlice(values, new(owner)), nil
+	}
+	return v, nil
+}
+
+// ToAny is the inverse of FromAny. It returns a mutable deep copy of v in which
+// each *Map[string, any] is converted to a map[string]any and each *List[any]
+// to a []any, recursively; all other values are returned unchanged. The
+// result is suitable for re-encoding with encoding/json.
+func ToAny(v any) any {
+	switch v := v.(type) {
+	case *Map[string, any]:
+		if v == nil {
+			return map[string]any(nil)
+		}
+		m := make(map[string]any, v.Len())
+		itr := v.Iterator()
+		for !itr.Done() {
+			key, elem, _ := itr.Next()
+			m[key] = ToAny(elem)
+		}
+		return m
+
+	case *List[any]:
+		if v == nil {
+			return []any(nil)
+		}
+		s := make([]any, 0, v.Len())
+		itr := v.Iterator()
+		for !itr.Done() {
+			_, elem := itr.Next()
+			s = append(s, ToAny(elem))
+		}
+		return s
+	}
+	return v
+}
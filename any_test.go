@@ -0,0 +1,119 @@
+package immutable
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFromAny(t *testing.T) {
+	const doc = `{
+		"name": "root",
+		"count": 3,
+		"ok": true,
+		"missing": null,
+		"tags": ["a", "b", {"nested": [1, [2, [3, {"deep": "yes"}]]]}],
+		"child": {"grandchild": {"values": [], "empty": {}}}
+	}`
+
+	t.Run("RoundTrip", func(t *testing.T) {
+		var src any
+		if err := json.Unmarshal([]byte(doc), &src); err != nil {
+			t.Fatal(err)
+		}
+		v, err := FromAnyE(src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		m, ok := v.(*Map[string, any])
+		if !ok {
+			t.Fatalf("unexpected type %T", v)
+		}
+		if tags, _ := m.Get("tags"); tags.(*List[any]).Len() != 3 {
+			t.Fatalf("unexpected tags: %#v", tags)
+		}
+		if child, _ := m.Get("child"); child.(*Map[string, any]).Len() != 1 {
+			t.Fatalf("unexpected child: %#v", child)
+		}
+		if got := ToAny(v); !reflect.DeepEqual(got, src) {
+			t.Fatalf("round trip mismatch:\n got: %v\nwant: %v", got, src)
+		}
+	})
+
+	t.Run("Isolated", func(t *testing.T) {
+		var src map[string]any
+		if err := json.Unmarshal([]byte(doc), &src); err != nil {
+			t.Fatal(err)
+		}
+		m := FromAny(src).(*Map[string, any])
+
+		src["name"] = "changed"
+		src["tags"].([]any)[0] = "changed"
+		src["child"].(map[string]any)["grandchild"] = nil
+
+		if v, _ := m.Get("name"); v != "root" {
+			t.Fatalf("unexpected name: %v", v)
+		}
+		if tags, _ := m.Get("tags"); tags.(*List[any]).Get(0) != "a" {
+			t.Fatalf("unexpected tag: %v", tags.(*List[any]).Get(0))
+		}
+		child, _ := m.Get("child")
+		if v, _ := child.(*Map[string, any]).Get("grandchild"); v == nil {
+			t.Fatal("expected grandchild")
+		}
+
+		// Values returned by ToAny are not shared with the immutable copy.
+		out := ToAny(m).(map[string]any)
+		out["tags"].([]any)[1] = "changed"
+		if tags, _ := m.Get("tags"); tags.(*List[any]).Get(1) != "b" {
+			t.Fatalf("unexpected tag: %v", tags.(*List[any]).Get(1))
+		}
+	})
+
+	t.Run("Scalars", func(t *testing.T) {
+		for _, v := range []any{nil, 1.5, "s", true, []int{1}, map[string]int{"a": 1}} {
+			if got := FromAny(v); !reflect.DeepEqual(got, v) {
+				t.Fatalf("FromAny(%v)=%v", v, got)
+			}
+			if got := ToAny(v); !reflect.DeepEqual(got, v) {
+				t.Fatalf("ToAny(%v)=%v", v, got)
+			}
+		}
+	})
+
+	t.Run("Shared", func(t *testing.T) {
+		// Repeated references that do not form a cycle are allowed.
+		shared := map[string]any{"x": []any{1.0}}
+		list := []any{shared, shared}
+		if _, err := FromAnyE(map[string]any{"a": list, "b": list}); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("Cycle", func(t *testing.T) {
+		m := map[string]any{}
+		m["inner"] = []any{"a", map[string]any{"self": m}}
+		if _, err := FromAnyE(m); !errors.Is(err, ErrCycle) {
+			t.Fatalf("unexpected error: %v", err)
+		} else if !strings.Contains(err.Error(), `key "inner": index 1: key "self"`) {
+			t.Fatalf("unexpected error path: %v", err)
+		}
+
+		s := []any{nil, nil}
+		s[1] = s
+		if _, err := FromAnyE(s); !errors.Is(err, ErrCycle) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		var r string
+		func() {
+			defer func() { r = recover().(string) }()
+			FromAny(s)
+		}()
+		if r != "immutable.FromAny: index 1: immutable: cycle detected" {
+			t.Fatalf("unexpected panic: %q", r)
+		}
+	})
+}
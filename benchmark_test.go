@@ -5,6 +5,7 @@ import (
 	"math/rand"
 	"runtime"
 	"sync"
+	"sync/atomic"
 	"testing"
 )
 
@@ -551,11 +552,113 @@ func BenchmarkBatchListBuilder_vs_Regular(b *testing.B) {
 	})
 }
 
+// BenchmarkListBuilderFrom appends a small batch to a large existing list,
+// comparing seeded builders against rebuilding the list from scratch.
+func BenchmarkListBuilderFrom(b *testing.B) {
+	const size, batch = 100000, 1000
+	seed := NewList[int]()
+	for i := 0; i < size; i++ {
+		seed = seed.Append(i)
+	}
+
+	b.Run("Rebuild", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			builder := NewBatchListBuilder[int](64)
+			for itr := seed.Iterator(); !itr.Done(); {
+				_, v := itr.Next()
+				builder.Append(v)
+			}
+			for j := 0; j < batch; j++ {
+				builder.Append(j)
+			}
+			_ = builder.List()
+		}
+	})
+
+	b.Run("ListBuilderFrom", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			builder := NewListBuilderFrom(seed)
+			for j := 0; j < batch; j++ {
+				builder.Append(j)
+			}
+			_ = builder.List()
+		}
+	})
+
+	b.Run("BatchListBuilderFrom", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			builder := NewBatchListBuilderFrom(seed, 64)
+			for j := 0; j < batch; j++ {
+				builder.Append(j)
+			}
+			_ = builder.List()
+		}
+	})
+}
+
+// BenchmarkBuilderReuse builds many small lists, comparing a new builder per
+// list against reusing one builder with TakeAndReset.
+func BenchmarkBuilderReuse(b *testing.B) {
+	const size = 10
+
+	b.Run("BatchListBuilder/New", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			builder := NewBatchListBuilder[int](16)
+			for j := 0; j < size; j++ {
+				builder.Append(j)
+			}
+			_ = builder.List()
+		}
+	})
+
+	b.Run("BatchListBuilder/TakeAndReset", func(b *testing.B) {
+		b.ReportAllocs()
+		builder := NewBatchListBuilder[int](16)
+		for i := 0; i < b.N; i++ {
+			for j := 0; j < size; j++ {
+				builder.Append(j)
+			}
+			_ = builder.TakeAndReset()
+		}
+	})
+
+	b.Run("BatchMapBuilder/New", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			builder := NewBatchMapBuilder[int, int](nil, 16)
+			for j := 0; j < size; j++ {
+				builder.Set(j, j)
+			}
+			_ = builder.Map()
+		}
+	})
+
+	b.Run("BatchMapBuilder/TakeAndReset", func(b *testing.B) {
+		b.ReportAllocs()
+		builder := NewBatchMapBuilder[int, int](nil, 16)
+		for i := 0; i < b.N; i++ {
+			for j := 0; j < size; j++ {
+				builder.Set(j, j)
+			}
+			_ = builder.TakeAndReset()
+		}
+	})
+}
+
 func BenchmarkBatchMapBuilder(b *testing.B) {
 	sizes := []int{100, 1000, 10000}
 	batchSizes := []int{16, 32, 64, 128}
 
 	for _, size := range sizes {
+		// Format values up front so the benchmark measures the builder.
+		values := make([]string, size)
+		for j := range values {
+			values[j] = fmt.Sprintf("value-%d", j)
+		}
 		for _, batchSize := range batchSizes {
 			b.Run(fmt.Sprintf("Size%d_Batch%d", size, batchSize), func(b *testing.B) {
 				b.ReportAllocs()
@@ -563,7 +666,7 @@ func BenchmarkBatchMapBuilder(b *testing.B) {
 				for i := 0; i < b.N; i++ {
 					builder := NewBatchMapBuilder[int, string](nil, batchSize)
 					for j := 0; j < size; j++ {
-						builder.Set(j, fmt.Sprintf("value-%d", j))
+						builder.Set(j, values[j])
 					}
 					_ = builder.Map()
 				}
@@ -572,15 +675,179 @@ func BenchmarkBatchMapBuilder(b *testing.B) {
 	}
 }
 
+// BenchmarkBatchBuilderCapacity compares builders without a capacity hint
+// against exact, low and high hints.
+func BenchmarkBatchBuilderCapacity(b *testing.B) {
+	const size = 10000
+	hints := []struct {
+		name string
+		hint int
+	}{{"None", -1}, {"Exact", size}, {"Low", size / 100}, {"High", size * 100}}
+
+	for _, h := range hints {
+		b.Run("BatchMapBuilder/"+h.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				var builder *BatchMapBuilder[int, int]
+				if h.hint < 0 {
+					builder = NewBatchMapBuilder[int, int](nil, 64)
+				} else {
+					builder = NewBatchMapBuilderWithCapacity[int, int](nil, 64, h.hint)
+				}
+				for j := 0; j < size; j++ {
+					builder.Set(j, j)
+				}
+				_ = builder.Map()
+			}
+		})
+	}
+	for _, h := range hints {
+		b.Run("BatchListBuilder/"+h.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				var builder *BatchListBuilder[int]
+				if h.hint < 0 {
+					builder = NewBatchListBuilder[int](64)
+				} else {
+					builder = NewBatchListBuilderWithCapacity[int](64, h.hint)
+				}
+				for j := 0; j < size; j++ {
+					builder.Append(j)
+				}
+				_ = builder.List()
+			}
+		})
+	}
+}
+
+// BenchmarkBatchMapBuilder_NodeArena compares building with and without the
+// node arena, reporting GC pause time alongside allocations.
+func BenchmarkNewMapOfParallel(b *testing.B) {
+	for _, size := range []int{1_000_000, 10_000_000} {
+		entries := make([]MapEntry[int, int], size)
+		for i := range entries {
+			entries[i] = MapEntry[int, int]{Key: i, Value: i}
+		}
+
+		b.Run(fmt.Sprintf("Size%d/BatchBuilder", size), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				builder := NewBatchMapBuilder[int, int](nil, 1024)
+				for _, e := range entries {
+					builder.Set(e.Key, e.Value)
+				}
+				_ = builder.Map()
+			}
+		})
+		for _, workers := range []int{1, 4, 8} {
+			b.Run(fmt.Sprintf("Size%d/Workers%d", size, workers), func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					_ = NewMapOfParallel[int, int](nil, entries, workers)
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkMap_StringHasher(b *testing.B) {
+	const size = 1_000_000
+	keys := mapTestStringKeys(size)
+	for _, tt := range []struct {
+		name   string
+		hasher Hasher[string]
+	}{{"Default", NewHasher("")}, {"Fast", NewFastStringHasher()}} {
+		builder := NewMapBuilder[string, int](tt.hasher)
+		for i, k := range keys {
+			builder.Set(k, i)
+		}
+		m := builder.Map()
+
+		b.Run(tt.name+"/Hash", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				tt.hasher.Hash(keys[i%size])
+			}
+		})
+		b.Run(tt.name+"/Get", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				m.Get(keys[i%size])
+			}
+		})
+		b.Run(tt.name+"/Set", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				m.Set(keys[i%size], i)
+			}
+		})
+	}
+}
+
+func BenchmarkRangeParallel(b *testing.B) {
+	const size = 1_000_000
+	mb := NewMapBuilder[int, int](nil)
+	lb := NewListBuilder[int]()
+	for i := 0; i < size; i++ {
+		mb.Set(i, i)
+		lb.Append(i)
+	}
+	m, l := mb.Map(), lb.List()
+
+	for _, workers := range []int{1, 2, 4} {
+		b.Run(fmt.Sprintf("Map/Workers%d", workers), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				var sum atomic.Int64
+				m.RangeParallel(workers, func(_, v int) { sum.Add(int64(v)) })
+			}
+		})
+		b.Run(fmt.Sprintf("List/Workers%d", workers), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				var sum atomic.Int64
+				l.RangeParallel(workers, func(_, v int) { sum.Add(int64(v)) })
+			}
+		})
+	}
+}
+
+func BenchmarkBatchMapBuilder_NodeArena(b *testing.B) {
+	const size = 10000
+	values := make([]string, size)
+	for j := range values {
+		values[j] = fmt.Sprintf("value-%d", j)
+	}
+
+	for _, arena := range []bool{false, true} {
+		b.Run(fmt.Sprintf("Arena=%v", arena), func(b *testing.B) {
+			b.ReportAllocs()
+			var before, after runtime.MemStats
+			runtime.ReadMemStats(&before)
+			for i := 0; i < b.N; i++ {
+				builder := NewBatchMapBuilder[int, string](nil, 64)
+				builder.UseNodeArena(arena)
+				for j := 0; j < size; j++ {
+					builder.Set(j, values[j])
+				}
+				_ = builder.Map()
+			}
+			runtime.ReadMemStats(&after)
+			b.ReportMetric(float64(after.PauseTotalNs-before.PauseTotalNs)/float64(b.N), "gc-pause-ns/op")
+		})
+	}
+}
+
 func BenchmarkBatchMapBuilder_vs_Regular(b *testing.B) {
 	const size = 10000
+	values := make([]string, size)
+	for j := range values {
+		values[j] = fmt.Sprintf("value-%d", j)
+	}
 
 	b.Run("BatchBuilder", func(b *testing.B) {
 		b.ReportAllocs()
 		for i := 0; i < b.N; i++ {
 			builder := NewBatchMapBuilder[int, string](nil, 64)
 			for j := 0; j < size; j++ {
-				builder.Set(j, fmt.Sprintf("value-%d", j))
+				builder.Set(j, values[j])
 			}
 			_ = builder.Map()
 		}
@@ -591,7 +858,7 @@ func BenchmarkBatchMapBuilder_vs_Regular(b *testing.B) {
 		for i := 0; i < b.N; i++ {
 			builder := NewMapBuilder[int, string](nil)
 			for j := 0; j < size; j++ {
-				builder.Set(j, fmt.Sprintf("value-%d", j))
+				builder.Set(j, values[j])
 			}
 			_ = builder.Map()
 		}
@@ -626,6 +893,42 @@ func BenchmarkStreamingListBuilder(b *testing.B) {
 	})
 }
 
+// BenchmarkStreamingListBuilder_Stream feeds a bursty producer through Stream;
+// AppendSlice of the same data is the baseline.
+func BenchmarkStreamingListBuilder_Stream(b *testing.B) {
+	const size = 10000
+	const burst = 1000
+	data := make([]int, size)
+	for i := range data {
+		data[i] = i
+	}
+
+	b.Run("Stream", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			values := make(chan int, burst)
+			go func() {
+				for _, v := range data {
+					values <- v
+				}
+				close(values)
+			}()
+			builder := NewStreamingListBuilder[int](64, 0)
+			builder.Stream(values)
+			_ = builder.List()
+		}
+	})
+
+	b.Run("AppendSlice", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			builder := NewStreamingListBuilder[int](64, 0)
+			builder.AppendSlice(data)
+			_ = builder.List()
+		}
+	})
+}
+
 func BenchmarkStreamingListBuilder_Operations(b *testing.B) {
 	const size = 1000
 	data := make([]int, size)
@@ -708,28 +1011,35 @@ func BenchmarkSmallMap_BatchBuilder(b *testing.B) {
 func BenchmarkSortedBatchBuilder(b *testing.B) {
 	const size = 1000
 
-	b.Run("SortedBuffer", func(b *testing.B) {
-		b.ReportAllocs()
-		for i := 0; i < b.N; i++ {
-			builder := NewSortedBatchBuilder[int, string](nil, 32, true)
-			for j := 0; j < size; j++ {
-				// Insert in reverse order to test sorting
-				builder.Set(size-j, fmt.Sprintf("value-%d", size-j))
+	values := make([]string, size+1)
+	for j := range values {
+		values[j] = fmt.Sprintf("value-%d", j)
+	}
+
+	for _, batchSize := range []int{32, 128} {
+		b.Run(fmt.Sprintf("SortedBuffer_Batch%d", batchSize), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				builder := NewSortedBatchBuilder[int, string](nil, batchSize, true)
+				for j := 0; j < size; j++ {
+					// Insert in reverse order to test sorting
+					builder.Set(size-j, values[size-j])
+				}
+				_ = builder.SortedMap()
 			}
-			_ = builder.SortedMap()
-		}
-	})
+		})
 
-	b.Run("UnsortedBuffer", func(b *testing.B) {
-		b.ReportAllocs()
-		for i := 0; i < b.N; i++ {
-			builder := NewSortedBatchBuilder[int, string](nil, 32, false)
-			for j := 0; j < size; j++ {
-				builder.Set(size-j, fmt.Sprintf("value-%d", size-j))
+		b.Run(fmt.Sprintf("UnsortedBuffer_Batch%d", batchSize), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				builder := NewSortedBatchBuilder[int, string](nil, batchSize, false)
+				for j := 0; j < size; j++ {
+					builder.Set(size-j, values[size-j])
+				}
+				_ = builder.SortedMap()
 			}
-			_ = builder.SortedMap()
-		}
-	})
+		})
+	}
 }
 
 // PGO Performance Tracking Benchmarks
@@ -837,6 +1147,17 @@ func BenchmarkPGO_SortedMapOperations_Heavy(b *testing.B) {
 		}
 	})
 
+	b.Run("SortedInsertion_BatchBuilder", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			builder := NewSortedBatchBuilder[int, string](nil, 128, true)
+			for j := 0; j < size; j++ {
+				builder.Set(j, fmt.Sprintf("value-%d", j))
+			}
+			_ = builder.SortedMap()
+		}
+	})
+
 	b.Run("ReverseSortedInsertion", func(b *testing.B) {
 		b.ReportAllocs()
 		for i := 0; i < b.N; i++ {
@@ -0,0 +1,489 @@
+package immutable
+
+import (
+	"bytes"
+	"cmp"
+	"encoding"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"math/bits"
+	"reflect"
+	"slices"
+)
+
+// binaryFormatVersion is the first byte of the data produced by the
+// MarshalBinary methods. UnmarshalBinary rejects any other version.
+//
+// The version byte is followed by the element count as a uvarint and then one
+// section per element stream: values for lists and sets, keys and then values
+// for maps. Each section is a uvarint byte length followed by either a
+// uvarint length-prefixed blob per element, for types implementing
+// encoding.BinaryMarshaler and encoding.BinaryUnmarshaler, or a single gob
+// stream holding every element.
+const binaryFormatVersion = 1
+
+// errBinaryCorrupt is returned when binary data is truncated or malformed.
+var errBinaryCorrupt = errors.New("truncated or corrupt data")
+
+// binaryMarshals reports whether elements of type T encode themselves through
+// encoding.BinaryMarshaler and encoding.BinaryUnmarshaler.
+func binaryMarshals[T any]() bool {
+	var zero T
+	_, marshals := any(zero).(encoding.BinaryMarshaler)
+	_, unmarshals := any(&zero).(encoding.BinaryUnmarshaler)
+	return marshals && unmarshals
+}
+
+// appendBinaryHeader appends the format version and element count to buf.
+func appendBinaryHeader(buf []byte, count int) []byte {
+	buf = append(buf, binaryFormatVersion)
+	return binary.AppendUvarint(buf, uint64(count))
+}
+
+// appendBinaryValues appends a section holding values to buf.
+func appendBinaryValues[T any](buf []byte, values []T) ([]byte, error) {
+	var body []byte
+	if binaryMarshals[T]() {
+		for _, v := range values {
+			data, err := any(v).(encoding.BinaryMarshaler).MarshalBinary()
+			if err != nil {
+				return nil, err
+			}
+			body = binary.AppendUvarint(body, uint64(len(data)))
+			body = append(body, data...)
+		}
+	} else {
+		var w bytes.Buffer
+		enc := gob.NewEncoder(&w)
+		for i := range values {
+			if err := enc.Encode(&values[i]); err != nil {
+				return nil, err
+			}
+		}
+		body = w.Bytes()
+	}
+	buf = binary.AppendUvarint(buf, uint64(len(body)))
+	return append(buf, body...), nil
+}
+
+// binaryDecoder reads data written by appendBinaryHeader and appendBinaryValues.
+type binaryDecoder struct {
+	data []byte
+}
+
+// newBinaryDecoder checks the format version of data and returns a decoder
+// positioned after the header, along with the element count.
+func newBinaryDecoder(data []byte) (*binaryDecoder, int, error) {
+	if len(data) == 0 {
+		return nil, 0, errBinaryCorrupt
+	} else if data[0] != binaryFormatVersion {
+		return nil, 0, fmt.Errorf("unsupported format version %d", data[0])
+	}
+	d := &binaryDecoder{data: data[1:]}
+	count, err := d.uvarint()
+	if err != nil {
+		return nil, 0, err
+	} else if count > uint64(len(d.data)) {
+		// Every element occupies at least one byte in each section.
+		return nil, 0, errBinaryCorrupt
+	}
+	return d, int(count), nil
+}
+
+func (d *binaryDecoder) uvarint() (uint64, error) {
+	v, n := binary.Uvarint(d.data)
+	if n <= 0 {
+		return 0, errBinaryCorrupt
+	}
+	d.data = d.data[n:]
+	return v, nil
+}
+
+func (d *binaryDecoder) bytes(n uint64) ([]byte, error) {
+	if n > uint64(len(d.data)) {
+		return nil, errBinaryCorrupt
+	}
+	b := d.data[:n]
+	d.data = d.data[n:]
+	return b, nil
+}
+
+// done returns an error if any data remains after the last section.
+func (d *binaryDecoder) done() error {
+	if len(d.data) != 0 {
+		return errBinaryCorrupt
+	}
+	return nil
+}
+
+// readBinaryValues reads a section holding count values.
+func readBinaryValues[T any](d *binaryDecoder, count int) ([]T, error) {
+	n, err := d.uvarint()
+	if err != nil {
+		return nil, err
+	}
+	body, err := d.bytes(n)
+	if err != nil {
+		return nil, err
+	} else if count > len(body) {
+		return nil, errBinaryCorrupt
+	}
+
+	values := make([]T, count)
+	if binaryMarshals[T]() {
+		section := &binaryDecoder{data: body}
+		for i := range values {
+			n, err := section.uvarint()
+			if err != nil {
+				return nil, err
+			}
+			data, err := section.bytes(n)
+			if err != nil {
+				return nil, err
+			}
+			if err := any(&values[i]).(encoding.BinaryUnmarshaler).UnmarshalBinary(data); err != nil {
+				return nil, err
+			}
+		}
+		return values, section.done()
+	}
+
+	r := bytes.NewReader(body)
+	dec := gob.NewDecoder(r)
+	for i := range values {
+		if err := dec.Decode(&values[i]); err != nil {
+			return nil, err
+		}
+	}
+	if r.Len() != 0 {
+		return nil, errBinaryCorrupt
+	}
+	return values, nil
+}
+
+// unmarshalBinaryValues decodes data holding a single section of values.
+func unmarshalBinaryValues[T any](data []byte) ([]T, error) {
+	d, count, err := newBinaryDecoder(data)
+	if err != nil {
+		return nil, err
+	}
+	values, err := readBinaryValues[T](d, count)
+	if err != nil {
+		return nil, err
+	}
+	return values, d.done()
+}
+
+// unmarshalBinaryEntries decodes data holding a section of keys followed by a
+// section of values.
+func unmarshalBinaryEntries[K, V any](data []byte) ([]K, []V, error) {
+	d, count, err := newBinaryDecoder(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	keys, err := readBinaryValues[K](d, count)
+	if err != nil {
+		return nil, nil, err
+	}
+	values, err := readBinaryValues[V](d, count)
+	if err != nil {
+		return nil, nil, err
+	}
+	return keys, values, d.done()
+}
+
+// buildMap returns a map holding keys and values, inserted grouped by hash as
+// in a BatchMapBuilder flush. If a key repeats, the last value wins.
+func buildMap[K, V any](hasher Hasher[K], keys []K, values []V) *Map[K, V] {
+	m := NewMap[K, V](hasher)
+	if len(keys) == 0 {
+		return m
+	}
+	entries := make([]mapHashedEntry[K, V], len(keys))
+	for i := range keys {
+		entries[i] = mapHashedEntry[K, V]{hash: hasher.Hash(keys[i]), key: keys[i], value: values[i]}
+	}
+	slices.SortStableFunc(entries, func(a, b mapHashedEntry[K, V]) int {
+		return cmp.Compare(bits.Reverse32(a.hash), bits.Reverse32(b.hash))
+	})
+
+	o := new(owner)
+	m.root = &mapArrayNode[K, V]{entries: []mapEntry[K, V]{{key: entries[0].key, value: entries[0].value}}, owner: o}
+	m.size = 1
+	var added int
+	m.root = mapSetGroup(m.root, entries[1:], 0, hasher, o, nil, &added)
+	m.size += added
+	return m
+}
+
+// resolveHasher returns hasher, or the built-in hasher for K if hasher is nil.
+func resolveHasher[K any](hasher Hasher[K], keys []K) (Hasher[K], error) {
+	if hasher != nil || len(keys) == 0 {
+		return hasher, nil
+	} else if t := reflect.TypeFor[K](); !hasDefaultHasher(t) {
+		return nil, fmt.Errorf("no built-in hasher for key type %s", t)
+	}
+	return NewHasher(keys[0]), nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler. Keys and values are
+// encoded with their own MarshalBinary methods if they implement
+// encoding.BinaryMarshaler and encoding.BinaryUnmarshaler, or with gob
+// otherwise.
+func (m *Map[K, V]) MarshalBinary() ([]byte, error) {
+	keys, values := make([]K, 0, m.Len()), make([]V, 0, m.Len())
+	itr := m.Iterator()
+	for !itr.Done() {
+		k, v, _ := itr.Next()
+		keys, values = append(keys, k), append(values, v)
+	}
+	buf, err := appendBinaryValues(appendBinaryHeader(nil, len(keys)), keys)
+	if err != nil {
+		return nil, fmt.Errorf("immutable.Map.MarshalBinary: %w", err)
+	}
+	if buf, err = appendBinaryValues(buf, values); err != nil {
+		return nil, fmt.Errorf("immutable.Map.MarshalBinary: %w", err)
+	}
+	return buf, nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler, replacing the
+// contents of the map with data produced by MarshalBinary. Like
+// UnmarshalJSON, it modifies the receiver and retains the map's hasher.
+func (m *Map[K, V]) UnmarshalBinary(data []byte) error {
+	keys, values, err := unmarshalBinaryEntries[K, V](data)
+	hasher := m.hasher
+	if err == nil {
+		hasher, err = resolveHasher(hasher, keys)
+	}
+	if err != nil {
+		return fmt.Errorf("immutable.Map.UnmarshalBinary: %w", err)
+	}
+	*m = *buildMap(hasher, keys, values)
+	return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler. Values are encoded with
+// their own MarshalBinary methods if they implement encoding.BinaryMarshaler
+// and encoding.BinaryUnmarshaler, or with gob otherwise.
+func (s Set[T]) MarshalBinary() ([]byte, error) {
+	var values []T
+	if s.m != nil {
+		values = make([]T, 0, s.Len())
+		itr := s.Iterator()
+		for !itr.Done() {
+			v, _ := itr.Next()
+			values = append(values, v)
+		}
+	}
+	buf, err := appendBinaryValues(appendBinaryHeader(nil, len(values)), values)
+	if err != nil {
+		return nil, fmt.Errorf("immutable.Set.MarshalBinary: %w", err)
+	}
+	return buf, nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler, replacing the
+// contents of the set with data produced by MarshalBinary. The set's hasher
+// is retained.
+func (s *Set[T]) UnmarshalBinary(data []byte) error {
+	var hasher Hasher[T]
+	if s.m != nil {
+		hasher = s.m.hasher
+	}
+	values, err := unmarshalBinaryValues[T](data)
+	if err == nil {
+		hasher, err = resolveHasher(hasher, values)
+	}
+	if err != nil {
+		return fmt.Errorf("immutable.Set.UnmarshalBinary: %w", err)
+	}
+	s.m = buildMap(hasher, values, make([]struct{}, len(values)))
+	return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler. Values are encoded with
+// their own MarshalBinary methods if they implement encoding.BinaryMarshaler
+// and encoding.BinaryUnmarshaler, or with gob otherwise.
+func (l *List[T]) MarshalBinary() ([]byte, error) {
+	values := make([]T, 0, l.Len())
+	itr := l.Iterator()
+	for !itr.Done() {
+		_, v := itr.Next()
+		values = append(values, v)
+	}
+	buf, err := appendBinaryValues(appendBinaryHeader(nil, len(values)), values)
+	if err != nil {
+		return nil, fmt.Errorf("immutable.List.MarshalBinary: %w", err)
+	}
+	return buf, nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler, replacing the
+// contents of the list with data produced by MarshalBinary. Like
+// Map.UnmarshalBinary, it modifies the receiver.
+func (l *List[T]) UnmarshalBinary(data []byte) error {
+	values, err := unmarshalBinaryValues[T](data)
+	if err != nil {
+		return fmt.Errorf("immutable.List.UnmarshalBinary: %w", err)
+	}
+	*l = *NewList[T]().appendSlice(values, new(owner))
+	return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler. Entries are written in
+// key order. Keys and values are encoded with their own MarshalBinary methods
+// if they implement encoding.BinaryMarshaler and encoding.BinaryUnmarshaler,
+// or with gob otherwise.
+func (m *SortedMap[K, V]) MarshalBinary() ([]byte, error) {
+	keys, values := make([]K, 0, m.Len()), make([]V, 0, m.Len())
+	itr := m.Iterator()
+	for !itr.Done() {
+		k, v, _ := itr.Next()
+		keys, values = append(keys, k), append(values, v)
+	}
+	buf, err := appendBinaryValues(appendBinaryHeader(nil, len(keys)), keys)
+	if err != nil {
+		return nil, fmt.Errorf("immutable.SortedMap.MarshalBinary: %w", err)
+	}
+	if buf, err = appendBinaryValues(buf, values); err != nil {
+		return nil, fmt.Errorf("immutable.SortedMap.MarshalBinary: %w", err)
+	}
+	return buf, nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler, replacing the
+// contents of the map with data produced by MarshalBinary. Entries in key
+// order are bulk loaded. Like Map.UnmarshalBinary, it modifies the receiver.
+// The map's comparer is retained; if it has none, the comparer registered for
+// K with RegisterComparer or the built-in comparer is used.
+func (m *SortedMap[K, V]) UnmarshalBinary(data []byte) error {
+	keys, values, err := unmarshalBinaryEntries[K, V](data)
+	var other *SortedMap[K, V]
+	if err == nil {
+		other, err = buildSortedMap(m.comparer, keys, values)
+	}
+	if err != nil {
+		return fmt.Errorf("immutable.SortedMap.UnmarshalBinary: %w", err)
+	}
+	*m = *other
+	return nil
+}
+
+// GobEncode implements gob.GobEncoder using the MarshalBinary encoding.
+func (m *SortedMap[K, V]) GobEncode() ([]byte, error) { return m.MarshalBinary() }
+
+// GobDecode implements gob.GobDecoder using the MarshalBinary encoding.
+func (m *SortedMap[K, V]) GobDecode(data []byte) error { return m.UnmarshalBinary(data) }
+
+// MarshalBinary implements encoding.BinaryMarshaler. Values are written in
+// sorted order and encoded as in SortedMap.MarshalBinary.
+func (s SortedSet[T]) MarshalBinary() ([]byte, error) {
+	var values []T
+	if s.m != nil {
+		values = make([]T, 0, s.Len())
+		itr := s.Iterator()
+		for !itr.Done() {
+			v, _ := itr.Next()
+			values = append(values, v)
+		}
+	}
+	buf, err := appendBinaryValues(appendBinaryHeader(nil, len(values)), values)
+	if err != nil {
+		return nil, fmt.Errorf("immutable.SortedSet.MarshalBinary: %w", err)
+	}
+	return buf, nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler, replacing the
+// contents of the set with data produced by MarshalBinary. Comparers are
+// resolved as in SortedMap.UnmarshalBinary.
+func (s *SortedSet[T]) UnmarshalBinary(data []byte) error {
+	var comparer Comparer[T]
+	if s.m != nil {
+		comparer = s.m.comparer
+	}
+	values, err := unmarshalBinaryValues[T](data)
+	var m *SortedMap[T, struct{}]
+	if err == nil {
+		m, err = buildSortedMap(comparer, values, make([]struct{}, len(values)))
+	}
+	if err != nil {
+		return fmt.Errorf("immutable.SortedSet.UnmarshalBinary: %w", err)
+	}
+	s.m = m
+	return nil
+}
+
+// GobEncode implements gob.GobEncoder using the MarshalBinary encoding.
+func (s SortedSet[T]) GobEncode() ([]byte, error) { return s.MarshalBinary() }
+
+// GobDecode implements gob.GobDecoder using the MarshalBinary encoding.
+func (s *SortedSet[T]) GobDecode(data []byte) error { return s.UnmarshalBinary(data) }
+
+// buildSortedMap returns a sorted map holding keys and values. Keys in
+// ascending order are bulk loaded; otherwise entries are inserted one at a
+// time. If comparer is nil, the registered or built-in comparer for K is used.
+func buildSortedMap[K, V any](comparer Comparer[K], keys []K, values []V) (*SortedMap[K, V], error) {
+	if comparer == nil {
+		comparer = registeredComparer[K]()
+	}
+	if t := reflect.TypeFor[K](); comparer == nil && len(keys) > 0 && !hasDefaultHasher(t) {
+		return nil, fmt.Errorf("no comparer for key type %s", t)
+	}
+
+	m := NewSortedMap[K, V](comparer)
+	entries := make([]mapEntry[K, V], len(keys))
+	for i := range entries {
+		entries[i] = mapEntry[K, V]{key: keys[i], value: values[i]}
+	}
+	o := new(owner)
+	if !m.appendSorted(entries, o) {
+		for i := range keys {
+			m = m.set(keys[i], values[i], o)
+		}
+	}
+	return m, nil
+}
+
+// GobEncode implements gob.GobEncoder using the MarshalBinary encoding.
+func (s Set[T]) GobEncode() ([]byte, error) { return s.MarshalBinary() }
+
+// GobDecode implements gob.GobDecoder using the MarshalBinary encoding.
+func (s *Set[T]) GobDecode(data []byte) error { return s.UnmarshalBinary(data) }
+
+// MarshalBinary implements encoding.BinaryMarshaler. Values are written in
+// FIFO order and encoded as in List.MarshalBinary.
+func (q *Queue[T]) MarshalBinary() ([]byte, error) {
+	values := make([]T, 0, q.Len())
+	itr := q.Iterator()
+	for !itr.Done() {
+		_, v, _ := itr.Next()
+		values = append(values, v)
+	}
+	buf, err := appendBinaryValues(appendBinaryHeader(nil, len(values)), values)
+	if err != nil {
+		return nil, fmt.Errorf("immutable.Queue.MarshalBinary: %w", err)
+	}
+	return buf, nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler, replacing the
+// contents of the queue with data produced by MarshalBinary. Like
+// List.UnmarshalBinary, it modifies the receiver.
+func (q *Queue[T]) UnmarshalBinary(data []byte) error {
+	values, err := unmarshalBinaryValues[T](data)
+	if err != nil {
+		return fmt.Errorf("immutable.Queue.UnmarshalBinary: %w", err)
+	}
+	*q = Queue[T]{front: NewList[T]().appendSlice(values, new(owner)), back: NewList[T](), size: len(values)}
+	return nil
+}
+
+// GobEncode implements gob.GobEncoder using the MarshalBinary encoding.
+func (q *Queue[T]) GobEncode() ([]byte, error) { return q.MarshalBinary() }
+
+// GobDecode implements gob.GobDecoder using the MarshalBinary encoding.
+func (q *Queue[T]) GobDecode(data []byte) error { return q.UnmarshalBinary(data) }
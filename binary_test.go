@@ -0,0 +1,281 @@
+package immutable
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/gob"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// binaryRecord is a value type encoded through gob.
+type binaryRecord struct {
+	Name string
+	Tags []string
+}
+
+func TestBinary(t *testing.T) {
+	t.Run("Map", func(t *testing.T) {
+		for _, n := range []int{0, 1, 8, 9, 100, 5000} {
+			m := NewMap[int, string](nil)
+			for i := 0; i < n; i++ {
+				m = m.Set(i*7, fmt.Sprint("v", i))
+			}
+			data, err := m.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var other Map[int, string]
+			if err := other.UnmarshalBinary(data); err != nil {
+				t.Fatalf("n=%d: %v", n, err)
+			}
+			if got, want := maps.Collect(mapAll(&other)), maps.Collect(mapAll(m)); !maps.Equal(got, want) || other.Len() != n {
+				t.Fatalf("n=%d: round trip mismatch (len=%d)", n, other.Len())
+			}
+		}
+	})
+
+	t.Run("MapStructValues", func(t *testing.T) {
+		m := NewMap[string, binaryRecord](nil).
+			Set("a", binaryRecord{Name: "alpha", Tags: []string{"x", "y"}}).
+			Set("b", binaryRecord{Name: "beta"})
+		data, err := m.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var other Map[string, binaryRecord]
+		if err := other.UnmarshalBinary(data); err != nil {
+			t.Fatal(err)
+		}
+		if v, ok := other.Get("a"); !ok || v.Name != "alpha" || !slices.Equal(v.Tags, []string{"x", "y"}) {
+			t.Fatalf("Get(a)=<%v,%v>", v, ok)
+		}
+	})
+
+	t.Run("BinaryMarshalerKeys", func(t *testing.T) {
+		// time.Time encodes itself; a comparer is needed since it has no default.
+		base := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+		m := NewSortedMap[time.Time, int](timeComparer{})
+		for i := 0; i < 100; i++ {
+			m = m.Set(base.Add(time.Duration(i)*time.Hour), i)
+		}
+		data, err := m.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		other := NewSortedMap[time.Time, int](timeComparer{})
+		if err := other.UnmarshalBinary(data); err != nil {
+			t.Fatal(err)
+		}
+		if other.Len() != 100 {
+			t.Fatalf("expected length 100, got %d", other.Len())
+		}
+		if v, ok := other.Get(base.Add(42 * time.Hour)); !ok || v != 42 {
+			t.Fatalf("Get()=<%v,%v>", v, ok)
+		}
+
+		var unordered SortedMap[time.Time, int]
+		if err := unordered.UnmarshalBinary(data); err == nil || !strings.Contains(err.Error(), "time.Time") {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("List", func(t *testing.T) {
+		for _, n := range []int{0, 1, 32, 33, 5000} {
+			l := NewList[int]()
+			for i := 0; i < n; i++ {
+				l = l.Append(i - n/2)
+			}
+			data, err := l.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var other List[int]
+			if err := other.UnmarshalBinary(data); err != nil {
+				t.Fatalf("n=%d: %v", n, err)
+			}
+			if got, want := slices.Collect(listValues(&other)), slices.Collect(listValues(l)); !slices.Equal(got, want) {
+				t.Fatalf("n=%d: round trip mismatch", n)
+			}
+			if other := other.Append(1); other.Len() != n+1 {
+				t.Fatalf("n=%d: unexpected length after append: %d", n, other.Len())
+			}
+		}
+	})
+
+	t.Run("Set", func(t *testing.T) {
+		s := NewSet[string](nil, "a", "b", "c")
+		data, err := s.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var other Set[string]
+		if err := other.UnmarshalBinary(data); err != nil {
+			t.Fatal(err)
+		}
+		if other.Len() != 3 || !other.Has("a") || !other.Has("c") || other.Has("d") {
+			t.Fatalf("unexpected set: %v", other.Items())
+		}
+
+		var empty Set[string]
+		if data, err = empty.MarshalBinary(); err != nil {
+			t.Fatal(err)
+		} else if err := other.UnmarshalBinary(data); err != nil || other.Len() != 0 {
+			t.Fatalf("unexpected empty set decode: %v, len=%d", err, other.Len())
+		}
+	})
+
+	t.Run("SortedMap", func(t *testing.T) {
+		m := NewSortedMap[string, int](nil)
+		for i := 0; i < 3000; i++ {
+			m = m.Set(fmt.Sprintf("k%05d", (i*7919)%3000), i)
+		}
+		data, err := m.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var other SortedMap[string, int]
+		if err := other.UnmarshalBinary(data); err != nil {
+			t.Fatal(err)
+		}
+		gotItr, wantItr := other.Iterator(), m.Iterator()
+		for !wantItr.Done() {
+			k0, v0, _ := gotItr.Next()
+			k1, v1, _ := wantItr.Next()
+			if k0 != k1 || v0 != v1 {
+				t.Fatalf("expected %s=%d, got %s=%d", k1, v1, k0, v0)
+			}
+		}
+		if !gotItr.Done() || other.Len() != m.Len() {
+			t.Fatalf("unexpected length %d", other.Len())
+		}
+	})
+
+	t.Run("Corrupt", func(t *testing.T) {
+		m := NewMap[string, binaryRecord](nil)
+		l := NewList[time.Time]()
+		for i := 0; i < 20; i++ {
+			m = m.Set(fmt.Sprint(i), binaryRecord{Name: fmt.Sprint("n", i), Tags: []string{"t"}})
+			l = l.Append(time.Unix(int64(i), 0))
+		}
+		mapData, _ := m.MarshalBinary()
+		listData, _ := l.MarshalBinary()
+
+		targets := []struct {
+			name string
+			data []byte
+			dst  encoding.BinaryUnmarshaler
+		}{
+			{"Map", mapData, &Map[string, binaryRecord]{}},
+			{"List", listData, &List[time.Time]{}},
+			{"SortedMap", mapData, &SortedMap[string, binaryRecord]{}},
+		}
+		for _, target := range targets {
+			// Every truncation must fail cleanly.
+			for n := 0; n < len(target.data); n++ {
+				if err := target.dst.UnmarshalBinary(target.data[:n]); err == nil {
+					t.Fatalf("%s: expected error for truncation at %d", target.name, n)
+				}
+			}
+			if err := target.dst.UnmarshalBinary(append(slices.Clone(target.data), 0)); err == nil {
+				t.Fatalf("%s: expected error for trailing data", target.name)
+			}
+			bad := slices.Clone(target.data)
+			bad[0] = 99
+			if err := target.dst.UnmarshalBinary(bad); err == nil || !strings.Contains(err.Error(), "version 99") {
+				t.Fatalf("%s: unexpected error: %v", target.name, err)
+			}
+			// Corrupting single bytes may decode, but must not panic.
+			for i := 1; i < len(target.data); i++ {
+				bad := slices.Clone(target.data)
+				bad[i] ^= 0xff
+				_ = target.dst.UnmarshalBinary(bad)
+			}
+		}
+	})
+}
+
+type timeComparer struct{}
+
+func (timeComparer) Compare(a, b time.Time) int { return a.Compare(b) }
+
+// gobVersion is a key type with no built-in comparer.
+type gobVersion struct{ Major, Minor int }
+
+type gobVersionComparer struct{}
+
+func (gobVersionComparer) Compare(a, b gobVersion) int {
+	if a.Major != b.Major {
+		return a.Major - b.Major
+	}
+	return a.Minor - b.Minor
+}
+
+func TestGob(t *testing.T) {
+	type collections struct {
+		List      *List[string]
+		Map       *Map[string, int]
+		Set       Set[int]
+		SortedMap *SortedMap[int, string]
+		SortedSet SortedSet[string]
+		Queue     *Queue[int]
+		Versions  *SortedMap[gobVersion, string]
+	}
+	RegisterComparer[gobVersion](gobVersionComparer{})
+
+	in := collections{
+		List:      NewList("a", "b", "c"),
+		Map:       NewMap[string, int](nil).Set("x", 1).Set("y", 2),
+		Set:       NewSet[int](nil, 3, 1, 2),
+		SortedMap: NewSortedMap[int, string](nil).Set(2, "two").Set(1, "one"),
+		SortedSet: NewSortedSet[string](nil, "pear", "fig"),
+		Queue:     NewQueue(1, 2).Enqueue(3),
+		Versions:  NewSortedMap[gobVersion, string](gobVersionComparer{}).Set(gobVersion{1, 2}, "b").Set(gobVersion{1, 0}, "a"),
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(in); err != nil {
+		t.Fatal(err)
+	}
+	var out collections
+	if err := gob.NewDecoder(&buf).Decode(&out); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := slices.Collect(listValues(out.List)); !slices.Equal(got, []string{"a", "b", "c"}) {
+		t.Fatalf("List: %v", got)
+	}
+	if got := maps.Collect(mapAll(out.Map)); !maps.Equal(got, map[string]int{"x": 1, "y": 2}) {
+		t.Fatalf("Map: %v", got)
+	}
+	if got := out.Set.Items(); len(got) != 3 || !out.Set.Has(1) || !out.Set.Has(2) || !out.Set.Has(3) {
+		t.Fatalf("Set: %v", got)
+	}
+	var keys []int
+	for itr := out.SortedMap.Iterator(); !itr.Done(); {
+		k, _, _ := itr.Next()
+		keys = append(keys, k)
+	}
+	if v, _ := out.SortedMap.Get(2); !slices.Equal(keys, []int{1, 2}) || v != "two" {
+		t.Fatalf("SortedMap: %v", keys)
+	}
+	if got := out.SortedSet.Items(); !slices.Equal(got, []string{"fig", "pear"}) {
+		t.Fatalf("SortedSet: %v", got)
+	}
+	var queued []int
+	for q := out.Queue; !q.Empty(); {
+		var v int
+		q, v, _ = q.Dequeue()
+		queued = append(queued, v)
+	}
+	if !slices.Equal(queued, []int{1, 2, 3}) {
+		t.Fatalf("Queue: %v", queued)
+	}
+	if k, v, _ := out.Versions.Iterator().Next(); k != (gobVersion{1, 0}) || v != "a" || out.Versions.Len() != 2 {
+		t.Fatalf("Versions: first entry %v=%v", k, v)
+	}
+}
@@ -0,0 +1,333 @@
+package immutable
+
+import "fmt"
+
+// listDiffMaxEdits is the largest number of insertions and deletions that
+// DiffLists will search for before giving up on a minimal edit script and
+// replacing the differing middle of the lists wholesale. The search takes
+// memory quadratic in this number.
+const listDiffMaxEdits = 1024
+
+// ListEditOp identifies the kind of change made by a ListEdit.
+type ListEditOp int
+
+const (
+	// ListEditInsert inserts Values before Index.
+	ListEditInsert ListEditOp = iota
+	// ListEditDelete removes Count elements starting at Index.
+	ListEditDelete
+	// ListEditReplace overwrites len(Values) elements starting at Index.
+	ListEditReplace
+)
+
+// String returns the name of the operation.
+func (op ListEditOp) String() string {
+	switch op {
+	case ListEditInsert:
+		return "insert"
+	case ListEditDelete:
+		return "delete"
+	case ListEditReplace:
+		return "replace"
+	}
+	return fmt.Sprintf("ListEditOp(%d)", int(op))
+}
+
+// ListEdit is a single operation of an edit script produced by DiffLists.
+// Index always refers to a position in the original list, not the list as
+// modified by earlier edits.
+type ListEdit[T any] struct {
+	Op     ListEditOp
+	Index  int
+	Count  int // number of elements removed by ListEditDelete
+	Values []T // elements added by ListEditInsert and ListEditReplace
+}
+
+// DiffLists returns an edit script that transforms old into new, ordered by
+// index. Elements are compared with eq, or with DeepEqual if eq is nil.
+//
+// The common prefix and suffix of the lists are trimmed first, skipping
+// subtrees the lists share, so diffing a list against a version of itself
+// with elements appended costs time proportional to the number appended. The
+// remainder is diffed with Myers' algorithm, which produces a minimal script;
+// if that would need more than about a thousand insertions and deletions the
+// remainder is replaced wholesale instead.
+func DiffLists[T any](old, new *List[T], eq func(a, b T) bool) []ListEdit[T] {
+	if eq == nil {
+		eq = func(a, b T) bool { return DeepEqual(a, b) }
+	}
+	n, m := listLen(old), listLen(new)
+	prefix := listCommonPrefix(old, new, eq)
+	suffix := listCommonSuffix(old, new, prefix, eq)
+
+	a := listRange(old, prefix, n-suffix)
+	b := listRange(new, prefix, m-suffix)
+	if len(a) == 0 && len(b) == 0 {
+		return nil
+	} else if len(a) == 0 {
+		return []ListEdit[T]{{Op: ListEditInsert, Index: prefix, Values: b}}
+	} else if len(b) == 0 {
+		return []ListEdit[T]{{Op: ListEditDelete, Index: prefix, Count: len(a)}}
+	}
+	if edits, ok := myersListEdits(a, b, prefix, eq); ok {
+		return edits
+	}
+	return listReplaceEdits(prefix, len(a), b)
+}
+
+// ApplyListEdits returns l with edits applied. The edits must be ordered by
+// index and must not overlap, as produced by DiffLists. An error is returned,
+// and l is left unchanged, if any edit is out of range or out of order.
+//
+// Unchanged runs before the first edit and after the last are retained by
+// slicing rather than copied.
+func ApplyListEdits[T any](l *List[T], edits []ListEdit[T]) (*List[T], error) {
+	n := listLen(l)
+	if l == nil {
+		l = NewList[T]()
+	}
+
+	// Validate every edit before building anything.
+	pos := 0
+	for i, e := range edits {
+		if e.Index < pos || e.Index > n {
+			return nil, fmt.Errorf("immutable.ApplyListEdits: edit %d: index %d out of range [%d:%d]", i, e.Index, pos, n)
+		}
+		switch e.Op {
+		case ListEditInsert:
+		case ListEditDelete:
+			if e.Count < 0 || e.Index+e.Count > n {
+				return nil, fmt.Errorf("immutable.ApplyListEdits: edit %d: delete of %d elements at %d out of range", i, e.Count, e.Index)
+			}
+			pos = e.Index + e.Count
+		case ListEditReplace:
+			if e.Index+len(e.Values) > n {
+				return nil, fmt.Errorf("immutable.ApplyListEdits: edit %d: replace of %d elements at %d out of range", i, len(e.Values), e.Index)
+			}
+			pos = e.Index + len(e.Values)
+		default:
+			return nil, fmt.Errorf("immutable.ApplyListEdits: edit %d: invalid operation %v", i, e.Op)
+		}
+	}
+	if len(edits) == 0 {
+		return l, nil
+	}
+
+	// Keep everything before the first edit, then gather the rest into a
+	// single slice so it can be appended in bulk.
+	other := l.Slice(0, edits[0].Index)
+	var values []T
+	pos = edits[0].Index
+	for _, e := range edits {
+		values = append(values, listRange(l, pos, e.Index)...)
+		switch e.Op {
+		case ListEditInsert:
+			values = append(values, e.Values...)
+			pos = e.Index
+		case ListEditDelete:
+			pos = e.Index + e.Count
+		case ListEditReplace:
+			values = append(values, e.Values...)
+			pos = e.Index + len(e.Values)
+		}
+	}
+	values = append(values, listRange(l, pos, n)...)
+	return other.appendSlice(values, nil), nil
+}
+
+// listRange returns the elements of l from index start up to end.
+func listRange[T any](l *List[T], start, end int) []T {
+	if start >= end {
+		return nil
+	}
+	return appendListRange(make([]T, 0, end-start), l, start, end)
+}
+
+// listCommonPrefix returns the number of leading elements a and b have in
+// common. When both are tries with the same origin, subtrees they share are
+// skipped without comparing their elements.
+func listCommonPrefix[T any](a, b *List[T], eq func(a, b T) bool) int {
+	n := min(listLen(a), listLen(b))
+	if n == 0 {
+		return 0
+	}
+	a, b = a.trie(), b.trie()
+	if a.origin == b.origin {
+		_, aslice := a.root.(*listSliceNode[T])
+		_, bslice := b.root.(*listSliceNode[T])
+		if !aslice && !bslice {
+			// A list that grew past its capacity keeps its old root as the
+			// first child of the new one, so descend until the heights match.
+			anode, bnode := a.root, b.root
+			for anode.depth() > bnode.depth() {
+				anode = anode.(*listBranchNode[T]).children[0]
+			}
+			for bnode.depth() > anode.depth() {
+				bnode = bnode.(*listBranchNode[T]).children[0]
+			}
+			if i := listNodesMismatch(anode, bnode, 0, a.origin, a.origin+n, eq); i >= 0 {
+				return i - a.origin
+			}
+			return n
+		}
+	}
+
+	aitr, bitr := a.Iterator(), b.Iterator()
+	for i := 0; i < n; i++ {
+		_, av := aitr.Next()
+		_, bv := bitr.Next()
+		if !eq(av, bv) {
+			return i
+		}
+	}
+	return n
+}
+
+// listNodesMismatch returns the first position from lo through hi-1 at which
+// two trie nodes of the same height whose first position is base differ, or
+// -1 if they do not.
+func listNodesMismatch[T any](a, b listNode[T], base, lo, hi int, eq func(a, b T) bool) int {
+	if a == b {
+		return -1
+	}
+	switch a := a.(type) {
+	case *listBranchNode[T]:
+		b := b.(*listBranchNode[T])
+		span := 1 << (a.d * listNodeBits)
+		for i := range a.children {
+			start := base + i*span
+			if start >= hi {
+				break
+			} else if start+span <= lo {
+				continue
+			}
+			if j := listNodesMismatch(a.children[i], b.children[i], start, lo, hi, eq); j >= 0 {
+				return j
+			}
+		}
+	case *listLeafNode[T]:
+		b := b.(*listLeafNode[T])
+		for i := max(lo-base, 0); i < min(hi-base, listNodeSize); i++ {
+			if !eq(a.children[i], b.children[i]) {
+				return base + i
+			}
+		}
+	}
+	return -1
+}
+
+// listCommonSuffix returns the number of trailing elements a and b have in
+// common, not counting the first prefix elements of either.
+func listCommonSuffix[T any](a, b *List[T], prefix int, eq func(a, b T) bool) int {
+	n := min(listLen(a), listLen(b)) - prefix
+	if n <= 0 {
+		return 0
+	}
+	aitr, bitr := a.Iterator(), b.Iterator()
+	aitr.Last()
+	bitr.Last()
+	for i := 0; i < n; i++ {
+		_, av := aitr.Prev()
+		_, bv := bitr.Prev()
+		if !eq(av, bv) {
+			return i
+		}
+	}
+	return n
+}
+
+// listReplaceEdits returns edits replacing n elements at index with values.
+func listReplaceEdits[T any](index, n int, values []T) []ListEdit[T] {
+	var edits []ListEdit[T]
+	if k := min(n, len(values)); k > 0 {
+		edits = append(edits, ListEdit[T]{Op: ListEditReplace, Index: index, Values: values[:k]})
+		index, n, values = index+k, n-k, values[k:]
+	}
+	if n > 0 {
+		edits = append(edits, ListEdit[T]{Op: ListEditDelete, Index: index, Count: n})
+	} else if len(values) > 0 {
+		edits = append(edits, ListEdit[T]{Op: ListEditInsert, Index: index, Values: values})
+	}
+	return edits
+}
+
+// myersListEdits returns a minimal edit script transforming a into b, whose
+// indices are offset by base. Runs of deletions and insertions at the same
+// position are merged into replacements. Returns false if the script would
+// need more than listDiffMaxEdits insertions and deletions.
+func myersListEdits[T any](a, b []T, base int, eq func(a, b T) bool) ([]ListEdit[T], bool) {
+	n, m := len(a), len(b)
+	offset := n + m + 1
+	v := make([]int, 2*offset+1)
+	var trace [][]int
+
+	// Find the length of the shortest edit script. Before each step, record
+	// the furthest reaching x on the diagonals the step reads from.
+	found := false
+	for d := 0; d <= min(n+m, listDiffMaxEdits) && !found; d++ {
+		trace = append(trace, append([]int(nil), v[offset-d-1:offset+d+2]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && eq(a[x], b[y]) {
+				x, y = x+1, y+1
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				found = true
+				break
+			}
+		}
+	}
+	if !found {
+		return nil, false
+	}
+
+	// Walk the trace backwards to recover the path, marking the deleted
+	// elements of a and the inserted elements of b.
+	deleted, inserted := make([]bool, n), make([]bool, m)
+	x, y := n, m
+	for d := len(trace) - 1; d > 0; d-- {
+		v := trace[d] // v[i] holds diagonal i-d-1
+		k := x - y
+		prevK := k - 1
+		if k == -d || (k != d && v[k-1+d+1] < v[k+1+d+1]) {
+			prevK = k + 1
+		}
+		prevX := v[prevK+d+1]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x, y = x-1, y-1
+		}
+		if x == prevX {
+			inserted[prevY] = true
+		} else {
+			deleted[prevX] = true
+		}
+		x, y = prevX, prevY
+	}
+
+	// Merge the marks into edits, pairing each run of deletions with the
+	// run of insertions that follows it.
+	var edits []ListEdit[T]
+	for x, y = 0, 0; x < n || y < m; {
+		if x < n && y < m && !deleted[x] && !inserted[y] {
+			x, y = x+1, y+1
+			continue
+		}
+		i, j := x, y
+		for x < n && deleted[x] {
+			x++
+		}
+		for y < m && inserted[y] {
+			y++
+		}
+		edits = append(edits, listReplaceEdits(base+i, x-i, b[j:y])...)
+	}
+	return edits, true
+}
@@ -0,0 +1,197 @@
+package immutable
+
+import (
+	"math/rand"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDiffLists(t *testing.T) {
+	eq := func(a, b int) bool { return a == b }
+
+	t.Run("Minimal", func(t *testing.T) {
+		a := NewList(1, 2, 3, 4, 5)
+		b := NewList(1, 9, 3, 5, 6)
+		edits := DiffLists(a, b, eq)
+		exp := []ListEdit[int]{
+			{Op: ListEditReplace, Index: 1, Values: []int{9}},
+			{Op: ListEditDelete, Index: 3, Count: 1},
+			{Op: ListEditInsert, Index: 5, Values: []int{6}},
+		}
+		if !reflect.DeepEqual(edits, exp) {
+			t.Fatalf("unexpected edits: %+v", edits)
+		}
+	})
+
+	t.Run("Equal", func(t *testing.T) {
+		a := NewList(1, 2, 3)
+		if edits := DiffLists(a, a.Set(0, 1), nil); edits != nil {
+			t.Fatalf("unexpected edits: %+v", edits)
+		}
+		if edits := DiffLists[int](nil, NewList[int](), eq); edits != nil {
+			t.Fatalf("unexpected edits: %+v", edits)
+		}
+	})
+
+	t.Run("AppendOnly", func(t *testing.T) {
+		a := NewList[int]()
+		for i := 0; i < 100000; i++ {
+			a = a.Append(i)
+		}
+		b := a.Append(-1).Append(-2)
+
+		var calls int
+		edits := DiffLists(a, b, func(x, y int) bool { calls++; return x == y })
+		exp := []ListEdit[int]{{Op: ListEditInsert, Index: a.Len(), Values: []int{-1, -2}}}
+		if !reflect.DeepEqual(edits, exp) {
+			t.Fatalf("unexpected edits: %+v", edits)
+		} else if calls > 2*listNodeSize {
+			t.Fatalf("expected shared nodes to be skipped, compared %d elements", calls)
+		}
+
+		// Growing past the trie's capacity adds a new root above the old one.
+		c := NewList[int]()
+		for i := 0; i < 1024; i++ {
+			c = c.Append(i)
+		}
+		calls = 0
+		edits = DiffLists(c, c.Append(1024), func(x, y int) bool { calls++; return x == y })
+		if len(edits) != 1 || edits[0].Index != 1024 {
+			t.Fatalf("unexpected edits: %+v", edits)
+		} else if calls > 2*listNodeSize {
+			t.Fatalf("expected shared nodes to be skipped, compared %d elements", calls)
+		}
+	})
+
+	t.Run("ReplaceAll", func(t *testing.T) {
+		a, b := NewListBuilder[int](), NewListBuilder[int]()
+		for i := 0; i < 2*listDiffMaxEdits; i++ {
+			a.Append(i)
+			b.Append(-i - 1)
+		}
+		edits := DiffLists(a.List(), b.List(), eq)
+		if len(edits) != 1 || edits[0].Op != ListEditReplace || len(edits[0].Values) != 2*listDiffMaxEdits {
+			t.Fatalf("unexpected edits: %d", len(edits))
+		}
+	})
+
+	RunRandom(t, "RoundTrip", func(t *testing.T, rand *rand.Rand) {
+		values := func(n int) []int {
+			a := make([]int, n)
+			for i := range a {
+				a[i] = rand.Intn(5)
+			}
+			return a
+		}
+		a := NewList(values(rand.Intn(100))...)
+
+		// Derive b from a so that the lists share structure.
+		b := a
+		for i := rand.Intn(10); i > 0; i-- {
+			switch rand.Intn(4) {
+			case 0:
+				b = b.Append(rand.Intn(5))
+			case 1:
+				b = b.Prepend(rand.Intn(5))
+			case 2:
+				if b.Len() > 0 {
+					b = b.Set(rand.Intn(b.Len()), rand.Intn(5))
+				}
+			case 3:
+				start := rand.Intn(b.Len() + 1)
+				b = b.Slice(start, start+rand.Intn(b.Len()-start+1))
+			}
+		}
+		if rand.Intn(4) == 0 {
+			b = NewList(values(rand.Intn(100))...)
+		}
+
+		edits := DiffLists(a, b, eq)
+		other, err := ApplyListEdits(a, edits)
+		if err != nil {
+			t.Fatal(err)
+		} else if !DeepEqual(other, b) {
+			t.Fatalf("round trip mismatch:\na=%v\nb=%v\ngot=%v\nedits=%+v", slices.Collect(listValues(a)), slices.Collect(listValues(b)), slices.Collect(listValues(other)), edits)
+		}
+
+		// The script must be no longer than replacing the differing middle.
+		var n int
+		for _, e := range edits {
+			n += e.Count + len(e.Values)
+		}
+		if n > a.Len()+b.Len() {
+			t.Fatalf("edit script too long: %d", n)
+		}
+	})
+}
+
+func TestApplyListEdits(t *testing.T) {
+	l := NewList(1, 2, 3, 4, 5)
+
+	t.Run("Empty", func(t *testing.T) {
+		if other, err := ApplyListEdits(l, nil); err != nil {
+			t.Fatal(err)
+		} else if other != l {
+			t.Fatal("expected original list")
+		}
+	})
+
+	t.Run("Nil", func(t *testing.T) {
+		other, err := ApplyListEdits[int](nil, []ListEdit[int]{{Op: ListEditInsert, Values: []int{1}}})
+		if err != nil {
+			t.Fatal(err)
+		} else if got := slices.Collect(listValues(other)); !slices.Equal(got, []int{1}) {
+			t.Fatalf("unexpected list: %v", got)
+		}
+	})
+
+	t.Run("Sequence", func(t *testing.T) {
+		other, err := ApplyListEdits(l, []ListEdit[int]{
+			{Op: ListEditInsert, Index: 0, Values: []int{0}},
+			{Op: ListEditDelete, Index: 1, Count: 2},
+			{Op: ListEditInsert, Index: 3, Values: []int{7, 8}},
+			{Op: ListEditReplace, Index: 4, Values: []int{9}},
+			{Op: ListEditInsert, Index: 5, Values: []int{10}},
+		})
+		if err != nil {
+			t.Fatal(err)
+		} else if got := slices.Collect(listValues(other)); !slices.Equal(got, []int{0, 1, 7, 8, 4, 9, 10}) {
+			t.Fatalf("unexpected list: %v", got)
+		}
+		if got := slices.Collect(listValues(l)); !slices.Equal(got, []int{1, 2, 3, 4, 5}) {
+			t.Fatalf("original list changed: %v", got)
+		}
+	})
+
+	t.Run("Truncate", func(t *testing.T) {
+		other, err := ApplyListEdits(l, []ListEdit[int]{{Op: ListEditDelete, Index: 2, Count: 3}})
+		if err != nil {
+			t.Fatal(err)
+		} else if got := slices.Collect(listValues(other)); !slices.Equal(got, []int{1, 2}) {
+			t.Fatalf("unexpected list: %v", got)
+		}
+	})
+
+	for _, tt := range []struct {
+		name  string
+		edits []ListEdit[int]
+		err   string
+	}{
+		{"IndexOutOfRange", []ListEdit[int]{{Op: ListEditInsert, Index: 6}}, "edit 0: index 6 out of range"},
+		{"NegativeIndex", []ListEdit[int]{{Op: ListEditInsert, Index: -1}}, "edit 0: index -1 out of range"},
+		{"DeleteOutOfRange", []ListEdit[int]{{Op: ListEditDelete, Index: 3, Count: 3}}, "edit 0: delete of 3 elements at 3 out of range"},
+		{"ReplaceOutOfRange", []ListEdit[int]{{Op: ListEditReplace, Index: 4, Values: []int{1, 2}}}, "edit 0: replace of 2 elements at 4 out of range"},
+		{"Overlap", []ListEdit[int]{{Op: ListEditDelete, Index: 1, Count: 2}, {Op: ListEditDelete, Index: 2, Count: 1}}, "edit 1: index 2 out of range"},
+		{"InvalidOp", []ListEdit[int]{{Op: ListEditOp(9)}}, "edit 0: invalid operation ListEditOp(9)"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ApplyListEdits(l, tt.edits); err == nil {
+				t.Fatal("expected error")
+			} else if !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("unexpected error: %s", err)
+			}
+		})
+	}
+}
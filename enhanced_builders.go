@@ -2,6 +2,13 @@ package immutable
 
 import (
 	"cmp"
+	"context"
+	"fmt"
+	"iter"
+	"math/bits"
+	"slices"
+	"sync"
+	"sync/atomic"
 )
 
 // BatchListBuilder provides enhanced batch operations for efficient List construction.
@@ -10,6 +17,10 @@ type BatchListBuilder[T any] struct {
 	list      *List[T]
 	batchSize int
 	buffer    []T
+	front     []T // prepended values, most recently prepended last
+
+	onFlush func(*List[T]) // called with a snapshot after each flush
+	owner   *owner         // owner of the nodes flushes may update in place
 }
 
 // NewBatchListBuilder returns a new batch-optimized list builder.
@@ -22,7 +33,37 @@ func NewBatchListBuilder[T any](batchSize int) *BatchListBuilder[T] {
 		list:      NewList[T](),
 		batchSize: batchSize,
 		buffer:    make([]T, 0, batchSize),
+		front:     make([]T, 0, batchSize),
+		owner:     new(owner),
+	}
+}
+
+// NewBatchListBuilderWithCapacity is like NewBatchListBuilder but takes the
+// expected length of the final list. Buffers are sized for at most that many
+// values and the prepend buffer is only allocated if Prepend is used. The hint
+// only affects performance; any number of values may be added.
+func NewBatchListBuilderWithCapacity[T any](batchSize, expectedSize int) *BatchListBuilder[T] {
+	if batchSize <= 0 {
+		batchSize = 32 // default batch size
+	}
+	return &BatchListBuilder[T]{
+		list:      NewList[T](),
+		batchSize: batchSize,
+		buffer:    make([]T, 0, min(batchSize, max(expectedSize, 0))),
+		owner:     new(owner),
+	}
+}
+
+// NewBatchListBuilderFrom is like NewBatchListBuilder but the builder starts
+// with the elements of l. The builder shares l's nodes, so the first flush to
+// each end of the list copies the nodes along that edge before filling them;
+// l itself is never modified.
+func NewBatchListBuilderFrom[T any](l *List[T], batchSize int) *BatchListBuilder[T] {
+	b := NewBatchListBuilder[T](batchSize)
+	if l != nil {
+		b.list = l.clone()
 	}
+	return b
 }
 
 // Append adds a single value to the batch buffer.
@@ -42,35 +83,104 @@ func (b *BatchListBuilder[T]) AppendSlice(values []T) {
 	}
 }
 
+// AppendSeq adds all values produced by seq.
+func (b *BatchListBuilder[T]) AppendSeq(seq iter.Seq[T]) {
+	for value := range seq {
+		b.Append(value)
+	}
+}
+
+// Prepend adds a single value to the front batch buffer.
+// Values are flushed to the beginning of the list when the buffer reaches capacity.
+func (b *BatchListBuilder[T]) Prepend(value T) {
+	b.front = append(b.front, value)
+	if len(b.front) >= b.batchSize {
+		b.flushFront()
+		b.publish()
+	}
+}
+
+// PrependSlice adds multiple values to the beginning of the list, preserving
+// their order so that values[0] becomes the first element.
+func (b *BatchListBuilder[T]) PrependSlice(values []T) {
+	for i := len(values) - 1; i >= 0; i-- {
+		b.Prepend(values[i])
+	}
+}
+
 // Flush commits all buffered values to the underlying list.
 func (b *BatchListBuilder[T]) Flush() {
-	if len(b.buffer) == 0 {
+	if len(b.front) == 0 && len(b.buffer) == 0 {
 		return
 	}
+	b.flushFront()
+	if len(b.buffer) > 0 {
+		// Slice-backed lists are extended in one allocation; trie-backed lists
+		// are filled a leaf at a time along the right spine, copying the nodes
+		// shared with a snapshot once and updating the copies in place.
+		b.list = b.list.appendSlice(b.buffer, b.owner)
 
-	// Fast path: if underlying list is slice-backed, extend in one allocation.
-	if sliceNode, ok := b.list.root.(*listSliceNode[T]); ok {
-		newLen := b.list.size + len(b.buffer)
-		newElements := make([]T, newLen)
-		copy(newElements, sliceNode.elements)
-		copy(newElements[b.list.size:], b.buffer)
-		b.list.root = &listSliceNode[T]{elements: newElements}
-		b.list.size = newLen
-	} else {
-		// Fallback: append one-by-one using mutable trie path
-		for _, value := range b.buffer {
-			b.list = b.list.append(value, true) // mutable for performance
-		}
+		// Clear buffer (reuse capacity)
+		b.buffer = b.buffer[:0]
+	}
+	b.publish()
+}
+
+// Snapshot flushes the builder and returns the list built so far without
+// invalidating the builder. Returns nil after List().
+func (b *BatchListBuilder[T]) Snapshot() *List[T] {
+	if b.list == nil {
+		return nil
+	}
+	b.Flush()
+	return b.snapshot()
+}
+
+// snapshot returns a copy of the list that is safe to retain. The builder
+// takes a new owner, so the next flush on each end copies the nodes it shares
+// with the snapshot once.
+func (b *BatchListBuilder[T]) snapshot() *List[T] {
+	b.owner = new(owner)
+	return b.list.clone()
+}
+
+// publish passes a snapshot of the list to the flush hook, if any.
+func (b *BatchListBuilder[T]) publish() {
+	if b.onFlush != nil {
+		b.onFlush(b.snapshot())
+	}
+}
+
+// flushFront commits the front buffer to the beginning of the underlying list
+// with a single bulk prepend.
+func (b *BatchListBuilder[T]) flushFront() {
+	if len(b.front) == 0 {
+		return
+	}
+	// The front buffer holds values in prepend order; reverse to list order.
+	for i, j := 0, len(b.front)-1; i < j; i, j = i+1, j-1 {
+		b.front[i], b.front[j] = b.front[j], b.front[i]
 	}
+	b.list = b.list.prependSlice(b.front, b.owner)
 
 	// Clear buffer (reuse capacity)
-	b.buffer = b.buffer[:0]
+	b.front = b.front[:0]
 }
 
-// Reset clears the builder state while retaining buffer capacity.
+// Reset clears the builder state while retaining buffer capacity. The builder
+// may be reused after List() once it has been reset.
 func (b *BatchListBuilder[T]) Reset() {
 	b.list = NewList[T]()
 	b.buffer = b.buffer[:0]
+	b.front = b.front[:0]
+	b.owner = new(owner)
+}
+
+// TakeAndReset returns the final list and resets the builder for reuse.
+func (b *BatchListBuilder[T]) TakeAndReset() *List[T] {
+	list := b.List()
+	b.Reset()
+	return list
 }
 
 // List returns the final list and invalidates the builder.
@@ -87,14 +197,127 @@ func (b *BatchListBuilder[T]) Len() int {
 	if b.list == nil {
 		return 0
 	}
-	return b.list.Len() + len(b.buffer)
+	return b.list.Len() + len(b.front) + len(b.buffer)
+}
+
+// Get returns the value at the given index without flushing. Indexes span
+// prepended values, committed values and appended values in list order.
+// Similar to slices, this method will panic if index is below zero or is
+// greater than or equal to the builder's length.
+func (b *BatchListBuilder[T]) Get(index int) T {
+	if index < 0 || index >= b.Len() {
+		panic(fmt.Sprintf("immutable.BatchListBuilder.Get: index %d out of bounds", index))
+	}
+	if index < len(b.front) {
+		return b.front[len(b.front)-1-index]
+	}
+	index -= len(b.front)
+	if index < b.list.Len() {
+		return b.list.Get(index)
+	}
+	return b.buffer[index-b.list.Len()]
+}
+
+// Range calls fn for each index and value in list order without flushing.
+// Iteration stops early if fn returns false. Like Iterator, Range walks a
+// snapshot so fn may modify the builder.
+func (b *BatchListBuilder[T]) Range(fn func(index int, value T) bool) {
+	for itr := b.Iterator(); !itr.Done(); {
+		if !fn(itr.Next()) {
+			return
+		}
+	}
+}
+
+// Iterator returns a new iterator over the builder's committed and buffered
+// values, positioned at the first index. The iterator walks a snapshot of the
+// builder, so later builder operations are not observed by it.
+func (b *BatchListBuilder[T]) Iterator() *BatchListIterator[T] {
+	if b.list == nil {
+		return &BatchListIterator[T]{list: NewList[T]()}
+	}
+	front := slices.Clone(b.front)
+	slices.Reverse(front)
+	return &BatchListIterator[T]{
+		front:  front,
+		list:   b.snapshot(),
+		buffer: slices.Clone(b.buffer),
+	}
+}
+
+// BatchListIterator represents an ordered iterator over a snapshot of a
+// BatchListBuilder, including values that had not been flushed.
+type BatchListIterator[T any] struct {
+	front  []T      // prepended values, in list order
+	list   *List[T] // committed values
+	buffer []T      // appended values
+	index  int      // current index
+	itr    *ListIterator[T]
+}
+
+// Done returns true if no more elements remain in the iterator.
+func (itr *BatchListIterator[T]) Done() bool {
+	return itr.index >= len(itr.front)+itr.list.Len()+len(itr.buffer)
+}
+
+// Clone returns an independent iterator at the same position. Moving either
+// iterator does not affect the other.
+func (itr *BatchListIterator[T]) Clone() *BatchListIterator[T] {
+	other := *itr
+	if itr.itr != nil {
+		other.itr = itr.itr.Clone()
+	}
+	return &other
+}
+
+// Next returns the current index and its value & moves the iterator forward.
+// Returns an index of -1 if there are no more elements to return.
+func (itr *BatchListIterator[T]) Next() (index int, value T) {
+	if itr.Done() {
+		return -1, value
+	}
+	index = itr.index
+	itr.index++
+
+	i := index - len(itr.front)
+	switch {
+	case i < 0:
+		return index, itr.front[index]
+	case i >= itr.list.Len():
+		return index, itr.buffer[i-itr.list.Len()]
+	}
+	if itr.itr == nil {
+		itr.itr = itr.list.Iterator()
+		itr.itr.Seek(i)
+	}
+	_, value = itr.itr.Next()
+	return index, value
 }
 
 // BatchMapBuilder provides enhanced batch operations for efficient Map construction.
 type BatchMapBuilder[K comparable, V any] struct {
 	m         *Map[K, V]
+	hasher    Hasher[K] // hasher for the map installed by Reset
 	batchSize int
 	buffer    []mapEntry[K, V]
+	order     []uint64               // scratch space for grouped flushes
+	hashed    []mapHashedEntry[K, V] // scratch space for grouped flushes
+
+	deleted    []bool // tombstone flags parallel to buffer; nil until Delete is used
+	tombstones int    // number of tombstones in buffer
+
+	// Len bookkeeping, maintained lazily so that builders which never call Len
+	// do not pay for hashing on every Set.
+	latest  map[uint32][]int // buffer position of the latest operation per key, by hash
+	counted int              // number of buffered operations accounted for in delta
+	delta   int              // change in size from the first counted operations
+
+	onFlush func(*Map[K, V]) // called with a snapshot after each flush
+	owner   *owner           // owner of the nodes flushes may update in place
+
+	hashArrayRoot bool // whether the first flush installs a hash array root
+
+	arena *mapNodeArena[K, V] // node allocator for flushes; nil unless UseNodeArena is enabled
 }
 
 // NewBatchMapBuilder returns a new batch-optimized map builder.
@@ -104,14 +327,53 @@ func NewBatchMapBuilder[K comparable, V any](hasher Hasher[K], batchSize int) *B
 	}
 	return &BatchMapBuilder[K, V]{
 		m:         NewMap[K, V](hasher),
+		hasher:    hasher,
 		batchSize: batchSize,
 		buffer:    make([]mapEntry[K, V], 0, batchSize),
+		owner:     new(owner),
+	}
+}
+
+// NewBatchMapBuilderWithCapacity is like NewBatchMapBuilder but takes the
+// expected number of entries in the final map. Maps expected to outgrow a
+// bitmap indexed root start with a hash array root instead of upgrading to one
+// through the array and bitmap indexed node types. The hint only affects
+// performance; any number of entries may be added.
+func NewBatchMapBuilderWithCapacity[K comparable, V any](hasher Hasher[K], batchSize, expectedSize int) *BatchMapBuilder[K, V] {
+	if batchSize <= 0 {
+		batchSize = 32
+	}
+	return &BatchMapBuilder[K, V]{
+		m:             NewMap[K, V](hasher),
+		hasher:        hasher,
+		batchSize:     batchSize,
+		buffer:        make([]mapEntry[K, V], 0, min(batchSize, max(expectedSize, 0))),
+		hashArrayRoot: expectedSize >= 2*mapNodeSize,
+		owner:         new(owner),
 	}
 }
 
 // Set adds a key/value pair to the batch buffer.
 func (b *BatchMapBuilder[K, V]) Set(key K, value V) {
 	b.buffer = append(b.buffer, mapEntry[K, V]{key: key, value: value})
+	if b.deleted != nil {
+		b.deleted = append(b.deleted, false)
+	}
+	if len(b.buffer) >= b.batchSize {
+		b.Flush()
+	}
+}
+
+// Delete records the removal of key in the batch buffer. Operations on the same
+// key are applied in order on flush, so a later Set of the key wins over an
+// earlier Delete and vice versa.
+func (b *BatchMapBuilder[K, V]) Delete(key K) {
+	if b.deleted == nil {
+		b.deleted = make([]bool, len(b.buffer), cap(b.buffer))
+	}
+	b.buffer = append(b.buffer, mapEntry[K, V]{key: key})
+	b.deleted = append(b.deleted, true)
+	b.tombstones++
 	if len(b.buffer) >= b.batchSize {
 		b.Flush()
 	}
@@ -124,63 +386,65 @@ func (b *BatchMapBuilder[K, V]) SetMap(entries map[K]V) {
 	}
 }
 
+// SetSeq adds all key/value pairs produced by seq.
+func (b *BatchMapBuilder[K, V]) SetSeq(seq iter.Seq2[K, V]) {
+	for k, v := range seq {
+		b.Set(k, v)
+	}
+}
+
 // Flush commits all buffered entries to the underlying map.
 func (b *BatchMapBuilder[K, V]) Flush() {
 	if len(b.buffer) == 0 {
 		return
 	}
 
-	// Fast path: if map is empty, build an array node in one shot with last-write-wins semantics.
-	if b.m.root == nil {
+	// Buffers with tombstones always take the grouped path, which resolves the
+	// final operation for each key before touching the map.
+	if b.tombstones > 0 {
+		b.flushGrouped()
+		b.clearBuffer()
+		b.publish()
+		return
+	}
+
+	// Fast path: if map is empty and the buffer fits in an array node, build it
+	// in one shot with last-write-wins semantics. Larger buffers are inserted
+	// grouped by hash so the result is a properly branched trie.
+	if b.m.root == nil && !b.hashArrayRoot && len(b.buffer) <= maxArrayMapSize {
 		var dedup []mapEntry[K, V]
-		if len(b.buffer) <= maxArrayMapSize {
-			// Tiny buffer: use slice-based last-occurrence dedup without maps.
-			for i := len(b.buffer) - 1; i >= 0; i-- {
-				key := b.buffer[i].key
-				found := false
-				for _, e := range dedup {
-					if b.m.hasher != nil {
-						if b.m.hasher.Equal(e.key, key) {
-							found = true
-							break
-						}
-					} else {
-						if any(e.key) == any(key) {
-							found = true
-							break
-						}
+		// Tiny buffer: use slice-based last-occurrence dedup without maps.
+		for i := len(b.buffer) - 1; i >= 0; i-- {
+			key := b.buffer[i].key
+			found := false
+			for _, e := range dedup {
+				if b.m.hasher != nil {
+					if b.m.hasher.Equal(e.key, key) {
+						found = true
+						break
+					}
+				} else {
+					if any(e.key) == any(key) {
+						found = true
+						break
 					}
 				}
-				if !found {
-					dedup = append(dedup, b.buffer[i])
-				}
-			}
-			// Reverse to restore original order of last occurrences
-			for i, j := 0, len(dedup)-1; i < j; i, j = i+1, j-1 {
-				dedup[i], dedup[j] = dedup[j], dedup[i]
-			}
-		} else {
-			// Larger buffer: map-based dedup
-			seen := make(map[K]struct{}, len(b.buffer))
-			for i := len(b.buffer) - 1; i >= 0; i-- {
-				e := b.buffer[i]
-				if _, ok := seen[e.key]; ok {
-					continue
-				}
-				seen[e.key] = struct{}{}
-				dedup = append(dedup, e)
 			}
-			for i, j := 0, len(dedup)-1; i < j; i, j = i+1, j-1 {
-				dedup[i], dedup[j] = dedup[j], dedup[i]
+			if !found {
+				dedup = append(dedup, b.buffer[i])
 			}
 		}
+		// Reverse to restore original order of last occurrences
+		for i, j := 0, len(dedup)-1; i < j; i, j = i+1, j-1 {
+			dedup[i], dedup[j] = dedup[j], dedup[i]
+		}
 		// Ensure hasher is set for Get operations
 		if b.m.hasher == nil && len(dedup) > 0 {
 			b.m.hasher = NewHasher(dedup[0].key)
 		}
 		// Install as array node
 		b.m.size = len(dedup)
-		b.m.root = &mapArrayNode[K, V]{entries: dedup}
+		b.m.root = &mapArrayNode[K, V]{entries: dedup, owner: b.owner}
 	} else if arr, ok := b.m.root.(*mapArrayNode[K, V]); ok {
 		// Small-structure fast path: stay in array node if total entries remain under threshold.
 		// Build last-write-wins overrides and first-seen order for new keys (slice-based for tiny buffers).
@@ -255,32 +519,176 @@ func (b *BatchMapBuilder[K, V]) Flush() {
 		if newCount <= maxArrayMapSize {
 			newEntries = append(newEntries, toAppend...)
 			b.m.size = newCount
-			b.m.root = &mapArrayNode[K, V]{entries: newEntries}
+			b.m.root = &mapArrayNode[K, V]{entries: newEntries, owner: b.owner}
 		} else {
-			// Fallback: set one-by-one using mutable path
-			for _, e := range b.buffer {
-				b.m = b.m.set(e.key, e.value, true)
-			}
+			// Outgrowing the array node: insert grouped by hash instead.
+			b.flushGrouped()
 		}
 	} else {
-		// Fallback: set one-by-one using mutable path
-		for _, entry := range b.buffer {
-			b.m = b.m.set(entry.key, entry.value, true) // mutable for performance
-		}
+		b.flushGrouped()
 	}
 
-	// Clear buffer (reuse capacity)
-	b.buffer = b.buffer[:0]
+	b.clearBuffer()
+	b.publish()
 }
 
-// Reset clears the builder state while retaining buffer capacity.
-func (b *BatchMapBuilder[K, V]) Reset() {
-	var hasher Hasher[K]
-	if b.m != nil {
-		hasher = b.m.hasher
+// Snapshot flushes the builder and returns the map built so far without
+// invalidating the builder. Returns nil after Map().
+func (b *BatchMapBuilder[K, V]) Snapshot() *Map[K, V] {
+	if b.m == nil {
+		return nil
+	}
+	b.Flush()
+	return b.snapshot()
+}
+
+// snapshot returns a copy of the map that is safe to retain. The builder
+// takes a new owner, so a later flush copies a node reachable from the
+// snapshot the first time it touches it and updates the copy in place after.
+func (b *BatchMapBuilder[K, V]) snapshot() *Map[K, V] {
+	b.owner = new(owner)
+	b.abandonArena()
+	return b.m.clone()
+}
+
+// UseNodeArena enables or disables slab allocation of trie nodes. With the
+// arena enabled, flushes allocate value and hash array nodes a slab at a time
+// instead of individually, which reduces allocations and GC work for large
+// builds. Nodes are only taken from a slab while the builder exclusively owns
+// the map; once the map is published by Map, Snapshot or a flush hook the
+// builder abandons its slabs and never reuses them.
+//
+// A slab stays in memory until none of its nodes are referenced, so maps that
+// are later reduced by many deletions may retain more memory than they would
+// otherwise. The arena is best suited to building large maps that are then
+// kept whole.
+func (b *BatchMapBuilder[K, V]) UseNodeArena(enabled bool) {
+	if !enabled {
+		b.arena = nil
+	} else if b.arena == nil {
+		b.arena = &mapNodeArena[K, V]{}
+	}
+}
+
+// abandonArena replaces the node arena, if enabled, with an empty one so that
+// nodes of a published map never share a slab with nodes created afterwards.
+func (b *BatchMapBuilder[K, V]) abandonArena() {
+	if b.arena != nil {
+		b.arena = &mapNodeArena[K, V]{}
 	}
-	b.m = NewMap[K, V](hasher)
+}
+
+// publish passes a snapshot of the map to the flush hook, if any.
+func (b *BatchMapBuilder[K, V]) publish() {
+	if b.onFlush != nil {
+		b.onFlush(b.snapshot())
+	}
+}
+
+// clearBuffer empties the buffer and its tombstones, retaining capacity.
+func (b *BatchMapBuilder[K, V]) clearBuffer() {
 	b.buffer = b.buffer[:0]
+	if b.deleted != nil {
+		b.deleted = b.deleted[:0]
+	}
+	b.tombstones = 0
+	clear(b.latest)
+	b.counted, b.delta = 0, 0
+}
+
+// flushGrouped commits the buffer, including any tombstones. Each key is hashed
+// once, entries are sorted so that those sharing a path through the trie are
+// adjacent, duplicates are dropped (the last operation on a key wins), and the
+// trie is then updated one shared descent per group rather than one full
+// descent per entry. Deletions are applied after all upserts.
+func (b *BatchMapBuilder[K, V]) flushGrouped() {
+	h := b.m.hasher
+	if h == nil {
+		h = NewHasher(b.buffer[0].key)
+		b.m.hasher = h
+	}
+
+	// Sort pointer-free keys of bit-reversed hash and buffer position; the
+	// position in the low bits keeps equal hashes in insertion order.
+	order := b.order[:0]
+	for i, e := range b.buffer {
+		order = append(order, uint64(bits.Reverse32(h.Hash(e.key)))<<32|uint64(i))
+	}
+	slices.Sort(order)
+
+	// Dedupe within runs of equal hashes, keeping the last occurrence of each key.
+	// Writes never overtake reads since each entry produces at most one output.
+	unique := order[:0]
+	for i := 0; i < len(order); {
+		j := i + 1
+		for j < len(order) && order[j]>>32 == order[i]>>32 {
+			j++
+		}
+		start := len(unique)
+		for k := i; k < j; k++ {
+			o, replaced := order[k], false
+			for m := start; m < len(unique); m++ {
+				if h.Equal(b.buffer[uint32(unique[m])].key, b.buffer[uint32(o)].key) {
+					unique[m], replaced = o, true
+					break
+				}
+			}
+			if !replaced {
+				unique = append(unique, o)
+			}
+		}
+		i = j
+	}
+
+	entries := b.hashed[:0]
+	for _, o := range unique {
+		if i := uint32(o); b.tombstones == 0 || !b.deleted[i] {
+			e := b.buffer[i]
+			entries = append(entries, mapHashedEntry[K, V]{hash: bits.Reverse32(uint32(o >> 32)), key: e.key, value: e.value})
+		}
+	}
+	b.hashed = entries
+	if len(entries) > 0 {
+		if b.m.root == nil && b.hashArrayRoot {
+			b.m.root = &mapHashArrayNode[K, V]{owner: b.owner}
+		} else if b.m.root == nil {
+			e := entries[0]
+			b.m.root = &mapArrayNode[K, V]{entries: []mapEntry[K, V]{{key: e.key, value: e.value}}, owner: b.owner}
+			b.m.size = 1
+			entries = entries[1:]
+		}
+		var added int
+		b.m.root = mapSetGroup(b.m.root, entries, 0, h, b.owner, b.arena, &added)
+		b.m.size += added
+	}
+	if b.tombstones > 0 {
+		for _, o := range unique {
+			if i := uint32(o); b.deleted[i] {
+				b.m = b.m.delete(b.buffer[i].key, b.owner)
+			}
+		}
+	}
+
+	// Retain scratch capacity without holding on to keys and values.
+	b.order = order[:0]
+	clear(b.hashed)
+	b.hashed = b.hashed[:0]
+}
+
+// Reset clears the builder state while retaining buffer capacity. The builder
+// may be reused after Map() once it has been reset.
+func (b *BatchMapBuilder[K, V]) Reset() {
+	b.m = NewMap[K, V](b.hasher)
+	b.owner = new(owner)
+	b.abandonArena()
+	b.clearBuffer()
+}
+
+// TakeAndReset returns the final map and resets the builder for reuse.
+func (b *BatchMapBuilder[K, V]) TakeAndReset() *Map[K, V] {
+	m := b.Map()
+	b.Reset()
+	return m
 }
 
 // Map returns the final map and invalidates the builder.
@@ -288,15 +696,61 @@ func (b *BatchMapBuilder[K, V]) Map() *Map[K, V] {
 	b.Flush()
 	m := b.m
 	b.m = nil
+	b.abandonArena()
 	return m
 }
 
-// Len returns the total number of entries (committed + buffered).
+// Len returns the number of entries the map will hold once buffered entries
+// are flushed. Updates of existing keys and repeated keys are not counted twice.
 func (b *BatchMapBuilder[K, V]) Len() int {
 	if b.m == nil {
 		return 0
 	}
-	return b.m.Len() + len(b.buffer)
+	if b.counted == len(b.buffer) {
+		return b.m.Len() + b.delta
+	}
+
+	h := b.m.hasher
+	if h == nil {
+		h = NewHasher(b.buffer[0].key)
+		b.m.hasher = h
+	}
+	if b.latest == nil {
+		b.latest = make(map[uint32][]int)
+	}
+	for i := b.counted; i < len(b.buffer); i++ {
+		key := b.buffer[i].key
+		hash := h.Hash(key)
+
+		// Determine whether the key is present before this operation: per its
+		// latest buffered operation if there is one, otherwise per the map.
+		var present, found bool
+		positions := b.latest[hash]
+		for j, p := range positions {
+			if h.Equal(b.buffer[p].key, key) {
+				present, found = !b.isDeleted(p), true
+				positions[j] = i
+				break
+			}
+		}
+		if !found {
+			_, present = b.m.Get(key)
+			b.latest[hash] = append(positions, i)
+		}
+
+		if deleted := b.isDeleted(i); present && deleted {
+			b.delta--
+		} else if !present && !deleted {
+			b.delta++
+		}
+	}
+	b.counted = len(b.buffer)
+	return b.m.Len() + b.delta
+}
+
+// isDeleted returns true if the buffered operation at i is a tombstone.
+func (b *BatchMapBuilder[K, V]) isDeleted(i int) bool {
+	return b.tombstones > 0 && b.deleted[i]
 }
 
 // StreamingListBuilder provides streaming operations with configurable flush triggers.
@@ -315,17 +769,66 @@ func NewStreamingListBuilder[T any](batchSize, autoFlushSize int) *StreamingList
 	}
 }
 
+// OnFlush registers fn to be called after each flush with a snapshot of the
+// list built so far. Snapshots are immutable and safe to retain: the builder
+// copies any nodes it shares with a published snapshot before changing them.
+// Passing nil removes the hook.
+func (b *StreamingListBuilder[T]) OnFlush(fn func(snapshot *List[T])) {
+	b.onFlush = fn
+}
+
 // Stream processes values through a streaming pipeline.
-// Automatically flushes when size thresholds are reached.
+// Automatically flushes when size thresholds are reached. After each blocking
+// receive, values already waiting on the channel are drained without blocking,
+// up to one batch at a time, so bursty producers are consumed in batches.
 func (b *StreamingListBuilder[T]) Stream(values <-chan T) {
-	for value := range values {
-		b.Append(value)
+	_ = b.StreamContext(context.Background(), values)
+}
 
-		// Auto-flush when reaching threshold
-		if b.autoFlushEnabled && b.Len() >= b.autoFlushSize {
-			b.Flush()
+// StreamContext is like Stream but stops early and returns ctx.Err() if ctx is
+// done before values is closed. Values received up to that point remain in the
+// builder, which stays valid so the partial list can still be retrieved.
+func (b *StreamingListBuilder[T]) StreamContext(ctx context.Context, values <-chan T) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case value, ok := <-values:
+			if !ok {
+				return nil
+			}
+			b.Append(value)
+			open := b.drain(values)
+
+			// Auto-flush when reaching threshold
+			if b.autoFlushEnabled && b.Len() >= b.autoFlushSize {
+				b.Flush()
+			}
+			if !open {
+				return nil
+			}
+		}
+	}
+}
+
+// drain appends values that are immediately available on the channel, up to
+// one batch. Returns false if the channel was closed.
+func (b *StreamingListBuilder[T]) drain(values <-chan T) bool {
+	for n := 1; n < b.batchSize; n++ {
+		select {
+		case value, ok := <-values:
+			if !ok {
+				return false
+			}
+			b.Append(value)
+		default:
+			return true
 		}
 	}
+	return true
 }
 
 // Filter processes values through a filter function before adding.
@@ -344,95 +847,182 @@ func (b *StreamingListBuilder[T]) Transform(values []T, transformFn func(T) T) {
 	}
 }
 
+// AppendSeq adds all values produced by seq.
+// Automatically flushes when size thresholds are reached.
+func (b *StreamingListBuilder[T]) AppendSeq(seq iter.Seq[T]) {
+	for value := range seq {
+		b.Append(value)
+
+		// Auto-flush when reaching threshold
+		if b.autoFlushEnabled && b.Len() >= b.autoFlushSize {
+			b.Flush()
+		}
+	}
+}
+
 // SortedBatchBuilder provides batch operations optimized for sorted data.
 type SortedBatchBuilder[K cmp.Ordered, V any] struct {
 	sm        *SortedMap[K, V]
+	comparer  Comparer[K]
 	batchSize int
 	buffer    []mapEntry[K, V]
-	sorted    bool // whether buffer is kept sorted
+	sorted    bool             // whether buffer is sorted before flushing
+	unordered bool             // whether buffered keys arrived out of order
+	order     []int            // scratch space for sorting the buffer
+	scratch   []mapEntry[K, V] // scratch space holding the sorted buffer
+	owner     *owner           // owner of the nodes flushes may update in place
 }
 
 // NewSortedBatchBuilder creates a batch builder for sorted maps.
-// If maintainSort is true, entries are kept sorted in the buffer for optimal insertion.
+// If maintainSort is true, buffered entries are sorted before each flush for optimal insertion.
 func NewSortedBatchBuilder[K cmp.Ordered, V any](comparer Comparer[K], batchSize int, maintainSort bool) *SortedBatchBuilder[K, V] {
 	if batchSize <= 0 {
 		batchSize = 32
 	}
 	return &SortedBatchBuilder[K, V]{
 		sm:        NewSortedMap[K, V](comparer),
+		comparer:  comparer,
 		batchSize: batchSize,
 		buffer:    make([]mapEntry[K, V], 0, batchSize),
 		sorted:    maintainSort,
+		owner:     new(owner),
 	}
 }
 
 // Set adds a key/value pair, maintaining sort order if enabled.
+// Entries are appended as-is and sorted once on flush, which is cheaper than
+// positioning each entry on insert.
 func (b *SortedBatchBuilder[K, V]) Set(key K, value V) {
-	entry := mapEntry[K, V]{key: key, value: value}
-
-	if b.sorted && len(b.buffer) > 0 {
-		// Insert in sorted position
-		insertIdx := 0
-		for i, existing := range b.buffer {
-			if defaultCompare(key, existing.key) <= 0 {
-				insertIdx = i
-				break
-			}
-			insertIdx = i + 1
-		}
-
-		// Insert at correct position
-		b.buffer = append(b.buffer, mapEntry[K, V]{})
-		copy(b.buffer[insertIdx+1:], b.buffer[insertIdx:])
-		b.buffer[insertIdx] = entry
-	} else {
-		// Simple append
-		b.buffer = append(b.buffer, entry)
+	assert(b.sm != nil, "immutable.SortedBatchBuilder: builder invalid after SortedMap() invocation")
+	if len(b.buffer) > 0 && b.compare(key, b.buffer[len(b.buffer)-1].key) < 0 {
+		b.unordered = true
 	}
+	b.buffer = append(b.buffer, mapEntry[K, V]{key: key, value: value})
 
 	if len(b.buffer) >= b.batchSize {
 		b.Flush()
 	}
 }
 
+// SetSeq adds all key/value pairs produced by seq.
+func (b *SortedBatchBuilder[K, V]) SetSeq(seq iter.Seq2[K, V]) {
+	for k, v := range seq {
+		b.Set(k, v)
+	}
+}
+
 // Flush commits buffered entries to the sorted map.
+// When the buffered keys all sort after the map's current maximum, as they do
+// for globally sorted input, they are bulk loaded onto the right edge of the
+// tree instead of being inserted one at a time.
 func (b *SortedBatchBuilder[K, V]) Flush() {
+	assert(b.sm != nil, "immutable.SortedBatchBuilder: builder invalid after SortedMap() invocation")
 	if len(b.buffer) == 0 {
 		return
 	}
 
-	// Batch set all buffered entries
-	for _, entry := range b.buffer {
-		b.sm = b.sm.set(entry.key, entry.value, true)
+	entries := b.buffer
+	if b.unordered && b.sorted {
+		// Sort buffer positions rather than entries to avoid moving keys and
+		// values around. Ties fall back to position so the last write wins.
+		order := b.order[:0]
+		for i := range b.buffer {
+			order = append(order, i)
+		}
+		slices.SortFunc(order, func(i, j int) int {
+			if c := b.compare(b.buffer[i].key, b.buffer[j].key); c != 0 {
+				return c
+			}
+			return cmp.Compare(i, j)
+		})
+		entries = b.scratch[:0]
+		for _, i := range order {
+			entries = append(entries, b.buffer[i])
+		}
+		b.order = order[:0]
+		b.scratch = entries
+		b.unordered = false
+	}
+
+	if b.unordered || !b.sm.appendSorted(entries, b.owner) {
+		// Batch set all buffered entries
+		for _, entry := range entries {
+			b.sm = b.sm.set(entry.key, entry.value, b.owner)
+		}
 	}
 
 	// Clear buffer
+	clear(b.scratch)
+	b.scratch = b.scratch[:0]
 	b.buffer = b.buffer[:0]
+	b.unordered = false
 }
 
-// SortedMap returns the final sorted map.
+// compare orders keys using the map's comparer, or the natural order of K if
+// the map has not yet been assigned one.
+func (b *SortedBatchBuilder[K, V]) compare(a, c K) int {
+	if b.sm.comparer == nil {
+		return defaultCompare(a, c)
+	}
+	return b.sm.comparer.Compare(a, c)
+}
+
+// SortedMap returns the final sorted map and invalidates the builder.
 func (b *SortedBatchBuilder[K, V]) SortedMap() *SortedMap[K, V] {
+	assert(b.sm != nil, "immutable.SortedBatchBuilder.SortedMap(): duplicate call to fetch map")
 	b.Flush()
 	sm := b.sm
 	b.sm = nil
 	return sm
 }
 
+// Reset clears the builder state while retaining buffer capacity. The builder
+// may be reused after SortedMap() once it has been reset.
+func (b *SortedBatchBuilder[K, V]) Reset() {
+	b.sm = NewSortedMap[K, V](b.comparer)
+	b.owner = new(owner)
+	clear(b.buffer)
+	b.buffer = b.buffer[:0]
+	b.unordered = false
+}
+
 // BatchSetBuilder provides enhanced batch operations for efficient Set construction.
 type BatchSetBuilder[T comparable] struct {
 	mapBuilder *BatchMapBuilder[T, struct{}]
+	pending    map[uint32][]int // buffer positions by hash, for deduping Adds
 }
 
 // NewBatchSetBuilder returns a new batch-optimized set builder.
 func NewBatchSetBuilder[T comparable](hasher Hasher[T], batchSize int) *BatchSetBuilder[T] {
 	return &BatchSetBuilder[T]{
 		mapBuilder: NewBatchMapBuilder[T, struct{}](hasher, batchSize),
+		pending:    make(map[uint32][]int),
+	}
+}
+
+// NewBatchSetBuilderWithCapacity is like NewBatchSetBuilder but takes the
+// expected number of values in the final set. See
+// NewBatchMapBuilderWithCapacity.
+func NewBatchSetBuilderWithCapacity[T comparable](hasher Hasher[T], batchSize, expectedSize int) *BatchSetBuilder[T] {
+	return &BatchSetBuilder[T]{
+		mapBuilder: NewBatchMapBuilderWithCapacity[T, struct{}](hasher, batchSize, expectedSize),
+		pending:    make(map[uint32][]int),
 	}
 }
 
-// Add inserts a value into the batch buffer.
+// Add inserts a value into the batch buffer. Values already in the set or the
+// buffer are ignored, so the buffer only ever holds new, distinct values.
 func (b *BatchSetBuilder[T]) Add(value T) {
+	assert(b.mapBuilder.m != nil, "immutable.BatchSetBuilder: builder invalid after Set() invocation")
+	if b.Has(value) {
+		return
+	}
+	hash := b.hasher(value).Hash(value)
+	b.pending[hash] = append(b.pending[hash], len(b.mapBuilder.buffer))
 	b.mapBuilder.Set(value, struct{}{})
+	if len(b.mapBuilder.buffer) == 0 {
+		clear(b.pending)
+	}
 }
 
 // AddSlice adds multiple values efficiently.
@@ -442,28 +1032,88 @@ func (b *BatchSetBuilder[T]) AddSlice(values []T) {
 	}
 }
 
+// AddSeq adds all values produced by seq.
+func (b *BatchSetBuilder[T]) AddSeq(seq iter.Seq[T]) {
+	for value := range seq {
+		b.Add(value)
+	}
+}
+
+// Has returns true if value is in the committed set or the batch buffer.
+func (b *BatchSetBuilder[T]) Has(value T) bool {
+	if b.mapBuilder.m == nil {
+		return false
+	}
+	if _, ok := b.mapBuilder.m.Get(value); ok {
+		return true
+	}
+	h := b.hasher(value)
+	for _, i := range b.pending[h.Hash(value)] {
+		if h.Equal(b.mapBuilder.buffer[i].key, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasher returns the hasher of the underlying map, resolving the default
+// hasher from value if none has been set yet.
+func (b *BatchSetBuilder[T]) hasher(value T) Hasher[T] {
+	if b.mapBuilder.m.hasher == nil {
+		b.mapBuilder.m.hasher = NewHasher(value)
+	}
+	return b.mapBuilder.m.hasher
+}
+
 // Flush commits all buffered values to the underlying set.
 func (b *BatchSetBuilder[T]) Flush() {
 	b.mapBuilder.Flush()
+	clear(b.pending)
+}
+
+// UseNodeArena enables or disables slab allocation of trie nodes, as described
+// for BatchMapBuilder.UseNodeArena.
+func (b *BatchSetBuilder[T]) UseNodeArena(enabled bool) {
+	b.mapBuilder.UseNodeArena(enabled)
 }
 
 // Set returns the final set and invalidates the builder.
 func (b *BatchSetBuilder[T]) Set() *Set[T] {
 	m := b.mapBuilder.Map()
+	clear(b.pending)
 	if m == nil {
 		return nil
 	}
 	return &Set[T]{m: m}
 }
 
-// Len returns the total number of elements (committed + buffered).
+// Reset clears the builder state while retaining buffer capacity. The builder
+// may be reused after Set() once it has been reset.
+func (b *BatchSetBuilder[T]) Reset() {
+	b.mapBuilder.Reset()
+	clear(b.pending)
+}
+
+// TakeAndReset returns the final set and resets the builder for reuse.
+func (b *BatchSetBuilder[T]) TakeAndReset() *Set[T] {
+	set := b.Set()
+	b.Reset()
+	return set
+}
+
+// Len returns the number of distinct elements (committed + buffered).
 func (b *BatchSetBuilder[T]) Len() int {
-	return b.mapBuilder.Len()
+	// Add only buffers new, distinct values, so no further dedup is needed.
+	if b.mapBuilder.m == nil {
+		return 0
+	}
+	return b.mapBuilder.m.Len() + len(b.mapBuilder.buffer)
 }
 
 // BatchSortedSetBuilder provides enhanced batch operations for efficient SortedSet construction.
 type BatchSortedSetBuilder[T cmp.Ordered] struct {
 	sortedBuilder *SortedBatchBuilder[T, struct{}]
+	pending       []T // buffered values in comparer order, for deduping Adds
 }
 
 // NewBatchSortedSetBuilder returns a new batch-optimized sorted set builder.
@@ -474,8 +1124,27 @@ func NewBatchSortedSetBuilder[T cmp.Ordered](comparer Comparer[T], batchSize int
 }
 
 // Add inserts a value into the batch buffer, maintaining sort order if enabled.
+// Values the set's comparer considers equal to one already in the set or the
+// buffer are ignored.
 func (b *BatchSortedSetBuilder[T]) Add(value T) {
+	assert(b.sortedBuilder.sm != nil, "immutable.BatchSortedSetBuilder: builder invalid after SortedSet() invocation")
+	i, found := slices.BinarySearchFunc(b.pending, value, b.sortedBuilder.compare)
+	if found {
+		return
+	} else if _, ok := b.sortedBuilder.sm.Get(value); ok {
+		return
+	}
+	b.pending = slices.Insert(b.pending, i, value)
 	b.sortedBuilder.Set(value, struct{}{})
+	if len(b.sortedBuilder.buffer) == 0 {
+		b.clearPending()
+	}
+}
+
+// clearPending empties the pending values, retaining their capacity.
+func (b *BatchSortedSetBuilder[T]) clearPending() {
+	clear(b.pending)
+	b.pending = b.pending[:0]
 }
 
 // AddSlice adds multiple values efficiently.
@@ -485,22 +1154,37 @@ func (b *BatchSortedSetBuilder[T]) AddSlice(values []T) {
 	}
 }
 
+// AddSeq adds all values produced by seq.
+func (b *BatchSortedSetBuilder[T]) AddSeq(seq iter.Seq[T]) {
+	for value := range seq {
+		b.Add(value)
+	}
+}
+
 // Flush commits buffered values to the sorted set.
 func (b *BatchSortedSetBuilder[T]) Flush() {
 	b.sortedBuilder.Flush()
+	b.clearPending()
+}
+
+// Reset clears the builder state while retaining buffer capacity.
+func (b *BatchSortedSetBuilder[T]) Reset() {
+	b.sortedBuilder.Reset()
+	b.clearPending()
 }
 
 // SortedSet returns the final sorted set.
 func (b *BatchSortedSetBuilder[T]) SortedSet() *SortedSet[T] {
 	sm := b.sortedBuilder.SortedMap()
-	if sm == nil {
-		return nil
-	}
+	b.clearPending()
 	return &SortedSet[T]{m: sm}
 }
 
-// Len returns the total number of elements (committed + buffered).
+// Len returns the number of distinct elements (committed + buffered).
 func (b *BatchSortedSetBuilder[T]) Len() int {
+	if b.sortedBuilder.sm == nil {
+		return 0
+	}
 	return b.sortedBuilder.sm.Len() + len(b.sortedBuilder.buffer)
 }
 
@@ -520,31 +1204,80 @@ func NewStreamingMapBuilder[K comparable, V any](hasher Hasher[K], batchSize, au
 	}
 }
 
-// Stream processes key/value pairs through a streaming pipeline.
-func (b *StreamingMapBuilder[K, V]) Stream(entries <-chan mapEntry[K, V]) {
-	for entry := range entries {
-		b.Set(entry.key, entry.value)
+// OnFlush registers fn to be called after each flush with a snapshot of the
+// map built so far. Snapshots are immutable and safe to retain: once a
+// snapshot has been published, flushes copy the nodes they touch instead of
+// updating them in place. Passing nil removes the hook.
+func (b *StreamingMapBuilder[K, V]) OnFlush(fn func(snapshot *Map[K, V])) {
+	b.onFlush = fn
+}
+
+// Stream processes key/value pairs through a streaming pipeline. Like
+// StreamingListBuilder.Stream, entries already waiting on the channel are
+// drained in batches after each blocking receive.
+func (b *StreamingMapBuilder[K, V]) Stream(entries <-chan MapEntry[K, V]) {
+	_ = b.StreamContext(context.Background(), entries)
+}
 
-		// Auto-flush when reaching threshold
-		if b.autoFlushEnabled && b.Len() >= b.autoFlushSize {
-			b.Flush()
+// StreamContext is like Stream but stops early and returns ctx.Err() if ctx is
+// done before entries is closed. Entries received up to that point remain in
+// the builder, which stays valid so the partial map can still be retrieved.
+func (b *StreamingMapBuilder[K, V]) StreamContext(ctx context.Context, entries <-chan MapEntry[K, V]) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case entry, ok := <-entries:
+			if !ok {
+				return nil
+			}
+			b.Set(entry.Key, entry.Value)
+			open := b.drain(entries)
+
+			// Auto-flush when reaching threshold
+			if b.autoFlushEnabled && b.Len() >= b.autoFlushSize {
+				b.Flush()
+			}
+			if !open {
+				return nil
+			}
+		}
+	}
+}
+
+// drain sets entries that are immediately available on the channel, up to one
+// batch. Returns false if the channel was closed.
+func (b *StreamingMapBuilder[K, V]) drain(entries <-chan MapEntry[K, V]) bool {
+	for n := 1; n < b.batchSize; n++ {
+		select {
+		case entry, ok := <-entries:
+			if !ok {
+				return false
+			}
+			b.Set(entry.Key, entry.Value)
+		default:
+			return true
 		}
 	}
+	return true
 }
 
 // Filter processes entries through a filter function before adding.
-func (b *StreamingMapBuilder[K, V]) Filter(entries []mapEntry[K, V], filterFn func(K, V) bool) {
+func (b *StreamingMapBuilder[K, V]) Filter(entries []MapEntry[K, V], filterFn func(K, V) bool) {
 	for _, entry := range entries {
-		if filterFn(entry.key, entry.value) {
-			b.Set(entry.key, entry.value)
+		if filterFn(entry.Key, entry.Value) {
+			b.Set(entry.Key, entry.Value)
 		}
 	}
 }
 
 // Transform processes entries through a transformation function.
-func (b *StreamingMapBuilder[K, V]) Transform(entries []mapEntry[K, V], transformFn func(K, V) (K, V)) {
+func (b *StreamingMapBuilder[K, V]) Transform(entries []MapEntry[K, V], transformFn func(K, V) (K, V)) {
 	for _, entry := range entries {
-		newKey, newValue := transformFn(entry.key, entry.value)
+		newKey, newValue := transformFn(entry.Key, entry.Value)
 		b.Set(newKey, newValue)
 	}
 }
@@ -560,3 +1293,246 @@ func (b *StreamingMapBuilder[K, V]) SetMany(entries map[K]V) {
 		}
 	}
 }
+
+// SetSeq adds all key/value pairs produced by seq.
+// Automatically flushes when size thresholds are reached.
+func (b *StreamingMapBuilder[K, V]) SetSeq(seq iter.Seq2[K, V]) {
+	for key, value := range seq {
+		b.Set(key, value)
+
+		// Auto-flush when reaching threshold
+		if b.autoFlushEnabled && b.Len() >= b.autoFlushSize {
+			b.Flush()
+		}
+	}
+}
+
+// ConcurrentListBuilder wraps a BatchListBuilder for use by multiple goroutines.
+// Values appended by a single goroutine keep their relative order; values from
+// different goroutines are interleaved in the order the appends acquire the
+// builder.
+type ConcurrentListBuilder[T any] struct {
+	mu      sync.Mutex
+	builder *BatchListBuilder[T]
+	list    *List[T] // final list, set on Close
+}
+
+// NewConcurrentListBuilder returns a new list builder that is safe for concurrent use.
+func NewConcurrentListBuilder[T any](batchSize int) *ConcurrentListBuilder[T] {
+	return &ConcurrentListBuilder[T]{builder: NewBatchListBuilder[T](batchSize)}
+}
+
+// Append adds value to the end of the list.
+func (b *ConcurrentListBuilder[T]) Append(value T) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	assert(b.builder != nil, "immutable.ConcurrentListBuilder.Append: builder invalid after Close() invocation")
+	b.builder.Append(value)
+}
+
+// AppendSlice adds values to the end of the list. The values are kept
+// contiguous, without values from other goroutines between them.
+func (b *ConcurrentListBuilder[T]) AppendSlice(values []T) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	assert(b.builder != nil, "immutable.ConcurrentListBuilder.AppendSlice: builder invalid after Close() invocation")
+	b.builder.AppendSlice(values)
+}
+
+// Flush commits all buffered values to the underlying list.
+func (b *ConcurrentListBuilder[T]) Flush() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	assert(b.builder != nil, "immutable.ConcurrentListBuilder.Flush: builder invalid after Close() invocation")
+	b.builder.Flush()
+}
+
+// Close finalizes the list. Appending after Close panics. Calling Close more
+// than once has no effect.
+func (b *ConcurrentListBuilder[T]) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.close()
+}
+
+func (b *ConcurrentListBuilder[T]) close() {
+	if b.builder != nil {
+		b.list = b.builder.List()
+		b.builder = nil
+	}
+}
+
+// List closes the builder and returns the final list.
+func (b *ConcurrentListBuilder[T]) List() *List[T] {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.close()
+	return b.list
+}
+
+// concurrentMapShards is the number of independently locked builders used by
+// ConcurrentMapBuilder. Must be a power of two.
+const concurrentMapShards = 16
+
+// ConcurrentMapBuilder builds a Map from multiple goroutines. Keys are spread
+// by hash over independently locked shards, so producers writing different keys
+// rarely contend, and the shards are merged when the builder is closed. Since
+// every key maps to a single shard, the last Set of a key wins as usual.
+type ConcurrentMapBuilder[K comparable, V any] struct {
+	hasher atomic.Pointer[Hasher[K]]
+	shards [concurrentMapShards]concurrentMapShard[K, V]
+
+	mu sync.Mutex // guards m
+	m  *Map[K, V] // final map, set on Close
+}
+
+// concurrentMapShard is a lockable batch builder holding a subset of keys.
+type concurrentMapShard[K comparable, V any] struct {
+	mu      sync.Mutex
+	builder *BatchMapBuilder[K, V]
+}
+
+// NewConcurrentMapBuilder returns a new map builder that is safe for concurrent use.
+func NewConcurrentMapBuilder[K comparable, V any](hasher Hasher[K], batchSize int) *ConcurrentMapBuilder[K, V] {
+	b := &ConcurrentMapBuilder[K, V]{}
+	if hasher != nil {
+		b.hasher.Store(&hasher)
+	}
+	for i := range b.shards {
+		b.shards[i].builder = NewBatchMapBuilder[K, V](hasher, batchSize)
+	}
+	return b
+}
+
+// shard returns the shard holding key.
+func (b *ConcurrentMapBuilder[K, V]) shard(key K) *concurrentMapShard[K, V] {
+	h := b.hasher.Load()
+	if h == nil {
+		// Default hashers are stateless, so racing initializations agree.
+		hasher := NewHasher(key)
+		b.hasher.CompareAndSwap(nil, &hasher)
+		h = b.hasher.Load()
+	}
+	return &b.shards[(*h).Hash(key)&(concurrentMapShards-1)]
+}
+
+// Set sets the value for the given key.
+func (b *ConcurrentMapBuilder[K, V]) Set(key K, value V) {
+	s := b.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	assert(s.builder != nil, "immutable.ConcurrentMapBuilder.Set: builder invalid after Close() invocation")
+	s.builder.Set(key, value)
+}
+
+// Delete removes the given key.
+func (b *ConcurrentMapBuilder[K, V]) Delete(key K) {
+	s := b.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	assert(s.builder != nil, "immutable.ConcurrentMapBuilder.Delete: builder invalid after Close() invocation")
+	s.builder.Delete(key)
+}
+
+// Flush commits all buffered entries of every shard.
+func (b *ConcurrentMapBuilder[K, V]) Flush() {
+	for i := range b.shards {
+		s := &b.shards[i]
+		s.mu.Lock()
+		assert(s.builder != nil, "immutable.ConcurrentMapBuilder.Flush: builder invalid after Close() invocation")
+		s.builder.Flush()
+		s.mu.Unlock()
+	}
+}
+
+// Close finalizes the map by merging all shards. Setting after Close panics.
+// Calling Close more than once has no effect.
+func (b *ConcurrentMapBuilder[K, V]) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.close()
+}
+
+func (b *ConcurrentMapBuilder[K, V]) close() {
+	if b.m != nil {
+		return
+	}
+
+	// Detach every shard so no further writes can land after the merge.
+	var builders []*BatchMapBuilder[K, V]
+	for i := range b.shards {
+		s := &b.shards[i]
+		s.mu.Lock()
+		builders = append(builders, s.builder)
+		s.builder = nil
+		s.mu.Unlock()
+	}
+
+	// Shards hold disjoint keys, so the smaller shards are inserted into the
+	// largest one through its grouped flush path.
+	slices.SortFunc(builders, func(x, y *BatchMapBuilder[K, V]) int { return cmp.Compare(y.Len(), x.Len()) })
+	dst := builders[0]
+	for _, src := range builders[1:] {
+		m := src.Map()
+		itr := m.Iterator()
+		for !itr.Done() {
+			k, v, _ := itr.Next()
+			dst.Set(k, v)
+		}
+	}
+	b.m = dst.Map()
+}
+
+// Map closes the builder and returns the final map.
+func (b *ConcurrentMapBuilder[K, V]) Map() *Map[K, V] {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.close()
+	return b.m
+}
+
+// collectBatchSize is the batch size used by the Collect constructors.
+const collectBatchSize = 64
+
+// CollectList returns a list of the values produced by seq, in order.
+func CollectList[T any](seq iter.Seq[T]) *List[T] {
+	b := NewBatchListBuilder[T](collectBatchSize)
+	b.AppendSeq(seq)
+	return b.List()
+}
+
+// CollectMap returns a map of the key/value pairs produced by seq. If a key is
+// produced more than once, the last value wins. If hasher is nil, a default
+// hasher is used for the key type. To convert from any ReadonlyMap, pass its
+// Range method as seq.
+func CollectMap[K comparable, V any](hasher Hasher[K], seq iter.Seq2[K, V]) *Map[K, V] {
+	b := NewBatchMapBuilder[K, V](hasher, collectBatchSize)
+	b.SetSeq(seq)
+	return b.Map()
+}
+
+// CollectSet returns a set of the values produced by seq. If hasher is nil, a
+// default hasher is used for the value type.
+func CollectSet[T comparable](hasher Hasher[T], seq iter.Seq[T]) Set[T] {
+	b := NewBatchSetBuilder[T](hasher, collectBatchSize)
+	b.AddSeq(seq)
+	return *b.Set()
+}
+
+// CollectSortedMap returns a sorted map of the key/value pairs produced by seq.
+// If a key is produced more than once, the last value wins. Keys produced in
+// ascending order are bulk loaded. If comparer is nil, a default comparer is
+// used for the key type. As with CollectMap, seq may be a ReadonlyMap's Range.
+func CollectSortedMap[K cmp.Ordered, V any](comparer Comparer[K], seq iter.Seq2[K, V]) *SortedMap[K, V] {
+	b := NewSortedBatchBuilder[K, V](comparer, collectBatchSize, true)
+	b.SetSeq(seq)
+	return b.SortedMap()
+}
+
+// CollectSortedSet returns a sorted set of the values produced by seq. If
+// comparer is nil, a default comparer is used for the value type.
+func CollectSortedSet[T cmp.Ordered](comparer Comparer[T], seq iter.Seq[T]) SortedSet[T] {
+	b := NewBatchSortedSetBuilder[T](comparer, collectBatchSize, true)
+	b.AddSeq(seq)
+	return *b.SortedSet()
+}
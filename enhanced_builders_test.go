@@ -1,12 +1,58 @@
 package immutable
 
 import (
+	"cmp"
+	"context"
+	"errors"
 	"fmt"
+	"iter"
+	"maps"
+	"math"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
 )
 
 // TestBatchListBuilder tests batch list construction
 func TestBatchListBuilder(t *testing.T) {
+	t.Run("From", func(t *testing.T) {
+		for _, n := range []int{0, 10, 5000} {
+			seed := NewList[int]()
+			for i := 0; i < n; i++ {
+				seed = seed.Append(i)
+			}
+			values, repr := slices.Collect(listValues(seed)), seed.GoString()
+
+			builder := NewBatchListBuilderFrom(seed, 16)
+			model := slices.Clone(values)
+			for i := 0; i < 1000; i++ {
+				builder.Append(n + i)
+				model = append(model, n+i)
+				if i%3 == 0 {
+					builder.Prepend(-i - 1)
+					model = append([]int{-i - 1}, model...)
+				}
+				if i%250 == 0 {
+					if got := slices.Collect(listValues(builder.Snapshot())); !slices.Equal(got, model) {
+						t.Fatalf("n=%d: unexpected snapshot after %d values", n, i)
+					}
+				}
+			}
+
+			if got := slices.Collect(listValues(builder.List())); !slices.Equal(got, model) {
+				t.Fatalf("n=%d: unexpected list: %v", n, got)
+			}
+			if got := slices.Collect(listValues(seed)); !slices.Equal(got, values) || seed.GoString() != repr {
+				t.Fatalf("n=%d: seed modified: %s", n, seed.GoString())
+			}
+		}
+
+		if builder := NewBatchListBuilderFrom[int](nil, 0); builder.Len() != 0 {
+			t.Fatalf("unexpected len: %d", builder.Len())
+		}
+	})
+
 	t.Run("BasicOperations", func(t *testing.T) {
 		builder := NewBatchListBuilder[int](3) // Small batch size for testing
 
@@ -59,6 +105,127 @@ func TestBatchListBuilder(t *testing.T) {
 		}
 	})
 
+	t.Run("Prepend", func(t *testing.T) {
+		builder := NewBatchListBuilder[int](3)
+		for i := 0; i < 100; i++ {
+			builder.Prepend(i)
+		}
+		if got := builder.Len(); got != 100 {
+			t.Errorf("Expected length 100, got %d", got)
+		}
+
+		list := builder.List()
+		if list.Len() != 100 {
+			t.Fatalf("Expected final list length 100, got %d", list.Len())
+		}
+		for i := 0; i < 100; i++ {
+			if got := list.Get(i); got != 99-i {
+				t.Errorf("Expected list[%d] = %d, got %d", i, 99-i, got)
+			}
+		}
+	})
+
+	t.Run("PrependSlice", func(t *testing.T) {
+		builder := NewBatchListBuilder[string](2)
+		builder.Append("d")
+		builder.PrependSlice([]string{"a", "b", "c"})
+		builder.Append("e")
+		list := builder.List()
+
+		expected := []string{"a", "b", "c", "d", "e"}
+		if list.Len() != len(expected) {
+			t.Fatalf("Expected length %d, got %d", len(expected), list.Len())
+		}
+		for i, want := range expected {
+			if got := list.Get(i); got != want {
+				t.Errorf("Expected list[%d] = %s, got %s", i, want, got)
+			}
+		}
+	})
+
+	t.Run("InterleavedPrependAppend", func(t *testing.T) {
+		// Batch sizes are chosen so flushes land between prepends and appends,
+		// and sizes cross the slice/trie threshold from both ends.
+		for _, batchSize := range []int{1, 2, 3, 7, 32, 64} {
+			for _, n := range []int{10, 33, 100, 2000} {
+				builder := NewBatchListBuilder[int](batchSize)
+				var expected []int
+				for i := 0; i < n; i++ {
+					switch i % 5 {
+					case 0, 3:
+						builder.Prepend(i)
+						expected = append([]int{i}, expected...)
+					case 4:
+						builder.PrependSlice([]int{-i, i})
+						expected = append([]int{-i, i}, expected...)
+					default:
+						builder.Append(i)
+						expected = append(expected, i)
+					}
+					if i%11 == 0 {
+						builder.Flush()
+					}
+				}
+				if got := builder.Len(); got != len(expected) {
+					t.Fatalf("batch=%d n=%d: expected Len %d, got %d", batchSize, n, len(expected), got)
+				}
+
+				list := builder.List()
+				if list.Len() != len(expected) {
+					t.Fatalf("batch=%d n=%d: expected length %d, got %d", batchSize, n, len(expected), list.Len())
+				}
+				for i, want := range expected {
+					if got := list.Get(i); got != want {
+						t.Fatalf("batch=%d n=%d: expected list[%d] = %d, got %d", batchSize, n, i, want, got)
+					}
+				}
+				itr := list.Iterator()
+				for !itr.Done() {
+					i, v := itr.Next()
+					if v != expected[i] {
+						t.Fatalf("batch=%d n=%d: iterator expected list[%d] = %d, got %d", batchSize, n, i, expected[i], v)
+					}
+				}
+			}
+		}
+	})
+
+	t.Run("FlushCrossesThreshold", func(t *testing.T) {
+		// Batch sizes that do not divide listSliceThreshold or listNodeSize force
+		// flushes that straddle the slice/trie switch and partially-filled leaves.
+		for _, batchSize := range []int{5, 20, 31, 33, 50, 1000} {
+			for _, n := range []int{31, 32, 33, 64, 65, 1025, 5000} {
+				builder := NewBatchListBuilder[int](batchSize)
+				for i := 0; i < n; i++ {
+					builder.Append(i)
+				}
+				list := builder.List()
+				if list.Len() != n {
+					t.Fatalf("batch=%d n=%d: expected length %d, got %d", batchSize, n, n, list.Len())
+				}
+				for i := 0; i < n; i++ {
+					if got := list.Get(i); got != i {
+						t.Fatalf("batch=%d n=%d: expected list[%d] = %d, got %d", batchSize, n, i, i, got)
+					}
+				}
+				itr := list.Iterator()
+				for !itr.Done() {
+					if i, v := itr.Next(); v != i {
+						t.Fatalf("batch=%d n=%d: iterator expected list[%d] = %d, got %d", batchSize, n, i, i, v)
+					}
+				}
+				// The built list must keep behaving like a regular list.
+				other := list.Append(n).Prepend(-1)
+				if other.Get(0) != -1 || other.Get(n+1) != n || other.Get(n/2+1) != n/2 {
+					t.Fatalf("batch=%d n=%d: unexpected values after Append/Prepend", batchSize, n)
+				}
+				if list.Len() != n {
+					t.Fatalf("batch=%d n=%d: built list modified by later Append", batchSize, n)
+				}
+			}
+		}
+	})
+
 	t.Run("EmptyBuilder", func(t *testing.T) {
 		builder := NewBatchListBuilder[int](10)
 		list := builder.List()
@@ -67,6 +234,91 @@ func TestBatchListBuilder(t *testing.T) {
 			t.Errorf("Expected empty list, got length %d", list.Len())
 		}
 	})
+
+	t.Run("GetAndIterator", func(t *testing.T) {
+		for _, batchSize := range []int{1, 3, 32, 100} {
+			builder := NewBatchListBuilder[int](batchSize)
+			var model []int
+			for i := 0; i < 300; i++ {
+				if i%4 == 0 {
+					builder.Prepend(i)
+					model = append([]int{i}, model...)
+				} else {
+					builder.Append(i)
+					model = append(model, i)
+				}
+
+				for j, want := range model {
+					if got := builder.Get(j); got != want {
+						t.Fatalf("batch=%d, i=%d: expected Get(%d) = %d, got %d", batchSize, i, j, want, got)
+					}
+				}
+				var got []int
+				for itr := builder.Iterator(); !itr.Done(); {
+					index, value := itr.Next()
+					if index != len(got) {
+						t.Fatalf("batch=%d, i=%d: expected index %d, got %d", batchSize, i, len(got), index)
+					}
+					got = append(got, value)
+				}
+				if !slices.Equal(got, model) {
+					t.Fatalf("batch=%d, i=%d: Iterator: expected %v, got %v", batchSize, i, model, got)
+				}
+				got = got[:0]
+				builder.Range(func(index int, value int) bool {
+					got = append(got, value)
+					return index < i/2
+				})
+				if want := model[:i/2+1]; !slices.Equal(got, want) {
+					t.Fatalf("batch=%d, i=%d: Range: expected %v, got %v", batchSize, i, want, got)
+				}
+			}
+			if list := builder.List(); !slices.Equal(slices.Collect(listValues(list)), model) {
+				t.Fatalf("batch=%d: unexpected final list", batchSize)
+			}
+		}
+	})
+
+	t.Run("IteratorSnapshot", func(t *testing.T) {
+		builder := NewBatchListBuilder[int](16)
+		for i := 0; i < 1000; i++ {
+			builder.Append(i)
+		}
+		builder.Prepend(-1)
+
+		// Mutate the builder while iterating.
+		var got []int
+		builder.Range(func(index int, value int) bool {
+			got = append(got, value)
+			builder.Append(1000 + index)
+			builder.Prepend(-2 - index)
+			return true
+		})
+		if len(got) != 1001 || got[0] != -1 {
+			t.Fatalf("Expected 1001 values starting at -1, got %d", len(got))
+		}
+		for i, v := range got[1:] {
+			if v != i {
+				t.Fatalf("Expected value %d at index %d, got %d", i, i+1, v)
+			}
+		}
+		if list := builder.List(); list.Len() != 3003 || list.Get(1002) != 0 {
+			t.Fatalf("Unexpected final list length %d", list.Len())
+		}
+	})
+
+	t.Run("GetOutOfRange", func(t *testing.T) {
+		var r string
+		func() {
+			defer func() { r = recover().(string) }()
+			builder := NewBatchListBuilder[int](4)
+			builder.AppendSlice([]int{1, 2, 3, 4, 5})
+			builder.Get(5)
+		}()
+		if r != `immutable.BatchListBuilder.Get: index 5 out of bounds` {
+			t.Fatalf("unexpected panic: %q", r)
+		}
+	})
 }
 
 // TestBatchMapBuilder tests batch map construction
@@ -116,6 +368,221 @@ func TestBatchMapBuilder(t *testing.T) {
 			}
 		}
 	})
+	t.Run("GroupedFlush", func(t *testing.T) {
+		// Limited hashes force collisions and shared trie paths; keys repeat
+		// within and across batches to exercise last-write-wins dedup.
+		hashers := map[string]Hasher[int]{
+			"Default": nil,
+			"Limited": &mockHasher[int]{
+				hash:  func(v int) uint32 { return uint32(v % 97) },
+				equal: func(a, b int) bool { return a == b },
+			},
+			"Spread": &mockHasher[int]{
+				hash:  func(v int) uint32 { return uint32(v) * 2654435761 },
+				equal: func(a, b int) bool { return a == b },
+			},
+		}
+		for name, hasher := range hashers {
+			for _, batchSize := range []int{1, 7, 64, 500} {
+				t.Run(fmt.Sprintf("%s/Batch%d", name, batchSize), func(t *testing.T) {
+					builder := NewBatchMapBuilder[int, int](hasher, batchSize)
+					expected := make(map[int]int)
+					for i := 0; i < 5000; i++ {
+						key := (i * 7919) % 3001
+						builder.Set(key, i)
+						expected[key] = i
+					}
+					m := builder.Map()
+					if m.Len() != len(expected) {
+						t.Fatalf("Expected length %d, got %d", len(expected), m.Len())
+					}
+					for key, want := range expected {
+						if got, ok := m.Get(key); !ok || got != want {
+							t.Fatalf("Expected m[%d] = %d, got %d (exists: %v)", key, want, got, ok)
+						}
+					}
+					seen := 0
+					itr := m.Iterator()
+					for !itr.Done() {
+						key, value, _ := itr.Next()
+						if expected[key] != value {
+							t.Fatalf("Iterator returned m[%d] = %d, expected %d", key, value, expected[key])
+						}
+						seen++
+					}
+					if seen != len(expected) {
+						t.Fatalf("Iterator returned %d entries, expected %d", seen, len(expected))
+					}
+
+					// The built map must keep behaving like a regular map.
+					other := m.Set(-1, -1).Delete(0)
+					if _, ok := other.Get(0); ok {
+						t.Fatal("Expected key 0 to be deleted")
+					}
+					if v, ok := m.Get(0); !ok || v != expected[0] {
+						t.Fatal("Built map modified by later Set/Delete")
+					}
+				})
+			}
+		}
+	})
+	t.Run("BranchRoots", func(t *testing.T) {
+		// Identity hashes put keys 0-31 in distinct root slots.
+		hasher := &mockHasher[int]{
+			hash:  func(v int) uint32 { return uint32(v) },
+			equal: func(a, b int) bool { return a == b },
+		}
+
+		// A single large flush into an empty map must build a trie rather
+		// than an oversized array node.
+		builder := NewBatchMapBuilder[int, int](hasher, 100)
+		for i := 0; i < 20; i++ {
+			builder.Set(i, i)
+		}
+		builder.Flush()
+		if _, ok := builder.m.root.(*mapBitmapIndexedNode[int, int]); !ok {
+			t.Fatalf("Expected bitmap indexed root, got %T", builder.m.root)
+		}
+
+		// Flush new slots, shared slots and updates into the bitmap root,
+		// pushing it over the limit for bitmap indexed nodes.
+		for i := 10; i < 30; i++ {
+			builder.Set(i, -i)
+			builder.Set(i+32, i) // same root slot as i
+		}
+		m := builder.Map()
+		if _, ok := m.root.(*mapHashArrayNode[int, int]); !ok {
+			t.Fatalf("Expected hash array root, got %T", m.root)
+		}
+		if m.Len() != 50 {
+			t.Fatalf("Expected length 50, got %d", m.Len())
+		}
+		for i := 0; i < 30; i++ {
+			want := i
+			if i >= 10 {
+				want = -i
+			}
+			if v, ok := m.Get(i); !ok || v != want {
+				t.Fatalf("Expected m[%d] = %d, got %d (exists: %v)", i, want, v, ok)
+			}
+		}
+		for i := 42; i < 62; i++ {
+			if v, ok := m.Get(i); !ok || v != i-32 {
+				t.Fatalf("Expected m[%d] = %d, got %d (exists: %v)", i, i-32, v, ok)
+			}
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		builder := NewBatchMapBuilder[int, string](nil, 100)
+		builder.Set(1, "one")
+		builder.Set(2, "two")
+		builder.Set(3, "three")
+		builder.Flush()
+
+		builder.Delete(1) // committed key only
+		if got := builder.Len(); got != 2 {
+			t.Errorf("Expected length 2, got %d", got)
+		}
+
+		builder.Delete(4) // delete then set
+		builder.Set(4, "four")
+		builder.Set(5, "five") // set then delete
+		builder.Delete(5)
+		builder.Delete(6) // missing key
+
+		m := builder.Map()
+		expected := map[int]string{2: "two", 3: "three", 4: "four"}
+		if m.Len() != len(expected) {
+			t.Fatalf("Expected final map length %d, got %d", len(expected), m.Len())
+		}
+		for key, want := range expected {
+			if got, ok := m.Get(key); !ok || got != want {
+				t.Errorf("Expected m[%d] = %s, got %s (exists: %v)", key, want, got, ok)
+			}
+		}
+		for _, key := range []int{1, 5, 6} {
+			if _, ok := m.Get(key); ok {
+				t.Errorf("Expected key %d to be deleted", key)
+			}
+		}
+	})
+
+	t.Run("LenExact", func(t *testing.T) {
+		// Limited hashes force collisions in the Len bookkeeping.
+		hasher := &mockHasher[int]{
+			hash:  func(v int) uint32 { return uint32(v % 13) },
+			equal: func(a, b int) bool { return a == b },
+		}
+		for _, batchSize := range []int{1, 5, 32, 1000} {
+			builder := NewBatchMapBuilder[int, int](hasher, batchSize)
+			model := make(map[int]int)
+			for i := 0; i < 2000; i++ {
+				key := (i * 37) % 101
+				if i%7 == 3 {
+					builder.Delete(key)
+					delete(model, key)
+				} else {
+					builder.Set(key, i)
+					model[key] = i
+				}
+				// Only check every few steps so that Len is also exercised
+				// over several uncounted operations at once.
+				if i%3 == 0 {
+					if got := builder.Len(); got != len(model) {
+						t.Fatalf("batch=%d i=%d: expected Len %d, got %d", batchSize, i, len(model), got)
+					}
+				}
+			}
+			if got := builder.Len(); got != len(model) {
+				t.Fatalf("batch=%d: expected Len %d, got %d", batchSize, len(model), got)
+			}
+			if m := builder.Map(); m.Len() != len(model) {
+				t.Fatalf("batch=%d: expected map length %d, got %d", batchSize, len(model), m.Len())
+			}
+		}
+	})
+
+	t.Run("StreamingLenExact", func(t *testing.T) {
+		builder := NewStreamingMapBuilder[string, int](nil, 4, 8)
+		for i := 0; i < 50; i++ {
+			builder.Set("a", i)
+			builder.Set(fmt.Sprint(i%5), i)
+			if want := 1 + min(i+1, 5); builder.Len() != want {
+				t.Fatalf("i=%d: expected Len %d, got %d", i, want, builder.Len())
+			}
+		}
+		if m := builder.Map(); m.Len() != 6 {
+			t.Fatalf("Expected map length 6, got %d", m.Len())
+		}
+	})
+
+	t.Run("DeleteMixed", func(t *testing.T) {
+		for _, batchSize := range []int{1, 7, 64, 500} {
+			builder := NewBatchMapBuilder[int, int](nil, batchSize)
+			expected := make(map[int]int)
+			for i := 0; i < 5000; i++ {
+				key := (i * 7919) % 1001
+				if i%3 == 0 {
+					builder.Delete(key)
+					delete(expected, key)
+				} else {
+					builder.Set(key, i)
+					expected[key] = i
+				}
+			}
+			m := builder.Map()
+			if m.Len() != len(expected) {
+				t.Fatalf("batch=%d: expected length %d, got %d", batchSize, len(expected), m.Len())
+			}
+			for key := 0; key < 1001; key++ {
+				want, wantOK := expected[key]
+				if got, ok := m.Get(key); ok != wantOK || got != want {
+					t.Fatalf("batch=%d: expected m[%d] = %d (%v), got %d (%v)", batchSize, key, want, wantOK, got, ok)
+				}
+			}
+		}
+	})
 }
 
 // TestBatchSetBuilder tests batch set construction
@@ -130,8 +597,9 @@ func TestBatchSetBuilder(t *testing.T) {
 		builder.Add(4)
 		builder.Add(2) // Duplicate should be ignored
 
-		// Note: Len() might include duplicates in buffer before final flush
-		// The important test is the final set length
+		if got := builder.Len(); got != 4 {
+			t.Errorf("Expected length 4, got %d", got)
+		}
 
 		// Get final set
 		set := builder.Set()
@@ -167,6 +635,63 @@ func TestBatchSetBuilder(t *testing.T) {
 			}
 		}
 	})
+
+	t.Run("Has", func(t *testing.T) {
+		builder := NewBatchSetBuilder[int](nil, 4)
+		builder.AddSlice([]int{1, 2, 3, 4, 5}) // 1-4 committed, 5 buffered
+
+		for _, value := range []int{1, 4, 5} {
+			if !builder.Has(value) {
+				t.Errorf("Expected builder to contain %d", value)
+			}
+		}
+		if builder.Has(6) {
+			t.Error("Expected builder not to contain 6")
+		}
+	})
+
+	t.Run("LenDedup", func(t *testing.T) {
+		// Limited hashes force collisions in the buffer index.
+		hasher := &mockHasher[int]{
+			hash:  func(v int) uint32 { return uint32(v % 7) },
+			equal: func(a, b int) bool { return a == b },
+		}
+		for _, batchSize := range []int{1, 3, 16, 1000} {
+			builder := NewBatchSetBuilder[int](hasher, batchSize)
+			seen := make(map[int]struct{})
+			for i := 0; i < 500; i++ {
+				value := (i * 31) % 101
+				builder.Add(value)
+				seen[value] = struct{}{}
+				if got := builder.Len(); got != len(seen) {
+					t.Fatalf("batch=%d i=%d: expected Len %d, got %d", batchSize, i, len(seen), got)
+				}
+			}
+			set := builder.Set()
+			if set.Len() != len(seen) {
+				t.Fatalf("batch=%d: expected set length %d, got %d", batchSize, len(seen), set.Len())
+			}
+			for value := range seen {
+				if !set.Has(value) {
+					t.Fatalf("batch=%d: expected set to contain %d", batchSize, value)
+				}
+			}
+		}
+	})
+
+	t.Run("AddAfterSet", func(t *testing.T) {
+		var r string
+		func() {
+			defer func() { r = recover().(string) }()
+			builder := NewBatchSetBuilder[int](nil, 4)
+			builder.Add(1)
+			builder.Set()
+			builder.Add(2)
+		}()
+		if r != `immutable.BatchSetBuilder: builder invalid after Set() invocation` {
+			t.Fatalf("unexpected panic: %q", r)
+		}
+	})
 }
 
 // TestStreamingListBuilder tests streaming list operations
@@ -233,6 +758,116 @@ func TestStreamingListBuilder(t *testing.T) {
 			}
 		}
 	})
+
+	t.Run("StreamContextCancel", func(t *testing.T) {
+		builder := NewStreamingListBuilder[int](3, 5)
+		ctx, cancel := context.WithCancel(context.Background())
+		values := make(chan int)
+		go func() {
+			for i := 0; i < 10; i++ {
+				values <- i
+			}
+			cancel() // stall without closing the channel
+		}()
+
+		if err := builder.StreamContext(ctx, values); !errors.Is(err, context.Canceled) {
+			t.Fatalf("Expected context.Canceled, got %v", err)
+		}
+		builder.Append(10) // builder remains usable
+		list := builder.List()
+		if list.Len() != 11 {
+			t.Fatalf("Expected length 11, got %d", list.Len())
+		}
+		for i := 0; i < 11; i++ {
+			if got := list.Get(i); got != i {
+				t.Errorf("Expected list[%d] = %d, got %d", i, i, got)
+			}
+		}
+	})
+
+	t.Run("StreamContextClosed", func(t *testing.T) {
+		builder := NewStreamingListBuilder[int](3, 5)
+		values := make(chan int, 4)
+		for i := 0; i < 4; i++ {
+			values <- i
+		}
+		close(values)
+
+		if err := builder.StreamContext(context.Background(), values); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if got := builder.List().Len(); got != 4 {
+			t.Errorf("Expected length 4, got %d", got)
+		}
+	})
+
+	t.Run("StreamBurst", func(t *testing.T) {
+		// Bursts larger than a batch, with the channel closed mid-drain.
+		for _, batchSize := range []int{1, 7, 64} {
+			builder := NewStreamingListBuilder[int](batchSize, 100)
+			values := make(chan int, 1000)
+			go func() {
+				for i := 0; i < 5000; i++ {
+					values <- i
+				}
+				close(values)
+			}()
+			builder.Stream(values)
+
+			list := builder.List()
+			if list.Len() != 5000 {
+				t.Fatalf("batch=%d: expected length 5000, got %d", batchSize, list.Len())
+			}
+			for i := 0; i < list.Len(); i++ {
+				if got := list.Get(i); got != i {
+					t.Fatalf("batch=%d: expected list[%d] = %d, got %d", batchSize, i, i, got)
+				}
+			}
+		}
+	})
+
+	t.Run("OnFlush", func(t *testing.T) {
+		for _, batchSize := range []int{1, 5, 32, 100} {
+			builder := NewStreamingListBuilder[int](batchSize, 0)
+			var model []int // logical builder contents, updated before each operation
+			var snapshots []*List[int]
+			var expected [][]int
+			builder.OnFlush(func(snapshot *List[int]) {
+				// Appends may still be buffered when a full front buffer flushes.
+				want := append([]int(nil), model[:len(model)-len(builder.buffer)]...)
+				snapshots = append(snapshots, snapshot)
+				expected = append(expected, want)
+			})
+
+			for i := 0; i < 3000; i++ {
+				if i%4 == 0 {
+					model = append([]int{-i}, model...)
+					builder.Prepend(-i)
+				} else {
+					model = append(model, i)
+					builder.Append(i)
+				}
+			}
+			final := builder.List()
+
+			if len(snapshots) == 0 {
+				t.Fatalf("batch=%d: expected OnFlush to be called", batchSize)
+			}
+			for i, snapshot := range snapshots {
+				if snapshot.Len() != len(expected[i]) {
+					t.Fatalf("batch=%d: snapshot %d: expected length %d, got %d", batchSize, i, len(expected[i]), snapshot.Len())
+				}
+				for j, want := range expected[i] {
+					if got := snapshot.Get(j); got != want {
+						t.Fatalf("batch=%d: snapshot %d: expected [%d] = %d, got %d", batchSize, i, j, want, got)
+					}
+				}
+			}
+			if last := snapshots[len(snapshots)-1]; last.Len() != final.Len() {
+				t.Fatalf("batch=%d: expected final snapshot length %d, got %d", batchSize, final.Len(), last.Len())
+			}
+		}
+	})
 }
 
 // TestStreamingMapBuilder tests streaming map operations
@@ -277,7 +912,7 @@ func TestStreamingMapBuilder(t *testing.T) {
 
 	t.Run("Filter", func(t *testing.T) {
 		builder := NewStreamingMapBuilder[int, string](nil, 5, 0)
-		entries := []mapEntry[int, string]{
+		entries := []MapEntry[int, string]{
 			{1, "one"}, {2, "two"}, {3, "three"}, {4, "four"}, {5, "five"},
 		}
 
@@ -297,6 +932,131 @@ func TestStreamingMapBuilder(t *testing.T) {
 			t.Errorf("Expected m[4] = four, got %s (exists: %v)", got, ok)
 		}
 	})
+
+	t.Run("StreamContextCancel", func(t *testing.T) {
+		builder := NewStreamingMapBuilder[int, int](nil, 3, 5)
+		ctx, cancel := context.WithCancel(context.Background())
+		entries := make(chan MapEntry[int, int])
+		go func() {
+			for i := 0; i < 10; i++ {
+				entries <- MapEntry[int, int]{Key: i, Value: i * 10}
+			}
+			cancel() // stall without closing the channel
+		}()
+
+		if err := builder.StreamContext(ctx, entries); !errors.Is(err, context.Canceled) {
+			t.Fatalf("Expected context.Canceled, got %v", err)
+		}
+		m := builder.Map()
+		if m.Len() != 10 {
+			t.Fatalf("Expected length 10, got %d", m.Len())
+		}
+		for i := 0; i < 10; i++ {
+			if got, ok := m.Get(i); !ok || got != i*10 {
+				t.Errorf("Expected m[%d] = %d, got %d (exists: %v)", i, i*10, got, ok)
+			}
+		}
+	})
+
+	t.Run("OnFlush", func(t *testing.T) {
+		hasher := &mockHasher[int]{
+			hash:  func(v int) uint32 { return uint32(v % 211) },
+			equal: func(a, b int) bool { return a == b },
+		}
+		for _, batchSize := range []int{1, 7, 64} {
+			builder := NewStreamingMapBuilder[int, int](hasher, batchSize, 0)
+			model := make(map[int]int)
+			var snapshots []*Map[int, int]
+			var expected []map[int]int
+			builder.OnFlush(func(snapshot *Map[int, int]) {
+				snapshots = append(snapshots, snapshot)
+				// The model is updated before each operation reaches the builder.
+				want := make(map[int]int, len(model))
+				for k, v := range model {
+					want[k] = v
+				}
+				expected = append(expected, want)
+			})
+
+			for i := 0; i < 3000; i++ {
+				key := (i * 7919) % 1009
+				if i%5 == 0 {
+					delete(model, key)
+					builder.Delete(key)
+				} else {
+					model[key] = i
+					builder.Set(key, i)
+				}
+			}
+			builder.Map()
+
+			if len(snapshots) == 0 {
+				t.Fatalf("batch=%d: expected OnFlush to be called", batchSize)
+			}
+			for i, snapshot := range snapshots {
+				if snapshot.Len() != len(expected[i]) {
+					t.Fatalf("batch=%d: snapshot %d: expected length %d, got %d", batchSize, i, len(expected[i]), snapshot.Len())
+				}
+				for key := 0; key < 1009; key++ {
+					want, wantOK := expected[i][key]
+					if got, ok := snapshot.Get(key); ok != wantOK || got != want {
+						t.Fatalf("batch=%d: snapshot %d: expected [%d] = %d (%v), got %d (%v)", batchSize, i, key, want, wantOK, got, ok)
+					}
+				}
+			}
+		}
+	})
+
+	t.Run("StreamBurst", func(t *testing.T) {
+		for _, batchSize := range []int{1, 7, 64} {
+			builder := NewStreamingMapBuilder[int, int](nil, batchSize, 100)
+			entries := make(chan MapEntry[int, int], 1000)
+			go func() {
+				for i := 0; i < 5000; i++ {
+					entries <- MapEntry[int, int]{Key: i % 1500, Value: i}
+				}
+				close(entries)
+			}()
+			builder.Stream(entries)
+
+			m := builder.Map()
+			if m.Len() != 1500 {
+				t.Fatalf("batch=%d: expected length 1500, got %d", batchSize, m.Len())
+			}
+			for key := 0; key < 1500; key++ {
+				want := key + 4500
+				if want >= 5000 {
+					want -= 1500
+				}
+				if got, ok := m.Get(key); !ok || got != want {
+					t.Fatalf("batch=%d: expected [%d] = %d, got %d (%v)", batchSize, key, want, got, ok)
+				}
+			}
+		}
+	})
+}
+
+func ExampleStreamingMapBuilder_Stream() {
+	entries := make(chan MapEntry[string, int])
+	go func() {
+		defer close(entries)
+		for i, name := range []string{"apple", "kiwi", "peach"} {
+			entries <- MapEntry[string, int]{Key: name, Value: (i + 1) * 100}
+		}
+	}()
+
+	b := NewStreamingMapBuilder[string, int](nil, 32, 0)
+	b.Stream(entries)
+
+	m := b.Map()
+	for _, name := range []string{"apple", "kiwi", "peach"} {
+		v, ok := m.Get(name)
+		fmt.Println(name, v, ok)
+	}
+	// Output:
+	// apple 100 true
+	// kiwi 200 true
+	// peach 300 true
 }
 
 // TestSortedBatchBuilder tests sorted batch operations
@@ -329,6 +1089,134 @@ func TestSortedBatchBuilder(t *testing.T) {
 		}
 	})
 
+	t.Run("SortedBufferLastWriteWins", func(t *testing.T) {
+		for _, sorted := range []bool{true, false} {
+			builder := NewSortedBatchBuilder[int, int](nil, 128, sorted)
+			expected := make(map[int]int)
+			for i := 0; i < 1000; i++ {
+				key := (i * 37) % 101
+				builder.Set(key, i)
+				expected[key] = i
+			}
+
+			sm := builder.SortedMap()
+			if sm.Len() != len(expected) {
+				t.Fatalf("sorted=%v: expected length %d, got %d", sorted, len(expected), sm.Len())
+			}
+			for key, want := range expected {
+				if got, ok := sm.Get(key); !ok || got != want {
+					t.Errorf("sorted=%v: expected sm[%d] = %d, got %d (exists: %v)", sorted, key, want, got, ok)
+				}
+			}
+		}
+	})
+
+	t.Run("BulkLoad", func(t *testing.T) {
+		// Compare against a map built with plain Set calls for sorted input,
+		// sorted input with duplicates, and input that periodically steps back
+		// below the current maximum to force the per-entry fallback.
+		inputs := map[string]func(i int) int{
+			"Ascending":  func(i int) int { return i },
+			"Duplicates": func(i int) int { return i / 3 },
+			"Sawtooth":   func(i int) int { return i%700 + i/700 },
+			"Strided":    func(i int) int { return i * 7 },
+		}
+		for name, keyFn := range inputs {
+			for _, sorted := range []bool{true, false} {
+				for _, batchSize := range []int{1, 5, 32, 33, 128, 1000} {
+					for _, n := range []int{0, 1, 31, 32, 33, 1057, 5000} {
+						builder := NewSortedBatchBuilder[int, int](nil, batchSize, sorted)
+						expected := NewSortedMap[int, int](nil)
+						for i := 0; i < n; i++ {
+							builder.Set(keyFn(i), i)
+							expected = expected.Set(keyFn(i), i)
+						}
+						if err := compareSortedMaps(builder.SortedMap(), expected); err != nil {
+							t.Fatalf("%s sorted=%v batch=%d n=%d: %s", name, sorted, batchSize, n, err)
+						}
+					}
+				}
+			}
+		}
+	})
+
+	t.Run("BulkLoadCustomComparer", func(t *testing.T) {
+		// A descending comparer disagrees with the buffer's natural sort order,
+		// so batches must not be bulk loaded as if they were ascending.
+		comparer := &mockComparer[int]{compare: func(a, b int) int { return cmp.Compare(b, a) }}
+		builder := NewSortedBatchBuilder[int, int](comparer, 16, true)
+		expected := NewSortedMap[int, int](comparer)
+		for i := 0; i < 1000; i++ {
+			builder.Set(i, i)
+			expected = expected.Set(i, i)
+		}
+		if err := compareSortedMaps(builder.SortedMap(), expected); err != nil {
+			t.Fatal(err)
+		}
+
+		// Descending input is sorted under this comparer, so every batch must
+		// stay ordered and be accepted by appendSorted.
+		builder = NewSortedBatchBuilder[int, int](comparer, 16, true)
+		expected = NewSortedMap[int, int](comparer)
+		for i := 999; i >= 0; i-- {
+			if len(builder.buffer) == builder.batchSize-1 {
+				batch := append(slices.Clone(builder.buffer), mapEntry[int, int]{key: i, value: i})
+				if builder.sm.Len() > 0 && comparer.Compare(batch[0].key, builder.sm.maxKey()) <= 0 {
+					t.Fatalf("expected batch ending at key %d to sort after the map's maximum", i)
+				} else if !NewSortedMap[int, int](comparer).appendSorted(batch, new(owner)) {
+					t.Fatalf("expected batch ending at key %d to be bulk loaded", i)
+				}
+			}
+			builder.Set(i, i)
+			if builder.unordered {
+				t.Fatalf("expected key %d to keep the buffer ordered", i)
+			}
+			expected = expected.Set(i, i)
+		}
+		if err := compareSortedMaps(builder.SortedMap(), expected); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("CustomComparerLastWriteWins", func(t *testing.T) {
+		// Distinct keys that the comparer treats as equal must resolve to the
+		// last write, as they would with repeated SortedMap.Set calls.
+		comparer := &mockComparer[string]{compare: func(a, b string) int {
+			return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+		}}
+		builder := NewSortedBatchBuilder[string, int](comparer, 10, true)
+		builder.Set("c", 0)
+		builder.Set("b", 1)
+		builder.Set("B", 2)
+		sm := builder.SortedMap()
+		if sm.Len() != 2 {
+			t.Fatalf("expected length 2, got %d", sm.Len())
+		}
+		if v, ok := sm.Get("b"); !ok || v != 2 {
+			t.Fatalf("expected sm[b] = 2, got %d (exists: %v)", v, ok)
+		}
+	})
+
+	t.Run("BulkLoadThenSet", func(t *testing.T) {
+		// Packed nodes from a bulk load must split correctly on later inserts.
+		builder := NewSortedBatchBuilder[int, int](nil, 100, true)
+		for i := 0; i < 2000; i += 2 {
+			builder.Set(i, i)
+		}
+		sm := builder.SortedMap()
+		expected := NewSortedMap[int, int](nil)
+		for i := 0; i < 2000; i += 2 {
+			expected = expected.Set(i, i)
+		}
+		for i := 1999; i > 0; i -= 2 {
+			sm = sm.Set(i, i)
+			expected = expected.Set(i, i)
+		}
+		if err := compareSortedMaps(sm, expected); err != nil {
+			t.Fatal(err)
+		}
+	})
+
 	t.Run("UnsortedBuffer", func(t *testing.T) {
 		builder := NewSortedBatchBuilder[int, string](nil, 5, false) // don't maintain sort
 
@@ -353,6 +1241,94 @@ func TestSortedBatchBuilder(t *testing.T) {
 			i++
 		}
 	})
+
+	t.Run("Reset", func(t *testing.T) {
+		builder := NewSortedBatchBuilder[int, int](nil, 4, false)
+		for i := 9; i >= 0; i-- {
+			builder.Set(i, i)
+		}
+		first := builder.SortedMap()
+
+		builder.Reset()
+		for i := 20; i < 26; i++ {
+			builder.Set(i, i*2)
+		}
+		second := builder.SortedMap()
+
+		expected := NewSortedMap[int, int](nil)
+		for i := 0; i < 10; i++ {
+			expected = expected.Set(i, i)
+		}
+		if err := compareSortedMaps(first, expected); err != nil {
+			t.Fatal(err)
+		}
+		expected = NewSortedMap[int, int](nil)
+		for i := 20; i < 26; i++ {
+			expected = expected.Set(i, i*2)
+		}
+		if err := compareSortedMaps(second, expected); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("SetAfterSortedMap", func(t *testing.T) {
+		var r string
+		func() {
+			defer func() { r = recover().(string) }()
+			builder := NewSortedBatchBuilder[int, int](nil, 4, true)
+			builder.Set(1, 1)
+			builder.SortedMap()
+			builder.Set(2, 2)
+		}()
+		if r != `immutable.SortedBatchBuilder: builder invalid after SortedMap() invocation` {
+			t.Fatalf("unexpected panic: %q", r)
+		}
+	})
+
+	t.Run("DuplicateSortedMap", func(t *testing.T) {
+		var r string
+		func() {
+			defer func() { r = recover().(string) }()
+			builder := NewSortedBatchBuilder[int, int](nil, 4, true)
+			builder.SortedMap()
+			builder.SortedMap()
+		}()
+		if r != `immutable.SortedBatchBuilder.SortedMap(): duplicate call to fetch map` {
+			t.Fatalf("unexpected panic: %q", r)
+		}
+	})
+}
+
+// compareSortedMaps returns an error if the maps differ in size, iteration
+// order, or lookups.
+func compareSortedMaps(got, want *SortedMap[int, int]) error {
+	if got.Len() != want.Len() {
+		return fmt.Errorf("expected length %d, got %d", want.Len(), got.Len())
+	}
+	gotItr, wantItr := got.Iterator(), want.Iterator()
+	for !wantItr.Done() {
+		k0, v0, _ := gotItr.Next()
+		k1, v1, _ := wantItr.Next()
+		if k0 != k1 || v0 != v1 {
+			return fmt.Errorf("expected entry %d=%d, got %d=%d", k1, v1, k0, v0)
+		}
+		if v, ok := got.Get(k1); !ok || v != v1 {
+			return fmt.Errorf("expected Get(%d) = %d, got %d (exists: %v)", k1, v1, v, ok)
+		}
+	}
+	if !gotItr.Done() {
+		return fmt.Errorf("iterator has extra entries")
+	}
+	gotItr.Last()
+	wantItr.Last()
+	for !wantItr.Done() {
+		k0, _, _ := gotItr.Prev()
+		k1, _, _ := wantItr.Prev()
+		if k0 != k1 {
+			return fmt.Errorf("expected key %d in reverse iteration, got %d", k1, k0)
+		}
+	}
+	return nil
 }
 
 // TestBatchSortedSetBuilder tests batch sorted set construction
@@ -408,6 +1384,571 @@ func TestBatchSortedSetBuilder(t *testing.T) {
 			i++
 		}
 	})
+
+	t.Run("Len", func(t *testing.T) {
+		builder := NewBatchSortedSetBuilder[int](nil, 4, true)
+		builder.AddSlice([]int{3, 1, 2, 1}) // 1, 2, 3 buffered
+		if got := builder.Len(); got != 3 {
+			t.Errorf("Expected length 3, got %d", got)
+		}
+		builder.AddSlice([]int{4, 2, 3, 5, 5, 1}) // flushes; duplicates span the boundary
+		if got := builder.Len(); got != 5 {
+			t.Errorf("Expected length 5, got %d", got)
+		}
+
+		set := builder.SortedSet()
+		if set.Len() != 5 {
+			t.Errorf("Expected final set length 5, got %d", set.Len())
+		}
+		if got := builder.Len(); got != 0 {
+			t.Errorf("Expected length 0 after SortedSet(), got %d", got)
+		}
+	})
+
+	t.Run("Reset", func(t *testing.T) {
+		builder := NewBatchSortedSetBuilder[int](nil, 3, true)
+		builder.AddSlice([]int{1, 2, 3, 4})
+		first := builder.SortedSet()
+
+		builder.Reset()
+		if got := builder.Len(); got != 0 {
+			t.Errorf("Expected length 0 after Reset(), got %d", got)
+		}
+		builder.AddSlice([]int{5, 4})
+		second := builder.SortedSet()
+
+		if first.Len() != 4 {
+			t.Errorf("Expected first set length 4, got %d", first.Len())
+		}
+		if second.Len() != 2 || !second.Has(4) || !second.Has(5) || second.Has(1) {
+			t.Errorf("Unexpected second set contents (length %d)", second.Len())
+		}
+	})
+
+	t.Run("ComparerDedup", func(t *testing.T) {
+		// Buffered values are deduped with the set's comparer, not ==.
+		fold := &mockComparer[string]{compare: func(a, b string) int {
+			return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+		}}
+		builder := NewBatchSortedSetBuilder[string](fold, 8, true)
+		builder.Add("a")
+		builder.Add("A")
+		if got := builder.Len(); got != 1 {
+			t.Fatalf("Expected length 1, got %d", got)
+		}
+		if set := builder.SortedSet(); set.Len() != 1 || !set.Has("A") {
+			t.Fatalf("Unexpected set %v", set.Items())
+		}
+
+		nan := NewBatchSortedSetBuilder[float64](&mockComparer[float64]{compare: cmp.Compare[float64]}, 8, true)
+		nan.AddSlice([]float64{math.NaN(), 1, math.NaN()})
+		if got := nan.Len(); got != 2 {
+			t.Fatalf("Expected length 2, got %d", got)
+		}
+		if set := nan.SortedSet(); set.Len() != 2 {
+			t.Fatalf("Expected final set length 2, got %d", set.Len())
+		}
+	})
+}
+
+// TestBatchBuilderSeq tests feeding builders from iterators.
+func TestBatchBuilderSeq(t *testing.T) {
+	goMap := make(map[int]string)
+	for i := 0; i < 500; i++ {
+		goMap[i*3] = fmt.Sprint(i)
+	}
+
+	t.Run("StreamingListBuilder", func(t *testing.T) {
+		var values []int
+		for i := 0; i < 1000; i++ {
+			values = append(values, i)
+		}
+		src := NewList(values...)
+
+		builder := NewStreamingListBuilder[int](16, 64)
+		var flushes int
+		builder.OnFlush(func(*List[int]) { flushes++ })
+		builder.AppendSeq(slices.Values(values[:500]))
+		builder.AppendSeq(listValues(src.Slice(500, 1000)))
+		list := builder.List()
+
+		if list.Len() != len(values) {
+			t.Fatalf("Expected length %d, got %d", len(values), list.Len())
+		}
+		for i, want := range values {
+			if got := list.Get(i); got != want {
+				t.Fatalf("Expected list[%d] = %d, got %d", i, want, got)
+			}
+		}
+		if flushes < len(values)/64 {
+			t.Errorf("Expected at least %d flushes, got %d", len(values)/64, flushes)
+		}
+	})
+
+	t.Run("StreamingMapBuilder", func(t *testing.T) {
+		builder := NewStreamingMapBuilder[int, string](nil, 16, 64)
+		builder.SetSeq(maps.All(goMap))
+		m := builder.Map()
+
+		other := NewStreamingMapBuilder[int, string](nil, 16, 64)
+		other.SetSeq(mapAll(m))
+		if got := maps.Collect(mapAll(other.Map())); !maps.Equal(got, goMap) {
+			t.Fatalf("Expected %d entries to round trip, got %d", len(goMap), len(got))
+		}
+	})
+
+	t.Run("BatchSetBuilder", func(t *testing.T) {
+		builder := NewBatchSetBuilder[int](nil, 16)
+		builder.AddSeq(maps.Keys(goMap))
+		builder.AddSeq(maps.Keys(goMap)) // duplicates are ignored
+		set := builder.Set()
+		if set.Len() != len(goMap) {
+			t.Fatalf("Expected length %d, got %d", len(goMap), set.Len())
+		}
+		for key := range goMap {
+			if !set.Has(key) {
+				t.Fatalf("Expected set to contain %d", key)
+			}
+		}
+	})
+
+	t.Run("SortedBatchBuilder", func(t *testing.T) {
+		builder := NewSortedBatchBuilder[int, string](nil, 16, true)
+		builder.SetSeq(maps.All(goMap))
+		sm := builder.SortedMap()
+		if sm.Len() != len(goMap) {
+			t.Fatalf("Expected length %d, got %d", len(goMap), sm.Len())
+		}
+		prev := -1
+		itr := sm.Iterator()
+		for !itr.Done() {
+			k, v, _ := itr.Next()
+			if k <= prev || v != goMap[k] {
+				t.Fatalf("Unexpected entry %d=%s after key %d", k, v, prev)
+			}
+			prev = k
+		}
+	})
+
+	t.Run("BatchSortedSetBuilder", func(t *testing.T) {
+		builder := NewBatchSortedSetBuilder[int](nil, 16, true)
+		builder.AddSeq(maps.Keys(goMap))
+		set := builder.SortedSet()
+		if set.Len() != len(goMap) {
+			t.Fatalf("Expected length %d, got %d", len(goMap), set.Len())
+		}
+	})
+}
+
+// listValues returns an iterator over the values of l.
+func listValues[T any](l *List[T]) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		itr := l.Iterator()
+		for !itr.Done() {
+			if _, v := itr.Next(); !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// mapAll returns an iterator over the key/value pairs of m.
+func mapAll[K, V any](m *Map[K, V]) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		itr := m.Iterator()
+		for !itr.Done() {
+			if k, v, _ := itr.Next(); !yield(k, v) {
+				return
+			}
+		}
+	}
+}
+
+// TestConcurrentBuilders tests builders fed by concurrent producers.
+func TestConcurrentBuilders(t *testing.T) {
+	const producers, n = 8, 5000
+
+	t.Run("List", func(t *testing.T) {
+		builder := NewConcurrentListBuilder[int](64)
+		var wg sync.WaitGroup
+		for p := 0; p < producers; p++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < n; i++ {
+					if i%100 == 0 {
+						builder.AppendSlice([]int{p*n + i, p*n + i + 1})
+						i++
+						continue
+					}
+					builder.Append(p*n + i)
+				}
+			}()
+		}
+		wg.Wait()
+		builder.Close()
+		builder.Close() // idempotent
+
+		list := builder.List()
+		if list.Len() != producers*n {
+			t.Fatalf("Expected length %d, got %d", producers*n, list.Len())
+		}
+		// Each producer's values must appear exactly once and in order.
+		next := make([]int, producers)
+		itr := list.Iterator()
+		for !itr.Done() {
+			_, v := itr.Next()
+			if p := v / n; v%n != next[p] {
+				t.Fatalf("Producer %d: expected value %d, got %d", p, p*n+next[p], v)
+			}
+			next[v/n]++
+		}
+	})
+
+	t.Run("Map", func(t *testing.T) {
+		builder := NewConcurrentMapBuilder[int, int](nil, 64)
+		var wg sync.WaitGroup
+		for p := 0; p < producers; p++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < n; i++ {
+					builder.Set(p*n+i, i)
+					builder.Set(-p-1, i) // repeatedly overwritten by this producer only
+				}
+				builder.Delete(p * n) // first key of each producer
+				if p == 0 {
+					builder.Flush()
+				}
+			}()
+		}
+		wg.Wait()
+
+		m := builder.Map()
+		if want := producers*(n-1) + producers; m.Len() != want {
+			t.Fatalf("Expected length %d, got %d", want, m.Len())
+		}
+		for p := 0; p < producers; p++ {
+			if _, ok := m.Get(p * n); ok {
+				t.Fatalf("Expected key %d to be deleted", p*n)
+			}
+			for i := 1; i < n; i++ {
+				if v, ok := m.Get(p*n + i); !ok || v != i {
+					t.Fatalf("Expected m[%d] = %d, got %d (exists: %v)", p*n+i, i, v, ok)
+				}
+			}
+			if v, ok := m.Get(-p - 1); !ok || v != n-1 {
+				t.Fatalf("Expected m[%d] = %d, got %d (exists: %v)", -p-1, n-1, v, ok)
+			}
+		}
+		if builder.Map() != m {
+			t.Fatal("Expected Map to return the same map after Close")
+		}
+	})
+
+	t.Run("SetAfterClose", func(t *testing.T) {
+		builder := NewConcurrentMapBuilder[int, int](nil, 8)
+		builder.Close()
+		defer func() {
+			if r := recover(); r == nil {
+				t.Fatal("Expected panic after Close")
+			}
+		}()
+		builder.Set(1, 1)
+	})
+}
+
+// TestBatchBuilderTakeAndReset tests reusing batch builders across builds.
+func TestBatchBuilderTakeAndReset(t *testing.T) {
+	t.Run("List", func(t *testing.T) {
+		builder := NewBatchListBuilder[int](4)
+		for round := 0; round < 3; round++ {
+			for i := 0; i < 10; i++ {
+				builder.Append(round*10 + i)
+			}
+			list := builder.TakeAndReset()
+			if list.Len() != 10 {
+				t.Fatalf("round %d: expected length 10, got %d", round, list.Len())
+			}
+			for i := 0; i < 10; i++ {
+				if got := list.Get(i); got != round*10+i {
+					t.Fatalf("round %d: expected list[%d] = %d, got %d", round, i, round*10+i, got)
+				}
+			}
+		}
+
+		builder.List()
+		builder.Reset() // revalidates after List()
+		builder.Append(1)
+		if got := builder.List().Len(); got != 1 {
+			t.Fatalf("Expected length 1 after Reset, got %d", got)
+		}
+	})
+
+	t.Run("Map", func(t *testing.T) {
+		hasher := &mockHasher[int]{
+			hash:  func(v int) uint32 { return uint32(v % 3) },
+			equal: func(a, b int) bool { return a == b },
+		}
+		builder := NewBatchMapBuilder[int, int](hasher, 4)
+		var maps []*Map[int, int]
+		for round := 0; round < 3; round++ {
+			for i := 0; i < 10; i++ {
+				builder.Set(i, round)
+			}
+			maps = append(maps, builder.TakeAndReset())
+		}
+		for round, m := range maps {
+			if m.Len() != 10 || m.hasher != hasher {
+				t.Fatalf("round %d: unexpected map (length %d)", round, m.Len())
+			}
+			for i := 0; i < 10; i++ {
+				if v, ok := m.Get(i); !ok || v != round {
+					t.Fatalf("round %d: expected m[%d] = %d, got %d (exists: %v)", round, i, round, v, ok)
+				}
+			}
+		}
+	})
+
+	t.Run("Set", func(t *testing.T) {
+		builder := NewBatchSetBuilder[int](nil, 4)
+		builder.AddSlice([]int{1, 2, 3, 2})
+		first := builder.TakeAndReset()
+		builder.AddSlice([]int{3, 4})
+		second := builder.Set()
+		if first.Len() != 3 || second.Len() != 2 || !second.Has(3) || second.Has(1) {
+			t.Fatalf("Unexpected sets: lengths %d and %d", first.Len(), second.Len())
+		}
+	})
+}
+
+// TestBatchBuilderSnapshot tests taking snapshots while continuing to build.
+func TestBatchBuilderSnapshot(t *testing.T) {
+	t.Run("List", func(t *testing.T) {
+		builder := NewBatchListBuilder[int](8)
+		for i := 0; i < 100; i++ {
+			builder.Append(i)
+		}
+		snap := builder.Snapshot()
+		for i := 0; i < 100; i++ {
+			builder.Append(100 + i)
+			builder.Prepend(-1 - i)
+		}
+		list := builder.List()
+
+		if snap.Len() != 100 {
+			t.Fatalf("Expected snapshot length 100, got %d", snap.Len())
+		}
+		for i := 0; i < 100; i++ {
+			if got := snap.Get(i); got != i {
+				t.Fatalf("Expected snapshot[%d] = %d, got %d", i, i, got)
+			}
+		}
+		if list.Len() != 300 || list.Get(0) != -100 || list.Get(299) != 199 {
+			t.Fatalf("Unexpected list (length %d)", list.Len())
+		}
+		if builder.Snapshot() != nil {
+			t.Fatal("Expected nil snapshot after List()")
+		}
+	})
+
+	t.Run("Map", func(t *testing.T) {
+		builder := NewBatchMapBuilder[int, int](nil, 8)
+		for i := 0; i < 100; i++ {
+			builder.Set(i, i)
+		}
+		snap := builder.Snapshot()
+		for i := 0; i < 100; i++ {
+			builder.Set(i, -i)
+		}
+		builder.Flush()
+
+		// Flushes after the snapshot copy shared nodes once, then update them in place.
+		root := builder.m.root
+		builder.Set(1, -1)
+		builder.Flush()
+		if builder.m.root != root {
+			t.Fatal("Expected flush after snapshot copy to update in place")
+		}
+
+		for i := 50; i < 100; i++ {
+			builder.Delete(i)
+		}
+		m := builder.Map()
+
+		if snap.Len() != 100 {
+			t.Fatalf("Expected snapshot length 100, got %d", snap.Len())
+		}
+		for i := 0; i < 100; i++ {
+			if v, ok := snap.Get(i); !ok || v != i {
+				t.Fatalf("Expected snapshot[%d] = %d, got %d (exists: %v)", i, i, v, ok)
+			}
+		}
+		if m.Len() != 50 {
+			t.Fatalf("Expected length 50, got %d", m.Len())
+		}
+	})
+}
+
+// TestBatchBuilderCapacity tests that capacity hints never affect contents.
+func TestBatchBuilderCapacity(t *testing.T) {
+	for _, size := range []int{5, 100, 3000} {
+		for _, hint := range []int{-1, 0, 1, size / 10, size, size * 10} {
+			t.Run(fmt.Sprintf("Size%d/Hint%d", size, hint), func(t *testing.T) {
+				lb := NewBatchListBuilderWithCapacity[int](16, hint)
+				var model []int
+				for i := 0; i < size; i++ {
+					if i%5 == 0 {
+						lb.Prepend(i)
+						model = append([]int{i}, model...)
+					} else {
+						lb.Append(i)
+						model = append(model, i)
+					}
+				}
+				if got := slices.Collect(listValues(lb.List())); !slices.Equal(got, model) {
+					t.Fatalf("List: expected %v, got %v", model, got)
+				}
+
+				mb := NewBatchMapBuilderWithCapacity[int, int](nil, 16, hint)
+				expected := make(map[int]int)
+				for i := 0; i < size; i++ {
+					mb.Set(i%(size/2+1), i)
+					expected[i%(size/2+1)] = i
+					if i%7 == 0 {
+						mb.Delete(i / 2)
+						delete(expected, i/2)
+					}
+				}
+				if got := maps.Collect(mapAll(mb.Map())); !maps.Equal(got, expected) {
+					t.Fatalf("Map: expected %v, got %v", expected, got)
+				}
+
+				sb := NewBatchSetBuilderWithCapacity[int](nil, 16, hint)
+				for i := 0; i < size; i++ {
+					sb.Add(i % (size/3 + 1))
+				}
+				if set := sb.Set(); set.Len() != size/3+1 {
+					t.Fatalf("Set: expected length %d, got %d", size/3+1, set.Len())
+				}
+			})
+		}
+	}
+
+	t.Run("DeleteAll", func(t *testing.T) {
+		builder := NewBatchMapBuilderWithCapacity[int, int](nil, 8, 1000)
+		for i := 0; i < 10; i++ {
+			builder.Set(i, i)
+		}
+		builder.Flush()
+		for i := 0; i < 10; i++ {
+			builder.Delete(i)
+		}
+		m := builder.Map()
+		if m.Len() != 0 || len(maps.Collect(mapAll(m))) != 0 {
+			t.Fatalf("Expected empty map, got length %d", m.Len())
+		}
+		if m = m.Set(1, 1); m.Len() != 1 {
+			t.Fatalf("Expected length 1, got %d", m.Len())
+		}
+	})
+}
+
+func TestBatchBuilderNodeArena(t *testing.T) {
+	t.Run("TakeAndReset", func(t *testing.T) {
+		builder := NewBatchMapBuilder[int, int](nil, 64)
+		builder.UseNodeArena(true)
+
+		// Every finalized map must be unaffected by later rounds reusing the builder.
+		var results []*Map[int, int]
+		for round := 0; round < 5; round++ {
+			for i := 0; i < 3000; i++ {
+				builder.Set(i*(round+1), round)
+			}
+			results = append(results, builder.TakeAndReset())
+		}
+		for round, m := range results {
+			if m.Len() != 3000 {
+				t.Fatalf("round %d: expected length 3000, got %d", round, m.Len())
+			}
+			for i := 0; i < 3000; i++ {
+				if v, ok := m.Get(i * (round + 1)); !ok || v != round {
+					t.Fatalf("round %d: Get(%d)=<%v,%v>", round, i*(round+1), v, ok)
+				}
+			}
+		}
+	})
+
+	t.Run("Snapshot", func(t *testing.T) {
+		builder := NewBatchMapBuilder[int, int](nil, 32)
+		builder.UseNodeArena(true)
+		var snapshots []*Map[int, int]
+		for i := 0; i < 5000; i++ {
+			if i%500 == 0 && i <= 2500 {
+				snapshots = append(snapshots, builder.Snapshot())
+			}
+			builder.Set(i%2500, i)
+		}
+		final := builder.Map()
+
+		for _, snap := range snapshots {
+			n := snap.Len()
+			for i := 0; i < n; i++ {
+				if v, ok := snap.Get(i); !ok || v != i {
+					t.Fatalf("snapshot len %d: Get(%d)=<%v,%v>", n, i, v, ok)
+				}
+			}
+			if _, ok := snap.Get(n); ok {
+				t.Fatalf("snapshot len %d: unexpected key %d", n, n)
+			}
+		}
+		for i := 0; i < 2500; i++ {
+			if v, ok := final.Get(i); !ok || v != i+2500 {
+				t.Fatalf("Get(%d)=<%v,%v>", i, v, ok)
+			}
+		}
+	})
+
+	t.Run("Mixed", func(t *testing.T) {
+		builder := NewBatchMapBuilderWithCapacity[string, int](nil, 16, 2000)
+		builder.UseNodeArena(true)
+		expected := make(map[string]int)
+		for i := 0; i < 2000; i++ {
+			key := fmt.Sprint(i % 700)
+			builder.Set(key, i)
+			expected[key] = i
+			if i%9 == 0 {
+				builder.Delete(fmt.Sprint(i / 3))
+				delete(expected, fmt.Sprint(i/3))
+			}
+			if i == 1000 {
+				builder.UseNodeArena(false)
+			}
+		}
+		m := builder.Map()
+		if got := maps.Collect(mapAll(m)); !maps.Equal(got, expected) {
+			t.Fatalf("expected %v, got %v", expected, got)
+		}
+		if other := m.Set("x", 1); other.Len() != m.Len()+1 || m.Len() != len(expected) {
+			t.Fatalf("unexpected lengths %d, %d", m.Len(), other.Len())
+		}
+	})
+
+	t.Run("Set", func(t *testing.T) {
+		builder := NewBatchSetBuilder[int](nil, 64)
+		builder.UseNodeArena(true)
+		for i := 0; i < 1000; i++ {
+			builder.Add(i % 400)
+		}
+		first := builder.TakeAndReset()
+		for i := 0; i < 1000; i++ {
+			builder.Add(-i)
+		}
+		second := builder.Set()
+		if first.Len() != 400 || second.Len() != 1000 || first.Has(-1) || !second.Has(-999) {
+			t.Fatalf("unexpected sets: %d, %d", first.Len(), second.Len())
+		}
+	})
 }
 
 // TestBatchBuilderEdgeCases tests edge cases and error conditions
@@ -458,3 +1999,77 @@ func TestBatchBuilderEdgeCases(t *testing.T) {
 		}
 	})
 }
+
+// TestCollect tests the package-level Collect constructors.
+func TestCollect(t *testing.T) {
+	t.Run("List", func(t *testing.T) {
+		for _, n := range []int{0, 1, 31, 32, 1000} {
+			values := make([]int, n)
+			for i := range values {
+				values[i] = i * 3
+			}
+			if got := slices.Collect(listValues(CollectList(slices.Values(values)))); !slices.Equal(got, values) {
+				t.Fatalf("n=%d: expected %v, got %v", n, values, got)
+			}
+		}
+	})
+
+	t.Run("Map", func(t *testing.T) {
+		for _, n := range []int{0, 1, 8, 9, 5000} {
+			goMap := make(map[string]int, n)
+			for i := 0; i < n; i++ {
+				goMap[fmt.Sprint("key", i)] = i
+			}
+			m := CollectMap(nil, maps.All(goMap))
+			if got := maps.Collect(mapAll(m)); !maps.Equal(got, goMap) {
+				t.Fatalf("n=%d: round trip mismatch", n)
+			}
+			if m.Len() != n {
+				t.Fatalf("n=%d: expected length %d, got %d", n, n, m.Len())
+			}
+		}
+	})
+
+	t.Run("Set", func(t *testing.T) {
+		values := []int{5, 3, 5, 1, 3, 9}
+		set := CollectSet(nil, slices.Values(values))
+		if set.Len() != 4 {
+			t.Fatalf("Expected length 4, got %d", set.Len())
+		}
+		for _, v := range values {
+			if !set.Has(v) {
+				t.Fatalf("Expected set to contain %d", v)
+			}
+		}
+	})
+
+	t.Run("SortedMap", func(t *testing.T) {
+		goMap := make(map[int]int)
+		for i := 0; i < 3000; i++ {
+			goMap[(i*7919)%2003] = i
+		}
+		sm := CollectSortedMap(nil, maps.All(goMap))
+		if sm.Len() != len(goMap) {
+			t.Fatalf("Expected length %d, got %d", len(goMap), sm.Len())
+		}
+		got := make(map[int]int)
+		prev := -1
+		for itr := sm.Iterator(); !itr.Done(); {
+			k, v, _ := itr.Next()
+			if k <= prev {
+				t.Fatalf("Keys out of order: %d after %d", k, prev)
+			}
+			got[k], prev = v, k
+		}
+		if !maps.Equal(got, goMap) {
+			t.Fatal("round trip mismatch")
+		}
+	})
+
+	t.Run("SortedSet", func(t *testing.T) {
+		set := CollectSortedSet(nil, slices.Values([]string{"pear", "apple", "fig", "apple"}))
+		if got, want := set.Items(), []string{"apple", "fig", "pear"}; !slices.Equal(got, want) {
+			t.Fatalf("Expected %v, got %v", want, got)
+		}
+	})
+}
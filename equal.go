@@ -0,0 +1,356 @@
+package immutable
+
+import "reflect"
+
+// deepEqualer is implemented by the collection types compared by DeepEqual.
+// deepEqual reports whether other has the same concrete type and equal
+// contents.
+type deepEqualer interface {
+	deepEqual(other any) bool
+}
+
+// DeepEqual reports whether a and b are deeply equal. Lists, maps, sorted
+// maps, sets, sorted sets and queues from this package are compared by
+// content, recursively, so a *Map[string, *List[int]] is equal to another
+// holding equal lists under equal keys. A nil collection is equal to an empty
+// one of the same type. Collections of different types are never equal, and
+// all other values are compared with reflect.DeepEqual.
+//
+// Map keys are matched with the maps' hashers and sorted map keys with their
+// comparers. Subtrees that two collections share through persistence are
+// recognized by pointer and skipped, so comparing a collection with a version
+// derived from it by a few edits costs far less than a full scan.
+func DeepEqual(a, b any) bool {
+	if a, ok := a.(deepEqualer); ok {
+		return a.deepEqual(b)
+	}
+	if _, ok := b.(deepEqualer); ok {
+		return false
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func (l *List[T]) deepEqual(other any) bool {
+	o, ok := other.(*List[T])
+	if !ok {
+		return false
+	}
+	n := listLen(l)
+	if n != listLen(o) {
+		return false
+	} else if n == 0 || (l.root == o.root && l.origin == o.origin && l.tail == o.tail) {
+		return true
+	}
+
+	// Lists with the same origin, height and tail length place each index at
+	// the same position, so shared subtrees can be skipped.
+	if m := l.tailLen(); l.origin == o.origin && l.root.depth() == o.root.depth() && m == o.tailLen() {
+		if _, ok := l.root.(*listSliceNode[T]); !ok {
+			return listNodesDeepEqual(l.root, o.root, 0, l.origin, l.origin+n-m) &&
+				(m == 0 || listNodesDeepEqual[T](l.tail, o.tail, 0, 0, m))
+		}
+	}
+
+	litr, oitr := l.Iterator(), o.Iterator()
+	for !litr.Done() {
+		_, lv := litr.Next()
+		_, ov := oitr.Next()
+		if !DeepEqual(lv, ov) {
+			return false
+		}
+	}
+	return true
+}
+
+// listLen returns the length of l, which may be nil.
+func listLen[T any](l *List[T]) int {
+	if l == nil {
+		return 0
+	}
+	return l.Len()
+}
+
+// listNodesDeepEqual compares the values at positions lo through hi-1 of two
+// trie nodes of the same height whose first position is base.
+func listNodesDeepEqual[T any](a, b listNode[T], base, lo, hi int) bool {
+	if a == b {
+		return true
+	}
+	switch a := a.(type) {
+	case *listBranchNode[T]:
+		b, ok := b.(*listBranchNode[T])
+		if !ok {
+			return false
+		}
+		span := 1 << (a.d * listNodeBits)
+		for i := range a.children {
+			start := base + i*span
+			if start >= hi {
+				break
+			} else if start+span <= lo {
+				continue
+			}
+			if !listNodesDeepEqual(a.children[i], b.children[i], start, lo, hi) {
+				return false
+			}
+		}
+		return true
+	case *listLeafNode[T]:
+		b, ok := b.(*listLeafNode[T])
+		if !ok {
+			return false
+		}
+		for i := max(lo-base, 0); i < min(hi-base, listNodeSize); i++ {
+			if !DeepEqual(a.children[i], b.children[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+func (m *Map[K, V]) deepEqual(other any) bool {
+	o, ok := other.(*Map[K, V])
+	if !ok {
+		return false
+	}
+	n := mapLen(m)
+	if n != mapLen(o) {
+		return false
+	} else if n == 0 || m.root == o.root {
+		return true
+	}
+	// With equal sizes, every key of m being found in o means o has no others.
+	return mapUnsharedEntries(m.root, o.root, func(key K, value V) bool {
+		ov, ok := o.Get(key)
+		return ok && DeepEqual(value, ov)
+	})
+}
+
+// mapLen returns the length of m, which may be nil.
+func mapLen[K, V any](m *Map[K, V]) int {
+	if m == nil {
+		return 0
+	}
+	return m.Len()
+}
+
+// mapUnsharedEntries calls fn for each entry of a except those in subtrees
+// that a shares with b at the same position, stopping if fn returns false.
+// Returns false if fn did.
+func mapUnsharedEntries[K, V any](a, b mapNode[K, V], fn func(key K, value V) bool) bool {
+	if a == b {
+		return true
+	}
+	switch a := a.(type) {
+	case *mapHashArrayNode[K, V]:
+		if b, ok := b.(*mapHashArrayNode[K, V]); ok {
+			for i := range a.nodes {
+				if !mapUnsharedEntries(a.nodes[i], b.nodes[i], fn) {
+					return false
+				}
+			}
+			return true
+		}
+	case *mapBitmapIndexedNode[K, V]:
+		if b, ok := b.(*mapBitmapIndexedNode[K, V]); ok && a.bitmap == b.bitmap {
+			for i := range a.nodes {
+				if !mapUnsharedEntries(a.nodes[i], b.nodes[i], fn) {
+					return false
+				}
+			}
+			return true
+		}
+	}
+	return mapNodeEntries(a, fn)
+}
+
+// mapNodeEntries calls fn for each entry in n and its descendants, stopping if
+// fn returns false. Returns false if fn did.
+func mapNodeEntries[K, V any](n mapNode[K, V], fn func(key K, value V) bool) bool {
+	switch n := n.(type) {
+	case *mapArrayNode[K, V]:
+		for _, e := range n.entries {
+			if !fn(e.key, e.value) {
+				return false
+			}
+		}
+	case *mapBitmapIndexedNode[K, V]:
+		for _, child := range n.nodes {
+			if !mapNodeEntries(child, fn) {
+				return false
+			}
+		}
+	case *mapHashArrayNode[K, V]:
+		for _, child := range n.nodes {
+			if !mapNodeEntries(child, fn) {
+				return false
+			}
+		}
+	case *mapValueNode[K, V]:
+		return fn(n.key, n.value)
+	case *mapHashCollisionNode[K, V]:
+		for _, e := range n.entries {
+			if !fn(e.key, e.value) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (m *SortedMap[K, V]) deepEqual(other any) bool {
+	o, ok := other.(*SortedMap[K, V])
+	if !ok {
+		return false
+	}
+	n := sortedMapLen(m)
+	if n != sortedMapLen(o) {
+		return false
+	} else if n == 0 || m.root == o.root {
+		return true
+	}
+	mitr, oitr := m.Iterator(), o.Iterator()
+	for !mitr.Done() {
+		mk, mv, _ := mitr.Next()
+		okey, ov, _ := oitr.Next()
+		if m.comparer.Compare(mk, okey) != 0 || !DeepEqual(mv, ov) {
+			return false
+		}
+	}
+	return true
+}
+
+// sortedMapLen returns the length of m, which may be nil.
+func sortedMapLen[K, V any](m *SortedMap[K, V]) int {
+	if m == nil {
+		return 0
+	}
+	return m.Len()
+}
+
+func (s Set[T]) deepEqual(other any) bool {
+	o, ok := other.(Set[T])
+	return ok && s.m.deepEqual(o.m)
+}
+
+func (s SortedSet[T]) deepEqual(other any) bool {
+	o, ok := other.(SortedSet[T])
+	return ok && s.m.deepEqual(o.m)
+}
+
+func (q *Queue[T]) deepEqual(other any) bool {
+	o, ok := other.(*Queue[T])
+	if !ok || q.Len() != o.Len() {
+		return false
+	}
+	if q.Len() == 0 {
+		return true
+	}
+	qitr, oitr := q.Iterator(), o.Iterator()
+	for !qitr.Done() {
+		_, qv, _ := qitr.Next()
+		_, ov, _ := oitr.Next()
+		if !DeepEqual(qv, ov) {
+			return false
+		}
+	}
+	return true
+}
+
+// nativer is implemented by the collection types converted by ToNative.
+type nativer interface {
+	native() any
+}
+
+// ToNative converts a collection from this package to the equivalent built-in
+// Go value: a List or Queue to a slice, a Map to a Go map, a Set to a
+// map[T]struct{}, a SortedMap to a []MapEntry in key order and a SortedSet to
+// a sorted slice. Collections nested as values are not converted; a nil
+// collection converts to a nil slice or map.
+//
+// ToNative's signature makes it usable as a go-cmp transformer, so that
+// cmp.Equal and cmp.Diff compare collections by content and report
+// differences element by element, including in nested collections:
+//
+//	cmp.Diff(want, got, cmp.Transformer("immutable", immutable.ToNative))
+//
+// Maps whose key type is not comparable convert to a []MapEntry instead.
+func ToNative(c nativer) any {
+	return c.native()
+}
+
+func (l *List[T]) native() any {
+	if l == nil {
+		return []T(nil)
+	}
+	values := make([]T, 0, l.Len())
+	for itr := l.Iterator(); !itr.Done(); {
+		_, v := itr.Next()
+		values = append(values, v)
+	}
+	return values
+}
+
+func (q *Queue[T]) native() any {
+	if q == nil {
+		return []T(nil)
+	}
+	values := make([]T, 0, q.Len())
+	for itr := q.Iterator(); !itr.Done(); {
+		_, v, _ := itr.Next()
+		values = append(values, v)
+	}
+	return values
+}
+
+func (m *Map[K, V]) native() any {
+	if !reflect.TypeFor[K]().Comparable() {
+		var entries []MapEntry[K, V]
+		if m != nil {
+			m.Range(func(key K, value V) bool {
+				entries = append(entries, MapEntry[K, V]{Key: key, Value: value})
+				return true
+			})
+		}
+		return entries
+	}
+	if m == nil {
+		return reflect.Zero(reflect.MapOf(reflect.TypeFor[K](), reflect.TypeFor[V]())).Interface()
+	}
+	out := reflect.MakeMapWithSize(reflect.MapOf(reflect.TypeFor[K](), reflect.TypeFor[V]()), m.Len())
+	m.Range(func(key K, value V) bool {
+		out.SetMapIndex(reflect.ValueOf(&key).Elem(), reflect.ValueOf(&value).Elem())
+		return true
+	})
+	return out.Interface()
+}
+
+func (m *SortedMap[K, V]) native() any {
+	if m == nil {
+		return []MapEntry[K, V](nil)
+	}
+	entries := make([]MapEntry[K, V], 0, m.Len())
+	m.Range(func(key K, value V) bool {
+		entries = append(entries, MapEntry[K, V]{Key: key, Value: value})
+		return true
+	})
+	return entries
+}
+
+func (s Set[T]) native() any {
+	return s.m.native()
+}
+
+func (s SortedSet[T]) native() any {
+	if s.m == nil {
+		return []T(nil)
+	}
+	values := make([]T, 0, s.m.Len())
+	s.m.Range(func(key T, _ struct{}) bool {
+		values = append(values, key)
+		return true
+	})
+	return values
+}
@@ -0,0 +1,182 @@
+package immutable
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestDeepEqual(t *testing.T) {
+	t.Run("Nested", func(t *testing.T) {
+		build := func(reverse bool) *Map[string, *List[*SortedMap[int, []string]]] {
+			m := NewMap[string, *List[*SortedMap[int, []string]]](nil)
+			for i := 0; i < 50; i++ {
+				key := i
+				if reverse {
+					key = 49 - i
+				}
+				l := NewList[*SortedMap[int, []string]]()
+				for j := 0; j < key%5; j++ {
+					l = l.Append(NewSortedMap[int, []string](nil).Set(j, []string{fmt.Sprint(key)}))
+				}
+				m = m.Set(fmt.Sprint(key), l)
+			}
+			return m
+		}
+		a, b := build(false), build(true)
+		if !DeepEqual(a, b) {
+			t.Fatal("expected equal nested maps")
+		}
+		l, _ := b.Get("49")
+		changed := b.Set("49", l.Set(3, l.Get(3).Set(3, []string{"x"})))
+		if DeepEqual(a, changed) {
+			t.Fatal("expected nested change to be detected")
+		}
+		if DeepEqual(a, b.Set("50", nil)) || DeepEqual(a, b.Delete("0")) {
+			t.Fatal("expected size change to be detected")
+		}
+	})
+
+	t.Run("Sets", func(t *testing.T) {
+		if !DeepEqual(NewSet[int](nil, 1, 2, 3), NewSet[int](nil, 3, 2, 1)) {
+			t.Fatal("expected equal sets")
+		}
+		if DeepEqual(NewSet[int](nil, 1, 2, 3), NewSet[int](nil, 1, 2, 4)) {
+			t.Fatal("expected unequal sets")
+		}
+		if !DeepEqual(NewSortedSet[string](nil, "b", "a"), NewSortedSet[string](nil, "a", "b")) {
+			t.Fatal("expected equal sorted sets")
+		}
+		if !DeepEqual([]any{NewSet[int](nil)}, []any{NewSet[int](nil)}) {
+			t.Fatal("expected leaves to use reflect.DeepEqual")
+		}
+	})
+
+	t.Run("Queue", func(t *testing.T) {
+		a := NewQueue(1, 2).Enqueue(3)
+		b := NewQueue(1).Enqueue(2).Enqueue(3)
+		if !DeepEqual(a, b) {
+			t.Fatal("expected equal queues")
+		}
+		if b, _, _ = b.Dequeue(); DeepEqual(a, b) {
+			t.Fatal("expected unequal queues")
+		}
+	})
+
+	t.Run("NilAndTypes", func(t *testing.T) {
+		if !DeepEqual((*List[int])(nil), NewList[int]()) || !DeepEqual(NewMap[int, int](nil), (*Map[int, int])(nil)) {
+			t.Fatal("expected nil collections to equal empty ones")
+		}
+		if !DeepEqual(Set[int]{}, NewSet[int](nil)) || !DeepEqual((*Queue[int])(nil), NewQueue[int]()) {
+			t.Fatal("expected nil collections to equal empty ones")
+		}
+		if DeepEqual(NewList(1), NewList[int64](1)) || DeepEqual(NewList(1), []int{1}) || DeepEqual([]int{1}, NewList(1)) {
+			t.Fatal("expected different types to be unequal")
+		}
+		m := NewMap[int, int](nil).Set(1, 1)
+		sm := NewSortedMap[int, int](nil).Set(1, 1)
+		if DeepEqual(m, sm) {
+			t.Fatal("expected map and sorted map to be unequal")
+		}
+	})
+
+	t.Run("Collisions", func(t *testing.T) {
+		h := &mockHasher[int]{
+			hash:  func(value int) uint32 { return uint32(value % 3) },
+			equal: func(a, b int) bool { return a == b },
+		}
+		a, b := NewMap[int, int](h), NewMap[int, int](h)
+		for i := 0; i < 30; i++ {
+			a, b = a.Set(i, i), b.Set(29-i, 29-i)
+		}
+		if !DeepEqual(a, b) || DeepEqual(a, b.Set(4, 0)) {
+			t.Fatal("unexpected collision map comparison")
+		}
+	})
+
+	t.Run("Lists", func(t *testing.T) {
+		var values []int
+		for i := 0; i < 3000; i++ {
+			values = append(values, i)
+		}
+		appended := NewList(values...)
+		prepended := NewList[int]()
+		for i := len(values) - 1; i >= 0; i-- {
+			prepended = prepended.Prepend(values[i])
+		}
+		if !DeepEqual(appended, prepended) || !DeepEqual(appended.Slice(100, 2100), prepended.Slice(100, 2100)) {
+			t.Fatal("expected equal lists")
+		}
+		if !DeepEqual(appended.Slice(5, 20), NewList(values[5:20]...)) {
+			t.Fatal("expected equal trie and slice lists")
+		}
+		if DeepEqual(appended, prepended.Set(2999, 0)) || DeepEqual(appended.Slice(0, 40), appended.Slice(1, 41)) {
+			t.Fatal("expected unequal lists")
+		}
+	})
+
+	// Non-nil funcs are never equal under reflect.DeepEqual, so these only
+	// succeed if shared subtrees are skipped rather than compared.
+	t.Run("SharedSubtrees", func(t *testing.T) {
+		m := NewMap[int, func()](nil)
+		l := NewList[func()]()
+		for i := 0; i < 5000; i++ {
+			m = m.Set(i, func() {})
+			// List leaves are compared whole, so leave the edited ones' funcs nil.
+			if i/listNodeSize == 123/listNodeSize || i/listNodeSize == 4000/listNodeSize {
+				l = l.Append(nil)
+			} else {
+				l = l.Append(func() {})
+			}
+		}
+		if !DeepEqual(m, m) || !DeepEqual(l, l) {
+			t.Fatal("expected identical collections to be equal")
+		}
+		if !DeepEqual(m.Set(123, nil).Set(4000, nil), m.Set(4000, nil).Set(123, nil)) {
+			t.Fatal("expected derived maps to be equal")
+		}
+		if !DeepEqual(l.Set(123, nil).Set(4000, nil), l.Set(4000, nil).Set(123, nil)) {
+			t.Fatal("expected derived lists to be equal")
+		}
+		if DeepEqual(m.Set(1, nil), m.Set(2, nil)) || DeepEqual(l.Set(1, nil), l.Set(1, nil)) {
+			t.Fatal("expected differing entries to be compared")
+		}
+	})
+}
+
+func TestToNative(t *testing.T) {
+	nested := NewMap[string, *List[int]](nil).Set("a", NewList(1, 2))
+	for _, tt := range []struct {
+		in   nativer
+		want any
+	}{
+		{NewList(1, 2, 3), []int{1, 2, 3}},
+		{(*List[string])(nil), []string(nil)},
+		{NewQueue(1).Enqueue(2), []int{1, 2}},
+		{NewMap[string, int](nil).Set("a", 1).Set("b", 2), map[string]int{"a": 1, "b": 2}},
+		{(*Map[string, int])(nil), map[string]int(nil)},
+		{NewMap[[]byte, int](&bytesHasher{}).Set([]byte("k"), 1), []MapEntry[[]byte, int]{{Key: []byte("k"), Value: 1}}},
+		{NewSortedMap[int, string](nil).Set(2, "b").Set(1, "a"), []MapEntry[int, string]{{1, "a"}, {2, "b"}}},
+		{NewSet[int](nil, 1, 2), map[int]struct{}{1: {}, 2: {}}},
+		{NewSortedSet[int](nil, 3, 1, 2), []int{1, 2, 3}},
+		{nested, map[string]*List[int]{"a": mustGet(nested, "a")}},
+	} {
+		if got := ToNative(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ToNative(%#v)=%#v, expected %#v", tt.in, got, tt.want)
+		}
+	}
+}
+
+// bytesHasher hashes byte slice keys, which are not comparable.
+type bytesHasher struct{}
+
+func (bytesHasher) Hash(key []byte) uint32 { return hashString(string(key)) }
+func (bytesHasher) Equal(a, b []byte) bool { return string(a) == string(b) }
+
+func mustGet[K, V any](m *Map[K, V], key K) V {
+	v, ok := m.Get(key)
+	if !ok {
+		panic(fmt.Sprintf("missing key %v", key))
+	}
+	return v
+}
@@ -0,0 +1,179 @@
+package immutable
+
+import (
+	"fmt"
+	"strings"
+)
+
+// goStringPrefix returns the type name of v as used by the GoString methods,
+// e.g. "&immutable.List[int]" for a *List[int].
+func goStringPrefix(v any) string {
+	name := fmt.Sprintf("%T", v)
+	if s, ok := strings.CutPrefix(name, "*"); ok {
+		return "&" + s
+	}
+	return name
+}
+
+// GoString implements fmt.GoStringer, describing the structure of the list
+// rather than its contents. It is used by the %#v verb.
+func (l *List[T]) GoString() string {
+	if l == nil {
+		return fmt.Sprintf("(%T)(nil)", l)
+	}
+	repr, depth := "slice", 0
+	switch root := l.root.(type) {
+	case nil:
+	case *listSliceNode[T]:
+		depth = 1
+	default:
+		repr, depth = "trie", int(root.depth())+1
+	}
+	return fmt.Sprintf("%s{len:%d, repr:%s, depth:%d}", goStringPrefix(l), l.size, repr, depth)
+}
+
+// listStringLimit is the number of elements printed by List.String before
+// the rest are elided.
+const listStringLimit = 32
+
+// String implements fmt.Stringer, formatting the list's elements with %v,
+// e.g. "List[1, 2, 3]". Only the first 32 elements are printed; any more are
+// replaced by an ellipsis.
+func (l *List[T]) String() string {
+	if l == nil {
+		return "<nil>"
+	}
+	var sb strings.Builder
+	sb.WriteString("List[")
+	var n int
+	listChunks(l, 0, min(l.size, listStringLimit), func(chunk []T) bool {
+		for _, v := range chunk {
+			if n++; n > 1 {
+				sb.WriteString(", ")
+			}
+			fmt.Fprint(&sb, v)
+		}
+		return true
+	})
+	if l.size > listStringLimit {
+		sb.WriteString(", ...")
+	}
+	sb.WriteString("]")
+	return sb.String()
+}
+
+// String implements fmt.Stringer, formatting the builder's current list as
+// List.String does.
+func (b *ListBuilder[T]) String() string { return b.list.String() }
+
+// mapNodeStats counts the nodes of each kind in a map trie.
+type mapNodeStats struct {
+	depth                                      int
+	array, bitmap, hashArray, collision, value int
+}
+
+// collectMapNodeStats adds n and its descendants, found at the given depth, to s.
+func collectMapNodeStats[K, V any](n mapNode[K, V], depth int, s *mapNodeStats) {
+	if n == nil {
+		return
+	}
+	s.depth = max(s.depth, depth)
+	switch n := n.(type) {
+	case *mapArrayNode[K, V]:
+		s.array++
+	case *mapBitmapIndexedNode[K, V]:
+		s.bitmap++
+		for _, child := range n.nodes {
+			collectMapNodeStats(child, depth+1, s)
+		}
+	case *mapHashArrayNode[K, V]:
+		s.hashArray++
+		for _, child := range n.nodes {
+			collectMapNodeStats(child, depth+1, s)
+		}
+	case *mapHashCollisionNode[K, V]:
+		s.collision++
+	case *mapValueNode[K, V]:
+		s.value++
+	}
+}
+
+// goStringBody returns the fields shared by the GoString output of Map and Set.
+func (m *Map[K, V]) goStringBody() string {
+	var s mapNodeStats
+	collectMapNodeStats(m.root, 1, &s)
+	return fmt.Sprintf("{len:%d, depth:%d, nodes:{array:%d, bitmap:%d, hashArray:%d, collision:%d, value:%d}}",
+		m.size, s.depth, s.array, s.bitmap, s.hashArray, s.collision, s.value)
+}
+
+// GoString implements fmt.GoStringer, describing the structure of the map,
+// including the number of trie nodes of each kind, rather than its contents.
+// It is used by the %#v verb.
+func (m *Map[K, V]) GoString() string {
+	if m == nil {
+		return fmt.Sprintf("(%T)(nil)", m)
+	}
+	return goStringPrefix(m) + m.goStringBody()
+}
+
+// GoString implements fmt.GoStringer, describing the structure of the set's
+// underlying map. It is used by the %#v verb.
+func (s Set[T]) GoString() string {
+	if s.m == nil {
+		return goStringPrefix(s) + NewMap[T, struct{}](nil).goStringBody()
+	}
+	return goStringPrefix(s) + s.m.goStringBody()
+}
+
+// goStringBody returns the fields shared by the GoString output of SortedMap
+// and SortedSet.
+func (m *SortedMap[K, V]) goStringBody() string {
+	var depth, branches, leaves int
+	if m.root != nil {
+		nodes := []sortedMapNode[K, V]{m.root}
+		for len(nodes) > 0 {
+			depth++
+			var next []sortedMapNode[K, V]
+			for _, n := range nodes {
+				switch n := n.(type) {
+				case *sortedMapBranchNode[K, V]:
+					branches++
+					for _, elem := range n.elems {
+						next = append(next, elem.node)
+					}
+				case *sortedMapLeafNode[K, V]:
+					leaves++
+				}
+			}
+			nodes = next
+		}
+	}
+	return fmt.Sprintf("{len:%d, depth:%d, nodes:{branch:%d, leaf:%d}}", m.size, depth, branches, leaves)
+}
+
+// GoString implements fmt.GoStringer, describing the structure of the map's
+// b+tree rather than its contents. It is used by the %#v verb.
+func (m *SortedMap[K, V]) GoString() string {
+	if m == nil {
+		return fmt.Sprintf("(%T)(nil)", m)
+	}
+	return goStringPrefix(m) + m.goStringBody()
+}
+
+// GoString implements fmt.GoStringer, describing the structure of the set's
+// underlying sorted map. It is used by the %#v verb.
+func (s SortedSet[T]) GoString() string {
+	if s.m == nil {
+		return goStringPrefix(s) + NewSortedMap[T, struct{}](nil).goStringBody()
+	}
+	return goStringPrefix(s) + s.m.goStringBody()
+}
+
+// GoString implements fmt.GoStringer, describing how the queue's elements are
+// split between its front and back lists. It is used by the %#v verb.
+func (q *Queue[T]) GoString() string {
+	if q == nil {
+		return fmt.Sprintf("(%T)(nil)", q)
+	}
+	return fmt.Sprintf("%s{len:%d, front:%d, back:%d}", goStringPrefix(q), q.size, q.front.Len(), q.back.Len())
+}
@@ -0,0 +1,167 @@
+package immutable
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+	"testing"
+)
+
+// parseGoString splits GoString output into its type prefix and a flattened
+// set of fields, so comparisons do not depend on field order. Nested fields
+// are named with a dotted path, e.g. "nodes.value".
+func parseGoString(t *testing.T, s string) (string, map[string]string) {
+	t.Helper()
+	i := strings.IndexByte(s, '{')
+	if i < 0 || !strings.HasSuffix(s, "}") {
+		t.Fatalf("malformed GoString: %q", s)
+	}
+	fields := make(map[string]string)
+	var parse func(prefix, body string)
+	parse = func(prefix, body string) {
+		for body != "" {
+			name, rest, ok := strings.Cut(body, ":")
+			if !ok {
+				t.Fatalf("malformed GoString: %q", s)
+			}
+			var value string
+			if strings.HasPrefix(rest, "{") {
+				end := strings.IndexByte(rest, '}')
+				parse(prefix+name+".", rest[1:end])
+				rest = rest[end+1:]
+			} else {
+				value, rest, _ = strings.Cut(rest, ", ")
+				fields[prefix+name] = value
+			}
+			body = strings.TrimPrefix(rest, ", ")
+		}
+	}
+	parse("", s[i+1:len(s)-1])
+	return s[:i], fields
+}
+
+func checkGoString(t *testing.T, v any, want string) {
+	t.Helper()
+	gotType, gotFields := parseGoString(t, fmt.Sprintf("%#v", v))
+	wantType, wantFields := parseGoString(t, want)
+	if gotType != wantType || !maps.Equal(gotFields, wantFields) {
+		t.Fatalf("expected %s, got %#v", want, v)
+	}
+}
+
+func TestGoString(t *testing.T) {
+	t.Run("List", func(t *testing.T) {
+		checkGoString(t, NewList[int](), "&immutable.List[int]{len:0, repr:slice, depth:1}")
+		checkGoString(t, NewList(1, 2, 3), "&immutable.List[int]{len:3, repr:slice, depth:1}")
+
+		l := NewList[int]()
+		for i := 0; i < 40; i++ {
+			l = l.Append(i)
+		}
+		checkGoString(t, l, "&immutable.List[int]{depth:3, len:40, repr:trie}")
+		checkGoString(t, &List[string]{}, "&immutable.List[string]{len:0, repr:slice, depth:0}")
+		if got := fmt.Sprintf("%#v", (*List[int])(nil)); got != "(*immutable.List[int])(nil)" {
+			t.Fatalf("unexpected nil GoString: %s", got)
+		}
+	})
+
+	t.Run("Map", func(t *testing.T) {
+		checkGoString(t, NewMap[int, string](nil),
+			"&immutable.Map[int,string]{len:0, depth:0, nodes:{array:0, bitmap:0, hashArray:0, collision:0, value:0}}")
+		checkGoString(t, NewMap[int, string](nil).Set(1, "a").Set(2, "b"),
+			"&immutable.Map[int,string]{len:2, depth:1, nodes:{array:1, bitmap:0, hashArray:0, collision:0, value:0}}")
+
+		m := NewMap[int, int](nil)
+		for i := 0; i < 1000; i++ {
+			m = m.Set(i, i)
+		}
+		_, fields := parseGoString(t, m.GoString())
+		if fields["len"] != "1000" || fields["nodes.hashArray"] == "0" || fields["nodes.value"] == "0" {
+			t.Fatalf("unexpected GoString: %s", m.GoString())
+		}
+
+		// Keys sharing a hash are stored in a collision node.
+		c := NewMap[string, int](&mockHasher[string]{
+			hash:  func(value string) uint32 { return 1 },
+			equal: func(a, b string) bool { return a == b },
+		})
+		for i := 0; i < 10; i++ {
+			c = c.Set(fmt.Sprint(i), i)
+		}
+		if _, fields := parseGoString(t, c.GoString()); fields["nodes.collision"] != "1" {
+			t.Fatalf("unexpected GoString: %s", c.GoString())
+		}
+	})
+
+	t.Run("SortedMap", func(t *testing.T) {
+		checkGoString(t, NewSortedMap[int, string](nil),
+			"&immutable.SortedMap[int,string]{len:0, depth:0, nodes:{branch:0, leaf:0}}")
+		checkGoString(t, NewSortedMap[int, string](nil).Set(1, "a"),
+			"&immutable.SortedMap[int,string]{len:1, depth:1, nodes:{branch:0, leaf:1}}")
+
+		m := NewSortedMap[int, int](nil)
+		for i := 0; i < 100; i++ {
+			m = m.Set(i, i)
+		}
+		_, fields := parseGoString(t, m.GoString())
+		if fields["len"] != "100" || fields["depth"] != "2" || fields["nodes.branch"] != "1" {
+			t.Fatalf("unexpected GoString: %s", m.GoString())
+		}
+	})
+
+	t.Run("Set", func(t *testing.T) {
+		checkGoString(t, NewSet[string](nil, "a", "b"),
+			"immutable.Set[string]{len:2, depth:1, nodes:{array:1, bitmap:0, hashArray:0, collision:0, value:0}}")
+		checkGoString(t, Set[string]{},
+			"immutable.Set[string]{len:0, depth:0, nodes:{array:0, bitmap:0, hashArray:0, collision:0, value:0}}")
+		checkGoString(t, NewSortedSet[int](nil, 3, 1),
+			"immutable.SortedSet[int]{len:2, depth:1, nodes:{branch:0, leaf:1}}")
+		checkGoString(t, SortedSet[int]{},
+			"immutable.SortedSet[int]{len:0, depth:0, nodes:{branch:0, leaf:0}}")
+	})
+
+	t.Run("Queue", func(t *testing.T) {
+		q := NewQueue(1, 2).Enqueue(3)
+		checkGoString(t, q, "&immutable.Queue[int]{len:3, front:2, back:1}")
+		if got := fmt.Sprintf("%#v", (*Queue[int])(nil)); got != "(*immutable.Queue[int])(nil)" {
+			t.Fatalf("unexpected nil GoString: %s", got)
+		}
+	})
+}
+
+func TestList_String(t *testing.T) {
+	for _, tt := range []struct {
+		l    *List[int]
+		want string
+	}{
+		{NewList[int](), "List[]"},
+		{NewList(1, 2, 3), "List[1, 2, 3]"},
+		{(*List[int])(nil), "<nil>"},
+	} {
+		if got := fmt.Sprint(tt.l); got != tt.want {
+			t.Fatalf("expected %q, got %q", tt.want, got)
+		}
+	}
+
+	l := NewList[int]()
+	for i := 0; i < 1000; i++ {
+		l = l.Append(i)
+	}
+	want := "List[0, 1, 2"
+	for i := 3; i < listStringLimit; i++ {
+		want += ", " + fmt.Sprint(i)
+	}
+	if got := fmt.Sprintf("%v", l); got != want+", ...]" {
+		t.Fatalf("unexpected truncated string: %s", got)
+	}
+	if got := l.Slice(0, listStringLimit).String(); got != want+"]" {
+		t.Fatalf("unexpected string at the limit: %s", got)
+	}
+
+	b := NewListBuilder[string]()
+	b.Append("")
+	b.Append("a")
+	if got := b.String(); got != "List[, a]" {
+		t.Fatalf("unexpected builder string: %q", got)
+	}
+}
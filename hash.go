@@ -0,0 +1,150 @@
+package immutable
+
+// Content hashes are built from the 32-bit hashes produced by a Hasher, mixed
+// into 64 bits. They depend only on the contents of a collection and the
+// hashers used, never on its internal structure or on pointers, so equal
+// collections hash equal however they were built. The built-in hashers are
+// deterministic, which makes hashes computed with them stable across
+// processes.
+//
+// Because each element contributes a 32-bit hash, distinct collections collide
+// more often than a true 64-bit hash would suggest: any two elements the
+// hasher maps to the same value are indistinguishable. Content hashes suit
+// memoization keys and change detection backed by an equality check, not
+// identity. Hashes are computed on each call rather than cached, since every
+// collection can be shared between goroutines and the result depends on the
+// hashers supplied.
+
+// mixHash64 is the splitmix64 finalizer, used to spread element hashes across
+// all 64 bits before they are combined.
+func mixHash64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+// Seeds distinguishing ordered from unordered content hashes.
+const (
+	hashSeedOrdered   = 0x9e3779b97f4a7c15
+	hashSeedUnordered = 0x6a09e667f3bcc909
+)
+
+// unorderedHash accumulates an order-independent hash of key/value entries.
+type unorderedHash struct {
+	sum uint64
+	n   int
+}
+
+func (h *unorderedHash) add(keyHash, valueHash uint32) {
+	h.sum += mixHash64(uint64(keyHash)<<32 | uint64(valueHash))
+	h.n++
+}
+
+func (h *unorderedHash) sum64() uint64 {
+	return mixHash64(h.sum ^ mixHash64(hashSeedUnordered+uint64(h.n)))
+}
+
+// HashList returns a content hash of the list's values in order. Lists with
+// equal values in the same order hash equal. If hasher is nil, the built-in
+// hasher for the value type is used; see NewHasher.
+func HashList[T any](l *List[T], hasher Hasher[T]) uint64 {
+	h := uint64(hashSeedOrdered)
+	if l == nil || l.Len() == 0 {
+		return mixHash64(h)
+	}
+	if hasher == nil {
+		var zero T
+		hasher = NewHasher(zero)
+	}
+	itr := l.Iterator()
+	for !itr.Done() {
+		_, v := itr.Next()
+		h = mixHash64(h ^ uint64(hasher.Hash(v)))
+	}
+	return mixHash64(h + uint64(l.Len()))
+}
+
+// HashMap returns an order-independent content hash of the map's entries.
+// Keys are hashed with the map's hasher and values with valueHasher, or the
+// built-in hasher for the value type if valueHasher is nil. Maps with equal
+// entries hash equal, as does a SortedMap with the same entries passed to
+// HashSortedMap with the same hashers.
+func HashMap[K, V any](m *Map[K, V], valueHasher Hasher[V]) uint64 {
+	var h unorderedHash
+	if m == nil || m.Len() == 0 {
+		return h.sum64()
+	}
+	if valueHasher == nil {
+		var zero V
+		valueHasher = NewHasher(zero)
+	}
+	itr := m.Iterator()
+	for !itr.Done() {
+		k, v, _ := itr.Next()
+		h.add(m.hasher.Hash(k), valueHasher.Hash(v))
+	}
+	return h.sum64()
+}
+
+// HashSortedMap returns an order-independent content hash of the map's
+// entries, matching HashMap for a Map with the same entries and hashers. A
+// nil keyHasher or valueHasher selects the built-in hasher for that type.
+func HashSortedMap[K, V any](m *SortedMap[K, V], keyHasher Hasher[K], valueHasher Hasher[V]) uint64 {
+	var h unorderedHash
+	if m == nil || m.Len() == 0 {
+		return h.sum64()
+	}
+	if keyHasher == nil {
+		var zero K
+		keyHasher = NewHasher(zero)
+	}
+	if valueHasher == nil {
+		var zero V
+		valueHasher = NewHasher(zero)
+	}
+	itr := m.Iterator()
+	for !itr.Done() {
+		k, v, _ := itr.Next()
+		h.add(keyHasher.Hash(k), valueHasher.Hash(v))
+	}
+	return h.sum64()
+}
+
+// HashSet returns an order-independent content hash of the set's values,
+// hashed with the set's hasher. Sets with equal values hash equal, as does a
+// SortedSet with the same values passed to HashSortedSet with the same hasher.
+func HashSet[T any](s Set[T]) uint64 {
+	var h unorderedHash
+	if s.m == nil || s.m.Len() == 0 {
+		return h.sum64()
+	}
+	itr := s.m.Iterator()
+	for !itr.Done() {
+		k, _, _ := itr.Next()
+		h.add(s.m.hasher.Hash(k), 0)
+	}
+	return h.sum64()
+}
+
+// HashSortedSet returns an order-independent content hash of the set's
+// values, matching HashSet for a Set with the same values and hasher. If
+// hasher is nil, the built-in hasher for the value type is used.
+func HashSortedSet[T any](s SortedSet[T], hasher Hasher[T]) uint64 {
+	var h unorderedHash
+	if s.m == nil || s.m.Len() == 0 {
+		return h.sum64()
+	}
+	if hasher == nil {
+		var zero T
+		hasher = NewHasher(zero)
+	}
+	itr := s.m.Iterator()
+	for !itr.Done() {
+		k, _, _ := itr.Next()
+		h.add(hasher.Hash(k), 0)
+	}
+	return h.sum64()
+}
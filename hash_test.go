@@ -0,0 +1,101 @@
+package immutable
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestHash(t *testing.T) {
+	t.Run("List", func(t *testing.T) {
+		values := make([]string, 500)
+		for i := range values {
+			values[i] = fmt.Sprint("v", i)
+		}
+		a := NewList(values...)
+		b := NewList[string]()
+		for i := len(values) - 1; i >= 0; i-- {
+			b = b.Prepend(values[i])
+		}
+		if HashList(a, nil) != HashList(b, nil) {
+			t.Fatal("expected equal lists to hash equal")
+		}
+		if HashList(a.Slice(0, 10), nil) != HashList(NewList(values[:10]...), nil) {
+			t.Fatal("expected equal slice and trie lists to hash equal")
+		}
+		if HashList(a, nil) == HashList(a.Set(250, "x"), nil) {
+			t.Fatal("expected changed list to hash differently")
+		}
+		if HashList(NewList("a", "b"), nil) == HashList(NewList("b", "a"), nil) {
+			t.Fatal("expected list hash to depend on order")
+		}
+		if HashList(NewList[string](), nil) != HashList[string](nil, nil) {
+			t.Fatal("expected empty and nil lists to hash equal")
+		}
+	})
+
+	t.Run("Map", func(t *testing.T) {
+		a := NewMap[int, string](nil)
+		b := NewMapBuilder[int, string](nil)
+		sm := NewSortedMap[int, string](nil)
+		for i := 0; i < 1000; i++ {
+			a = a.Set(i, fmt.Sprint(i))
+			b.Set(999-i, fmt.Sprint(999-i))
+			sm = sm.Set(i, fmt.Sprint(i))
+		}
+		// Overwrite and delete so b's history differs from a's.
+		b.Set(5000, "x")
+		b.Delete(5000)
+		hash := HashMap(a, nil)
+		if hash != HashMap(b.Map(), nil) {
+			t.Fatal("expected equal maps to hash equal")
+		}
+		if hash != HashSortedMap(sm, nil, nil) {
+			t.Fatal("expected equal map and sorted map to hash equal")
+		}
+		if HashMap(NewMap[int, string](nil), nil) != HashSortedMap[int, string](nil, nil, nil) {
+			t.Fatal("expected empty maps to hash equal")
+		}
+
+		// A one-entry change should change the hash.
+		changed := 0
+		for i := 0; i < 1000; i++ {
+			if HashMap(a.Set(i, "changed"), nil) != hash {
+				changed++
+			}
+			if HashMap(a.Delete(i), nil) != hash {
+				changed++
+			}
+		}
+		if changed != 2000 {
+			t.Fatalf("expected every single-entry change to alter the hash, %d of 2000 did", changed)
+		}
+	})
+
+	t.Run("Set", func(t *testing.T) {
+		a := NewSet[string](nil, "x", "y", "z")
+		b := NewSet[string](nil, "z", "y", "x", "y")
+		if HashSet(a) != HashSet(b) {
+			t.Fatal("expected equal sets to hash equal")
+		}
+		if HashSet(a) != HashSortedSet(NewSortedSet[string](nil, "y", "x", "z"), nil) {
+			t.Fatal("expected equal set and sorted set to hash equal")
+		}
+		if HashSet(a) == HashSet(a.Delete("y")) || HashSet(a) == HashSet(a.Add("w")) {
+			t.Fatal("expected changed set to hash differently")
+		}
+		if HashSet(Set[string]{}) != HashSortedSet(SortedSet[string]{}, nil) {
+			t.Fatal("expected empty sets to hash equal")
+		}
+	})
+
+	t.Run("Stable", func(t *testing.T) {
+		// Built-in hashers are deterministic, so hashes must not change between
+		// processes or releases.
+		if got := HashList(NewList(1, 2, 3), nil); got != 0x913fe893fc5edecf {
+			t.Fatalf("unexpected list hash %#x", got)
+		}
+		if got := HashMap(NewMap[string, int](nil).Set("a", 1).Set("b", 2), nil); got != 0xdea52937227e6af6 {
+			t.Fatalf("unexpected map hash %#x", got)
+		}
+	})
+}
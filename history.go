@@ -0,0 +1,90 @@
+package immutable
+
+// History is an immutable record of successive versions of a Map, retaining
+// at most a fixed number of the most recent ones for rollback and debugging.
+// Like the package's collections, every operation returns a new History and
+// leaves the receiver unchanged.
+//
+// Versions derived from one another with Map.Set and Map.Delete share all but
+// the nodes on the paths they changed, so retaining many versions of a large
+// map costs little more than retaining the newest one.
+type History[K, V any] struct {
+	versions *List[*Map[K, V]] // retained versions, oldest first
+	current  int               // index of the current version in versions
+	limit    int               // maximum number of retained versions, or zero
+}
+
+// NewHistory returns a history whose only version is initial. Committing more
+// than limit versions evicts the oldest; a limit of zero or less retains every
+// version.
+func NewHistory[K, V any](initial *Map[K, V], limit int) *History[K, V] {
+	return &History[K, V]{
+		versions: NewList(initial),
+		limit:    max(limit, 0),
+	}
+}
+
+// Len returns the number of retained versions, including any that can be
+// reached with Redo.
+func (h *History[K, V]) Len() int {
+	return h.versions.Len()
+}
+
+// Index returns the position of the current version, where 0 is the oldest
+// retained version.
+func (h *History[K, V]) Index() int {
+	return h.current
+}
+
+// Current returns the current version.
+func (h *History[K, V]) Current() *Map[K, V] {
+	return h.versions.Get(h.current)
+}
+
+// At returns the retained version at index i, where 0 is the oldest, and
+// whether i is in range.
+func (h *History[K, V]) At(i int) (*Map[K, V], bool) {
+	if i < 0 || i >= h.versions.Len() {
+		return nil, false
+	}
+	return h.versions.Get(i), true
+}
+
+// Commit returns a history with next as its current version. Versions that
+// had been undone are discarded, and the oldest version is evicted if the
+// limit is exceeded.
+func (h *History[K, V]) Commit(next *Map[K, V]) *History[K, V] {
+	versions := h.versions
+	if h.current < versions.Len()-1 {
+		versions = versions.Slice(0, h.current+1)
+	}
+	versions = versions.Append(next)
+	if h.limit > 0 && versions.Len() > h.limit {
+		versions = versions.Slice(versions.Len()-h.limit, versions.Len())
+	}
+	return &History[K, V]{versions: versions, current: versions.Len() - 1, limit: h.limit}
+}
+
+// Undo returns a history whose current version is the one before the current
+// version. Returns the receiver and false if the current version is the
+// oldest retained one.
+func (h *History[K, V]) Undo() (*History[K, V], bool) {
+	if h.current == 0 {
+		return h, false
+	}
+	other := *h
+	other.current--
+	return &other, true
+}
+
+// Redo returns a history whose current version is the one after the current
+// version, reversing an Undo. Returns the receiver and false if there is no
+// later version.
+func (h *History[K, V]) Redo() (*History[K, V], bool) {
+	if h.current == h.versions.Len()-1 {
+		return h, false
+	}
+	other := *h
+	other.current++
+	return &other, true
+}
@@ -0,0 +1,100 @@
+package immutable
+
+import "testing"
+
+func TestHistory(t *testing.T) {
+	version := func(h *History[string, int]) int {
+		v, _ := h.Current().Get("v")
+		return v
+	}
+	m := NewMap[string, int](nil).Set("v", 0)
+
+	t.Run("Limit", func(t *testing.T) {
+		h := NewHistory(m, 3)
+		for i := 1; i <= 5; i++ {
+			h = h.Commit(h.Current().Set("v", i))
+		}
+		if h.Len() != 3 || h.Index() != 2 || version(h) != 5 {
+			t.Fatalf("unexpected history: len=%d index=%d version=%d", h.Len(), h.Index(), version(h))
+		}
+		if oldest, ok := h.At(0); !ok || oldest.Len() != 1 {
+			t.Fatal("expected oldest version")
+		} else if v, _ := oldest.Get("v"); v != 3 {
+			t.Fatalf("expected version 3 to be oldest, got %d", v)
+		}
+		if _, ok := h.At(3); ok {
+			t.Fatal("expected At(3) to be out of range")
+		}
+		if _, ok := h.At(-1); ok {
+			t.Fatal("expected At(-1) to be out of range")
+		}
+	})
+
+	t.Run("Unlimited", func(t *testing.T) {
+		h := NewHistory(m, 0)
+		for i := 1; i <= 100; i++ {
+			h = h.Commit(h.Current().Set("v", i))
+		}
+		if h.Len() != 101 {
+			t.Fatalf("unexpected length %d", h.Len())
+		}
+	})
+
+	t.Run("UndoRedo", func(t *testing.T) {
+		h := NewHistory(m, 10)
+		if _, ok := h.Undo(); ok {
+			t.Fatal("expected nothing to undo")
+		}
+		for i := 1; i <= 3; i++ {
+			h = h.Commit(h.Current().Set("v", i))
+		}
+		if _, ok := h.Redo(); ok {
+			t.Fatal("expected nothing to redo")
+		}
+
+		undone, ok := h.Undo()
+		if !ok || version(undone) != 2 || version(h) != 3 {
+			t.Fatalf("unexpected undo: %d, original %d", version(undone), version(h))
+		}
+		undone, _ = undone.Undo()
+		if version(undone) != 1 || undone.Len() != 4 {
+			t.Fatalf("unexpected undo: %d", version(undone))
+		}
+		redone, ok := undone.Redo()
+		if !ok || version(redone) != 2 {
+			t.Fatalf("unexpected redo: %d", version(redone))
+		}
+
+		// Committing after an undo discards the undone versions.
+		branched := undone.Commit(undone.Current().Set("v", 10))
+		if branched.Len() != 3 || version(branched) != 10 {
+			t.Fatalf("unexpected branch: len=%d version=%d", branched.Len(), version(branched))
+		}
+		if _, ok := branched.Redo(); ok {
+			t.Fatal("expected nothing to redo after commit")
+		}
+		if prev, _ := branched.Undo(); version(prev) != 1 {
+			t.Fatalf("unexpected undo after branch: %d", version(prev))
+		}
+		// The original history is unaffected.
+		if h.Len() != 4 || version(h) != 3 {
+			t.Fatalf("original history changed: len=%d version=%d", h.Len(), version(h))
+		}
+	})
+
+	t.Run("UndoEviction", func(t *testing.T) {
+		h := NewHistory(m, 2)
+		h = h.Commit(h.Current().Set("v", 1))
+		h, _ = h.Undo()
+		h = h.Commit(h.Current().Set("v", 2))
+		h = h.Commit(h.Current().Set("v", 3))
+		if h.Len() != 2 || version(h) != 3 {
+			t.Fatalf("unexpected history: len=%d version=%d", h.Len(), version(h))
+		}
+		if prev, ok := h.Undo(); !ok || version(prev) != 2 {
+			t.Fatalf("unexpected undo: %d", version(prev))
+		} else if _, ok := prev.Undo(); ok {
+			t.Fatal("expected evicted version to be unreachable")
+		}
+	})
+}
@@ -38,16 +38,24 @@
 // for int, string, and byte slice keys. If you are using one of these key types
 // then simply pass a nil into the constructor. Otherwise you will need to
 // implement a custom Hasher or Comparer type. Please see the provided
-// implementations for reference.
+// implementations for reference, or HasherFunc and ComparableHasherFunc to
+// build a Hasher from a hash function. For string keys, NewFastStringHasher is a
+// faster alternative to the built-in hasher whose hashes vary between
+// processes.
 package immutable
 
 import (
 	"cmp"
+	"errors"
 	"fmt"
+	"hash/maphash"
 	"math/bits"
 	"reflect"
+	"runtime"
+	"slices"
 	"sort"
 	"strings"
+	"sync"
 )
 
 // Size thresholds for each type of branch node.
@@ -63,6 +71,50 @@ const (
 	mapNodeMask = mapNodeSize - 1
 )
 
+// ErrBuilderFinalized is returned when a builder's collection is fetched after
+// the builder has already been finalized.
+var ErrBuilderFinalized = errors.New("immutable: builder invalid after finalization")
+
+// owner identifies the builder, or the single bulk operation, that exclusively
+// owns a set of nodes. Nodes are stamped with the owner that created them and
+// may be updated in place only by an operation holding the same owner; all
+// other nodes are copied on write and the copy is stamped instead. A nil owner
+// stamps nothing, so every node touched by a persistent operation is copied.
+// Builders take a new owner whenever they share their nodes with a snapshot,
+// which leaves each shared node to be copied once on its next update.
+type owner struct {
+	_ byte // non-zero size so that every owner has a distinct address
+}
+
+// owns returns true if o is non-nil and is the owner stamped on a node.
+func (o *owner) owns(stamp *owner) bool {
+	return o != nil && o == stamp
+}
+
+// ReadonlyMap is the read-only view of a map shared by Map, SortedMap and their
+// builders, for functions that only need to look up or visit entries. Range
+// has the signature of an iter.Seq2, so any ReadonlyMap's Range method can be
+// passed to CollectMap or CollectSortedMap to convert between map types.
+type ReadonlyMap[K, V any] interface {
+	// Len returns the number of entries in the map.
+	Len() int
+
+	// Get returns the value for the given key and whether it was found.
+	Get(key K) (value V, ok bool)
+
+	// Range calls fn for each entry until fn returns false. Entries are
+	// visited in key order for sorted maps and in an unspecified order
+	// otherwise.
+	Range(fn func(key K, value V) bool)
+}
+
+var (
+	_ ReadonlyMap[string, int] = (*Map[string, int])(nil)
+	_ ReadonlyMap[string, int] = (*MapBuilder[string, int])(nil)
+	_ ReadonlyMap[string, int] = (*SortedMap[string, int])(nil)
+	_ ReadonlyMap[string, int] = (*SortedMapBuilder[string, int])(nil)
+)
+
 // Map represents an immutable hash map implementation. The map uses a Hasher
 // to generate hashes and check for equality of key values.
 //
@@ -90,17 +142,293 @@ func NewMapOf[K comparable, V any](hasher Hasher[K], entries map[K]V) *Map[K, V]
 	m := &Map[K, V]{
 		hasher: hasher,
 	}
+	o := new(owner)
 	for k, v := range entries {
-		m.set(k, v, true)
+		m.set(k, v, o)
 	}
 	return m
 }
 
+// mapParallelThreshold is the number of entries below which
+// NewMapOfParallel inserts entries on the calling goroutine.
+const mapParallelThreshold = 4096
+
+// NewMapOfParallel returns a new instance of Map containing entries, built
+// with up to workers goroutines. If workers is zero or less,
+// runtime.GOMAXPROCS(0) is used. If a key appears more than once, the last
+// entry for it wins, exactly as if the entries had been set in order.
+//
+// Keys are hashed concurrently and partitioned by their first hash fragment,
+// then each of the root's subtrees is built by a single goroutine and the
+// root is assembled from them. The hasher must be safe for concurrent use;
+// if it is nil, a default hasher is chosen based on the first key.
+func NewMapOfParallel[K, V any](hasher Hasher[K], entries []MapEntry[K, V], workers int) *Map[K, V] {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if len(entries) < mapParallelThreshold {
+		m, o := NewMap[K, V](hasher), new(owner)
+		for _, e := range entries {
+			m = m.set(e.Key, e.Value, o)
+		}
+		return m
+	}
+	if hasher == nil {
+		hasher = NewHasher(entries[0].Key)
+	}
+
+	// Hash keys concurrently, one contiguous range per worker.
+	hashes := make([]uint32, len(entries))
+	parallelRange(len(entries), workers, func(start, end int) {
+		for i := start; i < end; i++ {
+			hashes[i] = hasher.Hash(entries[i].Key)
+		}
+	})
+
+	// Partition entry indexes by the root slot their key hashes to, keeping
+	// input order within each partition.
+	var counts [mapNodeSize]int
+	for _, hash := range hashes {
+		counts[hash&mapNodeMask]++
+	}
+	var partitions [mapNodeSize][]uint32
+	for i, n := range counts {
+		partitions[i] = make([]uint32, 0, n)
+	}
+	for i, hash := range hashes {
+		partitions[hash&mapNodeMask] = append(partitions[hash&mapNodeMask], uint32(i))
+	}
+
+	// Build each subtree of the root independently. Subtrees are disjoint, so
+	// the workers can share a single owner.
+	o := new(owner)
+	root := &mapHashArrayNode[K, V]{owner: o}
+	var sizes [mapNodeSize]int
+	parallelRange(mapNodeSize, workers, func(start, end int) {
+		arena := &mapNodeArena[K, V]{}
+		for frag := start; frag < end; frag++ {
+			group := mapPartitionEntries(entries, hashes, partitions[frag], hasher)
+			if len(group) == 0 {
+				continue
+			}
+			added := 1
+			var child mapNode[K, V] = arena.newValueNode(group[0].hash, group[0].key, group[0].value, o)
+			if len(group) > 1 {
+				child = mapSetGroup(child, group[1:], mapNodeBits, hasher, o, arena, &added)
+			}
+			root.nodes[frag] = child
+			sizes[frag] = added
+		}
+	})
+
+	m := &Map[K, V]{root: root, hasher: hasher}
+	for _, n := range sizes {
+		if n > 0 {
+			root.count++
+			m.size += n
+		}
+	}
+	return m
+}
+
+// mapPartitionEntries returns the entries at the given indexes, which are in
+// ascending order, with their hashes. The result is ordered by bit-reversed
+// hash as required by mapSetGroup, and where keys are repeated only the last
+// entry for each is kept.
+func mapPartitionEntries[K, V any](entries []MapEntry[K, V], hashes []uint32, indexes []uint32, h Hasher[K]) []mapHashedEntry[K, V] {
+	order := make([]uint64, len(indexes))
+	for i, idx := range indexes {
+		order[i] = uint64(bits.Reverse32(hashes[idx]))<<32 | uint64(idx)
+	}
+	slices.Sort(order)
+
+	group := make([]mapHashedEntry[K, V], 0, len(order))
+	for i := 0; i < len(order); {
+		// Within a run of equal hashes, a later entry replaces an earlier
+		// one with an equal key.
+		j, start := i, len(group)
+		for ; j < len(order) && order[j]>>32 == order[i]>>32; j++ {
+			e := entries[uint32(order[j])]
+			replaced := false
+			for k := start; k < len(group); k++ {
+				if h.Equal(group[k].key, e.Key) {
+					group[k].value, replaced = e.Value, true
+					break
+				}
+			}
+			if !replaced {
+				group = append(group, mapHashedEntry[K, V]{hash: hashes[uint32(order[j])], key: e.Key, value: e.Value})
+			}
+		}
+		i = j
+	}
+	return group
+}
+
+// parallelRange splits [0, n) into up to workers contiguous ranges and calls
+// fn for each concurrently, returning once all calls have returned.
+func parallelRange(n, workers int, fn func(start, end int)) {
+	step := (n + workers - 1) / workers
+	var wg sync.WaitGroup
+	for start := 0; start < n; start += step {
+		wg.Add(1)
+		go func(start, end int) {
+			defer wg.Done()
+			fn(start, end)
+		}(start, min(start+step, n))
+	}
+	wg.Wait()
+}
+
 // Len returns the number of elements in the map.
 func (m *Map[K, V]) Len() int {
 	return m.size
 }
 
+// DeleteFunc returns a copy of the map without the entries for which pred
+// returns true. Subtrees without matching entries are shared with the original
+// map, and if no entries match the original map itself is returned, so callers
+// can detect the no-op by comparing pointers.
+func (m *Map[K, V]) DeleteFunc(pred func(key K, value V) bool) *Map[K, V] {
+	if m.root == nil {
+		return m
+	}
+	var removed int
+	root := mapDeleteFunc(m.root, pred, &removed)
+	if removed == 0 {
+		return m
+	}
+	other := m.clone()
+	other.size = m.size - removed
+	other.root = root
+	return other
+}
+
+// mapDeleteFunc returns node without the entries matching pred, or nil if none
+// remain, adding the number removed to removed. Unchanged nodes are returned
+// as is.
+func mapDeleteFunc[K, V any](node mapNode[K, V], pred func(key K, value V) bool, removed *int) mapNode[K, V] {
+	switch n := node.(type) {
+	case *mapArrayNode[K, V]:
+		entries := mapDeleteEntries(n.entries, pred, removed)
+		if len(entries) == len(n.entries) {
+			return n
+		} else if len(entries) == 0 {
+			return nil
+		}
+		return &mapArrayNode[K, V]{entries: entries}
+
+	case *mapBitmapIndexedNode[K, V]:
+		var other *mapBitmapIndexedNode[K, V]
+		for i, child := range n.nodes {
+			newChild := mapDeleteFunc(child, pred, removed)
+			if newChild == child && other == nil {
+				continue
+			} else if other == nil {
+				other = &mapBitmapIndexedNode[K, V]{nodes: make([]mapNode[K, V], 0, len(n.nodes))}
+				for j := range i {
+					other.bitmap |= mapBitmapBit(n.bitmap, j)
+					other.nodes = append(other.nodes, n.nodes[j])
+				}
+			}
+			if newChild != nil {
+				other.bitmap |= mapBitmapBit(n.bitmap, i)
+				other.nodes = append(other.nodes, newChild)
+			}
+		}
+		if other == nil {
+			return n
+		} else if len(other.nodes) == 0 {
+			return nil
+		}
+		return other
+
+	case *mapHashArrayNode[K, V]:
+		var other *mapHashArrayNode[K, V]
+		for i, child := range n.nodes {
+			if child == nil {
+				continue
+			}
+			if newChild := mapDeleteFunc(child, pred, removed); newChild != child {
+				if other == nil {
+					other = n.clone(nil)
+				}
+				other.nodes[i] = newChild
+				if newChild == nil {
+					other.count--
+				}
+			}
+		}
+		if other == nil {
+			return n
+		} else if other.count == 0 {
+			return nil
+		} else if other.count >= maxBitmapIndexedSize {
+			return other
+		}
+		// Convert back to a bitmap indexed node, as delete does.
+		bitmap := &mapBitmapIndexedNode[K, V]{nodes: make([]mapNode[K, V], 0, other.count)}
+		for i, child := range other.nodes {
+			if child != nil {
+				bitmap.bitmap |= 1 << uint(i)
+				bitmap.nodes = append(bitmap.nodes, child)
+			}
+		}
+		return bitmap
+
+	case *mapValueNode[K, V]:
+		if pred(n.key, n.value) {
+			*removed++
+			return nil
+		}
+		return n
+
+	case *mapHashCollisionNode[K, V]:
+		entries := mapDeleteEntries(n.entries, pred, removed)
+		switch len(entries) {
+		case len(n.entries):
+			return n
+		case 0:
+			return nil
+		case 1:
+			return &mapValueNode[K, V]{keyHash: n.keyHash, key: entries[0].key, value: entries[0].value}
+		}
+		return &mapHashCollisionNode[K, V]{keyHash: n.keyHash, entries: entries}
+	}
+	return node
+}
+
+// mapDeleteEntries returns entries without those matching pred, adding the
+// number removed to removed. entries itself is returned if none match.
+func mapDeleteEntries[K, V any](entries []mapEntry[K, V], pred func(key K, value V) bool, removed *int) []mapEntry[K, V] {
+	var other []mapEntry[K, V]
+	for i, e := range entries {
+		if !pred(e.key, e.value) {
+			if other != nil {
+				other = append(other, e)
+			}
+			continue
+		}
+		if other == nil {
+			other = append(make([]mapEntry[K, V], 0, len(entries)-1), entries[:i]...)
+		}
+		*removed++
+	}
+	if other == nil {
+		return entries
+	}
+	return other
+}
+
+// mapBitmapBit returns the bitmap bit of the i-th child of a bitmap indexed
+// node.
+func mapBitmapBit(bitmap uint32, i int) uint32 {
+	for ; i > 0; i-- {
+		bitmap &= bitmap - 1
+	}
+	return bitmap & -bitmap
+}
+
 // clone returns a shallow copy of m.
 func (m *Map[K, V]) clone() *Map[K, V] {
 	other := *m
@@ -124,10 +452,10 @@ func (m *Map[K, V]) Get(key K) (value V, ok bool) {
 // This function will return a new map even if the updated value is the same as
 // the existing value because Map does not track value equality.
 func (m *Map[K, V]) Set(key K, value V) *Map[K, V] {
-	return m.set(key, value, false)
+	return m.set(key, value, nil)
 }
 
-func (m *Map[K, V]) set(key K, value V, mutable bool) *Map[K, V] {
+func (m *Map[K, V]) set(key K, value V, o *owner) *Map[K, V] {
 	// Set a hasher on the first value if one does not already exist.
 	hasher := m.hasher
 	if hasher == nil {
@@ -136,7 +464,7 @@ func (m *Map[K, V]) set(key K, value V, mutable bool) *Map[K, V] {
 
 	// Generate copy if necessary.
 	other := m
-	if !mutable {
+	if o == nil {
 		other = m.clone()
 	}
 	other.hasher = hasher
@@ -144,14 +472,14 @@ func (m *Map[K, V]) set(key K, value V, mutable bool) *Map[K, V] {
 	// If the map is empty, initialize with a simple array node.
 	if m.root == nil {
 		other.size = 1
-		other.root = &mapArrayNode[K, V]{entries: []mapEntry[K, V]{{key: key, value: value}}}
+		other.root = &mapArrayNode[K, V]{entries: []mapEntry[K, V]{{key: key, value: value}}, owner: o}
 		return other
 	}
 
 	// Otherwise copy the map and delegate insertion to the root.
 	// Resized will return true if the key does not currently exist.
 	var resized bool
-	other.root = m.root.set(key, value, 0, hasher.Hash(key), hasher, mutable, &resized)
+	other.root = m.root.set(key, value, 0, hasher.Hash(key), hasher, o, &resized)
 	if resized {
 		other.size++
 	}
@@ -161,10 +489,10 @@ func (m *Map[K, V]) set(key K, value V, mutable bool) *Map[K, V] {
 // Delete returns a map with the given key removed.
 // Removing a non-existent key will cause this method to return the same map.
 func (m *Map[K, V]) Delete(key K) *Map[K, V] {
-	return m.delete(key, false)
+	return m.delete(key, nil)
 }
 
-func (m *Map[K, V]) delete(key K, mutable bool) *Map[K, V] {
+func (m *Map[K, V]) delete(key K, o *owner) *Map[K, V] {
 	// Return original map if no keys exist.
 	if m.root == nil {
 		return m
@@ -172,14 +500,14 @@ func (m *Map[K, V]) delete(key K, mutable bool) *Map[K, V] {
 
 	// If the delete did not change the node then return the original map.
 	var resized bool
-	newRoot := m.root.delete(key, 0, m.hasher.Hash(key), m.hasher, mutable, &resized)
+	newRoot := m.root.delete(key, 0, m.hasher.Hash(key), m.hasher, o, &resized)
 	if !resized {
 		return m
 	}
 
 	// Generate copy if necessary.
 	other := m
-	if !mutable {
+	if o == nil {
 		other = m.clone()
 	}
 
@@ -196,14 +524,58 @@ func (m *Map[K, V]) Iterator() *MapIterator[K, V] {
 	return itr
 }
 
+// Range calls fn for each key/value pair in the map until fn returns false.
+// The order of iteration is unspecified.
+func (m *Map[K, V]) Range(fn func(key K, value V) bool) {
+	for itr := m.Iterator(); !itr.Done(); {
+		if k, v, _ := itr.Next(); !fn(k, v) {
+			return
+		}
+	}
+}
+
+// RangeParallel calls fn for every key/value pair in the map, walking the
+// subtrees under the root in up to workers goroutines. If workers is zero or
+// less, runtime.GOMAXPROCS(0) is used. fn is called concurrently and must be
+// safe for concurrent use; calls are in no particular order and cannot stop
+// the walk early. RangeParallel returns once all calls have returned.
+func (m *Map[K, V]) RangeParallel(workers int, fn func(key K, value V)) {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	visit := func(key K, value V) bool {
+		fn(key, value)
+		return true
+	}
+
+	var children []mapNode[K, V]
+	switch root := m.root.(type) {
+	case *mapHashArrayNode[K, V]:
+		children = root.nodes[:]
+	case *mapBitmapIndexedNode[K, V]:
+		children = root.nodes
+	}
+	if workers == 1 || len(children) == 0 {
+		mapNodeEntries(m.root, visit)
+		return
+	}
+	parallelRange(len(children), workers, func(start, end int) {
+		for _, child := range children[start:end] {
+			mapNodeEntries(child, visit)
+		}
+	})
+}
+
 // MapBuilder represents an efficient builder for creating Maps.
 type MapBuilder[K, V any] struct {
-	m *Map[K, V] // current state
+	m      *Map[K, V] // current state
+	hasher Hasher[K]  // hasher for the map installed by Reset
+	owner  *owner     // owner of the nodes the builder may update in place
 }
 
 // NewMapBuilder returns a new instance of MapBuilder.
 func NewMapBuilder[K, V any](hasher Hasher[K]) *MapBuilder[K, V] {
-	return &MapBuilder[K, V]{m: NewMap[K, V](hasher)}
+	return &MapBuilder[K, V]{m: NewMap[K, V](hasher), hasher: hasher, owner: new(owner)}
 }
 
 // Map returns the underlying map. Only call once.
@@ -215,6 +587,25 @@ func (b *MapBuilder[K, V]) Map() *Map[K, V] {
 	return m
 }
 
+// TryMap is like Map but returns ErrBuilderFinalized instead of panicking if
+// the map has already been fetched.
+func (b *MapBuilder[K, V]) TryMap() (*Map[K, V], error) {
+	if b.m == nil {
+		return nil, ErrBuilderFinalized
+	}
+	return b.Map(), nil
+}
+
+// Snapshot returns the current state of the map without invalidating the
+// builder. The snapshot is unaffected by later changes to the builder, which
+// copies each node it shares with the snapshot on its first update and then
+// updates the copy in place.
+func (b *MapBuilder[K, V]) Snapshot() *Map[K, V] {
+	assert(b.m != nil, "immutable.MapBuilder: builder invalid after Map() invocation")
+	b.owner = new(owner)
+	return b.m.clone()
+}
+
 // Len returns the number of elements in the underlying map.
 func (b *MapBuilder[K, V]) Len() int {
 	assert(b.m != nil, "immutable.MapBuilder: builder invalid after Map() invocation")
@@ -230,26 +621,49 @@ func (b *MapBuilder[K, V]) Get(key K) (value V, ok bool) {
 // Set sets the value of the given key. See Map.Set() for additional details.
 func (b *MapBuilder[K, V]) Set(key K, value V) {
 	assert(b.m != nil, "immutable.MapBuilder: builder invalid after Map() invocation")
-	b.m = b.m.set(key, value, true)
+	b.m = b.m.set(key, value, b.owner)
 }
 
 // Delete removes the given key. See Map.Delete() for additional details.
 func (b *MapBuilder[K, V]) Delete(key K) {
 	assert(b.m != nil, "immutable.MapBuilder: builder invalid after Map() invocation")
-	b.m = b.m.delete(key, true)
+	b.m = b.m.delete(key, b.owner)
 }
 
-// Iterator returns a new iterator for the underlying map.
+// Iterator returns a new iterator over a snapshot of the underlying map, so
+// the builder may be updated during iteration. As after Snapshot, each node
+// the iterator shares is copied by the next update that touches it.
 func (b *MapBuilder[K, V]) Iterator() *MapIterator[K, V] {
+	return b.Snapshot().Iterator()
+}
+
+// Range calls fn for each key/value pair in the underlying map until fn
+// returns false. fn must not update the builder; use Iterator to update the
+// builder while iterating.
+func (b *MapBuilder[K, V]) Range(fn func(key K, value V) bool) {
 	assert(b.m != nil, "immutable.MapBuilder: builder invalid after Map() invocation")
-	return b.m.Iterator()
+	b.m.Range(fn)
+}
+
+// Reset discards the contents of the builder. A builder that has been reset
+// is valid again after Map() and can be reused.
+func (b *MapBuilder[K, V]) Reset() {
+	b.m = NewMap[K, V](b.hasher)
+	b.owner = new(owner)
+}
+
+// TakeAndReset returns the map and resets the builder for reuse.
+func (b *MapBuilder[K, V]) TakeAndReset() *Map[K, V] {
+	m := b.Map()
+	b.Reset()
+	return m
 }
 
 // mapNode represents any node in the map tree.
 type mapNode[K, V any] interface {
 	get(key K, shift uint, keyHash uint32, h Hasher[K]) (value V, ok bool)
-	set(key K, value V, shift uint, keyHash uint32, h Hasher[K], mutable bool, resized *bool) mapNode[K, V]
-	delete(key K, shift uint, keyHash uint32, h Hasher[K], mutable bool, resized *bool) mapNode[K, V]
+	set(key K, value V, shift uint, keyHash uint32, h Hasher[K], o *owner, resized *bool) mapNode[K, V]
+	delete(key K, shift uint, keyHash uint32, h Hasher[K], o *owner, resized *bool) mapNode[K, V]
 }
 
 var _ mapNode[string, any] = (*mapArrayNode[string, any])(nil)
@@ -272,6 +686,7 @@ var _ mapLeafNode[string, any] = (*mapHashCollisionNode[string, any])(nil)
 // indexed node once a given threshold size is crossed.
 type mapArrayNode[K, V any] struct {
 	entries []mapEntry[K, V]
+	owner   *owner // owner allowed to update the node in place
 }
 
 // indexOf returns the entry index of the given key. Returns -1 if key not found.
@@ -295,7 +710,7 @@ func (n *mapArrayNode[K, V]) get(key K, shift uint, keyHash uint32, h Hasher[K])
 
 // set inserts or updates the value for a given key. If the key is inserted and
 // the new size crosses the max size threshold, a bitmap indexed node is returned.
-func (n *mapArrayNode[K, V]) set(key K, value V, shift uint, keyHash uint32, h Hasher[K], mutable bool, resized *bool) mapNode[K, V] {
+func (n *mapArrayNode[K, V]) set(key K, value V, shift uint, keyHash uint32, h Hasher[K], o *owner, resized *bool) mapNode[K, V] {
 	idx := n.indexOf(key, h)
 
 	// Mark as resized if the key doesn't exist.
@@ -306,15 +721,15 @@ func (n *mapArrayNode[K, V]) set(key K, value V, shift uint, keyHash uint32, h H
 	// If we are adding and it crosses the max size threshold, expand the node.
 	// We do this by continually setting the entries to a value node and expanding.
 	if idx == -1 && len(n.entries) >= maxArrayMapSize {
-		var node mapNode[K, V] = newMapValueNode(h.Hash(key), key, value)
+		var node mapNode[K, V] = newMapValueNode(h.Hash(key), key, value, o)
 		for _, entry := range n.entries {
-			node = node.set(entry.key, entry.value, 0, h.Hash(entry.key), h, false, resized)
+			node = node.set(entry.key, entry.value, 0, h.Hash(entry.key), h, o, resized)
 		}
 		return node
 	}
 
-	// Update in-place if mutable.
-	if mutable {
+	// Update in-place if owned.
+	if o.owns(n.owner) {
 		if idx != -1 {
 			n.entries[idx] = mapEntry[K, V]{key, value}
 		} else {
@@ -325,7 +740,7 @@ func (n *mapArrayNode[K, V]) set(key K, value V, shift uint, keyHash uint32, h H
 
 	// Update existing entry if a match is found.
 	// Otherwise append to the end of the element list if it doesn't exist.
-	var other mapArrayNode[K, V]
+	other := mapArrayNode[K, V]{owner: o}
 	if idx != -1 {
 		other.entries = make([]mapEntry[K, V], len(n.entries))
 		copy(other.entries, n.entries)
@@ -340,7 +755,7 @@ func (n *mapArrayNode[K, V]) set(key K, value V, shift uint, keyHash uint32, h H
 
 // delete removes the given key from the node. Returns the same node if key does
 // not exist. Returns a nil node when removing the last entry.
-func (n *mapArrayNode[K, V]) delete(key K, shift uint, keyHash uint32, h Hasher[K], mutable bool, resized *bool) mapNode[K, V] {
+func (n *mapArrayNode[K, V]) delete(key K, shift uint, keyHash uint32, h Hasher[K], o *owner, resized *bool) mapNode[K, V] {
 	idx := n.indexOf(key, h)
 
 	// Return original node if key does not exist.
@@ -354,8 +769,8 @@ func (n *mapArrayNode[K, V]) delete(key K, shift uint, keyHash uint32, h Hasher[
 		return nil
 	}
 
-	// Update in-place, if mutable.
-	if mutable {
+	// Update in-place, if owned.
+	if o.owns(n.owner) {
 		copy(n.entries[idx:], n.entries[idx+1:])
 		n.entries[len(n.entries)-1] = mapEntry[K, V]{}
 		n.entries = n.entries[:len(n.entries)-1]
@@ -363,7 +778,7 @@ func (n *mapArrayNode[K, V]) delete(key K, shift uint, keyHash uint32, h Hasher[
 	}
 
 	// Otherwise create a copy with the given entry removed.
-	other := &mapArrayNode[K, V]{entries: make([]mapEntry[K, V], len(n.entries)-1)}
+	other := &mapArrayNode[K, V]{entries: make([]mapEntry[K, V], len(n.entries)-1), owner: o}
 	copy(other.entries[:idx], n.entries[:idx])
 	copy(other.entries[idx:], n.entries[idx+1:])
 	return other
@@ -375,6 +790,7 @@ func (n *mapArrayNode[K, V]) delete(key K, shift uint, keyHash uint32, h Hasher[
 type mapBitmapIndexedNode[K, V any] struct {
 	bitmap uint32
 	nodes  []mapNode[K, V]
+	owner  *owner // owner allowed to update the node in place
 }
 
 // get returns the value for the given key.
@@ -389,7 +805,7 @@ func (n *mapBitmapIndexedNode[K, V]) get(key K, shift uint, keyHash uint32, h Ha
 
 // set inserts or updates the value for the given key. If a new key is inserted
 // and the size crosses the max size threshold then a hash array node is returned.
-func (n *mapBitmapIndexedNode[K, V]) set(key K, value V, shift uint, keyHash uint32, h Hasher[K], mutable bool, resized *bool) mapNode[K, V] {
+func (n *mapBitmapIndexedNode[K, V]) set(key K, value V, shift uint, keyHash uint32, h Hasher[K], o *owner, resized *bool) mapNode[K, V] {
 	// Extract the index for the bit segment of the key hash.
 	keyHashFrag := (keyHash >> shift) & mapNodeMask
 
@@ -409,15 +825,15 @@ func (n *mapBitmapIndexedNode[K, V]) set(key K, value V, shift uint, keyHash uin
 	// If the node doesn't exist then create a simple value leaf node.
 	var newNode mapNode[K, V]
 	if exists {
-		newNode = n.nodes[idx].set(key, value, shift+mapNodeBits, keyHash, h, mutable, resized)
+		newNode = n.nodes[idx].set(key, value, shift+mapNodeBits, keyHash, h, o, resized)
 	} else {
-		newNode = newMapValueNode(keyHash, key, value)
+		newNode = newMapValueNode(keyHash, key, value, o)
 	}
 
 	// Convert to a hash-array node once we exceed the max bitmap size.
 	// Copy each node based on their bit position within the bitmap.
 	if !exists && len(n.nodes) > maxBitmapIndexedSize {
-		var other mapHashArrayNode[K, V]
+		other := mapHashArrayNode[K, V]{owner: o}
 		for i := uint(0); i < uint(len(other.nodes)); i++ {
 			if n.bitmap&(uint32(1)<<i) != 0 {
 				other.nodes[i] = n.nodes[other.count]
@@ -429,8 +845,8 @@ func (n *mapBitmapIndexedNode[K, V]) set(key K, value V, shift uint, keyHash uin
 		return &other
 	}
 
-	// Update in-place if mutable.
-	if mutable {
+	// Update in-place if owned.
+	if o.owns(n.owner) {
 		if exists {
 			n.nodes[idx] = newNode
 		} else {
@@ -444,7 +860,7 @@ func (n *mapBitmapIndexedNode[K, V]) set(key K, value V, shift uint, keyHash uin
 
 	// If node exists at given slot then overwrite it with new node.
 	// Otherwise expand the node list and insert new node into appropriate position.
-	other := &mapBitmapIndexedNode[K, V]{bitmap: n.bitmap | bit}
+	other := &mapBitmapIndexedNode[K, V]{bitmap: n.bitmap | bit, owner: o}
 	if exists {
 		other.nodes = make([]mapNode[K, V], len(n.nodes))
 		copy(other.nodes, n.nodes)
@@ -461,7 +877,7 @@ func (n *mapBitmapIndexedNode[K, V]) set(key K, value V, shift uint, keyHash uin
 // delete removes the key from the tree. If the key does not exist then the
 // original node is returned. If removing the last child node then a nil is
 // returned. Note that shrinking the node will not convert it to an array node.
-func (n *mapBitmapIndexedNode[K, V]) delete(key K, shift uint, keyHash uint32, h Hasher[K], mutable bool, resized *bool) mapNode[K, V] {
+func (n *mapBitmapIndexedNode[K, V]) delete(key K, shift uint, keyHash uint32, h Hasher[K], o *owner, resized *bool) mapNode[K, V] {
 	bit := uint32(1) << ((keyHash >> shift) & mapNodeMask)
 
 	// Return original node if key does not exist.
@@ -474,7 +890,7 @@ func (n *mapBitmapIndexedNode[K, V]) delete(key K, shift uint, keyHash uint32, h
 
 	// Delegate delete to child node.
 	child := n.nodes[idx]
-	newChild := child.delete(key, shift+mapNodeBits, keyHash, h, mutable, resized)
+	newChild := child.delete(key, shift+mapNodeBits, keyHash, h, o, resized)
 
 	// Return original node if key doesn't exist in child.
 	if !*resized {
@@ -488,8 +904,8 @@ func (n *mapBitmapIndexedNode[K, V]) delete(key K, shift uint, keyHash uint32, h
 			return nil
 		}
 
-		// Update in-place if mutable.
-		if mutable {
+		// Update in-place if owned.
+		if o.owns(n.owner) {
 			n.bitmap ^= bit
 			copy(n.nodes[idx:], n.nodes[idx+1:])
 			n.nodes[len(n.nodes)-1] = nil
@@ -498,7 +914,7 @@ func (n *mapBitmapIndexedNode[K, V]) delete(key K, shift uint, keyHash uint32, h
 		}
 
 		// Return copy with bit removed from bitmap and node removed from node list.
-		other := &mapBitmapIndexedNode[K, V]{bitmap: n.bitmap ^ bit, nodes: make([]mapNode[K, V], len(n.nodes)-1)}
+		other := &mapBitmapIndexedNode[K, V]{bitmap: n.bitmap ^ bit, nodes: make([]mapNode[K, V], len(n.nodes)-1), owner: o}
 		copy(other.nodes[:idx], n.nodes[:idx])
 		copy(other.nodes[idx:], n.nodes[idx+1:])
 		return other
@@ -506,8 +922,8 @@ func (n *mapBitmapIndexedNode[K, V]) delete(key K, shift uint, keyHash uint32, h
 
 	// Generate copy, if necessary.
 	other := n
-	if !mutable {
-		other = &mapBitmapIndexedNode[K, V]{bitmap: n.bitmap, nodes: make([]mapNode[K, V], len(n.nodes))}
+	if !o.owns(n.owner) {
+		other = &mapBitmapIndexedNode[K, V]{bitmap: n.bitmap, nodes: make([]mapNode[K, V], len(n.nodes)), owner: o}
 		copy(other.nodes, n.nodes)
 	}
 
@@ -521,11 +937,13 @@ func (n *mapBitmapIndexedNode[K, V]) delete(key K, shift uint, keyHash uint32, h
 type mapHashArrayNode[K, V any] struct {
 	count uint                       // number of set nodes
 	nodes [mapNodeSize]mapNode[K, V] // child node slots, may contain empties
+	owner *owner                     // owner allowed to update the node in place
 }
 
-// clone returns a shallow copy of n.
-func (n *mapHashArrayNode[K, V]) clone() *mapHashArrayNode[K, V] {
+// clone returns a shallow copy of n stamped with o.
+func (n *mapHashArrayNode[K, V]) clone(o *owner) *mapHashArrayNode[K, V] {
 	other := *n
+	other.owner = o
 	return &other
 }
 
@@ -539,7 +957,7 @@ func (n *mapHashArrayNode[K, V]) get(key K, shift uint, keyHash uint32, h Hasher
 }
 
 // set returns a node with the value set for the given key.
-func (n *mapHashArrayNode[K, V]) set(key K, value V, shift uint, keyHash uint32, h Hasher[K], mutable bool, resized *bool) mapNode[K, V] {
+func (n *mapHashArrayNode[K, V]) set(key K, value V, shift uint, keyHash uint32, h Hasher[K], o *owner, resized *bool) mapNode[K, V] {
 	idx := (keyHash >> shift) & mapNodeMask
 	node := n.nodes[idx]
 
@@ -548,15 +966,15 @@ func (n *mapHashArrayNode[K, V]) set(key K, value V, shift uint, keyHash uint32,
 	var newNode mapNode[K, V]
 	if node == nil {
 		*resized = true
-		newNode = newMapValueNode(keyHash, key, value)
+		newNode = newMapValueNode(keyHash, key, value, o)
 	} else {
-		newNode = node.set(key, value, shift+mapNodeBits, keyHash, h, mutable, resized)
+		newNode = node.set(key, value, shift+mapNodeBits, keyHash, h, o, resized)
 	}
 
 	// Generate copy, if necessary.
 	other := n
-	if !mutable {
-		other = n.clone()
+	if !o.owns(n.owner) {
+		other = n.clone(o)
 	}
 
 	// Update child node (and update size, if new).
@@ -570,7 +988,7 @@ func (n *mapHashArrayNode[K, V]) set(key K, value V, shift uint, keyHash uint32,
 // delete returns a node with the given key removed. Returns the same node if
 // the key does not exist. If node shrinks to within bitmap-indexed size then
 // converts to a bitmap-indexed node.
-func (n *mapHashArrayNode[K, V]) delete(key K, shift uint, keyHash uint32, h Hasher[K], mutable bool, resized *bool) mapNode[K, V] {
+func (n *mapHashArrayNode[K, V]) delete(key K, shift uint, keyHash uint32, h Hasher[K], o *owner, resized *bool) mapNode[K, V] {
 	idx := (keyHash >> shift) & mapNodeMask
 	node := n.nodes[idx]
 
@@ -580,14 +998,14 @@ func (n *mapHashArrayNode[K, V]) delete(key K, shift uint, keyHash uint32, h Has
 	}
 
 	// Return original node if child is unchanged.
-	newNode := node.delete(key, shift+mapNodeBits, keyHash, h, mutable, resized)
+	newNode := node.delete(key, shift+mapNodeBits, keyHash, h, o, resized)
 	if !*resized {
 		return n
 	}
 
 	// If we remove a node and drop below a threshold, convert back to bitmap indexed node.
 	if newNode == nil && n.count <= maxBitmapIndexedSize {
-		other := &mapBitmapIndexedNode[K, V]{nodes: make([]mapNode[K, V], 0, n.count-1)}
+		other := &mapBitmapIndexedNode[K, V]{nodes: make([]mapNode[K, V], 0, n.count-1), owner: o}
 		for i, child := range n.nodes {
 			if child != nil && uint32(i) != idx {
 				other.bitmap |= 1 << uint(i)
@@ -599,8 +1017,8 @@ func (n *mapHashArrayNode[K, V]) delete(key K, shift uint, keyHash uint32, h Has
 
 	// Generate copy, if necessary.
 	other := n
-	if !mutable {
-		other = n.clone()
+	if !o.owns(n.owner) {
+		other = n.clone(o)
 	}
 
 	// Return copy of node with child updated.
@@ -618,14 +1036,16 @@ type mapValueNode[K, V any] struct {
 	keyHash uint32
 	key     K
 	value   V
+	owner   *owner // owner allowed to update the node in place
 }
 
-// newMapValueNode returns a new instance of mapValueNode.
-func newMapValueNode[K, V any](keyHash uint32, key K, value V) *mapValueNode[K, V] {
+// newMapValueNode returns a new instance of mapValueNode stamped with o.
+func newMapValueNode[K, V any](keyHash uint32, key K, value V, o *owner) *mapValueNode[K, V] {
 	return &mapValueNode[K, V]{
 		keyHash: keyHash,
 		key:     key,
 		value:   value,
+		owner:   o,
 	}
 }
 
@@ -646,34 +1066,34 @@ func (n *mapValueNode[K, V]) get(key K, shift uint, keyHash uint32, h Hasher[K])
 // the node's key then a new value node is returned. If key is not equal to the
 // node's key but has the same hash then a hash collision node is returned.
 // Otherwise the nodes are merged into a branch node.
-func (n *mapValueNode[K, V]) set(key K, value V, shift uint, keyHash uint32, h Hasher[K], mutable bool, resized *bool) mapNode[K, V] {
+func (n *mapValueNode[K, V]) set(key K, value V, shift uint, keyHash uint32, h Hasher[K], o *owner, resized *bool) mapNode[K, V] {
 	// If the keys match then return a new value node overwriting the value.
 	if h.Equal(n.key, key) {
-		// Update in-place if mutable.
-		if mutable {
+		// Update in-place if owned.
+		if o.owns(n.owner) {
 			n.value = value
 			return n
 		}
 		// Otherwise return a new copy.
-		return newMapValueNode(n.keyHash, key, value)
+		return newMapValueNode(n.keyHash, key, value, o)
 	}
 
 	*resized = true
 
 	// Recursively merge nodes together if key hashes are different.
 	if n.keyHash != keyHash {
-		return mergeIntoNode[K, V](n, shift, keyHash, key, value)
+		return mergeIntoNode[K, V](n, shift, keyHash, key, value, o)
 	}
 
 	// Merge into collision node if hash matches.
 	return &mapHashCollisionNode[K, V]{keyHash: keyHash, entries: []mapEntry[K, V]{
 		{key: n.key, value: n.value},
 		{key: key, value: value},
-	}}
+	}, owner: o}
 }
 
 // delete returns nil if the key matches the node's key. Otherwise returns the original node.
-func (n *mapValueNode[K, V]) delete(key K, shift uint, keyHash uint32, h Hasher[K], mutable bool, resized *bool) mapNode[K, V] {
+func (n *mapValueNode[K, V]) delete(key K, shift uint, keyHash uint32, h Hasher[K], o *owner, resized *bool) mapNode[K, V] {
 	// Return original node if the keys do not match.
 	if !h.Equal(n.key, key) {
 		return n
@@ -689,6 +1109,7 @@ func (n *mapValueNode[K, V]) delete(key K, shift uint, keyHash uint32, h Hasher[
 type mapHashCollisionNode[K, V any] struct {
 	keyHash uint32 // key hash for all entries
 	entries []mapEntry[K, V]
+	owner   *owner // owner allowed to update the node in place
 }
 
 // keyHashValue returns the key hash for all entries on the node.
@@ -718,15 +1139,15 @@ func (n *mapHashCollisionNode[K, V]) get(key K, shift uint, keyHash uint32, h Ha
 }
 
 // set returns a copy of the node with key set to the given value.
-func (n *mapHashCollisionNode[K, V]) set(key K, value V, shift uint, keyHash uint32, h Hasher[K], mutable bool, resized *bool) mapNode[K, V] {
+func (n *mapHashCollisionNode[K, V]) set(key K, value V, shift uint, keyHash uint32, h Hasher[K], o *owner, resized *bool) mapNode[K, V] {
 	// Merge node with key/value pair if this is not a hash collision.
 	if n.keyHash != keyHash {
 		*resized = true
-		return mergeIntoNode[K, V](n, shift, keyHash, key, value)
+		return mergeIntoNode[K, V](n, shift, keyHash, key, value, o)
 	}
 
-	// Update in-place if mutable.
-	if mutable {
+	// Update in-place if owned.
+	if o.owns(n.owner) {
 		if idx := n.indexOf(key, h); idx == -1 {
 			*resized = true
 			n.entries = append(n.entries, mapEntry[K, V]{key, value})
@@ -738,7 +1159,7 @@ func (n *mapHashCollisionNode[K, V]) set(key K, value V, shift uint, keyHash uin
 
 	// Append to end of node if key doesn't exist & mark resized.
 	// Otherwise copy nodes and overwrite at matching key index.
-	other := &mapHashCollisionNode[K, V]{keyHash: n.keyHash}
+	other := &mapHashCollisionNode[K, V]{keyHash: n.keyHash, owner: o}
 	if idx := n.indexOf(key, h); idx == -1 {
 		*resized = true
 		other.entries = make([]mapEntry[K, V], len(n.entries)+1)
@@ -755,7 +1176,7 @@ func (n *mapHashCollisionNode[K, V]) set(key K, value V, shift uint, keyHash uin
 // delete returns a node with the given key deleted. Returns the same node if
 // the key does not exist. If removing the key would shrink the node to a single
 // entry then a value node is returned.
-func (n *mapHashCollisionNode[K, V]) delete(key K, shift uint, keyHash uint32, h Hasher[K], mutable bool, resized *bool) mapNode[K, V] {
+func (n *mapHashCollisionNode[K, V]) delete(key K, shift uint, keyHash uint32, h Hasher[K], o *owner, resized *bool) mapNode[K, V] {
 	idx := n.indexOf(key, h)
 
 	// Return original node if key is not found.
@@ -772,11 +1193,12 @@ func (n *mapHashCollisionNode[K, V]) delete(key K, shift uint, keyHash uint32, h
 			keyHash: n.keyHash,
 			key:     n.entries[idx^1].key,
 			value:   n.entries[idx^1].value,
+			owner:   o,
 		}
 	}
 
-	// Remove entry in-place if mutable.
-	if mutable {
+	// Remove entry in-place if owned.
+	if o.owns(n.owner) {
 		copy(n.entries[idx:], n.entries[idx+1:])
 		n.entries[len(n.entries)-1] = mapEntry[K, V]{}
 		n.entries = n.entries[:len(n.entries)-1]
@@ -784,24 +1206,24 @@ func (n *mapHashCollisionNode[K, V]) delete(key K, shift uint, keyHash uint32, h
 	}
 
 	// Return copy without entry if immutable.
-	other := &mapHashCollisionNode[K, V]{keyHash: n.keyHash, entries: make([]mapEntry[K, V], len(n.entries)-1)}
+	other := &mapHashCollisionNode[K, V]{keyHash: n.keyHash, entries: make([]mapEntry[K, V], len(n.entries)-1), owner: o}
 	copy(other.entries[:idx], n.entries[:idx])
 	copy(other.entries[idx:], n.entries[idx+1:])
 	return other
 }
 
-// mergeIntoNode merges a key/value pair into an existing node.
-// Caller must verify that node's keyHash is not equal to keyHash.
-func mergeIntoNode[K, V any](node mapLeafNode[K, V], shift uint, keyHash uint32, key K, value V) mapNode[K, V] {
+// mergeIntoNode merges a key/value pair into an existing node, stamping the
+// new nodes with o. Caller must verify that node's keyHash is not equal to keyHash.
+func mergeIntoNode[K, V any](node mapLeafNode[K, V], shift uint, keyHash uint32, key K, value V, o *owner) mapNode[K, V] {
 	idx1 := (node.keyHashValue() >> shift) & mapNodeMask
 	idx2 := (keyHash >> shift) & mapNodeMask
 
 	// Recursively build branch nodes to combine the node and its key.
-	other := &mapBitmapIndexedNode[K, V]{bitmap: (1 << idx1) | (1 << idx2)}
+	other := &mapBitmapIndexedNode[K, V]{bitmap: (1 << idx1) | (1 << idx2), owner: o}
 	if idx1 == idx2 {
-		other.nodes = []mapNode[K, V]{mergeIntoNode(node, shift+mapNodeBits, keyHash, key, value)}
+		other.nodes = []mapNode[K, V]{mergeIntoNode(node, shift+mapNodeBits, keyHash, key, value, o)}
 	} else {
-		if newNode := newMapValueNode(keyHash, key, value); idx1 < idx2 {
+		if newNode := newMapValueNode(keyHash, key, value, o); idx1 < idx2 {
 			other.nodes = []mapNode[K, V]{node, newNode}
 		} else {
 			other.nodes = []mapNode[K, V]{newNode, node}
@@ -810,12 +1232,199 @@ func mergeIntoNode[K, V any](node mapLeafNode[K, V], shift uint, keyHash uint32,
 	return other
 }
 
+// mapHashedEntry is a key/value pair with its precomputed key hash.
+type mapHashedEntry[K, V any] struct {
+	hash  uint32
+	key   K
+	value V
+}
+
+// mapSetGroup inserts entries into node, descending once per shared hash
+// fragment instead of once per entry. Entries must be ordered by their
+// bit-reversed hash, so that entries sharing a path through the trie are
+// adjacent at every depth, contain no duplicate keys, and share all hash
+// fragments below shift. Nodes owned by o are updated in place; every other
+// node on a touched path is copied once and the copy stamped with o. New value
+// and hash array nodes are taken from arena, which may be nil. The number of
+// newly inserted keys is added to added.
+func mapSetGroup[K, V any](node mapNode[K, V], entries []mapHashedEntry[K, V], shift uint, h Hasher[K], o *owner, arena *mapNodeArena[K, V], added *int) mapNode[K, V] {
+	switch n := node.(type) {
+	case *mapHashArrayNode[K, V]:
+		if !o.owns(n.owner) {
+			n = n.clone(o)
+		}
+		for len(entries) > 0 {
+			frag := (entries[0].hash >> shift) & mapNodeMask
+			group := mapHashedGroup(entries, shift)
+			entries = entries[len(group):]
+
+			child := n.nodes[frag]
+			if child == nil {
+				child = arena.newValueNode(group[0].hash, group[0].key, group[0].value, o)
+				group = group[1:]
+				n.count++
+				*added++
+			}
+			if len(group) > 0 {
+				child = mapSetGroup(child, group, shift+mapNodeBits, h, o, arena, added)
+			}
+			n.nodes[frag] = child
+		}
+		return n
+
+	case *mapBitmapIndexedNode[K, V]:
+		// Find the slots this batch adds so the node is resized at most once.
+		var newBits uint32
+		for _, e := range entries {
+			newBits |= uint32(1) << ((e.hash >> shift) & mapNodeMask)
+		}
+		newBits &^= n.bitmap
+
+		// Convert to a hash array node if the new slots exceed what set would
+		// allow a bitmap indexed node to hold.
+		if bits.OnesCount32(n.bitmap|newBits) > maxBitmapIndexedSize+1 {
+			other := arena.newHashArrayNode(o)
+			other.count = uint(len(n.nodes))
+			for i, j := uint(0), 0; i < mapNodeSize; i++ {
+				if n.bitmap&(uint32(1)<<i) != 0 {
+					other.nodes[i] = n.nodes[j]
+					j++
+				}
+			}
+			return mapSetGroup(other, entries, shift, h, o, arena, added)
+		}
+
+		// Copy shared nodes once, after which the batch is applied in place.
+		if !o.owns(n.owner) {
+			nodes := make([]mapNode[K, V], len(n.nodes), len(n.nodes)+bits.OnesCount32(newBits))
+			copy(nodes, n.nodes)
+			n = &mapBitmapIndexedNode[K, V]{bitmap: n.bitmap, nodes: nodes, owner: o}
+		}
+
+		// Open empty slots for the new children, growing the node list at most once.
+		if newBits != 0 {
+			if total := bits.OnesCount32(n.bitmap | newBits); cap(n.nodes) < total {
+				nodes := make([]mapNode[K, V], len(n.nodes), min(2*total, maxBitmapIndexedSize+1))
+				copy(nodes, n.nodes)
+				n.nodes = nodes
+			}
+			// Move existing children back to front, one segment per new slot.
+			bitmap := n.bitmap | newBits
+			src, hi := len(n.nodes), bits.OnesCount32(bitmap)
+			n.nodes = n.nodes[:hi]
+			for nb := newBits; nb != 0; nb &^= 1 << (31 - bits.LeadingZeros32(nb)) {
+				bit := uint32(1) << (31 - bits.LeadingZeros32(nb))
+				idx := bits.OnesCount32(bitmap & (bit - 1))
+				count := hi - idx - 1
+				copy(n.nodes[idx+1:hi], n.nodes[src-count:src])
+				n.nodes[idx] = nil
+				src, hi = src-count, idx
+			}
+			n.bitmap = bitmap
+		}
+
+		for len(entries) > 0 {
+			bit := uint32(1) << ((entries[0].hash >> shift) & mapNodeMask)
+			group := mapHashedGroup(entries, shift)
+			entries = entries[len(group):]
+
+			idx := bits.OnesCount32(n.bitmap & (bit - 1))
+			child := n.nodes[idx]
+			if child == nil {
+				child = arena.newValueNode(group[0].hash, group[0].key, group[0].value, o)
+				group = group[1:]
+				*added++
+			}
+			if len(group) > 0 {
+				child = mapSetGroup(child, group, shift+mapNodeBits, h, o, arena, added)
+			}
+			n.nodes[idx] = child
+		}
+		return n
+
+	default:
+		// Leaf and array nodes take entries one at a time. Once a set expands
+		// the node into a branch, the remaining entries are grouped again.
+		for i, e := range entries {
+			var resized bool
+			node = node.set(e.key, e.value, shift, e.hash, h, o, &resized)
+			if resized {
+				*added++
+			}
+			switch node.(type) {
+			case *mapHashArrayNode[K, V], *mapBitmapIndexedNode[K, V]:
+				return mapSetGroup(node, entries[i+1:], shift, h, o, arena, added)
+			}
+		}
+		return node
+	}
+}
+
+// mapArenaSlabSize is the number of nodes in each slab of a mapNodeArena.
+const mapArenaSlabSize = 256
+
+// mapNodeArena allocates map nodes from slabs rather than one at a time,
+// reducing allocations and GC work when a builder creates many nodes. A slab
+// is kept alive until none of its nodes are referenced, so an arena should
+// only be used for nodes the caller expects to retain. A nil arena allocates
+// each node individually.
+type mapNodeArena[K, V any] struct {
+	values     []mapValueNode[K, V]     // unused remainder of the value node slab
+	hashArrays []mapHashArrayNode[K, V] // unused remainder of the hash array node slab
+}
+
+// newValueNode returns a new value node for the given key/value pair, stamped
+// with o.
+func (a *mapNodeArena[K, V]) newValueNode(keyHash uint32, key K, value V, o *owner) *mapValueNode[K, V] {
+	if a == nil {
+		return newMapValueNode(keyHash, key, value, o)
+	}
+	if len(a.values) == 0 {
+		a.values = make([]mapValueNode[K, V], mapArenaSlabSize)
+	}
+	n := &a.values[0]
+	a.values = a.values[1:]
+	n.keyHash, n.key, n.value, n.owner = keyHash, key, value, o
+	return n
+}
+
+// newHashArrayNode returns a new, empty hash array node stamped with o.
+func (a *mapNodeArena[K, V]) newHashArrayNode(o *owner) *mapHashArrayNode[K, V] {
+	if a == nil {
+		return &mapHashArrayNode[K, V]{owner: o}
+	}
+	if len(a.hashArrays) == 0 {
+		a.hashArrays = make([]mapHashArrayNode[K, V], mapArenaSlabSize/16)
+	}
+	n := &a.hashArrays[0]
+	a.hashArrays = a.hashArrays[1:]
+	n.owner = o
+	return n
+}
+
+// mapHashedGroup returns the leading run of entries sharing the hash fragment
+// at shift with the first entry.
+func mapHashedGroup[K, V any](entries []mapHashedEntry[K, V], shift uint) []mapHashedEntry[K, V] {
+	frag := (entries[0].hash >> shift) & mapNodeMask
+	i := 1
+	for i < len(entries) && (entries[i].hash>>shift)&mapNodeMask == frag {
+		i++
+	}
+	return entries[:i]
+}
+
 // mapEntry represents a single key/value pair.
 type mapEntry[K, V any] struct {
 	key   K
 	value V
 }
 
+// MapEntry is a key/value pair passed to and from map builders.
+type MapEntry[K, V any] struct {
+	Key   K
+	Value V
+}
+
 // MapIterator represents an iterator over a map's key/value pairs. Although
 // map keys are not sorted, the iterator's order is deterministic.
 type MapIterator[K, V any] struct {
@@ -830,6 +1439,13 @@ func (itr *MapIterator[K, V]) Done() bool {
 	return itr.depth == -1
 }
 
+// Clone returns an independent iterator at the same position. Moving either
+// iterator does not affect the other.
+func (itr *MapIterator[K, V]) Clone() *MapIterator[K, V] {
+	other := *itr
+	return &other
+}
+
 // First resets the iterator to the first key/value pair.
 func (itr *MapIterator[K, V]) First() {
 	// Exit immediately if the map is empty.
@@ -852,20 +1468,31 @@ func (itr *MapIterator[K, V]) Next() (key K, value V, ok bool) {
 	}
 
 	// Retrieve current index & value. Current node is always a leaf.
+	// Entries after the current one in the same node are reached directly;
+	// otherwise next is left to find the following leaf.
 	elem := &itr.stack[itr.depth]
 	switch node := elem.node.(type) {
 	case *mapArrayNode[K, V]:
 		entry := &node.entries[elem.index]
 		key, value = entry.key, entry.value
+		if elem.index < len(node.entries)-1 {
+			elem.index++
+			return key, value, true
+		}
 	case *mapValueNode[K, V]:
 		key, value = node.key, node.value
 	case *mapHashCollisionNode[K, V]:
 		entry := &node.entries[elem.index]
 		key, value = entry.key, entry.value
+		if elem.index < len(node.entries)-1 {
+			elem.index++
+			return key, value, true
+		}
 	}
 
 	// Move up stack until we find a node that has remaining position ahead
-	// and move that element forward by one.
+	// and move that element forward by one. The current leaf has none.
+	itr.depth--
 	itr.next()
 	return key, value, true
 }
@@ -979,8 +1606,9 @@ func NewSortedMapOf[K comparable, V any](comparer Comparer[K], entries map[K]V)
 	m := &SortedMap[K, V]{
 		comparer: comparer,
 	}
+	o := new(owner)
 	for k, v := range entries {
-		m.set(k, v, true)
+		m.set(k, v, o)
 	}
 	return m
 }
@@ -1002,10 +1630,10 @@ func (m *SortedMap[K, V]) Get(key K) (V, bool) {
 
 // Set returns a copy of the map with the key set to the given value.
 func (m *SortedMap[K, V]) Set(key K, value V) *SortedMap[K, V] {
-	return m.set(key, value, false)
+	return m.set(key, value, nil)
 }
 
-func (m *SortedMap[K, V]) set(key K, value V, mutable bool) *SortedMap[K, V] {
+func (m *SortedMap[K, V]) set(key K, value V, o *owner) *SortedMap[K, V] {
 	// Set a comparer on the first value if one does not already exist.
 	comparer := m.comparer
 	if comparer == nil {
@@ -1014,7 +1642,7 @@ func (m *SortedMap[K, V]) set(key K, value V, mutable bool) *SortedMap[K, V] {
 
 	// Create copy, if necessary.
 	other := m
-	if !mutable {
+	if o == nil {
 		other = m.clone()
 	}
 	other.comparer = comparer
@@ -1022,16 +1650,28 @@ func (m *SortedMap[K, V]) set(key K, value V, mutable bool) *SortedMap[K, V] {
 	// If no values are set then initialize with a leaf node.
 	if m.root == nil {
 		other.size = 1
-		other.root = &sortedMapLeafNode[K, V]{entries: []mapEntry[K, V]{{key: key, value: value}}}
+		other.root = &sortedMapLeafNode[K, V]{entries: []mapEntry[K, V]{{key: key, value: value}}, owner: o}
+		return other
+	}
+
+	// Keys beyond the current maximum, as in ascending inserts, are appended
+	// along the right edge without searching each node.
+	if comparer.Compare(key, m.maxKey()) > 0 {
+		newRoot, splitNode := sortedMapAppendEntry(m.root, mapEntry[K, V]{key: key, value: value}, o)
+		if splitNode != nil {
+			newRoot = newSortedMapBranchNode(o, newRoot, splitNode)
+		}
+		other.size = m.size + 1
+		other.root = newRoot
 		return other
 	}
 
 	// Otherwise delegate to root node.
 	// If a split occurs then grow the tree from the root.
 	var resized bool
-	newRoot, splitNode := m.root.set(key, value, comparer, mutable, &resized)
+	newRoot, splitNode := m.root.set(key, value, comparer, o, &resized)
 	if splitNode != nil {
-		newRoot = newSortedMapBranchNode(newRoot, splitNode)
+		newRoot = newSortedMapBranchNode(o, newRoot, splitNode)
 	}
 
 	// Update root and size (if resized).
@@ -1043,13 +1683,186 @@ func (m *SortedMap[K, V]) set(key K, value V, mutable bool) *SortedMap[K, V] {
 	return other
 }
 
+// appendSorted bulk loads entries onto the right edge of the tree, filling the
+// rightmost leaf and adding new leaves and branches bottom-up instead of
+// inserting one entry at a time. Entries must be in ascending key order and all
+// greater than the map's current maximum key; equal adjacent keys are allowed
+// and the last one wins. Returns false without changing the map if the entries
+// do not meet these conditions.
+//
+// Nodes along the right edge owned by o are updated in place and the rest are
+// copied once. The map itself is updated in place so this may only be used on
+// maps exclusively owned by the caller, such as a builder's.
+func (m *SortedMap[K, V]) appendSorted(entries []mapEntry[K, V], o *owner) bool {
+	if len(entries) == 0 {
+		return true
+	}
+	comparer := m.comparer
+	if comparer == nil {
+		comparer = NewComparer(entries[0].key)
+	}
+
+	// Validate before touching the tree so callers can fall back to set().
+	if m.root != nil && comparer.Compare(entries[0].key, m.maxKey()) <= 0 {
+		return false
+	}
+	for i := 1; i < len(entries); i++ {
+		if comparer.Compare(entries[i-1].key, entries[i].key) > 0 {
+			return false
+		}
+	}
+
+	// Collapse runs of equal keys to their last entry.
+	n := 1
+	for i := 1; i < len(entries); i++ {
+		if comparer.Compare(entries[n-1].key, entries[i].key) == 0 {
+			entries[n-1] = entries[i]
+		} else {
+			entries[n] = entries[i]
+			n++
+		}
+	}
+	entries = entries[:n]
+
+	// Grow the tree upward until a single root remains.
+	nodes := sortedMapAppend(m.root, entries, o)
+	for len(nodes) > 1 {
+		var parents []sortedMapNode[K, V]
+		for len(nodes) > 0 {
+			k := min(len(nodes), sortedMapNodeSize)
+			parents = append(parents, newSortedMapBranchNode(o, nodes[:k]...))
+			nodes = nodes[k:]
+		}
+		nodes = parents
+	}
+	m.comparer = comparer
+	m.root = nodes[0]
+	m.size += len(entries)
+	return true
+}
+
+// maxKey returns the greatest key in the map. The map must not be empty.
+func (m *SortedMap[K, V]) maxKey() K {
+	node := m.root
+	for {
+		switch n := node.(type) {
+		case *sortedMapBranchNode[K, V]:
+			node = n.elems[len(n.elems)-1].node
+		case *sortedMapLeafNode[K, V]:
+			return n.entries[len(n.entries)-1].key
+		}
+	}
+}
+
+// sortedMapAppendEntry appends e, whose key is greater than every key in the
+// subtree rooted at node, to the subtree's rightmost leaf. Nodes on the right
+// edge are updated in place if owned by o and copied otherwise. A full node is
+// left as it is and a new sibling holding just the new entry or child is
+// returned alongside it, so ascending inserts fill every node rather than
+// leaving each half empty as a split in the middle would.
+func sortedMapAppendEntry[K, V any](node sortedMapNode[K, V], e mapEntry[K, V], o *owner) (sortedMapNode[K, V], sortedMapNode[K, V]) {
+	switch n := node.(type) {
+	case *sortedMapLeafNode[K, V]:
+		if len(n.entries) >= sortedMapNodeSize {
+			return n, &sortedMapLeafNode[K, V]{entries: []mapEntry[K, V]{e}, owner: o}
+		}
+		if o.owns(n.owner) {
+			n.entries = append(n.entries, e)
+			return n, nil
+		}
+		entries := make([]mapEntry[K, V], len(n.entries)+1)
+		copy(entries, n.entries)
+		entries[len(n.entries)] = e
+		return &sortedMapLeafNode[K, V]{entries: entries, owner: o}, nil
+
+	case *sortedMapBranchNode[K, V]:
+		last := len(n.elems) - 1
+		child, splitNode := sortedMapAppendEntry(n.elems[last].node, e, o)
+		if splitNode != nil && len(n.elems) >= sortedMapNodeSize {
+			// The child is unchanged when it splits, so this node is too.
+			return n, &sortedMapBranchNode[K, V]{elems: []sortedMapBranchElem[K, V]{{key: e.key, node: splitNode}}, owner: o}
+		}
+		other := n
+		if !o.owns(n.owner) {
+			size := len(n.elems)
+			if splitNode != nil {
+				size++
+			}
+			other = &sortedMapBranchNode[K, V]{elems: make([]sortedMapBranchElem[K, V], len(n.elems), size), owner: o}
+			copy(other.elems, n.elems)
+		}
+		other.elems[last].node = child
+		if splitNode != nil {
+			other.elems = append(other.elems, sortedMapBranchElem[K, V]{key: e.key, node: splitNode})
+		}
+		return other, nil
+	}
+	panic("immutable.SortedMap: unexpected node type")
+}
+
+// sortedMapAppend appends entries to the rightmost path of the subtree rooted
+// at node, updating nodes owned by o in place and copying the rest once. It
+// returns the updated node followed by any new siblings created at the same
+// level once it is full. A nil node is built from scratch.
+func sortedMapAppend[K, V any](node sortedMapNode[K, V], entries []mapEntry[K, V], o *owner) []sortedMapNode[K, V] {
+	var nodes []sortedMapNode[K, V]
+	switch n := node.(type) {
+	case nil:
+	case *sortedMapLeafNode[K, V]:
+		k := min(len(entries), sortedMapNodeSize-len(n.entries))
+		if k > 0 && !o.owns(n.owner) {
+			other := make([]mapEntry[K, V], len(n.entries), len(n.entries)+k)
+			copy(other, n.entries)
+			n = &sortedMapLeafNode[K, V]{entries: other, owner: o}
+		}
+		n.entries = append(n.entries, entries[:k]...)
+		entries = entries[k:]
+		nodes = append(nodes, n)
+	case *sortedMapBranchNode[K, V]:
+		if !o.owns(n.owner) {
+			other := make([]sortedMapBranchElem[K, V], len(n.elems))
+			copy(other, n.elems)
+			n = &sortedMapBranchNode[K, V]{elems: other, owner: o}
+		}
+		last := len(n.elems) - 1
+		children := sortedMapAppend(n.elems[last].node, entries, o)
+		n.elems[last].node = children[0]
+		for _, child := range children[1:] {
+			n.elems = append(n.elems, sortedMapBranchElem[K, V]{key: child.minKey(), node: child})
+		}
+		nodes = append(nodes, n)
+
+		// Split off full siblings if the branch overflowed.
+		if len(n.elems) > sortedMapNodeSize {
+			elems := n.elems[sortedMapNodeSize:]
+			n.elems = n.elems[:sortedMapNodeSize:sortedMapNodeSize]
+			for len(elems) > 0 {
+				k := min(len(elems), sortedMapNodeSize)
+				nodes = append(nodes, &sortedMapBranchNode[K, V]{elems: elems[:k:k], owner: o})
+				elems = elems[k:]
+			}
+		}
+		return nodes
+	}
+
+	// Pack remaining entries into full leaves.
+	for len(entries) > 0 {
+		k := min(len(entries), sortedMapNodeSize)
+		leaf := &sortedMapLeafNode[K, V]{entries: make([]mapEntry[K, V], k), owner: o}
+		copy(leaf.entries, entries[:k])
+		nodes = append(nodes, leaf)
+		entries = entries[k:]
+	}
+	return nodes
+}
+
 // Delete returns a copy of the map with the key removed.
 // Returns the original map if key does not exist.
 func (m *SortedMap[K, V]) Delete(key K) *SortedMap[K, V] {
-	return m.delete(key, false)
+	return m.delete(key, nil)
 }
 
-func (m *SortedMap[K, V]) delete(key K, mutable bool) *SortedMap[K, V] {
+func (m *SortedMap[K, V]) delete(key K, o *owner) *SortedMap[K, V] {
 	// Return original map if no keys exist.
 	if m.root == nil {
 		return m
@@ -1057,14 +1870,14 @@ func (m *SortedMap[K, V]) delete(key K, mutable bool) *SortedMap[K, V] {
 
 	// If the delete did not change the node then return the original map.
 	var resized bool
-	newRoot := m.root.delete(key, m.comparer, mutable, &resized)
+	newRoot := m.root.delete(key, m.comparer, o, &resized)
 	if !resized {
 		return m
 	}
 
 	// Create copy, if necessary.
 	other := m
-	if !mutable {
+	if o == nil {
 		other = m.clone()
 	}
 
@@ -1074,6 +1887,111 @@ func (m *SortedMap[K, V]) delete(key K, mutable bool) *SortedMap[K, V] {
 	return other
 }
 
+// DeleteRange returns a copy of the map without the keys from lo up to but not
+// including hi. If no keys are in the range the original map is returned.
+//
+// Subtrees entirely inside the range are dropped whole and those outside it
+// are shared with the original map, so only the nodes on the paths to lo and
+// hi are copied. Dropped subtrees are still visited to count their entries.
+func (m *SortedMap[K, V]) DeleteRange(lo, hi K) *SortedMap[K, V] {
+	if m.root == nil || m.comparer.Compare(lo, hi) >= 0 {
+		return m
+	}
+	return m.deleteRange(lo, &hi)
+}
+
+// deleteRange implements DeleteRange, without an upper bound if hi is nil.
+func (m *SortedMap[K, V]) deleteRange(lo K, hi *K) *SortedMap[K, V] {
+	var removed int
+	root := sortedMapDeleteRange(m.root, lo, hi, m.comparer, &removed)
+	if removed == 0 {
+		return m
+	}
+	// Remove levels left with a single child.
+	for {
+		if branch, ok := root.(*sortedMapBranchNode[K, V]); ok && len(branch.elems) == 1 {
+			root = branch.elems[0].node
+			continue
+		}
+		break
+	}
+
+	other := m.clone()
+	other.size = m.size - removed
+	other.root = root
+	return other
+}
+
+// sortedMapDeleteRange returns node without the keys from lo up to hi, or to
+// the end if hi is nil, or nil if none remain, adding the number of entries
+// removed to removed.
+func sortedMapDeleteRange[K, V any](node sortedMapNode[K, V], lo K, hi *K, c Comparer[K], removed *int) sortedMapNode[K, V] {
+	switch n := node.(type) {
+	case *sortedMapLeafNode[K, V]:
+		i, j := n.indexOf(lo, c), len(n.entries)
+		if hi != nil {
+			j = n.indexOf(*hi, c)
+		}
+		if i == j {
+			return n
+		} else if *removed += j - i; j-i == len(n.entries) {
+			return nil
+		}
+		other := &sortedMapLeafNode[K, V]{entries: make([]mapEntry[K, V], 0, len(n.entries)-(j-i))}
+		other.entries = append(append(other.entries, n.entries[:i]...), n.entries[j:]...)
+		return other
+
+	case *sortedMapBranchNode[K, V]:
+		// Only the children holding lo and hi are partly in the range; those
+		// between are removed whole.
+		i, j := n.indexOf(lo, c), len(n.elems)-1
+		if hi != nil {
+			j = n.indexOf(*hi, c)
+		}
+		before := *removed
+		first := sortedMapDeleteRange(n.elems[i].node, lo, hi, c, removed)
+		var last sortedMapNode[K, V]
+		if j > i {
+			for _, elem := range n.elems[i+1 : j] {
+				*removed += sortedMapNodeLen(elem.node)
+			}
+			last = sortedMapDeleteRange(n.elems[j].node, lo, hi, c, removed)
+		}
+		if *removed == before {
+			return n
+		}
+
+		other := &sortedMapBranchNode[K, V]{elems: make([]sortedMapBranchElem[K, V], 0, len(n.elems)-(j-i)+1)}
+		other.elems = append(other.elems, n.elems[:i]...)
+		for _, child := range []sortedMapNode[K, V]{first, last} {
+			if child != nil {
+				other.elems = append(other.elems, sortedMapBranchElem[K, V]{key: child.minKey(), node: child})
+			}
+		}
+		other.elems = append(other.elems, n.elems[j+1:]...)
+		if len(other.elems) == 0 {
+			return nil
+		}
+		return other
+	}
+	return node
+}
+
+// sortedMapNodeLen returns the number of entries in node and its descendants.
+func sortedMapNodeLen[K, V any](node sortedMapNode[K, V]) int {
+	switch n := node.(type) {
+	case *sortedMapLeafNode[K, V]:
+		return len(n.entries)
+	case *sortedMapBranchNode[K, V]:
+		var total int
+		for _, elem := range n.elems {
+			total += sortedMapNodeLen(elem.node)
+		}
+		return total
+	}
+	return 0
+}
+
 // clone returns a shallow copy of m.
 func (m *SortedMap[K, V]) clone() *SortedMap[K, V] {
 	other := *m
@@ -1087,14 +2005,85 @@ func (m *SortedMap[K, V]) Iterator() *SortedMapIterator[K, V] {
 	return itr
 }
 
+// Range calls fn for each key/value pair in key order until fn returns false.
+func (m *SortedMap[K, V]) Range(fn func(key K, value V) bool) {
+	for itr := m.Iterator(); !itr.Done(); {
+		if k, v, _ := itr.Next(); !fn(k, v) {
+			return
+		}
+	}
+}
+
+// AscendAfter returns up to limit entries with keys after cursor, in key
+// order, for paginating over the map. It also returns the cursor for the next
+// page, which is the last key returned or cursor if there are none, and
+// whether any keys remain after it. Only the entries returned are visited.
+func (m *SortedMap[K, V]) AscendAfter(cursor K, limit int) (entries []MapEntry[K, V], next K, more bool) {
+	entries = make([]MapEntry[K, V], 0, max(min(limit, m.size), 0))
+	next, more = m.page(cursor, limit, false, func(key K, value V) {
+		entries = append(entries, MapEntry[K, V]{Key: key, Value: value})
+	})
+	return entries, next, more
+}
+
+// DescendBefore is like AscendAfter but returns entries with keys before
+// cursor, in reverse key order.
+func (m *SortedMap[K, V]) DescendBefore(cursor K, limit int) (entries []MapEntry[K, V], next K, more bool) {
+	entries = make([]MapEntry[K, V], 0, max(min(limit, m.size), 0))
+	next, more = m.page(cursor, limit, true, func(key K, value V) {
+		entries = append(entries, MapEntry[K, V]{Key: key, Value: value})
+	})
+	return entries, next, more
+}
+
+// page calls fn for up to limit entries with keys after cursor, or before it
+// if reverse is set, and returns the cursor for the next page and whether any
+// keys remain.
+func (m *SortedMap[K, V]) page(cursor K, limit int, reverse bool, fn func(key K, value V)) (next K, more bool) {
+	itr := SortedMapIterator[K, V]{m: m}
+	itr.Seek(cursor)
+	step := itr.Next
+	if reverse {
+		// Every key from the seek position on is at or after cursor.
+		if itr.Done() {
+			itr.Last()
+		} else {
+			itr.Prev()
+		}
+		step = itr.Prev
+	} else if !itr.Done() && m.comparer.Compare(itr.peek(), cursor) == 0 {
+		itr.Next()
+	}
+
+	next = cursor
+	for n := 0; n < limit && !itr.Done(); n++ {
+		key, value, _ := step()
+		fn(key, value)
+		next = key
+	}
+	return next, !itr.Done()
+}
+
 // SortedMapBuilder represents an efficient builder for creating sorted maps.
 type SortedMapBuilder[K, V any] struct {
-	m *SortedMap[K, V] // current state
+	m     *SortedMap[K, V] // current state
+	owner *owner           // owner of the nodes the builder may update in place
 }
 
 // NewSortedMapBuilder returns a new instance of SortedMapBuilder.
 func NewSortedMapBuilder[K, V any](comparer Comparer[K]) *SortedMapBuilder[K, V] {
-	return &SortedMapBuilder[K, V]{m: NewSortedMap[K, V](comparer)}
+	return &SortedMapBuilder[K, V]{m: NewSortedMap[K, V](comparer), owner: new(owner)}
+}
+
+// NewSortedMapBuilderFrom returns a new instance of SortedMapBuilder holding
+// the entries of m. The builder shares m's nodes, so m is never modified: each
+// node is copied the first time an update touches it and updated in place
+// from then on.
+func NewSortedMapBuilderFrom[K, V any](m *SortedMap[K, V]) *SortedMapBuilder[K, V] {
+	if m == nil {
+		return NewSortedMapBuilder[K, V](nil)
+	}
+	return &SortedMapBuilder[K, V]{m: m.clone(), owner: new(owner)}
 }
 
 // SortedMap returns the current copy of the map.
@@ -1106,6 +2095,24 @@ func (b *SortedMapBuilder[K, V]) Map() *SortedMap[K, V] {
 	return m
 }
 
+// TryMap is like Map but returns ErrBuilderFinalized instead of panicking if
+// the map has already been fetched.
+func (b *SortedMapBuilder[K, V]) TryMap() (*SortedMap[K, V], error) {
+	if b.m == nil {
+		return nil, ErrBuilderFinalized
+	}
+	return b.Map(), nil
+}
+
+// Snapshot returns the current state of the map without invalidating the
+// builder. Like MapBuilder.Snapshot, later updates copy shared nodes once and
+// then update the copies in place.
+func (b *SortedMapBuilder[K, V]) Snapshot() *SortedMap[K, V] {
+	assert(b.m != nil, "immutable.SortedMapBuilder: builder invalid after Map() invocation")
+	b.owner = new(owner)
+	return b.m.clone()
+}
+
 // Len returns the number of elements in the underlying map.
 func (b *SortedMapBuilder[K, V]) Len() int {
 	assert(b.m != nil, "immutable.SortedMapBuilder: builder invalid after Map() invocation")
@@ -1121,19 +2128,28 @@ func (b *SortedMapBuilder[K, V]) Get(key K) (value V, ok bool) {
 // Set sets the value of the given key. See SortedMap.Set() for additional details.
 func (b *SortedMapBuilder[K, V]) Set(key K, value V) {
 	assert(b.m != nil, "immutable.SortedMapBuilder: builder invalid after Map() invocation")
-	b.m = b.m.set(key, value, true)
+	b.m = b.m.set(key, value, b.owner)
 }
 
 // Delete removes the given key. See SortedMap.Delete() for additional details.
 func (b *SortedMapBuilder[K, V]) Delete(key K) {
 	assert(b.m != nil, "immutable.SortedMapBuilder: builder invalid after Map() invocation")
-	b.m = b.m.delete(key, true)
+	b.m = b.m.delete(key, b.owner)
 }
 
-// Iterator returns a new iterator for the underlying map positioned at the first key.
+// Iterator returns a new iterator over a snapshot of the underlying map
+// positioned at the first key. Updates made during iteration are not
+// observed; they copy the shared nodes they touch once, as after Snapshot.
 func (b *SortedMapBuilder[K, V]) Iterator() *SortedMapIterator[K, V] {
+	return b.Snapshot().Iterator()
+}
+
+// Range calls fn for each key/value pair in the underlying map, in key order,
+// until fn returns false. As with MapBuilder.Range, fn must not update the
+// builder.
+func (b *SortedMapBuilder[K, V]) Range(fn func(key K, value V) bool) {
 	assert(b.m != nil, "immutable.SortedMapBuilder: builder invalid after Map() invocation")
-	return b.m.Iterator()
+	b.m.Range(fn)
 }
 
 // sortedMapNode represents a branch or leaf node in the sorted map.
@@ -1141,8 +2157,8 @@ type sortedMapNode[K, V any] interface {
 	minKey() K
 	indexOf(key K, c Comparer[K]) int
 	get(key K, c Comparer[K]) (value V, ok bool)
-	set(key K, value V, c Comparer[K], mutable bool, resized *bool) (sortedMapNode[K, V], sortedMapNode[K, V])
-	delete(key K, c Comparer[K], mutable bool, resized *bool) sortedMapNode[K, V]
+	set(key K, value V, c Comparer[K], o *owner, resized *bool) (sortedMapNode[K, V], sortedMapNode[K, V])
+	delete(key K, c Comparer[K], o *owner, resized *bool) sortedMapNode[K, V]
 }
 
 var _ sortedMapNode[string, any] = (*sortedMapBranchNode[string, any])(nil)
@@ -1151,10 +2167,12 @@ var _ sortedMapNode[string, any] = (*sortedMapLeafNode[string, any])(nil)
 // sortedMapBranchNode represents a branch in the sorted map.
 type sortedMapBranchNode[K, V any] struct {
 	elems []sortedMapBranchElem[K, V]
+	owner *owner // owner allowed to update the node in place
 }
 
-// newSortedMapBranchNode returns a new branch node with the given child nodes.
-func newSortedMapBranchNode[K, V any](children ...sortedMapNode[K, V]) *sortedMapBranchNode[K, V] {
+// newSortedMapBranchNode returns a new branch node with the given child nodes,
+// stamped with o.
+func newSortedMapBranchNode[K, V any](o *owner, children ...sortedMapNode[K, V]) *sortedMapBranchNode[K, V] {
 	// Fetch min keys for every child.
 	elems := make([]sortedMapBranchElem[K, V], len(children))
 	for i, child := range children {
@@ -1164,7 +2182,7 @@ func newSortedMapBranchNode[K, V any](children ...sortedMapNode[K, V]) *sortedMa
 		}
 	}
 
-	return &sortedMapBranchNode[K, V]{elems: elems}
+	return &sortedMapBranchNode[K, V]{elems: elems, owner: o}
 }
 
 // minKey returns the lowest key stored in this node's tree.
@@ -1187,14 +2205,14 @@ func (n *sortedMapBranchNode[K, V]) get(key K, c Comparer[K]) (value V, ok bool)
 }
 
 // set returns a copy of the node with the key set to the given value.
-func (n *sortedMapBranchNode[K, V]) set(key K, value V, c Comparer[K], mutable bool, resized *bool) (sortedMapNode[K, V], sortedMapNode[K, V]) {
+func (n *sortedMapBranchNode[K, V]) set(key K, value V, c Comparer[K], o *owner, resized *bool) (sortedMapNode[K, V], sortedMapNode[K, V]) {
 	idx := n.indexOf(key, c)
 
 	// Delegate insert to child node.
-	newNode, splitNode := n.elems[idx].node.set(key, value, c, mutable, resized)
+	newNode, splitNode := n.elems[idx].node.set(key, value, c, o, resized)
 
-	// Update in-place, if mutable.
-	if mutable {
+	// Update in-place, if owned.
+	if o.owns(n.owner) {
 		n.elems[idx] = sortedMapBranchElem[K, V]{key: newNode.minKey(), node: newNode}
 		if splitNode != nil {
 			n.elems = append(n.elems, sortedMapBranchElem[K, V]{})
@@ -1205,8 +2223,8 @@ func (n *sortedMapBranchNode[K, V]) set(key K, value V, c Comparer[K], mutable b
 		// If the child splits and we have no more room then we split too.
 		if len(n.elems) > sortedMapNodeSize {
 			splitIdx := len(n.elems) / 2
-			newNode := &sortedMapBranchNode[K, V]{elems: n.elems[:splitIdx:splitIdx]}
-			splitNode := &sortedMapBranchNode[K, V]{elems: n.elems[splitIdx:]}
+			newNode := &sortedMapBranchNode[K, V]{elems: n.elems[:splitIdx:splitIdx], owner: o}
+			splitNode := &sortedMapBranchNode[K, V]{elems: n.elems[splitIdx:], owner: o}
 			return newNode, splitNode
 		}
 		return n, nil
@@ -1214,7 +2232,7 @@ func (n *sortedMapBranchNode[K, V]) set(key K, value V, c Comparer[K], mutable b
 
 	// If no split occurs, copy branch and update keys.
 	// If the child splits, insert new key/child into copy of branch.
-	var other sortedMapBranchNode[K, V]
+	other := sortedMapBranchNode[K, V]{owner: o}
 	if splitNode == nil {
 		other.elems = make([]sortedMapBranchElem[K, V], len(n.elems))
 		copy(other.elems, n.elems)
@@ -1239,8 +2257,8 @@ func (n *sortedMapBranchNode[K, V]) set(key K, value V, c Comparer[K], mutable b
 	// If the child splits and we have no more room then we split too.
 	if len(other.elems) > sortedMapNodeSize {
 		splitIdx := len(other.elems) / 2
-		newNode := &sortedMapBranchNode[K, V]{elems: other.elems[:splitIdx:splitIdx]}
-		splitNode := &sortedMapBranchNode[K, V]{elems: other.elems[splitIdx:]}
+		newNode := &sortedMapBranchNode[K, V]{elems: other.elems[:splitIdx:splitIdx], owner: o}
+		splitNode := &sortedMapBranchNode[K, V]{elems: other.elems[splitIdx:], owner: o}
 		return newNode, splitNode
 	}
 
@@ -1250,11 +2268,11 @@ func (n *sortedMapBranchNode[K, V]) set(key K, value V, c Comparer[K], mutable b
 
 // delete returns a node with the key removed. Returns the same node if the key
 // does not exist. Returns nil if all child nodes are removed.
-func (n *sortedMapBranchNode[K, V]) delete(key K, c Comparer[K], mutable bool, resized *bool) sortedMapNode[K, V] {
+func (n *sortedMapBranchNode[K, V]) delete(key K, c Comparer[K], o *owner, resized *bool) sortedMapNode[K, V] {
 	idx := n.indexOf(key, c)
 
 	// Return original node if child has not changed.
-	newNode := n.elems[idx].node.delete(key, c, mutable, resized)
+	newNode := n.elems[idx].node.delete(key, c, o, resized)
 	if !*resized {
 		return n
 	}
@@ -1266,8 +2284,8 @@ func (n *sortedMapBranchNode[K, V]) delete(key K, c Comparer[K], mutable bool, r
 			return nil
 		}
 
-		// If mutable, update in-place.
-		if mutable {
+		// If owned, update in-place.
+		if o.owns(n.owner) {
 			copy(n.elems[idx:], n.elems[idx+1:])
 			n.elems[len(n.elems)-1] = sortedMapBranchElem[K, V]{}
 			n.elems = n.elems[:len(n.elems)-1]
@@ -1275,20 +2293,20 @@ func (n *sortedMapBranchNode[K, V]) delete(key K, c Comparer[K], mutable bool, r
 		}
 
 		// Return a copy without the given node.
-		other := &sortedMapBranchNode[K, V]{elems: make([]sortedMapBranchElem[K, V], len(n.elems)-1)}
+		other := &sortedMapBranchNode[K, V]{elems: make([]sortedMapBranchElem[K, V], len(n.elems)-1), owner: o}
 		copy(other.elems[:idx], n.elems[:idx])
 		copy(other.elems[idx:], n.elems[idx+1:])
 		return other
 	}
 
-	// If mutable, update in-place.
-	if mutable {
+	// If owned, update in-place.
+	if o.owns(n.owner) {
 		n.elems[idx] = sortedMapBranchElem[K, V]{key: newNode.minKey(), node: newNode}
 		return n
 	}
 
 	// Return a copy with the updated node.
-	other := &sortedMapBranchNode[K, V]{elems: make([]sortedMapBranchElem[K, V], len(n.elems))}
+	other := &sortedMapBranchNode[K, V]{elems: make([]sortedMapBranchElem[K, V], len(n.elems)), owner: o}
 	copy(other.elems, n.elems)
 	other.elems[idx] = sortedMapBranchElem[K, V]{
 		key:  newNode.minKey(),
@@ -1305,6 +2323,7 @@ type sortedMapBranchElem[K, V any] struct {
 // sortedMapLeafNode represents a leaf node in the sorted map.
 type sortedMapLeafNode[K, V any] struct {
 	entries []mapEntry[K, V]
+	owner   *owner // owner allowed to update the node in place
 }
 
 // minKey returns the first key stored in this node.
@@ -1334,13 +2353,13 @@ func (n *sortedMapLeafNode[K, V]) get(key K, c Comparer[K]) (value V, ok bool) {
 
 // set returns a copy of node with the key set to the given value. If the update
 // causes the node to grow beyond the maximum size then it is split in two.
-func (n *sortedMapLeafNode[K, V]) set(key K, value V, c Comparer[K], mutable bool, resized *bool) (sortedMapNode[K, V], sortedMapNode[K, V]) {
+func (n *sortedMapLeafNode[K, V]) set(key K, value V, c Comparer[K], o *owner, resized *bool) (sortedMapNode[K, V], sortedMapNode[K, V]) {
 	// Find the insertion index for the key.
 	idx := n.indexOf(key, c)
 	exists := idx < len(n.entries) && c.Compare(n.entries[idx].key, key) == 0
 
-	// Update in-place, if mutable.
-	if mutable {
+	// Update in-place, if owned.
+	if o.owns(n.owner) {
 		if !exists {
 			*resized = true
 			n.entries = append(n.entries, mapEntry[K, V]{})
@@ -1351,8 +2370,8 @@ func (n *sortedMapLeafNode[K, V]) set(key K, value V, c Comparer[K], mutable boo
 		// If the key doesn't exist and we exceed our max allowed values then split.
 		if len(n.entries) > sortedMapNodeSize {
 			splitIdx := len(n.entries) / 2
-			newNode := &sortedMapLeafNode[K, V]{entries: n.entries[:splitIdx:splitIdx]}
-			splitNode := &sortedMapLeafNode[K, V]{entries: n.entries[splitIdx:]}
+			newNode := &sortedMapLeafNode[K, V]{entries: n.entries[:splitIdx:splitIdx], owner: o}
+			splitNode := &sortedMapLeafNode[K, V]{entries: n.entries[splitIdx:], owner: o}
 			return newNode, splitNode
 		}
 		return n, nil
@@ -1376,18 +2395,18 @@ func (n *sortedMapLeafNode[K, V]) set(key K, value V, c Comparer[K], mutable boo
 	// If the key doesn't exist and we exceed our max allowed values then split.
 	if len(newEntries) > sortedMapNodeSize {
 		splitIdx := len(newEntries) / 2
-		newNode := &sortedMapLeafNode[K, V]{entries: newEntries[:splitIdx:splitIdx]}
-		splitNode := &sortedMapLeafNode[K, V]{entries: newEntries[splitIdx:]}
+		newNode := &sortedMapLeafNode[K, V]{entries: newEntries[:splitIdx:splitIdx], owner: o}
+		splitNode := &sortedMapLeafNode[K, V]{entries: newEntries[splitIdx:], owner: o}
 		return newNode, splitNode
 	}
 
 	// Otherwise return the new leaf node with the updated entry.
-	return &sortedMapLeafNode[K, V]{entries: newEntries}, nil
+	return &sortedMapLeafNode[K, V]{entries: newEntries, owner: o}, nil
 }
 
 // delete returns a copy of node with key removed. Returns the original node if
 // the key does not exist. Returns nil if the removed key is the last remaining key.
-func (n *sortedMapLeafNode[K, V]) delete(key K, c Comparer[K], mutable bool, resized *bool) sortedMapNode[K, V] {
+func (n *sortedMapLeafNode[K, V]) delete(key K, c Comparer[K], o *owner, resized *bool) sortedMapNode[K, V] {
 	idx := n.indexOf(key, c)
 
 	// Return original node if key is not found.
@@ -1401,8 +2420,8 @@ func (n *sortedMapLeafNode[K, V]) delete(key K, c Comparer[K], mutable bool, res
 		return nil
 	}
 
-	// Update in-place, if mutable.
-	if mutable {
+	// Update in-place, if owned.
+	if o.owns(n.owner) {
 		copy(n.entries[idx:], n.entries[idx+1:])
 		n.entries[len(n.entries)-1] = mapEntry[K, V]{}
 		n.entries = n.entries[:len(n.entries)-1]
@@ -1410,7 +2429,7 @@ func (n *sortedMapLeafNode[K, V]) delete(key K, c Comparer[K], mutable bool, res
 	}
 
 	// Return copy of node with entry removed.
-	other := &sortedMapLeafNode[K, V]{entries: make([]mapEntry[K, V], len(n.entries)-1)}
+	other := &sortedMapLeafNode[K, V]{entries: make([]mapEntry[K, V], len(n.entries)-1), owner: o}
 	copy(other.entries[:idx], n.entries[:idx])
 	copy(other.entries[idx:], n.entries[idx+1:])
 	return other
@@ -1418,6 +2437,11 @@ func (n *sortedMapLeafNode[K, V]) delete(key K, c Comparer[K], mutable bool, res
 
 // SortedMapIterator represents an iterator over a sorted map.
 // Iteration can occur in natural or reverse order based on use of Next() or Prev().
+//
+// Next and Prev follow the same cursor discipline as ListIterator: both return
+// the pair under the cursor and then move it, so mixing them yields that pair
+// again, and once the cursor moves past either end the iterator is done until
+// it is repositioned with First, Last or Seek.
 type SortedMapIterator[K, V any] struct {
 	m *SortedMap[K, V] // source map
 
@@ -1430,6 +2454,13 @@ func (itr *SortedMapIterator[K, V]) Done() bool {
 	return itr.depth == -1
 }
 
+// Clone returns an independent iterator at the same position. Moving either
+// iterator does not affect the other.
+func (itr *SortedMapIterator[K, V]) Clone() *SortedMapIterator[K, V] {
+	other := *itr
+	return &other
+}
+
 // First moves the iterator to the first key/value pair.
 func (itr *SortedMapIterator[K, V]) First() {
 	if itr.m.root == nil {
@@ -1454,7 +2485,7 @@ func (itr *SortedMapIterator[K, V]) Last() {
 
 // Seek moves the iterator position to the given key in the map.
 // If the key does not exist then the next key is used. If no more keys exist
-// then the iteartor is marked as done.
+// then the iterator is marked as done.
 func (itr *SortedMapIterator[K, V]) Seek(key K) {
 	if itr.m.root == nil {
 		itr.depth = -1
@@ -1465,8 +2496,15 @@ func (itr *SortedMapIterator[K, V]) Seek(key K) {
 	itr.seek(key)
 }
 
+// peek returns the current key without moving the iterator, which must not be
+// done.
+func (itr *SortedMapIterator[K, V]) peek() K {
+	elem := &itr.stack[itr.depth]
+	return elem.node.(*sortedMapLeafNode[K, V]).entries[elem.index].key
+}
+
 // Next returns the current key/value pair and moves the iterator forward.
-// Returns a nil key if the there are no more elements to return.
+// Returns a zero key and ok of false if the iterator is done.
 func (itr *SortedMapIterator[K, V]) Next() (key K, value V, ok bool) {
 	// Return nil key if iteration is complete.
 	if itr.Done() {
@@ -1479,7 +2517,13 @@ func (itr *SortedMapIterator[K, V]) Next() (key K, value V, ok bool) {
 	leafEntry := &leafNode.entries[leafElem.index]
 	key, value = leafEntry.key, leafEntry.value
 
-	// Move to the next available key/value pair.
+	// Move to the next available key/value pair, staying in the leaf if it
+	// has one.
+	if leafElem.index < len(leafNode.entries)-1 {
+		leafElem.index++
+		return key, value, true
+	}
+	itr.depth--
 	itr.next()
 
 	// Only occurs when iterator is done.
@@ -1510,7 +2554,7 @@ func (itr *SortedMapIterator[K, V]) next() {
 }
 
 // Prev returns the current key/value pair and moves the iterator backward.
-// Returns a nil key if the there are no more elements to return.
+// Returns a zero key and ok of false if the iterator is done.
 func (itr *SortedMapIterator[K, V]) Prev() (key K, value V, ok bool) {
 	// Return nil key if iteration is complete.
 	if itr.Done() {
@@ -1523,6 +2567,11 @@ func (itr *SortedMapIterator[K, V]) Prev() (key K, value V, ok bool) {
 	leafEntry := &leafNode.entries[leafElem.index]
 	key, value = leafEntry.key, leafEntry.value
 
+	if leafElem.index > 0 {
+		leafElem.index--
+		return key, value, true
+	}
+	itr.depth--
 	itr.prev()
 	return key, value, true
 }
@@ -1649,6 +2698,77 @@ func hashString(value string) uint32 {
 	return hash
 }
 
+// HasherFunc returns a Hasher that calls hash and equal. Keys that are equal
+// must have equal hashes.
+func HasherFunc[K any](hash func(key K) uint32, equal func(a, b K) bool) Hasher[K] {
+	assert(hash != nil && equal != nil, "immutable.HasherFunc: hash and equal functions must not be nil")
+	return &funcHasher[K]{hash: hash, equal: equal}
+}
+
+// ComparableHasherFunc returns a Hasher that calls hash and compares keys
+// with ==.
+func ComparableHasherFunc[K comparable](hash func(key K) uint32) Hasher[K] {
+	return HasherFunc(hash, func(a, b K) bool { return a == b })
+}
+
+// funcHasher implements Hasher with functions.
+type funcHasher[K any] struct {
+	hash  func(key K) uint32
+	equal func(a, b K) bool
+}
+
+// Hash returns a hash for key.
+func (h *funcHasher[K]) Hash(key K) uint32 { return h.hash(key) }
+
+// Equal returns true if a is equal to b.
+func (h *funcHasher[K]) Equal(a, b K) bool { return h.equal(a, b) }
+
+// SeededHasher returns a Hasher that mixes seed into the hashes of h, so maps
+// using different seeds lay out the same keys differently. Keys whose hashes
+// collide under h still collide.
+func SeededHasher[K any](h Hasher[K], seed uint32) Hasher[K] {
+	assert(h != nil, "immutable.SeededHasher: hasher must not be nil")
+	return &seededHasher[K]{h: h, seed: seed}
+}
+
+// seededHasher implements Hasher by mixing a seed into another hasher's hashes.
+type seededHasher[K any] struct {
+	h    Hasher[K]
+	seed uint32
+}
+
+// Hash returns a hash for key.
+func (h *seededHasher[K]) Hash(key K) uint32 {
+	x := mixHash64(uint64(h.seed)<<32 | uint64(h.h.Hash(key)))
+	return uint32(x ^ (x >> 32))
+}
+
+// Equal returns true if a is equal to b.
+func (h *seededHasher[K]) Equal(a, b K) bool { return h.h.Equal(a, b) }
+
+// fastStringSeed seeds every hasher returned by NewFastStringHasher, so that
+// they agree with each other within a process.
+var fastStringSeed = maphash.MakeSeed()
+
+// NewFastStringHasher returns a string hasher backed by hash/maphash. It is
+// faster than the built-in string hasher on long keys and distributes similar
+// keys better, but its hashes are randomly seeded per process: content hashes
+// of maps using it are not stable across processes. Pass it to NewMap or
+// NewMapBuilder in place of nil to use it.
+func NewFastStringHasher() Hasher[string] { return fastStringHasher{} }
+
+// fastStringHasher implements Hasher for strings using hash/maphash.
+type fastStringHasher struct{}
+
+// Hash returns a hash for key.
+func (fastStringHasher) Hash(key string) uint32 {
+	h := maphash.String(fastStringSeed, key)
+	return uint32(h ^ (h >> 32))
+}
+
+// Equal returns true if a is equal to b.
+func (fastStringHasher) Equal(a, b string) bool { return a == b }
+
 // reflectIntHasher implements a reflection-based Hasher for keys.
 type reflectHasher[K any] struct{}
 
@@ -1768,6 +2888,38 @@ func NewComparer[K any](key K) Comparer[K] {
 	panic(fmt.Sprintf("immutable.NewComparer: must set comparer for %T type", key))
 }
 
+// registeredComparers holds the comparers added by RegisterComparer, keyed by
+// the reflect.Type of the key.
+var registeredComparers sync.Map
+
+// RegisterComparer records c as the comparer for keys of type K when decoding
+// sorted collections. Decoders use it in place of the built-in comparer when
+// the destination has no comparer of its own, such as a SortedMap allocated by
+// encoding/gob. Like gob.Register, it is intended to be called during
+// initialization.
+func RegisterComparer[K any](c Comparer[K]) {
+	registeredComparers.Store(reflect.TypeFor[K](), c)
+}
+
+// registeredComparer returns the comparer registered for K, if any.
+func registeredComparer[K any]() Comparer[K] {
+	if c, ok := registeredComparers.Load(reflect.TypeFor[K]()); ok {
+		return c.(Comparer[K])
+	}
+	return nil
+}
+
+// hasDefaultHasher reports whether NewHasher and NewComparer support keys of
+// type t, i.e. whether a map of such keys may be created without a hasher or
+// comparer.
+func hasDefaultHasher(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr, reflect.String:
+		return true
+	}
+	return false
+}
+
 // defaultComparer compares two values (int-ish and string-ish types are supported). Implements Comparer.
 type defaultComparer[K any] struct{}
 
@@ -4,8 +4,15 @@ import (
 	"cmp"
 	"flag"
 	"fmt"
+	"maps"
+	"math"
 	"math/rand"
+	"reflect"
+	"slices"
 	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"testing"
 )
 
@@ -262,6 +269,436 @@ func TestList(t *testing.T) {
 			t.Fatal(err)
 		}
 	})
+
+	t.Run("BuilderTakeAndReset", func(t *testing.T) {
+		b := NewListBuilder[int]()
+		b.Append(1)
+		b.Append(2)
+		first := b.TakeAndReset()
+
+		b.Append(3) // builder is valid again
+		second := b.List()
+
+		if first.Len() != 2 || first.Get(0) != 1 || first.Get(1) != 2 {
+			t.Fatalf("unexpected first list: len=%d", first.Len())
+		}
+		if second.Len() != 1 || second.Get(0) != 3 {
+			t.Fatalf("unexpected second list: len=%d", second.Len())
+		}
+
+		b.Reset() // revalidates after List()
+		if n := b.Len(); n != 0 {
+			t.Fatalf("unexpected len after reset: %d", n)
+		}
+	})
+
+	t.Run("BuilderSnapshot", func(t *testing.T) {
+		b := NewListBuilder[int]()
+		for i := 0; i < 1000; i++ {
+			b.Append(i)
+		}
+		snap := b.Snapshot()
+
+		// Overwrite, grow and shrink the builder after the snapshot.
+		for i := 0; i < 1000; i++ {
+			b.Set(i, -i)
+		}
+		for i := 0; i < 100; i++ {
+			b.Append(1000 + i)
+			b.Prepend(-1000 - i)
+		}
+
+		// Nodes copied after the snapshot are owned by the builder again.
+		root := b.list.root
+		b.Set(500, -500)
+		if b.list.root != root {
+			t.Fatal("expected Set after snapshot copy to update in place")
+		}
+
+		b.Slice(50, 1150)
+		list := b.List()
+
+		if snap.Len() != 1000 {
+			t.Fatalf("unexpected snapshot len: %d", snap.Len())
+		}
+		for i := 0; i < 1000; i++ {
+			if v := snap.Get(i); v != i {
+				t.Fatalf("snapshot Get(%d)=%v", i, v)
+			}
+		}
+		if list.Len() != 1100 || list.Get(50) != 0 || list.Get(51) != -1 {
+			t.Fatalf("unexpected list: len=%d", list.Len())
+		}
+
+		if _, err := b.TryList(); err != ErrBuilderFinalized {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("BuilderFrom", func(t *testing.T) {
+		for _, n := range []int{0, 10, 5000} {
+			seed := NewList[int]()
+			for i := 0; i < n; i++ {
+				seed = seed.Append(i)
+			}
+			values, repr := slices.Collect(listValues(seed)), seed.GoString()
+
+			b := NewListBuilderFrom(seed)
+			model := slices.Clone(values)
+			for i := range model {
+				model[i] = -i
+				b.Set(i, -i)
+			}
+			for i := 0; i < 100; i++ {
+				b.Append(n + i)
+				b.Prepend(-n - i)
+				model = append(model, n+i)
+				model = append([]int{-n - i}, model...)
+			}
+			b.Slice(10, b.Len()-10)
+			model = model[10 : len(model)-10]
+
+			if got := slices.Collect(listValues(b.List())); !slices.Equal(got, model) {
+				t.Fatalf("n=%d: unexpected list: %v", n, got)
+			}
+			if got := slices.Collect(listValues(seed)); !slices.Equal(got, values) || seed.GoString() != repr {
+				t.Fatalf("n=%d: seed modified: %s", n, seed.GoString())
+			}
+		}
+
+		if b := NewListBuilderFrom[int](nil); b.Len() != 0 {
+			t.Fatalf("unexpected len: %d", b.Len())
+		}
+	})
+
+	t.Run("SliceCompacts", func(t *testing.T) {
+		l := NewList[int]()
+		for i := 0; i < 1000; i++ {
+			l = l.Append(i)
+		}
+		for _, r := range [][2]int{{0, 10}, {495, 505}, {990, 1000}, {100, 100}, {0, 32}} {
+			other := l.Slice(r[0], r[1])
+			if _, ok := other.root.(*listSliceNode[int]); !ok {
+				t.Fatalf("Slice(%d, %d): expected slice node, got %T", r[0], r[1], other.root)
+			}
+			if got, want := slices.Collect(listValues(other)), listRange(l, r[0], r[1]); !slices.Equal(got, want) {
+				t.Fatalf("Slice(%d, %d): unexpected values %v", r[0], r[1], got)
+			}
+			if other = other.Append(-1); other.Get(other.Len()-1) != -1 || l.Len() != 1000 {
+				t.Fatalf("Slice(%d, %d): unexpected append", r[0], r[1])
+			}
+		}
+		if _, ok := l.Slice(0, 33).root.(*listSliceNode[int]); ok {
+			t.Fatal("expected larger slices to stay trie-backed")
+		}
+
+		b := NewListBuilderFrom(l)
+		b.Slice(100, 110)
+		b.Set(0, -1)
+		if got := slices.Collect(listValues(b.List())); got[0] != -1 || got[9] != 109 || l.Get(100) != 100 {
+			t.Fatalf("unexpected builder slice: %v", got)
+		}
+	})
+
+	t.Run("ShallowTrie", func(t *testing.T) {
+		// Cover roots of depth 1 and 2, with and without a prepended origin.
+		for _, n := range []int{33, 500, 1024, 1500} {
+			var lists []*List[int]
+			l := NewList[int]()
+			for i := 0; i < n; i++ {
+				l = l.Append(i)
+			}
+			lists = append(lists, l, NewList(slices.Collect(listValues(l))...))
+			l = NewList[int]()
+			for i := n - 1; i >= 0; i-- {
+				l = l.Prepend(i)
+			}
+			lists = append(lists, l)
+
+			for _, l := range lists {
+				repr := l.GoString()
+				other := l
+				for i := 0; i < n; i += 7 {
+					if v := l.Get(i); v != i {
+						t.Fatalf("n=%d: Get(%d)=%d", n, i, v)
+					}
+					other = other.Set(i, -i)
+				}
+				for i := 0; i < n; i++ {
+					want := i
+					if i%7 == 0 {
+						want = -i
+					}
+					if v := other.Get(i); v != want {
+						t.Fatalf("n=%d: Get(%d)=%d after Set, expected %d", n, i, v, want)
+					}
+				}
+				if l.GoString() != repr {
+					t.Fatalf("n=%d: original modified by Set", n)
+				}
+
+				b := NewListBuilderFrom(l)
+				b.Set(n-1, -1)
+				if v := b.Get(n - 1); v != -1 || l.Get(n-1) != n-1 {
+					t.Fatalf("n=%d: unexpected builder Set: %d, %d", n, v, l.Get(n-1))
+				}
+			}
+		}
+	})
+
+	t.Run("BuilderIterator", func(t *testing.T) {
+		b := NewListBuilder[int]()
+		for i := 0; i < 1000; i++ {
+			b.Append(i)
+		}
+
+		// Mutate the builder while iterating.
+		var got []int
+		for itr := b.Iterator(); !itr.Done(); {
+			_, v := itr.Next()
+			got = append(got, v)
+			b.Append(1000 + len(got))
+			b.Set(len(got)-1, -1)
+		}
+		if len(got) != 1000 {
+			t.Fatalf("unexpected iterator len: %d", len(got))
+		}
+		for i, v := range got {
+			if v != i {
+				t.Fatalf("iterator value %d=%v", i, v)
+			}
+		}
+
+		// The builder owns the nodes it copied while iterating.
+		root := b.list.root
+		b.Set(0, -1)
+		b.Append(2001)
+		if b.list.root != root {
+			t.Fatal("expected Set and Append after iteration to update in place")
+		}
+
+		if list := b.List(); list.Len() != 2001 || list.Get(999) != -1 || list.Get(2000) != 2001 {
+			t.Fatalf("unexpected list: len=%d", list.Len())
+		}
+	})
+}
+
+func TestList_GetOK(t *testing.T) {
+	for _, n := range []int{0, 10, 33, 1000, 40000} {
+		l := NewList[int]()
+		for i := n/2 - 1; i >= 0; i-- {
+			l = l.Prepend(i)
+		}
+		for i := n / 2; i < n; i++ {
+			l = l.Append(i)
+		}
+		for _, index := range []int{0, n / 2, n - 1} {
+			if v, ok := l.GetOK(index); n > 0 && (!ok || v != index) {
+				t.Fatalf("n=%d: GetOK(%d)=%d, %v", n, index, v, ok)
+			}
+		}
+		for _, index := range []int{-1, n, n + 100, math.MinInt} {
+			if v, ok := l.GetOK(index); ok || v != 0 {
+				t.Fatalf("n=%d: GetOK(%d)=%d, %v", n, index, v, ok)
+			}
+		}
+	}
+}
+
+func TestList_Tail(t *testing.T) {
+	// checkList fails unless l holds exactly values, read every way the tail
+	// is read from.
+	checkList := func(t *testing.T, l *List[int], values []int) {
+		t.Helper()
+		if l.Len() != len(values) {
+			t.Fatalf("Len()=%d, expected %d", l.Len(), len(values))
+		}
+		for i, v := range values {
+			if got := l.Get(i); got != v {
+				t.Fatalf("Get(%d)=%d, expected %d", i, got, v)
+			}
+		}
+		if got := slices.Collect(l.Values()); !slices.Equal(got, values) {
+			t.Fatalf("Values()=%v, expected %v", got, values)
+		}
+		var backward []int
+		l.EachReverse(func(_ int, v int) bool {
+			backward = append(backward, v)
+			return true
+		})
+		slices.Reverse(backward)
+		if !slices.Equal(backward, values) {
+			t.Fatalf("EachReverse()=%v, expected %v", backward, values)
+		}
+		itr := l.Iterator()
+		for i, v := range values {
+			if j, got := itr.Next(); j != i || got != v {
+				t.Fatalf("Next()=<%d,%d>, expected <%d,%d>", j, got, i, v)
+			}
+		}
+		itr.Last()
+		for i := len(values) - 1; i >= 0; i-- {
+			if j, got := itr.Prev(); j != i || got != values[i] {
+				t.Fatalf("Prev()=<%d,%d>, expected <%d,%d>", j, got, i, values[i])
+			}
+		}
+	}
+
+	t.Run("LeafBoundary", func(t *testing.T) {
+		var values []int
+		versions := []*List[int]{NewList[int]()}
+		for i := 0; i < 200; i++ {
+			l := versions[i]
+			// A sibling appended to the same version must not be observed.
+			l.Append(-1)
+			versions = append(versions, l.Append(i))
+			values = append(values, i)
+			checkList(t, versions[i+1], values)
+		}
+		for i, l := range versions {
+			checkList(t, l, values[:i])
+		}
+	})
+
+	t.Run("Allocations", func(t *testing.T) {
+		l := NewList[int]()
+		for i := 0; i < 1000; i++ {
+			l = l.Append(i)
+		}
+		if n := l.tailLen(); n == 0 || n == listNodeSize {
+			t.Fatalf("unexpected tail length %d", n)
+		}
+		// Only the list and its tail are copied.
+		if allocs := testing.AllocsPerRun(10, func() { l.Append(0) }); allocs > 2 {
+			t.Fatalf("Append: unexpected allocations: %v", allocs)
+		}
+		if allocs := testing.AllocsPerRun(10, func() { l.Set(999, 0) }); allocs > 2 {
+			t.Fatalf("Set: unexpected allocations: %v", allocs)
+		}
+	})
+
+	t.Run("Slice", func(t *testing.T) {
+		var values []int
+		l := NewList[int]()
+		for i := 0; i < 1000; i++ {
+			l, values = l.Append(i), append(values, i)
+		}
+		for _, r := range [][2]int{{0, 999}, {0, 992}, {992, 1000}, {500, 1000}, {995, 998}, {10, 60}} {
+			s := l.Slice(r[0], r[1])
+			checkList(t, s, values[r[0]:r[1]])
+			s = s.Append(-1).Append(-2)
+			checkList(t, s, append(slices.Clone(values[r[0]:r[1]]), -1, -2))
+		}
+		checkList(t, l, values)
+	})
+
+	t.Run("Prepend", func(t *testing.T) {
+		var values []int
+		l := NewList[int]()
+		for i := 0; i < 1000; i++ {
+			l, values = l.Append(i), append(values, i)
+		}
+		p := l.Prepend(-1)
+		checkList(t, p, append([]int{-1}, values...))
+		p = p.Append(1000)
+		checkList(t, p, append(append([]int{-1}, values...), 1000))
+		checkList(t, l, values)
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		var values []int
+		l := NewList[int]()
+		for i := 0; i < 100; i++ {
+			l, values = l.Append(i), append(values, i)
+		}
+		want := slices.Clone(values)
+		want[99], want[98], want[3] = -99, 3, 1098
+		other := l.Set(99, -99).UpdateAt(98, func(v int) int { return v + 1000 }).Swap(3, 98)
+		checkList(t, other, want)
+		checkList(t, l, values)
+	})
+
+	t.Run("Iterator", func(t *testing.T) {
+		l := NewList[int]()
+		for i := 0; i < 100; i++ {
+			l = l.Append(i)
+		}
+		itr := l.Iterator()
+		for _, index := range []int{99, 3, 96, 95, 64, 63, 0, 97} {
+			itr.Seek(index)
+			if i, v := itr.Next(); i != index || v != index {
+				t.Fatalf("Seek(%d): Next()=<%d,%d>", index, i, v)
+			}
+		}
+		itr.Seek(10)
+		for _, n := range []int{88, -40, 30, -70, 80} {
+			index := itr.Index() + n
+			if !itr.Skip(n) {
+				t.Fatalf("Skip(%d) failed", n)
+			} else if i, v, _ := itr.Peek(); i != index || v != index {
+				t.Fatalf("Skip(%d): Peek()=<%d,%d>", n, i, v)
+			}
+		}
+	})
+
+	t.Run("Builders", func(t *testing.T) {
+		var values []int
+		l := NewList[int]()
+		for i := 0; i < 100; i++ {
+			l, values = l.Append(i), append(values, i)
+		}
+		b := NewListBuilderFrom(l)
+		b.Append(100)
+		b.Set(99, -1)
+		bb := NewBatchListBuilderFrom(l, 4)
+		for i := 0; i < 10; i++ {
+			bb.Append(-i)
+		}
+		bb.Flush()
+		if got := b.List(); got.Len() != 101 || got.Get(99) != -1 || got.Get(100) != 100 {
+			t.Fatalf("unexpected builder list %v", got)
+		} else if got := bb.List(); got.Len() != 110 || got.Get(100) != 0 || got.Get(109) != -9 {
+			t.Fatalf("unexpected batch builder list %v", got)
+		}
+		checkList(t, l, values)
+	})
+
+	t.Run("Compare", func(t *testing.T) {
+		l := NewList[int]()
+		for i := 0; i < 100; i++ {
+			l = l.Append(i)
+		}
+		a, b := l.Append(1), l.Append(2)
+		if CompareLists(a, b) >= 0 || DeepEqual(a, b) {
+			t.Fatal("expected lists differing in their tails to differ")
+		} else if !DeepEqual(a, l.Append(1)) || CompareLists(a, l.Append(1)) != 0 {
+			t.Fatal("expected equal lists")
+		} else if !DeepEqual(l.Slice(0, 99).Append(99), l) {
+			t.Fatal("expected lists with and without a tail to be equal")
+		}
+	})
+
+	RunRandom(t, "Random", func(t *testing.T, rand *rand.Rand) {
+		var values []int
+		l := NewList[int]()
+		for i := 0; i < 2000; i++ {
+			switch rnd := rand.Intn(100); {
+			case rnd == 0 && len(values) > 0:
+				start := rand.Intn(len(values))
+				end := start + rand.Intn(len(values)-start)
+				l, values = l.Slice(start, end), slices.Clone(values[start:end])
+			case rnd < 5:
+				l, values = l.Prepend(i), append([]int{i}, values...)
+			case rnd < 15 && len(values) > 0:
+				index := rand.Intn(len(values))
+				l = l.Set(index, i)
+				values[index] = i
+			default:
+				l, values = l.Append(i), append(values, i)
+			}
+		}
+		checkList(t, l, values)
+	})
 }
 
 func TestList_Contains(t *testing.T) {
@@ -342,139 +779,1900 @@ func TestList_Contains(t *testing.T) {
 	})
 }
 
-// TList represents a list that operates on a standard Go slice & immutable list.
-type TList struct {
-	im, prev *List[int]
-	builder  *ListBuilder[int]
-	std      []int
+func TestListContains(t *testing.T) {
+	for _, n := range []int{0, 10, 64, 100000} {
+		l := NewList[int]()
+		for i := n/2 - 1; i >= 0; i-- {
+			l = l.Prepend(i * 1000)
+		}
+		for i := n / 2; i < n; i++ {
+			l = l.Append(i * 1000)
+		}
+		for _, v := range []int{0, 1000 * (n / 2), 1000 * (n - 1), -1, 1000 * n} {
+			want := v >= 0 && v < 1000*n
+			if got := ListContains(l, v); got != want {
+				t.Fatalf("n=%d: ListContains(%d)=%v", n, v, got)
+			}
+			if got := l.Contains(v); got != want {
+				t.Fatalf("n=%d: Contains(%d)=%v", n, v, got)
+			}
+		}
+	}
+
+	t.Run("Allocs", func(t *testing.T) {
+		l := NewList[int]()
+		for i := 0; i < 10000; i++ {
+			l = l.Append(i * 1000)
+		}
+		if allocs := testing.AllocsPerRun(5, func() { ListContains(l, -1) }); allocs != 0 {
+			t.Fatalf("ListContains allocated %v times", allocs)
+		}
+		if allocs := testing.AllocsPerRun(5, func() { l.Contains(-1) }); allocs > 5 {
+			t.Fatalf("Contains allocated %v times", allocs)
+		}
+	})
 }
 
-// NewTList returns a new instance of TList.
-func NewTList() *TList {
-	return &TList{
-		im:      NewList[int](),
-		builder: NewListBuilder[int](),
+func TestList_IndexOf(t *testing.T) {
+	for _, n := range []int{0, 10, 64, 1000} {
+		l := NewList[int]()
+		for i := n/2 - 1; i >= 0; i-- {
+			l = l.Prepend(i % 50)
+		}
+		for i := n / 2; i < n; i++ {
+			l = l.Append(i % 50)
+		}
+		b := NewListBuilder[int]()
+		for i := 0; i < n; i++ {
+			b.Append(i % 50)
+		}
+
+		for _, v := range []int{0, 7, 49, 50} {
+			want := slices.Index(slices.Collect(listValues(l)), v)
+			if got := l.IndexOf(v); got != want {
+				t.Fatalf("n=%d: IndexOf(%d)=%d, expected %d", n, v, got, want)
+			}
+			if got := b.IndexOf(v); got != want {
+				t.Fatalf("n=%d: builder IndexOf(%d)=%d, expected %d", n, v, got, want)
+			}
+		}
+		var calls int
+		got := l.IndexFunc(func(v int) bool { calls++; return v == 40 })
+		if n > 40 && (got != 40 || calls != 41) {
+			t.Fatalf("n=%d: IndexFunc=%d after %d calls", n, got, calls)
+		} else if n <= 40 && got != -1 {
+			t.Fatalf("n=%d: IndexFunc=%d, expected -1", n, got)
+		}
+		if got := b.IndexFunc(func(v int) bool { return v < 0 }); got != -1 {
+			t.Fatalf("n=%d: builder IndexFunc=%d, expected -1", n, got)
+		}
 	}
-}
 
-// Len returns the size of the list.
-func (l *TList) Len() int {
-	return len(l.std)
+	t.Run("NonComparable", func(t *testing.T) {
+		l := NewList([]byte("foo"), []byte("bar"), []byte("bar"))
+		if got := l.IndexOf([]byte("bar")); got != 1 {
+			t.Fatalf("unexpected index %d", got)
+		}
+		if got := l.IndexOf([]byte("baz")); got != -1 {
+			t.Fatalf("unexpected index %d", got)
+		}
+	})
 }
 
-// ChooseIndex returns a randomly chosen, valid index from the standard slice.
-func (l *TList) ChooseIndex(rand *rand.Rand) int {
-	if len(l.std) == 0 {
-		return -1
+func TestList_Count(t *testing.T) {
+	for _, n := range []int{0, 10, 64, 1000} {
+		l := NewList[int]()
+		for i := n/2 - 1; i >= 0; i-- {
+			l = l.Prepend(i % 7)
+		}
+		for i := n / 2; i < n; i++ {
+			l = l.Append(i % 7)
+		}
+		values := slices.Collect(listValues(l))
+		for _, v := range []int{0, 3, 7} {
+			want := len(values) - len(slices.DeleteFunc(slices.Clone(values), func(x int) bool { return x == v }))
+			if got := l.Count(v); got != want {
+				t.Fatalf("n=%d: Count(%d)=%d, expected %d", n, v, got, want)
+			}
+		}
+		if got := l.CountFunc(func(v int) bool { return v < 2 }); got != l.Count(0)+l.Count(1) {
+			t.Fatalf("n=%d: unexpected CountFunc %d", n, got)
+		}
 	}
-	return rand.Intn(len(l.std))
+
+	t.Run("NonComparable", func(t *testing.T) {
+		l := NewList([]byte("foo"), []byte("bar"), []byte("foo"))
+		if got := l.Count([]byte("foo")); got != 2 {
+			t.Fatalf("unexpected count %d", got)
+		}
+		la := NewList[any](1, "a", []int{1}, []int{1}, 1)
+		if got := la.Count([]int{1}); got != 2 {
+			t.Fatalf("unexpected count %d", got)
+		}
+		if got := la.Count(1); got != 2 {
+			t.Fatalf("unexpected count %d", got)
+		}
+	})
 }
 
-// ChooseSliceIndices returns randomly chosen, valid indices for slicing.
-func (l *TList) ChooseSliceIndices(rand *rand.Rand) (start, end int) {
-	if len(l.std) == 0 {
-		return 0, 0
+func TestList_Any(t *testing.T) {
+	l := NewList[int]()
+	for i := 0; i < 1000; i++ {
+		l = l.Append(i)
+	}
+	for _, tt := range []struct {
+		name             string
+		pred             func(int) bool
+		any, every, none bool
+	}{
+		{"AllMatch", func(v int) bool { return v >= 0 }, true, true, false},
+		{"SomeMatch", func(v int) bool { return v == 500 }, true, false, false},
+		{"NoneMatch", func(v int) bool { return v < 0 }, false, false, true},
+	} {
+		if got := l.Any(tt.pred); got != tt.any {
+			t.Fatalf("%s: Any=%v", tt.name, got)
+		}
+		if got := l.Every(tt.pred); got != tt.every {
+			t.Fatalf("%s: Every=%v", tt.name, got)
+		}
+		if got := l.None(tt.pred); got != tt.none {
+			t.Fatalf("%s: None=%v", tt.name, got)
+		}
 	}
-	start = rand.Intn(len(l.std))
-	end = rand.Intn(len(l.std)-start) + start
-	return start, end
-}
 
-// Append adds v to the end of slice and List.
-func (l *TList) Append(v int) {
-	l.prev = l.im
-	l.im = l.im.Append(v)
-	l.builder.Append(v)
-	l.std = append(l.std, v)
-}
+	t.Run("Empty", func(t *testing.T) {
+		l, pred := NewList[int](), func(int) bool { return true }
+		if l.Any(pred) || !l.Every(pred) || !l.None(pred) {
+			t.Fatal("unexpected empty list result")
+		}
+	})
 
-// Prepend adds v to the beginning of the slice and List.
-func (l *TList) Prepend(v int) {
-	l.prev = l.im
-	l.im = l.im.Prepend(v)
-	l.builder.Prepend(v)
-	l.std = append([]int{v}, l.std...)
+	t.Run("EarlyExit", func(t *testing.T) {
+		var calls int
+		count := func(pred func(int) bool) func(int) bool {
+			return func(v int) bool { calls++; return pred(v) }
+		}
+		l.Any(count(func(v int) bool { return v == 40 }))
+		l.Every(count(func(v int) bool { return v < 40 }))
+		l.None(count(func(v int) bool { return v == 40 }))
+		if calls != 3*41 {
+			t.Fatalf("expected early exit, called %d times", calls)
+		}
+	})
 }
 
-// Set updates the value at index i to v in the slice and List.
-func (l *TList) Set(i, v int) {
-	l.prev = l.im
-	l.im = l.im.Set(i, v)
-	l.builder.Set(i, v)
-	l.std[i] = v
+func TestList_FindLast(t *testing.T) {
+	for _, n := range []int{0, 10, 64, 1000} {
+		l := NewList[int]()
+		for i := n/2 - 1; i >= 0; i-- {
+			l = l.Prepend(i % 50)
+		}
+		for i := n / 2; i < n; i++ {
+			l = l.Append(i % 50)
+		}
+		values := slices.Collect(listValues(l))
+
+		for _, v := range []int{0, 7, 49, 50} {
+			want := -1
+			for i := len(values) - 1; i >= 0; i-- {
+				if values[i] == v {
+					want = i
+					break
+				}
+			}
+			if got := l.LastIndexOf(v); got != want {
+				t.Fatalf("n=%d: LastIndexOf(%d)=%d, expected %d", n, v, got, want)
+			}
+		}
+
+		var calls int
+		v, index, ok := l.FindLast(func(v int) bool { calls++; return v < 5 })
+		if n == 0 {
+			if v != 0 || index != -1 || ok {
+				t.Fatalf("unexpected result for empty list: %d, %d, %v", v, index, ok)
+			}
+			continue
+		}
+		want := len(values) - 1
+		for values[want] >= 5 {
+			want--
+		}
+		if !ok || index != want || v != values[want] || calls != n-want {
+			t.Fatalf("n=%d: FindLast=%d, %d, %v after %d calls", n, v, index, ok, calls)
+		}
+		if _, index, ok := l.FindLast(func(v int) bool { return v < 0 }); ok || index != -1 {
+			t.Fatalf("n=%d: expected no match", n)
+		}
+	}
+
+	t.Run("Sliced", func(t *testing.T) {
+		l := NewList[int]()
+		for i := 0; i < 1000; i++ {
+			l = l.Append(i)
+		}
+		l = l.Slice(100, 600)
+		if _, index, ok := l.FindLast(func(v int) bool { return v < 300 }); !ok || index != 199 {
+			t.Fatalf("unexpected index %d", index)
+		}
+		if got := l.LastIndexOf(700); got != -1 {
+			t.Fatalf("unexpected index %d", got)
+		}
+	})
+}
+
+func TestList_All(t *testing.T) {
+	for _, n := range []int{0, 1, 32, 33, 1000} {
+		l := NewList[int]()
+		for i := n/2 - 1; i >= 0; i-- {
+			l = l.Prepend(i)
+		}
+		for i := n / 2; i < n; i++ {
+			l = l.Append(i)
+		}
+
+		var next int
+		for i, v := range l.All() {
+			if i != next || v != next {
+				t.Fatalf("n=%d: unexpected element %d: %d", n, i, v)
+			}
+			next++
+		}
+		if next != n {
+			t.Fatalf("n=%d: expected %d elements, got %d", n, n, next)
+		}
+		if vs := slices.Collect(l.Values()); !slices.Equal(vs, slices.Collect(listValues(l))) {
+			t.Fatalf("n=%d: unexpected values: %v", n, vs)
+		}
+		next = n - 1
+		for i, v := range l.Backward() {
+			if i != next || v != next {
+				t.Fatalf("n=%d: unexpected element %d: %d", n, i, v)
+			}
+			next--
+		}
+		if next != -1 {
+			t.Fatalf("n=%d: backward stopped at %d", n, next)
+		}
+	}
+
+	t.Run("Break", func(t *testing.T) {
+		l := NewList[int]()
+		for i := 0; i < 1000; i++ {
+			l = l.Append(i)
+		}
+		var seen []int
+		for i, v := range l.All() {
+			if i == 40 {
+				break
+			}
+			seen = append(seen, v)
+		}
+		for v := range l.Values() {
+			if v == 3 {
+				break
+			}
+		}
+		for i := range l.Backward() {
+			if i == 960 {
+				break
+			}
+			seen = append(seen, i)
+		}
+		if len(seen) != 79 || seen[39] != 39 || seen[40] != 999 || seen[78] != 961 {
+			t.Fatalf("unexpected elements before break: %v", seen)
+		}
+	})
+}
+
+func TestList_Compress(t *testing.T) {
+	t.Run("Sliced", func(t *testing.T) {
+		values := make([]int, 1000000)
+		for i := range values {
+			values[i] = i
+		}
+		// The slice straddles a boundary between children of depth 3, so the
+		// root cannot be contracted below them.
+		start := 2<<(3*listNodeBits) - 50
+		l := NewList[int]().AppendSlice(values).Slice(start, start+100)
+		other := l.Compress()
+		if got, want := slices.Collect(other.Values()), values[start:start+100]; !slices.Equal(got, want) {
+			t.Fatalf("Values()=%v, expected %v", got, want)
+		} else if other.origin != 0 {
+			t.Fatalf("unexpected origin %d", other.origin)
+		} else if before, after := l.root.depth(), other.root.depth(); after != 1 || after >= before {
+			t.Fatalf("depth %d, expected 1 and less than %d", after, before)
+		} else if before, after := MemoryFootprint(l), MemoryFootprint(other); after >= before {
+			t.Fatalf("footprint %d, expected less than %d", after, before)
+		} else if SharedSize(l, other).SharedNodes != 0 {
+			t.Fatal("expected no nodes to be shared")
+		}
+	})
+
+	t.Run("Small", func(t *testing.T) {
+		l := NewList[int]()
+		for i := 0; i < 1000; i++ {
+			l = l.Prepend(i)
+		}
+		other := l.Slice(10, 20).Compress()
+		if _, ok := other.root.(*listSliceNode[int]); !ok {
+			t.Fatalf("expected a slice-backed list, got %#v", other)
+		} else if got, want := slices.Collect(other.Values()), []int{989, 988, 987, 986, 985, 984, 983, 982, 981, 980}; !slices.Equal(got, want) {
+			t.Fatalf("Values()=%v, expected %v", got, want)
+		} else if other.Compress() != other {
+			t.Fatal("expected a compact slice-backed list to be returned as is")
+		}
+	})
+
+	t.Run("Sizes", func(t *testing.T) {
+		for _, n := range []int{0, 1, 32, 33, 64, 100, 1024, 1025, 40000} {
+			var values []int
+			l := NewList[int]()
+			for i := 0; i < n; i++ {
+				if i%3 == 0 {
+					l, values = l.Prepend(i), append([]int{i}, values...)
+				} else {
+					l, values = l.Append(i), append(values, i)
+				}
+			}
+			other := l.Compress()
+			if got := slices.Collect(other.Values()); !slices.Equal(got, values) && n > 0 {
+				t.Fatalf("n=%d: unexpected values after Compress", n)
+			}
+			// The compressed list is a regular list in every other respect.
+			other = other.Prepend(-1).Append(-2).Set(0, -3)
+			values = append(append([]int{-3}, values...), -2)
+			if got := slices.Collect(other.Values()); !slices.Equal(got, values) {
+				t.Fatalf("n=%d: unexpected values after updates", n)
+			} else if got := slices.Collect(other.Slice(1, n+1).Values()); !slices.Equal(got, values[1:n+1]) && n > 0 {
+				t.Fatalf("n=%d: unexpected values after Slice", n)
+			}
+		}
+	})
+}
+
+func TestList_SplitAt(t *testing.T) {
+	for _, n := range []int{0, 10, 33, 1000} {
+		l := NewList[int]()
+		for i := n/2 - 1; i >= 0; i-- {
+			l = l.Prepend(i)
+		}
+		for i := n / 2; i < n; i++ {
+			l = l.Append(i)
+		}
+		repr, values := l.GoString(), slices.Collect(listValues(l))
+		for _, i := range []int{0, 1, n / 3, n - 1, n} {
+			if i < 0 || i > n {
+				continue
+			}
+			prefix, suffix := l.SplitAt(i)
+			if vs := slices.Collect(listValues(prefix)); !slices.Equal(vs, values[:i]) {
+				t.Fatalf("n=%d i=%d: unexpected prefix %v", n, i, vs)
+			}
+			if vs := slices.Collect(listValues(suffix)); !slices.Equal(vs, values[i:]) {
+				t.Fatalf("n=%d i=%d: unexpected suffix %v", n, i, vs)
+			}
+			if prefix.Concat(suffix).Len() != n || prefix.Append(-1).Get(i) != -1 || suffix.Prepend(-1).Get(0) != -1 {
+				t.Fatalf("n=%d i=%d: unexpected update", n, i)
+			}
+		}
+		if l.GoString() != repr {
+			t.Fatalf("n=%d: original modified", n)
+		}
+	}
+
+	t.Run("Shared", func(t *testing.T) {
+		l := NewList[int]()
+		for i := 0; i < 10000; i++ {
+			l = l.Append(i)
+		}
+		prefix, suffix := l.SplitAt(5000)
+		if SharedSize(l, prefix).SharedNodes == 0 || SharedSize(l, suffix).SharedNodes == 0 {
+			t.Fatal("expected both halves to share nodes with the original")
+		}
+	})
+
+	t.Run("OutOfBounds", func(t *testing.T) {
+		for _, i := range []int{-1, 4} {
+			func() {
+				defer func() {
+					if r := recover(); r == nil || r.(string) != fmt.Sprintf("immutable.List.SplitAt: index %d out of bounds", i) {
+						t.Fatalf("unexpected panic: %#v", r)
+					}
+				}()
+				NewList(1, 2, 3).SplitAt(i)
+			}()
+		}
+	})
+}
+
+func TestList_ForEachRange(t *testing.T) {
+	for _, n := range []int{0, 10, 33, 5000} {
+		l := NewList[int]()
+		for i := n/2 - 1; i >= 0; i-- {
+			l = l.Prepend(i)
+		}
+		for i := n / 2; i < n; i++ {
+			l = l.Append(i)
+		}
+		for _, r := range [][2]int{{0, n}, {0, 0}, {n / 3, n / 2}, {n / 2, n}} {
+			next := r[0]
+			l.ForEachRange(r[0], r[1], func(i, v int) bool {
+				if i != next || v != next {
+					t.Fatalf("n=%d %v: unexpected element %d: %d", n, r, i, v)
+				}
+				next++
+				return true
+			})
+			if next != r[1] {
+				t.Fatalf("n=%d %v: stopped at %d", n, r, next)
+			}
+		}
+	}
+
+	t.Run("Stop", func(t *testing.T) {
+		l := NewList[int]()
+		for i := 0; i < 1000; i++ {
+			l = l.Append(i)
+		}
+		var visited []int
+		l.ForEachRange(100, 900, func(i, v int) bool {
+			visited = append(visited, i)
+			return len(visited) < 50
+		})
+		if len(visited) != 50 || visited[0] != 100 || visited[49] != 149 {
+			t.Fatalf("unexpected visits: %v", visited)
+		}
+	})
+
+	t.Run("OutOfBounds", func(t *testing.T) {
+		for _, tt := range []struct {
+			start, end int
+			msg        string
+		}{
+			{-1, 2, "immutable.List.ForEachRange: start index -1 out of bounds"},
+			{0, 4, "immutable.List.ForEachRange: end index 4 out of bounds"},
+			{2, 1, "immutable.List.ForEachRange: invalid slice index: [2:1]"},
+		} {
+			func() {
+				defer func() {
+					if r := recover(); r == nil || r.(string) != tt.msg {
+						t.Fatalf("unexpected panic: %#v", r)
+					}
+				}()
+				NewList(1, 2, 3).ForEachRange(tt.start, tt.end, func(int, int) bool { return true })
+			}()
+		}
+	})
+}
+
+func TestList_Windows(t *testing.T) {
+	l := NewList[int]()
+	for i := 0; i < 200; i++ {
+		l = l.Append(i)
+	}
+	for _, tt := range []struct{ size, step, count int }{
+		{1, 1, 200},
+		{3, 1, 198},
+		{50, 1, 151},
+		{50, 7, 22},
+		{200, 1, 1},
+		{201, 1, 0},
+		{5, 300, 1},
+	} {
+		var count int
+		for w := range l.WindowsStep(tt.size, tt.step) {
+			start := count * tt.step
+			if vs := slices.Collect(listValues(w)); len(vs) != tt.size || vs[0] != start || vs[tt.size-1] != start+tt.size-1 {
+				t.Fatalf("size=%d step=%d: unexpected window %d: %v", tt.size, tt.step, count, vs)
+			}
+			count++
+		}
+		if count != tt.count {
+			t.Fatalf("size=%d step=%d: expected %d windows, got %d", tt.size, tt.step, tt.count, count)
+		}
+	}
+
+	t.Run("Shared", func(t *testing.T) {
+		for w := range l.Windows(100) {
+			if SharedSize(l, w).SharedNodes == 0 {
+				t.Fatal("expected windows to share nodes with the list")
+			}
+			break
+		}
+	})
+
+	t.Run("Average", func(t *testing.T) {
+		var avgs []int
+		for w := range NewList(1, 2, 3, 4, 5).Windows(3) {
+			avgs = append(avgs, ReduceList(w, 0, func(acc, v int) int { return acc + v })/w.Len())
+		}
+		if !slices.Equal(avgs, []int{2, 3, 4}) {
+			t.Fatalf("unexpected averages: %v", avgs)
+		}
+	})
+
+	t.Run("InvalidSize", func(t *testing.T) {
+		for _, size := range []int{0, -1} {
+			func() {
+				defer func() {
+					if r := recover(); r == nil || r.(string) != "immutable.List.Windows: size must be positive" {
+						t.Fatalf("unexpected panic: %#v", r)
+					}
+				}()
+				l.Windows(size)
+			}()
+		}
+	})
+}
+
+func TestList_Rotate(t *testing.T) {
+	for _, size := range []int{1, 10, 33, 1000} {
+		l := NewList[int]()
+		for i := size/2 - 1; i >= 0; i-- {
+			l = l.Prepend(i)
+		}
+		for i := size / 2; i < size; i++ {
+			l = l.Append(i)
+		}
+		repr := l.GoString()
+		for _, n := range []int{0, 1, 5, size / 2, size - 1, size, size + 3, -1, -size - 3} {
+			k := ((n % size) + size) % size
+			want := append(slices.Collect(listValues(l.Slice(k, size))), slices.Collect(listValues(l.Slice(0, k)))...)
+			got := l.Rotate(n)
+			if vs := slices.Collect(listValues(got)); !slices.Equal(vs, want) {
+				t.Fatalf("size=%d n=%d: expected %v, got %v", size, n, want, vs)
+			}
+			if k == 0 && got != l {
+				t.Fatalf("size=%d n=%d: expected the receiver", size, n)
+			}
+		}
+		if l.GoString() != repr {
+			t.Fatalf("size=%d: original modified", size)
+		}
+	}
+
+	if l := NewList[int](); l.Rotate(3) != l {
+		t.Fatal("expected the receiver for an empty list")
+	}
+}
+
+func TestList_Splice(t *testing.T) {
+	RunRandom(t, "Random", func(t *testing.T, rand *rand.Rand) {
+		n := rand.Intn(2000)
+		l := NewList[int]()
+		for i := n/2 - 1; i >= 0; i-- {
+			l = l.Prepend(i)
+		}
+		for i := n / 2; i < n; i++ {
+			l = l.Append(i)
+		}
+		repr, values := l.GoString(), slices.Collect(listValues(l))
+
+		start := rand.Intn(n + 1)
+		end := start + rand.Intn(n-start+1)
+		replacement := make([]int, rand.Intn(100))
+		for i := range replacement {
+			replacement[i] = -i
+		}
+		got := l.Splice(start, end, replacement...)
+		want := slices.Replace(slices.Clone(values), start, end, replacement...)
+		if vs := slices.Collect(listValues(got)); !slices.Equal(vs, want) {
+			t.Fatalf("[%d:%d] with %d values: unexpected list", start, end, len(replacement))
+		}
+		if got = got.Append(-1).Prepend(-2); got.Get(0) != -2 || got.Get(got.Len()-1) != -1 {
+			t.Fatal("unexpected append or prepend")
+		}
+		if l.GoString() != repr {
+			t.Fatal("original modified")
+		}
+	})
+
+	t.Run("NoOp", func(t *testing.T) {
+		l := NewList(1, 2, 3)
+		if l.Splice(1, 1) != l {
+			t.Fatal("expected the receiver for an empty splice")
+		}
+	})
+
+	t.Run("ReplacementCopied", func(t *testing.T) {
+		replacement := []int{7, 8}
+		l := NewList(1, 2, 3).Splice(0, 0, replacement...)
+		replacement[0] = 0
+		if got := slices.Collect(listValues(l)); !slices.Equal(got, []int{7, 8, 1, 2, 3}) {
+			t.Fatalf("unexpected list: %v", got)
+		}
+	})
+
+	t.Run("OutOfBounds", func(t *testing.T) {
+		for _, tt := range []struct {
+			start, end int
+			msg        string
+		}{
+			{-1, 2, "immutable.List.Splice: start index -1 out of bounds"},
+			{0, 4, "immutable.List.Splice: end index 4 out of bounds"},
+			{2, 1, "immutable.List.Splice: invalid slice index: [2:1]"},
+		} {
+			func() {
+				defer func() {
+					if r := recover(); r == nil || r.(string) != tt.msg {
+						t.Fatalf("unexpected panic: %#v", r)
+					}
+				}()
+				NewList(1, 2, 3).Splice(tt.start, tt.end, 0)
+			}()
+		}
+	})
+}
+
+func TestList_Pop(t *testing.T) {
+	l := NewList[int]()
+	for i := 0; i < 40000; i++ {
+		l = l.Append(i)
+	}
+	repr := l.GoString()
+
+	popped := l
+	for i := 39999; i >= 40; i-- {
+		var v int
+		var ok bool
+		if popped, v, ok = popped.Pop(); !ok || v != i || popped.Len() != i {
+			t.Fatalf("unexpected pop at %d: %d, %v", i, v, ok)
+		}
+	}
+	// The trie contracts as its branches empty.
+	checkGoString(t, popped, "&immutable.List[int]{len:40, repr:trie, depth:2}")
+	if got := slices.Collect(listValues(popped.Append(-1))); len(got) != 41 || got[39] != 39 || got[40] != -1 {
+		t.Fatalf("unexpected list after pops: %v", got)
+	}
+
+	shifted := l
+	for i := 0; i < 39960; i++ {
+		var v int
+		var ok bool
+		if shifted, v, ok = shifted.Shift(); !ok || v != i || shifted.Len() != 39999-i {
+			t.Fatalf("unexpected shift at %d: %d, %v", i, v, ok)
+		}
+	}
+	if got := shifted.Prepend(-1); got.Get(0) != -1 || got.Get(1) != 39960 || got.Len() != 41 {
+		t.Fatalf("unexpected list after shifts: %v", got)
+	}
+	if l.GoString() != repr {
+		t.Fatal("original modified")
+	}
+
+	t.Run("Empty", func(t *testing.T) {
+		l := NewList(1)
+		l, v, ok := l.Pop()
+		if !ok || v != 1 || l.Len() != 0 {
+			t.Fatalf("unexpected pop: %d, %v", v, ok)
+		}
+		if next, v, ok := l.Pop(); ok || v != 0 || next != l {
+			t.Fatal("expected pop of an empty list to fail")
+		}
+		if next, v, ok := l.Shift(); ok || v != 0 || next != l {
+			t.Fatal("expected shift of an empty list to fail")
+		}
+	})
+}
+
+func TestListIterator_Peek(t *testing.T) {
+	RunRandom(t, "Random", func(t *testing.T, rand *rand.Rand) {
+		n := rand.Intn(2000)
+		l := NewList[int]()
+		for i := n/2 - 1; i >= 0; i-- {
+			l = l.Prepend(i)
+		}
+		for i := n / 2; i < n; i++ {
+			l = l.Append(i)
+		}
+
+		itr := l.Iterator()
+		for k := 0; k < 500; k++ {
+			switch rand.Intn(5) {
+			case 0:
+				itr.First()
+			case 1:
+				itr.Last()
+			case 2:
+				if n > 0 {
+					itr.Seek(rand.Intn(n))
+				}
+			case 3:
+				itr.Next()
+			case 4:
+				itr.Prev()
+			}
+
+			index, value, ok := itr.Peek()
+			wantIndex, wantValue := itr.Clone().Next()
+			if index != wantIndex || value != wantValue || ok != (wantIndex >= 0) {
+				t.Fatalf("Peek()=(%d, %d, %v), Next() returns (%d, %d)", index, value, ok, wantIndex, wantValue)
+			}
+			if prevIndex, prevValue := itr.Clone().Prev(); prevIndex != index || prevValue != value {
+				t.Fatalf("Peek()=(%d, %d), Prev() returns (%d, %d)", index, value, prevIndex, prevValue)
+			}
+		}
+	})
+
+	t.Run("Done", func(t *testing.T) {
+		itr := NewList(1, 2).Iterator()
+		itr.Next()
+		if index, value, ok := itr.Peek(); index != 1 || value != 2 || !ok {
+			t.Fatalf("unexpected peek: %d, %d, %v", index, value, ok)
+		}
+		itr.Next()
+		if index, value, ok := itr.Peek(); index != -1 || value != 0 || ok {
+			t.Fatalf("unexpected peek when done: %d, %d, %v", index, value, ok)
+		}
+		if _, _, ok := NewList[int]().Iterator().Peek(); ok {
+			t.Fatal("unexpected peek on an empty list")
+		}
+	})
+}
+
+func TestListIterator_Index(t *testing.T) {
+	for _, n := range []int{0, 10, 1000} {
+		l := NewList[int]()
+		for i := 0; i < n; i++ {
+			l = l.Append(i)
+		}
+		itr := l.Iterator()
+		check := func(op string) {
+			t.Helper()
+			want, _ := itr.Clone().Next()
+			if got := itr.Index(); got != want {
+				t.Fatalf("n=%d after %s: Index()=%d, expected %d", n, op, got, want)
+			}
+		}
+		check("Iterator")
+		itr.Last()
+		check("Last")
+		itr.Next()
+		check("Next past the end")
+		if itr.First(); n > 0 {
+			itr.Seek(n / 2)
+			check("Seek")
+			saved := itr.Index()
+			itr.Next()
+			itr.Next()
+			itr.Prev()
+			check("Next and Prev")
+			itr.Seek(saved)
+			if _, v := itr.Next(); v != n/2 {
+				t.Fatalf("n=%d: unexpected value after restoring: %d", n, v)
+			}
+			itr.First()
+			check("First")
+			itr.Prev()
+			check("Prev past the start")
+		}
+	}
+}
+
+func TestListIterator_Skip(t *testing.T) {
+	RunRandom(t, "Random", func(t *testing.T, rand *rand.Rand) {
+		n := 1 + rand.Intn(20000)
+		l := NewList[int]()
+		for i := n/2 - 1; i >= 0; i-- {
+			l = l.Prepend(i)
+		}
+		for i := n / 2; i < n; i++ {
+			l = l.Append(i)
+		}
+
+		itr := l.Iterator()
+		for k := 0; k < 200; k++ {
+			if itr.Done() {
+				itr.Seek(rand.Intn(n))
+			}
+			from := itr.index
+			var step int
+			switch rand.Intn(3) {
+			case 0:
+				step = rand.Intn(3) - 1
+			case 1:
+				step = rand.Intn(64) - 32
+			case 2:
+				step = rand.Intn(2*n) - n
+			}
+
+			ok := itr.Skip(step)
+			if want := from+step >= 0 && from+step < n; ok != want || itr.Done() == want {
+				t.Fatalf("Skip(%d) from %d: ok=%v, done=%v", step, from, ok, itr.Done())
+			}
+			if ok {
+				if index, value := itr.Clone().Next(); index != from+step || value != from+step {
+					t.Fatalf("Skip(%d) from %d: Next() returns (%d, %d)", step, from, index, value)
+				}
+				if index, value := itr.Clone().Prev(); index != from+step || value != from+step {
+					t.Fatalf("Skip(%d) from %d: Prev() returns (%d, %d)", step, from, index, value)
+				}
+			}
+		}
+	})
+
+	t.Run("Done", func(t *testing.T) {
+		itr := NewList(1, 2, 3).Iterator()
+		if !itr.Skip(2) || itr.Skip(1) || !itr.Done() || itr.Skip(-1) {
+			t.Fatal("expected skipping past the end to finish the iterator")
+		}
+		itr.First()
+		if itr.Skip(-1) || !itr.Done() {
+			t.Fatal("expected skipping before the start to finish the iterator")
+		}
+	})
+}
+
+func TestList_EachReverse(t *testing.T) {
+	for _, n := range []int{0, 10, 33, 10000} {
+		l := NewList[int]()
+		for i := n/2 - 1; i >= 0; i-- {
+			l = l.Prepend(i)
+		}
+		for i := n / 2; i < n; i++ {
+			l = l.Append(i)
+		}
+		next := n - 1
+		l.EachReverse(func(i, v int) bool {
+			if i != next || v != next {
+				t.Fatalf("n=%d: unexpected element %d: %d", n, i, v)
+			}
+			next--
+			return true
+		})
+		if next != -1 {
+			t.Fatalf("n=%d: stopped at %d", n, next)
+		}
+	}
+
+	t.Run("Stop", func(t *testing.T) {
+		l := NewList[int]()
+		for i := 0; i < 10000; i++ {
+			l = l.Append(i)
+		}
+		var count int
+		fn := func(int, int) bool {
+			count++
+			return count < 50
+		}
+		if allocs := testing.AllocsPerRun(10, func() { count = 0; l.EachReverse(fn) }); allocs != 0 {
+			t.Fatalf("unexpected allocations: %v", allocs)
+		}
+		if count != 50 {
+			t.Fatalf("expected 50 visits, got %d", count)
+		}
+	})
+}
+
+func TestList_Take(t *testing.T) {
+	for _, size := range []int{0, 10, 33, 1000} {
+		l := NewList[int]()
+		for i := 0; i < size; i++ {
+			l = l.Append(i)
+		}
+		values := slices.Collect(listValues(l))
+		for _, n := range []int{-5, 0, 1, size / 2, size - 1, size, size + 10} {
+			k := min(max(n, 0), size)
+			if vs := slices.Collect(listValues(l.Take(n))); !slices.Equal(vs, values[:k]) {
+				t.Fatalf("size=%d: Take(%d)=%v", size, n, vs)
+			}
+			if vs := slices.Collect(listValues(l.Drop(n))); !slices.Equal(vs, values[k:]) {
+				t.Fatalf("size=%d: Drop(%d)=%v", size, n, vs)
+			}
+			pred := func(v int) bool { return v < n }
+			if vs := slices.Collect(listValues(l.TakeWhile(pred))); !slices.Equal(vs, values[:k]) {
+				t.Fatalf("size=%d: TakeWhile(<%d)=%v", size, n, vs)
+			}
+			if vs := slices.Collect(listValues(l.DropWhile(pred))); !slices.Equal(vs, values[k:]) {
+				t.Fatalf("size=%d: DropWhile(<%d)=%v", size, n, vs)
+			}
+		}
+		if l.Take(size) != l || l.Drop(0) != l {
+			t.Fatalf("size=%d: expected the receiver for the full range", size)
+		}
+	}
+
+	t.Run("StopsAtFirstMismatch", func(t *testing.T) {
+		l := NewList(1, 2, 5, 1, 2)
+		if got := slices.Collect(listValues(l.TakeWhile(func(v int) bool { return v < 3 }))); !slices.Equal(got, []int{1, 2}) {
+			t.Fatalf("unexpected list: %v", got)
+		}
+		if got := slices.Collect(listValues(l.DropWhile(func(v int) bool { return v < 3 }))); !slices.Equal(got, []int{5, 1, 2}) {
+			t.Fatalf("unexpected list: %v", got)
+		}
+	})
+}
+
+func TestList_UpdateAt(t *testing.T) {
+	for _, n := range []int{1, 10, 33, 1000, 40000} {
+		l := NewList[int]()
+		for i := n/2 - 1; i >= 0; i-- {
+			l = l.Prepend(i)
+		}
+		for i := n / 2; i < n; i++ {
+			l = l.Append(i)
+		}
+		repr := l.GoString()
+
+		b := NewListBuilder[int]()
+		for i := 0; i < n; i++ {
+			b.Append(i)
+		}
+		other := l
+		for _, index := range []int{0, n / 2, n - 1} {
+			var calls int
+			inc := func(old int) int { calls++; return old + 1 }
+			other = other.UpdateAt(index, inc)
+			b.UpdateAt(index, inc)
+			if calls != 2 {
+				t.Fatalf("n=%d index=%d: fn called %d times", n, index, calls)
+			}
+		}
+		for _, got := range []*List[int]{other, b.List()} {
+			for _, index := range []int{0, n / 2, n - 1} {
+				want := index + 1
+				if n == 1 {
+					want = 3
+				}
+				if got.Get(index) != want {
+					t.Fatalf("n=%d: unexpected value at %d: %d", n, index, got.Get(index))
+				}
+			}
+			if n > 2 && got.Get(1) != 1 {
+				t.Fatalf("n=%d: unexpected value at 1: %d", n, got.Get(1))
+			}
+		}
+		if l.GoString() != repr || l.Get(0) != 0 {
+			t.Fatalf("n=%d: original modified", n)
+		}
+	}
+
+	t.Run("OutOfBounds", func(t *testing.T) {
+		for _, index := range []int{-1, 3} {
+			func() {
+				defer func() {
+					if r := recover(); r == nil || r.(string) != fmt.Sprintf("immutable.List.UpdateAt: index %d out of bounds", index) {
+						t.Fatalf("unexpected panic: %#v", r)
+					}
+				}()
+				NewList(1, 2, 3).UpdateAt(index, func(v int) int { return v })
+			}()
+		}
+	})
+}
+
+func TestList_Swap(t *testing.T) {
+	RunRandom(t, "Random", func(t *testing.T, rand *rand.Rand) {
+		n := 1 + rand.Intn(2000)
+		l := NewList[int]()
+		for i := n/2 - 1; i >= 0; i-- {
+			l = l.Prepend(i)
+		}
+		for i := n / 2; i < n; i++ {
+			l = l.Append(i)
+		}
+		repr := l.GoString()
+		values := slices.Collect(listValues(l))
+
+		b := NewListBuilder[int]()
+		for _, v := range values {
+			b.Append(v)
+		}
+		other := l
+		for k := 0; k < 20; k++ {
+			i, j := rand.Intn(n), rand.Intn(n)
+			values[i], values[j] = values[j], values[i]
+			other = other.Swap(i, j)
+			b.Swap(i, j)
+		}
+		if vs := slices.Collect(listValues(other)); !slices.Equal(vs, values) {
+			t.Fatalf("expected %v, got %v", values, vs)
+		}
+		if vs := slices.Collect(listValues(b.List())); !slices.Equal(vs, values) {
+			t.Fatalf("builder expected %v, got %v", values, vs)
+		}
+		if l.GoString() != repr {
+			t.Fatal("original modified")
+		}
+	})
+
+	t.Run("SameIndex", func(t *testing.T) {
+		l := NewList(1, 2, 3)
+		if l.Swap(1, 1) != l {
+			t.Fatal("expected the receiver when i equals j")
+		}
+	})
+
+	t.Run("CopiesOnce", func(t *testing.T) {
+		l := NewList[int]()
+		for i := 0; i < 1000; i++ {
+			l = l.Append(i)
+		}
+		// A swap within one leaf copies the root, one branch and one leaf.
+		if allocs := testing.AllocsPerRun(10, func() { l.Swap(3, 5) }); allocs > 4 {
+			t.Fatalf("unexpected allocations: %v", allocs)
+		}
+	})
+
+	t.Run("OutOfBounds", func(t *testing.T) {
+		for _, index := range []int{-1, 3} {
+			func() {
+				defer func() {
+					if r := recover(); r == nil || r.(string) != fmt.Sprintf("immutable.List.Swap: index %d out of bounds", index) {
+						t.Fatalf("unexpected panic: %#v", r)
+					}
+				}()
+				NewList(1, 2, 3).Swap(0, index)
+			}()
+		}
+	})
+}
+
+// TList represents a list that operates on a standard Go slice & immutable list.
+type TList struct {
+	im, prev *List[int]
+	builder  *ListBuilder[int]
+	std      []int
+}
+
+// NewTList returns a new instance of TList.
+func NewTList() *TList {
+	return &TList{
+		im:      NewList[int](),
+		builder: NewListBuilder[int](),
+	}
+}
+
+// Len returns the size of the list.
+func (l *TList) Len() int {
+	return len(l.std)
+}
+
+// ChooseIndex returns a randomly chosen, valid index from the standard slice.
+func (l *TList) ChooseIndex(rand *rand.Rand) int {
+	if len(l.std) == 0 {
+		return -1
+	}
+	return rand.Intn(len(l.std))
+}
+
+// ChooseSliceIndices returns randomly chosen, valid indices for slicing.
+func (l *TList) ChooseSliceIndices(rand *rand.Rand) (start, end int) {
+	if len(l.std) == 0 {
+		return 0, 0
+	}
+	start = rand.Intn(len(l.std))
+	end = rand.Intn(len(l.std)-start) + start
+	return start, end
+}
+
+// Append adds v to the end of slice and List.
+func (l *TList) Append(v int) {
+	l.prev = l.im
+	l.im = l.im.Append(v)
+	l.builder.Append(v)
+	l.std = append(l.std, v)
+}
+
+// Prepend adds v to the beginning of the slice and List.
+func (l *TList) Prepend(v int) {
+	l.prev = l.im
+	l.im = l.im.Prepend(v)
+	l.builder.Prepend(v)
+	l.std = append([]int{v}, l.std...)
+}
+
+// Set updates the value at index i to v in the slice and List.
+func (l *TList) Set(i, v int) {
+	l.prev = l.im
+	l.im = l.im.Set(i, v)
+	l.builder.Set(i, v)
+	l.std[i] = v
+}
+
+// Slice contracts the slice and List to the range of start/end indices.
+func (l *TList) Slice(start, end int) {
+	l.prev = l.im
+	l.im = l.im.Slice(start, end)
+	l.builder.Slice(start, end)
+	l.std = l.std[start:end]
+}
+
+// Validate returns an error if the slice and List are different.
+func (l *TList) Validate() error {
+	if got, exp := l.im.Len(), len(l.std); got != exp {
+		return fmt.Errorf("Len()=%v, expected %d", got, exp)
+	} else if got, exp := l.builder.Len(), len(l.std); got != exp {
+		return fmt.Errorf("Len()=%v, expected %d", got, exp)
+	}
+
+	for i := range l.std {
+		if got, exp := l.im.Get(i), l.std[i]; got != exp {
+			return fmt.Errorf("Get(%d)=%v, expected %v", i, got, exp)
+		} else if got, exp := l.builder.Get(i), l.std[i]; got != exp {
+			return fmt.Errorf("Builder.List/Get(%d)=%v, expected %v", i, got, exp)
+		}
+	}
+
+	if err := l.validateForwardIterator("basic", l.im.Iterator()); err != nil {
+		return err
+	} else if err := l.validateBackwardIterator("basic", l.im.Iterator()); err != nil {
+		return err
+	}
+
+	// Iterate the builder's list directly: Iterator takes a snapshot, after
+	// which the next operations copy nodes rather than update them in place.
+	if err := l.validateForwardIterator("builder", l.builder.list.Iterator()); err != nil {
+		return err
+	} else if err := l.validateBackwardIterator("builder", l.builder.list.Iterator()); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (l *TList) validateForwardIterator(typ string, itr *ListIterator[int]) error {
+	for i := range l.std {
+		if j, v := itr.Next(); i != j || l.std[i] != v {
+			return fmt.Errorf("ListIterator.Next()=<%v,%v>, expected <%v,%v> [%s]", j, v, i, l.std[i], typ)
+		}
+
+		done := i == len(l.std)-1
+		if v := itr.Done(); v != done {
+			return fmt.Errorf("ListIterator.Done()=%v, expected %v [%s]", v, done, typ)
+		}
+	}
+	if i, v := itr.Next(); i != -1 || v != 0 {
+		return fmt.Errorf("ListIterator.Next()=<%v,%v>, expected DONE [%s]", i, v, typ)
+	}
+	return nil
+}
+
+func (l *TList) validateBackwardIterator(typ string, itr *ListIterator[int]) error {
+	itr.Last()
+	for i := len(l.std) - 1; i >= 0; i-- {
+		if j, v := itr.Prev(); i != j || l.std[i] != v {
+			return fmt.Errorf("ListIterator.Prev()=<%v,%v>, expected <%v,%v> [%s]", j, v, i, l.std[i], typ)
+		}
+
+		done := i == 0
+		if v := itr.Done(); v != done {
+			return fmt.Errorf("ListIterator.Done()=%v, expected %v [%s]", v, done, typ)
+		}
+	}
+	if i, v := itr.Prev(); i != -1 || v != 0 {
+		return fmt.Errorf("ListIterator.Prev()=<%v,%v>, expected DONE [%s]", i, v, typ)
+	}
+	return nil
+}
+
+func TestList_RemoveAll(t *testing.T) {
+	for _, n := range []int{0, 10, 1000} {
+		l := NewList[int]()
+		for i := n/2 - 1; i >= 0; i-- {
+			l = l.Prepend(i)
+		}
+		for i := n / 2; i < n; i++ {
+			l = l.Append(i)
+		}
+		values := slices.Collect(listValues(l))
+
+		for _, tt := range []struct {
+			name string
+			pred func(int) bool
+		}{
+			{"None", func(v int) bool { return v < 0 }},
+			{"All", func(v int) bool { return true }},
+			{"Even", func(v int) bool { return v%2 == 0 }},
+			{"Front", func(v int) bool { return v < 5 }},
+			{"Back", func(v int) bool { return v >= n-5 }},
+			{"Ends", func(v int) bool { return v < 3 || v >= n-3 }},
+		} {
+			var calls int
+			got := l.RemoveAll(func(v int) bool { calls++; return tt.pred(v) })
+			want := slices.DeleteFunc(slices.Clone(values), tt.pred)
+			if vs := slices.Collect(listValues(got)); !slices.Equal(vs, want) || got.Len() != len(want) {
+				t.Fatalf("n=%d %s: expected %v, got %v", n, tt.name, want, vs)
+			}
+			if len(want) == n && got != l {
+				t.Fatalf("n=%d %s: expected the receiver when nothing is removed", n, tt.name)
+			}
+			if calls != n {
+				t.Fatalf("n=%d %s: predicate called %d times", n, tt.name, calls)
+			}
+			if got = got.Append(-1); got.Get(got.Len()-1) != -1 {
+				t.Fatalf("n=%d %s: unexpected append", n, tt.name)
+			}
+		}
+		if vs := slices.Collect(listValues(l)); !slices.Equal(vs, values) {
+			t.Fatalf("n=%d: original modified", n)
+		}
+	}
+
+	t.Run("SharedPrefix", func(t *testing.T) {
+		l := NewList[int]()
+		for i := 0; i < 10000; i++ {
+			l = l.Append(i)
+		}
+		other := l.RemoveAll(func(v int) bool { return v >= 9990 })
+		if stats := SharedSize(l, other); stats.SharedNodes == 0 {
+			t.Fatalf("expected nodes before the first match to be shared: %+v", stats)
+		}
+	})
+
+	t.Run("RemoveValue", func(t *testing.T) {
+		l := NewList(1, 2, 1, 3, 1)
+		if got := slices.Collect(listValues(l.RemoveValue(1))); !slices.Equal(got, []int{2, 3}) {
+			t.Fatalf("unexpected list: %v", got)
+		}
+		if l.RemoveValue(4) != l {
+			t.Fatal("expected the receiver when the value is absent")
+		}
+		ls := NewList([]int{1}, []int{2}, []int{1})
+		if got := ls.RemoveValue([]int{1}); got.Len() != 1 || got.Get(0)[0] != 2 {
+			t.Fatalf("unexpected list of slices: %v", slices.Collect(listValues(got)))
+		}
+	})
+}
+
+func TestList_InsertAt(t *testing.T) {
+	for _, n := range []int{0, 1, 31, 32, 33, 64, 1000} {
+		// Build half the list by prepending so the trie has a non-zero origin.
+		l := NewList[int]()
+		for i := n/2 - 1; i >= 0; i-- {
+			l = l.Prepend(i)
+		}
+		for i := n / 2; i < n; i++ {
+			l = l.Append(i)
+		}
+		repr := l.GoString()
+
+		for _, index := range []int{0, 1, n / 2, n - 1, n} {
+			if index < 0 || index > n {
+				continue
+			}
+			want := slices.Insert(slices.Collect(listValues(l)), index, -1)
+			got := l.InsertAt(index, -1)
+			if vs := slices.Collect(listValues(got)); !slices.Equal(vs, want) || got.Len() != n+1 {
+				t.Fatalf("n=%d index=%d: expected %v, got %v", n, index, want, vs)
+			}
+			if got = got.Append(-2).Prepend(-3); got.Get(0) != -3 || got.Get(n+2) != -2 || got.Get(index+1) != -1 {
+				t.Fatalf("n=%d index=%d: unexpected append or prepend", n, index)
+			}
+
+			b := NewListBuilder[int]()
+			for i := 0; i < n; i++ {
+				b.Append(i)
+			}
+			b.InsertAt(index, -1)
+			if vs := slices.Collect(listValues(b.List())); !slices.Equal(vs, want) {
+				t.Fatalf("n=%d index=%d: builder expected %v, got %v", n, index, want, vs)
+			}
+		}
+		if l.GoString() != repr {
+			t.Fatalf("n=%d: original modified", n)
+		}
+	}
+
+	t.Run("Repeated", func(t *testing.T) {
+		l, want := NewList[int](), []int{}
+		for i := 0; i < 200; i++ {
+			index := (i * 7) % (len(want) + 1)
+			l, want = l.InsertAt(index, i), slices.Insert(want, index, i)
+		}
+		if vs := slices.Collect(listValues(l)); !slices.Equal(vs, want) {
+			t.Fatalf("expected %v, got %v", want, vs)
+		}
+	})
+
+	t.Run("OutOfBounds", func(t *testing.T) {
+		for _, index := range []int{-1, 4} {
+			func() {
+				defer func() {
+					if r := recover(); r == nil || r.(string) != fmt.Sprintf("immutable.List.InsertAt: index %d out of bounds", index) {
+						t.Fatalf("unexpected panic: %#v", r)
+					}
+				}()
+				NewList(1, 2, 3).InsertAt(index, 0)
+			}()
+		}
+	})
+}
+
+func TestList_RemoveAt(t *testing.T) {
+	for _, n := range []int{1, 2, 32, 33, 34, 65, 1000} {
+		l := NewList[int]()
+		for i := n/2 - 1; i >= 0; i-- {
+			l = l.Prepend(i)
+		}
+		for i := n / 2; i < n; i++ {
+			l = l.Append(i)
+		}
+		repr := l.GoString()
+
+		for _, index := range []int{0, 1, n / 2, n - 2, n - 1} {
+			if index < 0 || index >= n {
+				continue
+			}
+			want := slices.Delete(slices.Collect(listValues(l)), index, index+1)
+			got := l.RemoveAt(index)
+			if vs := slices.Collect(listValues(got)); !slices.Equal(vs, want) || got.Len() != n-1 {
+				t.Fatalf("n=%d index=%d: expected %v, got %v", n, index, want, vs)
+			}
+			if got = got.Append(-1).Prepend(-2); got.Get(0) != -2 || got.Get(n) != -1 {
+				t.Fatalf("n=%d index=%d: unexpected append or prepend", n, index)
+			}
+
+			b := NewListBuilder[int]()
+			for i := 0; i < n; i++ {
+				b.Append(i)
+			}
+			b.RemoveAt(index)
+			if vs := slices.Collect(listValues(b.List())); !slices.Equal(vs, want) {
+				t.Fatalf("n=%d index=%d: builder expected %v, got %v", n, index, want, vs)
+			}
+		}
+		if l.GoString() != repr {
+			t.Fatalf("n=%d: original modified", n)
+		}
+	}
+
+	t.Run("Sliced", func(t *testing.T) {
+		l := NewList[int]()
+		for i := 0; i < 1000; i++ {
+			l = l.Append(i)
+		}
+		l = l.Slice(100, 900)
+		want := slices.Collect(listValues(l))
+		for _, index := range []int{700, 10, 0, 300} {
+			l, want = l.RemoveAt(index), slices.Delete(want, index, index+1)
+		}
+		if vs := slices.Collect(listValues(l)); !slices.Equal(vs, want) {
+			t.Fatalf("expected %v, got %v", want, vs)
+		}
+	})
+
+	t.Run("SharedNodes", func(t *testing.T) {
+		l := NewList[int]()
+		for i := 0; i < 10000; i++ {
+			l = l.Append(i)
+		}
+		for _, index := range []int{5, 9990} {
+			if stats := SharedSize(l, l.RemoveAt(index)); stats.SharedNodes == 0 {
+				t.Fatalf("index=%d: expected untouched nodes to be shared: %+v", index, stats)
+			}
+		}
+	})
+
+	t.Run("OutOfBounds", func(t *testing.T) {
+		for _, index := range []int{-1, 3} {
+			func() {
+				defer func() {
+					if r := recover(); r == nil || r.(string) != fmt.Sprintf("immutable.List.RemoveAt: index %d out of bounds", index) {
+						t.Fatalf("unexpected panic: %#v", r)
+					}
+				}()
+				NewList(1, 2, 3).RemoveAt(index)
+			}()
+		}
+	})
 }
 
-// Slice contracts the slice and List to the range of start/end indices.
-func (l *TList) Slice(start, end int) {
-	l.prev = l.im
-	l.im = l.im.Slice(start, end)
-	l.builder.Slice(start, end)
-	l.std = l.std[start:end]
+func TestList_AppendSlice(t *testing.T) {
+	for _, m := range []int{0, 1, 20, 32, 33, 1000} {
+		for _, n := range []int{0, 1, 12, 13, 32, 33, 1000} {
+			l := NewList[int]()
+			for i := m - 1; i >= 0; i-- {
+				l = l.Prepend(i)
+			}
+			repr := l.GoString()
+			values := make([]int, n)
+			for i := range values {
+				values[i] = m + i
+			}
+
+			got := l.AppendSlice(values)
+			if vs := slices.Collect(listValues(got)); len(vs) != m+n || !slices.IsSorted(vs) || (m+n > 0 && vs[m+n-1] != m+n-1) {
+				t.Fatalf("m=%d n=%d: unexpected list: %v", m, n, vs)
+			}
+			if n == 0 && got != l {
+				t.Fatalf("m=%d: expected the receiver for no values", m)
+			}
+			if n > 0 {
+				if values[0] = -1; got.Get(m) != m {
+					t.Fatalf("m=%d n=%d: list aliases values", m, n)
+				}
+			}
+			if got = got.Append(-1).Prepend(-2); got.Get(0) != -2 || got.Get(m+n+1) != -1 {
+				t.Fatalf("m=%d n=%d: unexpected append or prepend", m, n)
+			}
+			if l.GoString() != repr {
+				t.Fatalf("m=%d n=%d: original modified", m, n)
+			}
+
+			b := NewListBuilder[int]()
+			for i := 0; i < m; i++ {
+				b.Append(i)
+			}
+			b.AppendSlice([]int{-1, -2})
+			b.Append(-3)
+			if got := b.List(); got.Len() != m+3 || got.Get(m) != -1 || got.Get(m+2) != -3 {
+				t.Fatalf("m=%d: unexpected builder list: %v", m, got)
+			}
+		}
+	}
 }
 
-// Validate returns an error if the slice and List are different.
-func (l *TList) Validate() error {
-	if got, exp := l.im.Len(), len(l.std); got != exp {
-		return fmt.Errorf("Len()=%v, expected %d", got, exp)
-	} else if got, exp := l.builder.Len(), len(l.std); got != exp {
-		return fmt.Errorf("Len()=%v, expected %d", got, exp)
+func TestList_PrependSlice(t *testing.T) {
+	for _, m := range []int{0, 1, 20, 32, 33, 1000} {
+		for _, n := range []int{0, 1, 12, 13, 32, 33, 1000} {
+			l := NewList[int]()
+			for i := 0; i < m; i++ {
+				l = l.Append(n + i)
+			}
+			repr := l.GoString()
+			values := make([]int, n)
+			for i := range values {
+				values[i] = i
+			}
+
+			got := l.PrependSlice(values)
+			if vs := slices.Collect(listValues(got)); len(vs) != m+n || !slices.IsSorted(vs) || (m+n > 0 && vs[m+n-1] != m+n-1) {
+				t.Fatalf("m=%d n=%d: unexpected list: %v", m, n, vs)
+			}
+			if n == 0 && got != l {
+				t.Fatalf("m=%d: expected the receiver for no values", m)
+			}
+			if n > 0 {
+				if values[0] = -1; got.Get(0) != 0 {
+					t.Fatalf("m=%d n=%d: list aliases values", m, n)
+				}
+			}
+			if got = got.Append(-1).Prepend(-2); got.Get(0) != -2 || got.Get(m+n+1) != -1 {
+				t.Fatalf("m=%d n=%d: unexpected append or prepend", m, n)
+			}
+			if l.GoString() != repr {
+				t.Fatalf("m=%d n=%d: original modified", m, n)
+			}
+
+			b := NewListBuilder[int]()
+			for i := 0; i < m; i++ {
+				b.Append(i)
+			}
+			b.PrependSlice([]int{-1, -2})
+			b.Append(-3)
+			if got := b.List(); got.Len() != m+3 || got.Get(0) != -1 || got.Get(1) != -2 || got.Get(m+2) != -3 {
+				t.Fatalf("m=%d: unexpected builder list: %v", m, got)
+			}
+		}
 	}
+}
 
-	for i := range l.std {
-		if got, exp := l.im.Get(i), l.std[i]; got != exp {
-			return fmt.Errorf("Get(%d)=%v, expected %v", i, got, exp)
-		} else if got, exp := l.builder.Get(i), l.std[i]; got != exp {
-			return fmt.Errorf("Builder.List/Get(%d)=%v, expected %v", i, got, exp)
+func TestList_Concat(t *testing.T) {
+	newList := func(start, n int, prepend bool) *List[int] {
+		l := NewList[int]()
+		for i := 0; i < n; i++ {
+			if prepend {
+				l = l.Prepend(start + n - 1 - i)
+			} else {
+				l = l.Append(start + i)
+			}
 		}
+		return l
 	}
 
-	if err := l.validateForwardIterator("basic", l.im.Iterator()); err != nil {
-		return err
-	} else if err := l.validateBackwardIterator("basic", l.im.Iterator()); err != nil {
-		return err
+	for _, m := range []int{0, 1, 20, 32, 33, 1000} {
+		for _, n := range []int{0, 1, 12, 32, 33, 1000} {
+			for _, prepend := range []bool{false, true} {
+				a, b := newList(0, m, prepend), newList(m, n, !prepend)
+				aRepr, bRepr := a.GoString(), b.GoString()
+
+				got := a.Concat(b)
+				if vs := slices.Collect(listValues(got)); len(vs) != m+n || !slices.IsSorted(vs) || (m+n > 0 && vs[m+n-1] != m+n-1) {
+					t.Fatalf("m=%d n=%d: unexpected list: %v", m, n, vs)
+				}
+				if got = got.Append(-1).Prepend(-2); got.Get(0) != -2 || got.Get(m+n+1) != -1 {
+					t.Fatalf("m=%d n=%d: unexpected append or prepend", m, n)
+				}
+				if a.GoString() != aRepr || b.GoString() != bRepr {
+					t.Fatalf("m=%d n=%d: input modified", m, n)
+				}
+			}
+		}
 	}
 
-	if err := l.validateForwardIterator("builder", l.builder.Iterator()); err != nil {
-		return err
-	} else if err := l.validateBackwardIterator("builder", l.builder.Iterator()); err != nil {
-		return err
+	t.Run("Allocs", func(t *testing.T) {
+		a, b := newList(0, 100000, false), newList(100000, 100000, false)
+		allocs := testing.AllocsPerRun(5, func() { a.Concat(b) })
+		if allocs > 10000 {
+			t.Fatalf("expected leaf-sized allocations, got %v", allocs)
+		}
+		if stats := SharedSize(b, b.Concat(newList(0, 10, false))); stats.SharedNodes == 0 {
+			t.Fatalf("expected the longer list's nodes to be shared: %+v", stats)
+		}
+	})
+}
+
+func TestMapList(t *testing.T) {
+	for _, n := range []int{0, 1, 32, 33, 1000} {
+		l := NewList[int]()
+		for i := n/2 - 1; i >= 0; i-- {
+			l = l.Prepend(i)
+		}
+		for i := n / 2; i < n; i++ {
+			l = l.Append(i)
+		}
+		l = l.Slice(0, n)
+
+		got := MapList(l, strconv.Itoa)
+		want := make([]string, n)
+		for i := range want {
+			want[i] = strconv.Itoa(i)
+		}
+		if vs := slices.Collect(listValues(got)); !slices.Equal(vs, want) {
+			t.Fatalf("n=%d: expected %v, got %v", n, want, vs)
+		}
+		if got = got.Append("x").Prepend("y"); got.Get(0) != "y" || got.Get(n+1) != "x" {
+			t.Fatalf("n=%d: unexpected append or prepend", n)
+		}
+
+		indexed := MapListIndexed(l, func(i, v int) int { return i*1000 + v })
+		for i := 0; i < n; i++ {
+			if v := indexed.Get(i); v != i*1001 {
+				t.Fatalf("n=%d: unexpected value at %d: %d", n, i, v)
+			}
+		}
 	}
-	return nil
+
+	t.Run("Allocs", func(t *testing.T) {
+		l := NewList[int]()
+		for i := 0; i < 10000; i++ {
+			l = l.Append(i)
+		}
+		allocs := testing.AllocsPerRun(5, func() { MapList(l, func(v int) int64 { return int64(v) }) })
+		if allocs > 10000/listNodeSize*2 {
+			t.Fatalf("expected leaf-sized allocations, got %v", allocs)
+		}
+	})
 }
 
-func (l *TList) validateForwardIterator(typ string, itr *ListIterator[int]) error {
-	for i := range l.std {
-		if j, v := itr.Next(); i != j || l.std[i] != v {
-			return fmt.Errorf("ListIterator.Next()=<%v,%v>, expected <%v,%v> [%s]", j, v, i, l.std[i], typ)
+func TestZipLists(t *testing.T) {
+	for _, m := range []int{0, 1, 32, 33, 1000} {
+		for _, n := range []int{0, 5, 33, 1000} {
+			a, b := NewList[int](), NewList[string]()
+			for i := 0; i < m; i++ {
+				a = a.Prepend(m - 1 - i)
+			}
+			for i := 0; i < n; i++ {
+				b = b.Append(strconv.Itoa(i))
+			}
+
+			zipped := ZipLists(a, b)
+			if zipped.Len() != min(m, n) {
+				t.Fatalf("m=%d n=%d: unexpected length %d", m, n, zipped.Len())
+			}
+			for i, p := range slices.Collect(listValues(zipped)) {
+				if p.First != i || p.Second != strconv.Itoa(i) {
+					t.Fatalf("m=%d n=%d: unexpected pair at %d: %+v", m, n, i, p)
+				}
+			}
+
+			firsts, seconds := UnzipList(zipped)
+			if vs := slices.Collect(listValues(firsts)); !slices.Equal(vs, slices.Collect(listValues(a.Take(min(m, n))))) {
+				t.Fatalf("m=%d n=%d: unexpected first values: %v", m, n, vs)
+			}
+			if vs := slices.Collect(listValues(seconds)); !slices.Equal(vs, slices.Collect(listValues(b.Take(min(m, n))))) {
+				t.Fatalf("m=%d n=%d: unexpected second values: %v", m, n, vs)
+			}
+		}
+	}
+}
+
+func TestNewListRepeat(t *testing.T) {
+	for _, n := range []int{-1, 0, 1, 32, 33, 1024, 1025, 40000} {
+		l := NewListRepeat("x", n)
+		if l.Len() != max(n, 0) {
+			t.Fatalf("n=%d: unexpected length %d", n, l.Len())
+		}
+		if vs := slices.Collect(listValues(l)); len(vs) != l.Len() || slices.ContainsFunc(vs, func(v string) bool { return v != "x" }) {
+			t.Fatalf("n=%d: unexpected values", n)
+		}
+		// Updates must copy the shared nodes rather than change every copy.
+		if n > 1 {
+			other := l.Set(n-1, "y").Set(0, "z").Append("a").Prepend("b")
+			if other.Get(n) != "y" || other.Get(1) != "z" || other.Get(2%n+1) == "y" || l.Get(0) != "x" || l.Get(n-1) != "x" {
+				t.Fatalf("n=%d: update changed shared nodes", n)
+			}
+			b := NewListBuilderFrom(l)
+			b.Set(n/2, "y")
+			if b.List().Get(n/2) != "y" || l.Get(n/2) != "x" || (n > 64 && l.Get(n/2+32) != "x") {
+				t.Fatalf("n=%d: builder update changed shared nodes", n)
+			}
+		}
+	}
+
+	t.Run("Allocs", func(t *testing.T) {
+		if allocs := testing.AllocsPerRun(5, func() { NewListRepeat(0, 1<<20) }); allocs > 20 {
+			t.Fatalf("expected shared nodes, got %v allocations", allocs)
 		}
+	})
+}
 
-		done := i == len(l.std)-1
-		if v := itr.Done(); v != done {
-			return fmt.Errorf("ListIterator.Done()=%v, expected %v [%s]", v, done, typ)
+func TestFlattenLists(t *testing.T) {
+	RunRandom(t, "Random", func(t *testing.T, rand *rand.Rand) {
+		outer, want := NewList[*List[int]](), []int{}
+		for i := rand.Intn(50); i > 0; i-- {
+			var inner *List[int]
+			switch rand.Intn(4) {
+			case 0: // nil
+			case 1:
+				inner = NewList[int]()
+				for j := rand.Intn(40); j > 0; j-- {
+					inner = inner.Append(rand.Int())
+				}
+			default:
+				inner = NewList[int]()
+				for j := rand.Intn(500); j > 0; j-- {
+					if rand.Intn(2) == 0 {
+						inner = inner.Append(rand.Int())
+					} else {
+						inner = inner.Prepend(rand.Int())
+					}
+				}
+				if inner.Len() > 10 {
+					inner = inner.Slice(rand.Intn(10), inner.Len())
+				}
+			}
+			if inner != nil {
+				want = append(want, slices.Collect(listValues(inner))...)
+			}
+			outer = outer.Append(inner)
+		}
+
+		got := FlattenLists(outer)
+		if vs := slices.Collect(listValues(got)); !slices.Equal(vs, want) || got.Len() != len(want) {
+			t.Fatalf("expected %d values, got %d", len(want), got.Len())
+		}
+		for i := 0; i < got.Len(); i += 1 + rand.Intn(10) {
+			if got.Get(i) != want[i] {
+				t.Fatalf("unexpected value at %d", i)
+			}
+		}
+		if got = got.Append(-1).Prepend(-2); got.Get(0) != -2 || got.Get(got.Len()-1) != -1 {
+			t.Fatal("unexpected append or prepend")
+		}
+		if i := got.Len() / 2; got.Set(i, -3).Get(i) != -3 {
+			t.Fatal("unexpected set")
+		}
+	})
+
+	t.Run("SharesAlignedLeaves", func(t *testing.T) {
+		a, b := NewList[int](), NewList[int]()
+		for i := 0; i < 1024; i++ {
+			a, b = a.Append(i), b.Append(-i)
+		}
+		aRepr, bRepr := a.GoString(), b.GoString()
+		got := FlattenLists(NewList(a, nil, b))
+		if stats := SharedSize(b, got); stats.SharedNodes < 32 {
+			t.Fatalf("expected the leaves of b to be shared: %+v", stats)
+		}
+		if got.Len() != 2048 || got.Get(1023) != 1023 || got.Get(1025) != -1 {
+			t.Fatalf("unexpected list: %s", got)
+		}
+		if a.GoString() != aRepr || b.GoString() != bRepr {
+			t.Fatal("input modified")
+		}
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		if got := FlattenLists(NewList[*List[int]](nil, NewList[int]())); got.Len() != 0 || got.Append(1).Get(0) != 1 {
+			t.Fatalf("unexpected list: %s", got)
+		}
+	})
+}
+
+func TestReduceList(t *testing.T) {
+	for _, n := range []int{0, 1, 32, 33, 1000, 40000} {
+		l := NewList[int]()
+		for i := n/2 - 1; i >= 0; i-- {
+			l = l.Prepend(i)
+		}
+		for i := n / 2; i < n; i++ {
+			l = l.Append(i)
+		}
+
+		if sum := ReduceList(l, 0, func(acc, v int) int { return acc + v }); sum != n*(n-1)/2 {
+			t.Fatalf("n=%d: unexpected sum %d", n, sum)
+		}
+		want := slices.Collect(listValues(l))
+		left := ReduceList(l, []int{}, func(acc []int, v int) []int { return append(acc, v) })
+		if !slices.Equal(left, want) {
+			t.Fatalf("n=%d: unexpected left fold: %v", n, left)
+		}
+		slices.Reverse(want)
+		right := ReduceListRight(l, []int{}, func(acc []int, v int) []int { return append(acc, v) })
+		if !slices.Equal(right, want) {
+			t.Fatalf("n=%d: unexpected right fold: %v", n, right)
 		}
 	}
-	if i, v := itr.Next(); i != -1 || v != 0 {
-		return fmt.Errorf("ListIterator.Next()=<%v,%v>, expected DONE [%s]", i, v, typ)
+
+	t.Run("Sliced", func(t *testing.T) {
+		l := NewList[int]()
+		for i := 0; i < 5000; i++ {
+			l = l.Append(i)
+		}
+		for _, r := range [][2]int{{0, 1}, {31, 33}, {100, 1100}, {4990, 5000}} {
+			s := l.Slice(r[0], r[1])
+			got := ReduceListRight(s, "", func(acc string, v int) string { return acc + strconv.Itoa(v) + "," })
+			var want string
+			for i := r[1] - 1; i >= r[0]; i-- {
+				want += strconv.Itoa(i) + ","
+			}
+			if got != want {
+				t.Fatalf("slice %v: expected %q, got %q", r, want, got)
+			}
+		}
+	})
+}
+
+func TestList_Unique(t *testing.T) {
+	for _, n := range []int{0, 10, 33, 1000} {
+		l := NewList[int]()
+		for i := 0; i < n; i++ {
+			l = l.Append((i * 7) % 37)
+		}
+		var want []int
+		for _, v := range slices.Collect(listValues(l)) {
+			if !slices.Contains(want, v) {
+				want = append(want, v)
+			}
+		}
+		got := l.Unique()
+		if vs := slices.Collect(listValues(got)); !slices.Equal(vs, want) {
+			t.Fatalf("n=%d: expected %v, got %v", n, want, vs)
+		}
+		if len(want) == n && got != l {
+			t.Fatalf("n=%d: expected the receiver without repeats", n)
+		}
+		if got.Unique() != got {
+			t.Fatalf("n=%d: expected the receiver for a unique list", n)
+		}
 	}
-	return nil
+
+	t.Run("NonComparable", func(t *testing.T) {
+		l := NewList[any](1, []int{1}, "a", nil, []int{1}, 1, []int{2}, nil)
+		want := []any{1, []int{1}, "a", nil, []int{2}}
+		if vs := slices.Collect(listValues(l.Unique())); !reflect.DeepEqual(vs, want) {
+			t.Fatalf("expected %v, got %v", want, vs)
+		}
+	})
+
+	t.Run("Func", func(t *testing.T) {
+		l := NewList([]string{"a", "x"}, []string{"b"}, []string{"a", "y"})
+		got := UniqueListFunc(l, func(v []string) string { return v[0] })
+		if vs := slices.Collect(listValues(got)); len(vs) != 2 || vs[0][1] != "x" || vs[1][0] != "b" {
+			t.Fatalf("unexpected list: %v", vs)
+		}
+		if UniqueListFunc(got, func(v []string) string { return v[0] }) != got {
+			t.Fatal("expected the receiver without repeats")
+		}
+	})
 }
 
-func (l *TList) validateBackwardIterator(typ string, itr *ListIterator[int]) error {
-	itr.Last()
-	for i := len(l.std) - 1; i >= 0; i-- {
-		if j, v := itr.Prev(); i != j || l.std[i] != v {
-			return fmt.Errorf("ListIterator.Prev()=<%v,%v>, expected <%v,%v> [%s]", j, v, i, l.std[i], typ)
+func TestList_Compact(t *testing.T) {
+	RunRandom(t, "Random", func(t *testing.T, rand *rand.Rand) {
+		// Runs of varying length, so some cross leaf boundaries.
+		var values []int
+		for n := rand.Intn(3000); len(values) < n; {
+			v := rand.Intn(5)
+			for i := rand.Intn(70); i >= 0; i-- {
+				values = append(values, v)
+			}
+		}
+		l := NewList[int]()
+		for i := len(values)/2 - 1; i >= 0; i-- {
+			l = l.Prepend(values[i])
+		}
+		for _, v := range values[len(values)/2:] {
+			l = l.Append(v)
 		}
 
-		done := i == 0
-		if v := itr.Done(); v != done {
-			return fmt.Errorf("ListIterator.Done()=%v, expected %v [%s]", v, done, typ)
+		want := slices.Compact(slices.Clone(values))
+		got := l.Compact()
+		if vs := slices.Collect(listValues(got)); !slices.Equal(vs, want) {
+			t.Fatalf("expected %v, got %v", want, vs)
+		}
+		if got.Compact() != got {
+			t.Fatal("expected the receiver without adjacent duplicates")
+		}
+		if vs := slices.Collect(listValues(l)); !slices.Equal(vs, values) {
+			t.Fatal("original modified")
+		}
+	})
+
+	t.Run("Func", func(t *testing.T) {
+		l := NewList(1, 2, 3, 10, 11, 25, 4)
+		near := func(a, b int) bool { return b-a < 2 && a-b < 2 }
+		// Values are compared with the first of their run, as in slices.CompactFunc.
+		if got := slices.Collect(listValues(l.CompactFunc(near))); !slices.Equal(got, []int{1, 3, 10, 25, 4}) {
+			t.Fatalf("unexpected list: %v", got)
+		}
+		ls := NewList([]int{1}, []int{1}, []int{2})
+		if got := ls.Compact(); got.Len() != 2 {
+			t.Fatalf("unexpected list of slices: %v", got)
+		}
+	})
+}
+
+func TestList_RangeParallel(t *testing.T) {
+	for _, n := range []int{0, 10, 1000, 100000} {
+		// Prepend half the values so the trie has an origin.
+		l := NewList[int]()
+		for i := n/2 - 1; i >= 0; i-- {
+			l = l.Prepend(i)
+		}
+		for i := n / 2; i < n; i++ {
+			l = l.Append(i)
+		}
+		for _, workers := range []int{0, 1, 3, 8} {
+			seen := make([]atomic.Int32, n)
+			l.RangeParallel(workers, func(index, value int) {
+				if index != value {
+					t.Errorf("n=%d: index %d has value %d", n, index, value)
+				}
+				seen[index].Add(1)
+			})
+			for i := range seen {
+				if c := seen[i].Load(); c != 1 {
+					t.Fatalf("n=%d workers=%d: index %d visited %d times", n, workers, i, c)
+				}
+			}
 		}
 	}
-	if i, v := itr.Prev(); i != -1 || v != 0 {
-		return fmt.Errorf("ListIterator.Prev()=<%v,%v>, expected DONE [%s]", i, v, typ)
+}
+
+func TestNewListFromSliceParallel(t *testing.T) {
+	for _, n := range []int{0, 1, 32, 33, 1024, 1025, 32768, 32769, 100000, 1 << 20} {
+		values := make([]int, n)
+		for i := range values {
+			values[i] = i
+		}
+		want := NewList(values...)
+		for _, workers := range []int{0, 1, 4, 8} {
+			l := NewListFromSliceParallel(values, workers)
+			if !DeepEqual(l, want) {
+				t.Fatalf("n=%d workers=%d: lists differ", n, workers)
+			} else if l.GoString() != want.GoString() {
+				t.Fatalf("n=%d workers=%d: unexpected structure %#v, want %#v", n, workers, l, want)
+			}
+			// The list must remain usable at both ends.
+			if n > 0 {
+				if other := l.Append(-1).Prepend(-2); other.Get(0) != -2 || other.Get(n+1) != -1 || other.Get(n) != n-1 {
+					t.Fatalf("n=%d workers=%d: unexpected list after update", n, workers)
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkNewListFromSliceParallel(b *testing.B) {
+	values := make([]int, 10_000_000)
+	for i := range values {
+		values[i] = i
+	}
+	b.Run("Sequential", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			NewListFromSliceParallel(values, 1)
+		}
+	})
+	for _, workers := range []int{4, 8} {
+		b.Run(fmt.Sprintf("Workers%d", workers), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				NewListFromSliceParallel(values, workers)
+			}
+		})
 	}
-	return nil
 }
 
 func BenchmarkList_Append(b *testing.B) {
@@ -485,6 +2683,28 @@ func BenchmarkList_Append(b *testing.B) {
 	}
 }
 
+func BenchmarkList_AppendSlice(b *testing.B) {
+	values := make([]int, 100000)
+	for i := range values {
+		values[i] = i
+	}
+	b.Run("Loop", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			l := NewList[int]()
+			for _, v := range values {
+				l = l.Append(v)
+			}
+		}
+	})
+	b.Run("AppendSlice", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			NewList[int]().AppendSlice(values)
+		}
+	})
+}
+
 func BenchmarkList_Prepend(b *testing.B) {
 	b.ReportAllocs()
 	l := NewList[int]()
@@ -493,6 +2713,47 @@ func BenchmarkList_Prepend(b *testing.B) {
 	}
 }
 
+func BenchmarkList_PrependSlice(b *testing.B) {
+	values := make([]int, 100000)
+	for i := range values {
+		values[i] = i
+	}
+	b.Run("Loop", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			l := NewList[int]()
+			for j := len(values) - 1; j >= 0; j-- {
+				l = l.Prepend(values[j])
+			}
+		}
+	})
+	b.Run("PrependSlice", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			NewList[int]().PrependSlice(values)
+		}
+	})
+}
+
+func BenchmarkList_Contains(b *testing.B) {
+	l := NewList[int]()
+	for i := 0; i < 500000; i++ {
+		l = l.Append(i * 1000)
+	}
+	b.Run("Method", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			l.Contains(-1)
+		}
+	})
+	b.Run("ListContains", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			ListContains(l, -1)
+		}
+	})
+}
+
 func BenchmarkList_Set(b *testing.B) {
 	const n = 10000
 
@@ -536,6 +2797,26 @@ func BenchmarkList_Iterator(b *testing.B) {
 			itr.Prev()
 		}
 	})
+
+	b.Run("All", func(b *testing.B) {
+		for i := 0; i < b.N; {
+			for range l.All() {
+				if i++; i >= b.N {
+					break
+				}
+			}
+		}
+	})
+
+	b.Run("Backward", func(b *testing.B) {
+		for i := 0; i < b.N; {
+			for range l.Backward() {
+				if i++; i >= b.N {
+					break
+				}
+			}
+		}
+	})
 }
 
 func BenchmarkBuiltinSlice_Append(b *testing.B) {
@@ -746,7 +3027,7 @@ func TestInternal_mapNode_Overwrite(t *testing.T) {
 	var node mapNode[int, int] = &mapArrayNode[int, int]{}
 	for i := 0; i < n; i++ {
 		var resized bool
-		node = node.set(i, i, 0, h.Hash(i), &h, false, &resized)
+		node = node.set(i, i, 0, h.Hash(i), &h, nil, &resized)
 		if !resized {
 			t.Fatal("expected resize")
 		}
@@ -754,7 +3035,7 @@ func TestInternal_mapNode_Overwrite(t *testing.T) {
 		// Overwrite every node.
 		for j := 0; j <= i; j++ {
 			var resized bool
-			node = node.set(j, i*j, 0, h.Hash(j), &h, false, &resized)
+			node = node.set(j, i*j, 0, h.Hash(j), &h, nil, &resized)
 			if resized {
 				t.Fatalf("expected no resize: i=%d, j=%d", i, j)
 			}
@@ -781,7 +3062,7 @@ func TestInternal_mapArrayNode(t *testing.T) {
 		n := &mapArrayNode[int, int]{}
 		for i := 0; i < 8; i++ {
 			var resized bool
-			n = n.set(i*10, i, 0, h.Hash(i*10), &h, false, &resized).(*mapArrayNode[int, int])
+			n = n.set(i*10, i, 0, h.Hash(i*10), &h, nil, &resized).(*mapArrayNode[int, int])
 			if !resized {
 				t.Fatal("expected resize")
 			}
@@ -800,7 +3081,7 @@ func TestInternal_mapArrayNode(t *testing.T) {
 		n := &mapArrayNode[int, int]{}
 		for i := 7; i >= 0; i-- {
 			var resized bool
-			n = n.set(i*10, i, 0, h.Hash(i*10), &h, false, &resized).(*mapArrayNode[int, int])
+			n = n.set(i*10, i, 0, h.Hash(i*10), &h, nil, &resized).(*mapArrayNode[int, int])
 			if !resized {
 				t.Fatal("expected resize")
 			}
@@ -819,7 +3100,7 @@ func TestInternal_mapArrayNode(t *testing.T) {
 		var n mapNode[int, int] = &mapArrayNode[int, int]{}
 		for i := 0; i < 100; i++ {
 			var resized bool
-			n = n.set(i, i, 0, h.Hash(i), &h, false, &resized)
+			n = n.set(i, i, 0, h.Hash(i), &h, nil, &resized)
 			if !resized {
 				t.Fatal("expected resize")
 			}
@@ -838,12 +3119,12 @@ func TestInternal_mapArrayNode(t *testing.T) {
 		var n mapNode[int, int] = &mapArrayNode[int, int]{}
 		for i := 0; i < 8; i++ {
 			var resized bool
-			n = n.set(i*10, i, 0, h.Hash(i*10), &h, false, &resized)
+			n = n.set(i*10, i, 0, h.Hash(i*10), &h, nil, &resized)
 		}
 
 		for _, i := range rand.Perm(8) {
 			var resized bool
-			n = n.delete(i*10, 0, h.Hash(i*10), &h, false, &resized)
+			n = n.delete(i*10, 0, h.Hash(i*10), &h, nil, &resized)
 		}
 		if n != nil {
 			t.Fatal("expected nil rand")
@@ -854,7 +3135,7 @@ func TestInternal_mapArrayNode(t *testing.T) {
 func TestInternal_mapValueNode(t *testing.T) {
 	t.Run("Simple", func(t *testing.T) {
 		var h defaultHasher[int]
-		n := newMapValueNode(h.Hash(2), 2, 3)
+		n := newMapValueNode(h.Hash(2), 2, 3, nil)
 		if v, ok := n.get(2, 0, h.Hash(2), &h); !ok {
 			t.Fatal("expected ok")
 		} else if v != 3 {
@@ -865,8 +3146,8 @@ func TestInternal_mapValueNode(t *testing.T) {
 	t.Run("KeyEqual", func(t *testing.T) {
 		var h defaultHasher[int]
 		var resized bool
-		n := newMapValueNode(h.Hash(2), 2, 3)
-		other := n.set(2, 4, 0, h.Hash(2), &h, false, &resized).(*mapValueNode[int, int])
+		n := newMapValueNode(h.Hash(2), 2, 3, nil)
+		other := n.set(2, 4, 0, h.Hash(2), &h, nil, &resized).(*mapValueNode[int, int])
 		if other == n {
 			t.Fatal("expected new node")
 		} else if got, exp := other.keyHash, h.Hash(2); got != exp {
@@ -886,8 +3167,8 @@ func TestInternal_mapValueNode(t *testing.T) {
 			equal: func(a, b int) bool { return a == b },
 		}
 		var resized bool
-		n := newMapValueNode(h.Hash(2), 2, 3)
-		other := n.set(4, 5, 0, h.Hash(4), h, false, &resized).(*mapHashCollisionNode[int, int])
+		n := newMapValueNode(h.Hash(2), 2, 3, nil)
+		other := n.set(4, 5, 0, h.Hash(4), h, nil, &resized).(*mapHashCollisionNode[int, int])
 		if got, exp := other.keyHash, h.Hash(2); got != exp {
 			t.Fatalf("keyHash=%v, expected %v", got, exp)
 		} else if got, exp := len(other.entries), 2; got != exp {
@@ -912,8 +3193,8 @@ func TestInternal_mapValueNode(t *testing.T) {
 		t.Run("NoConflict", func(t *testing.T) {
 			var h defaultHasher[int]
 			var resized bool
-			n := newMapValueNode(h.Hash(2), 2, 3)
-			other := n.set(4, 5, 0, h.Hash(4), &h, false, &resized).(*mapBitmapIndexedNode[int, int])
+			n := newMapValueNode(h.Hash(2), 2, 3, nil)
+			other := n.set(4, 5, 0, h.Hash(4), &h, nil, &resized).(*mapBitmapIndexedNode[int, int])
 			if got, exp := other.bitmap, uint32(0x14); got != exp {
 				t.Fatalf("bitmap=0x%02x, expected 0x%02x", got, exp)
 			} else if got, exp := len(other.nodes), 2; got != exp {
@@ -948,8 +3229,8 @@ func TestInternal_mapValueNode(t *testing.T) {
 		t.Run("NoConflictReverse", func(t *testing.T) {
 			var h defaultHasher[int]
 			var resized bool
-			n := newMapValueNode(h.Hash(4), 4, 5)
-			other := n.set(2, 3, 0, h.Hash(2), &h, false, &resized).(*mapBitmapIndexedNode[int, int])
+			n := newMapValueNode(h.Hash(4), 4, 5, nil)
+			other := n.set(2, 3, 0, h.Hash(2), &h, nil, &resized).(*mapBitmapIndexedNode[int, int])
 			if got, exp := other.bitmap, uint32(0x14); got != exp {
 				t.Fatalf("bitmap=0x%02x, expected 0x%02x", got, exp)
 			} else if got, exp := len(other.nodes), 2; got != exp {
@@ -987,8 +3268,8 @@ func TestInternal_mapValueNode(t *testing.T) {
 				equal: func(a, b int) bool { return a == b },
 			}
 			var resized bool
-			n := newMapValueNode(h.Hash(2), 2, 3)
-			other := n.set(4, 5, 0, h.Hash(4), h, false, &resized).(*mapBitmapIndexedNode[int, int])
+			n := newMapValueNode(h.Hash(2), 2, 3, nil)
+			other := n.set(4, 5, 0, h.Hash(4), h, nil, &resized).(*mapBitmapIndexedNode[int, int])
 			if got, exp := other.bitmap, uint32(0x01); got != exp { // mask is zero, expect first slot.
 				t.Fatalf("bitmap=0x%02x, expected 0x%02x", got, exp)
 			} else if got, exp := len(other.nodes), 1; got != exp {
@@ -1157,6 +3438,125 @@ func TestMap_Set(t *testing.T) {
 			t.Fatal(err)
 		}
 	})
+
+	t.Run("BuilderTakeAndReset", func(t *testing.T) {
+		h := mockHasher[int]{
+			hash:  func(value int) uint32 { return 0 }, // force collisions
+			equal: func(a, b int) bool { return a == b },
+		}
+		b := NewMapBuilder[int, int](&h)
+		b.Set(1, 1)
+		first := b.TakeAndReset()
+
+		b.Set(2, 2) // builder is valid again
+		second := b.Map()
+
+		if v, ok := first.Get(1); !ok || v != 1 || first.Len() != 1 {
+			t.Fatalf("unexpected first map: Get(1)=<%v,%v> len=%d", v, ok, first.Len())
+		}
+		if v, ok := second.Get(2); !ok || v != 2 || second.Len() != 1 {
+			t.Fatalf("unexpected second map: Get(2)=<%v,%v> len=%d", v, ok, second.Len())
+		}
+		if second.hasher != &h {
+			t.Fatal("expected hasher to be retained across reset")
+		}
+	})
+
+	t.Run("BuilderSnapshot", func(t *testing.T) {
+		b := NewMapBuilder[int, int](nil)
+		for i := 0; i < 1000; i++ {
+			b.Set(i, i)
+		}
+		snap := b.Snapshot()
+
+		for i := 0; i < 1000; i++ {
+			if i%2 == 0 {
+				b.Set(i, -i)
+			} else {
+				b.Delete(i)
+			}
+		}
+		b.Set(1000, 1000)
+
+		// Nodes copied after the snapshot are owned by the builder again.
+		root := b.m.root
+		b.Set(0, 0)
+		if b.m.root != root {
+			t.Fatal("expected Set after snapshot copy to update in place")
+		}
+
+		m, err := b.TryMap()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if snap.Len() != 1000 {
+			t.Fatalf("unexpected snapshot len: %d", snap.Len())
+		}
+		for i := 0; i < 1000; i++ {
+			if v, ok := snap.Get(i); !ok || v != i {
+				t.Fatalf("snapshot Get(%d)=<%v,%v>", i, v, ok)
+			}
+		}
+		if m.Len() != 501 {
+			t.Fatalf("unexpected map len: %d", m.Len())
+		}
+		if v, ok := m.Get(2); !ok || v != -2 {
+			t.Fatalf("Get(2)=<%v,%v>", v, ok)
+		}
+
+		if _, err := b.TryMap(); err != ErrBuilderFinalized {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("BuilderIterator", func(t *testing.T) {
+		b := NewMapBuilder[int, int](nil)
+		for i := 0; i < 1000; i++ {
+			b.Set(i, i)
+		}
+
+		// Mutate the builder while iterating.
+		got, model := make(map[int]int), make(map[int]int)
+		for i := 0; i < 1000; i++ {
+			model[i] = i
+		}
+		for itr := b.Iterator(); !itr.Done(); {
+			k, v, _ := itr.Next()
+			got[k] = v
+			b.Set(1000+len(got), 0)
+			b.Set(k, -k)
+			b.Delete((k + 1) % 1000)
+			model[1000+len(got)], model[k] = 0, -k
+			delete(model, (k+1)%1000)
+		}
+		if len(got) != 1000 {
+			t.Fatalf("unexpected iterator len: %d", len(got))
+		}
+		for k, v := range got {
+			if v != k {
+				t.Fatalf("iterator value %d=%v", k, v)
+			}
+		}
+
+		// The builder owns the nodes it copied while iterating.
+		root := b.m.root
+		b.Set(1000, 0)
+		if b.m.root != root {
+			t.Fatal("expected Set after iteration to update in place")
+		}
+		model[1000] = 0
+
+		m := b.Map()
+		if m.Len() != len(model) {
+			t.Fatalf("unexpected map len: %d", m.Len())
+		}
+		for k, want := range model {
+			if v, ok := m.Get(k); !ok || v != want {
+				t.Fatalf("Get(%d)=<%v,%v>", k, v, ok)
+			}
+		}
+	})
 }
 
 // Ensure map can support overwrites as it expands.
@@ -1272,6 +3672,149 @@ func TestMap_Delete(t *testing.T) {
 }
 
 // Ensure map works even with hash conflicts.
+func TestMap_DeleteFunc(t *testing.T) {
+	RunRandom(t, "Model", func(t *testing.T, rand *rand.Rand) {
+		// A small hash range mixes value, collision and bitmap nodes.
+		var h Hasher[int]
+		if rand.Intn(2) == 0 {
+			h = &mockHasher[int]{
+				hash:  func(k int) uint32 { return uint32(k % 500) },
+				equal: func(a, b int) bool { return a == b },
+			}
+		}
+		m := NewMap[int, int](h)
+		model := make(map[int]int)
+		for i := rand.Intn(5000); i > 0; i-- {
+			k := rand.Intn(10000)
+			m, model[k] = m.Set(k, -k), -k
+		}
+		mod := 1 + rand.Intn(20)
+		pred := func(k, _ int) bool { return k%mod == 0 }
+
+		got := m.DeleteFunc(pred)
+		maps.DeleteFunc(model, pred)
+		if got.Len() != len(model) {
+			t.Fatalf("expected %d entries, got %d", len(model), got.Len())
+		}
+		for k, v := range model {
+			if gv, ok := got.Get(k); !ok || gv != v {
+				t.Fatalf("Get(%d)=<%d,%v>, expected %d", k, gv, ok, v)
+			}
+		}
+		for itr := got.Iterator(); !itr.Done(); {
+			if k, _, _ := itr.Next(); pred(k, 0) {
+				t.Fatalf("unexpected key %d", k)
+			}
+		}
+		// The result must remain usable.
+		for k := range model {
+			got = got.Delete(k)
+		}
+		if got.Len() != 0 {
+			t.Fatalf("unexpected length after deleting the rest: %d", got.Len())
+		}
+	})
+
+	t.Run("NoOp", func(t *testing.T) {
+		m := NewMap[int, int](nil)
+		if m.DeleteFunc(func(int, int) bool { return true }) != m {
+			t.Fatal("expected empty map to be returned")
+		}
+		for i := 0; i < 1000; i++ {
+			m = m.Set(i, i)
+		}
+		if m.DeleteFunc(func(k, _ int) bool { return k < 0 }) != m {
+			t.Fatal("expected map to be returned when nothing matches")
+		}
+		if other := m.DeleteFunc(func(int, int) bool { return true }); other.Len() != 0 || other.root != nil {
+			t.Fatalf("expected all entries to be removed, got %d", other.Len())
+		}
+	})
+
+	t.Run("SharedSubtrees", func(t *testing.T) {
+		m := NewMap[int, int](nil)
+		for i := 0; i < 100000; i++ {
+			m = m.Set(i, i)
+		}
+		other := m.DeleteFunc(func(k, _ int) bool { return k == 12345 })
+		if other.Len() != m.Len()-1 {
+			t.Fatalf("unexpected length %d", other.Len())
+		}
+		a, b := m.root.(*mapHashArrayNode[int, int]), other.root.(*mapHashArrayNode[int, int])
+		var changed int
+		for i := range a.nodes {
+			if a.nodes[i] != b.nodes[i] {
+				changed++
+			}
+		}
+		if changed != 1 {
+			t.Fatalf("expected one top-level subtree to change, got %d", changed)
+		}
+	})
+}
+
+func TestMap_RangeParallel(t *testing.T) {
+	for _, n := range []int{0, 5, 1000, 100000} {
+		m := NewMap[int, int](nil)
+		for i := 0; i < n; i++ {
+			m = m.Set(i, i*2)
+		}
+		for _, workers := range []int{0, 1, 3, 8} {
+			seen := make([]atomic.Int32, n)
+			var sum atomic.Int64
+			m.RangeParallel(workers, func(key, value int) {
+				seen[key].Add(1)
+				sum.Add(int64(value))
+			})
+			for i := range seen {
+				if c := seen[i].Load(); c != 1 {
+					t.Fatalf("n=%d workers=%d: key %d visited %d times", n, workers, i, c)
+				}
+			}
+			if want := int64(n) * int64(n-1); sum.Load() != want {
+				t.Fatalf("n=%d workers=%d: expected sum %d, got %d", n, workers, want, sum.Load())
+			}
+		}
+	}
+}
+
+func TestNewMapOfParallel(t *testing.T) {
+	limited := &mockHasher[int]{
+		hash:  func(value int) uint32 { return hashUint64(uint64(value)) % 0x3FF },
+		equal: func(a, b int) bool { return a == b },
+	}
+	for _, hasher := range []Hasher[int]{nil, limited} {
+		for _, n := range []int{0, 100, 5000, 100000} {
+			// Repeat keys so that the last entry for each must win.
+			rand := rand.New(rand.NewSource(int64(n)))
+			entries := make([]MapEntry[int, int], n)
+			want := NewMap[int, int](hasher)
+			for i := range entries {
+				entries[i] = MapEntry[int, int]{Key: rand.Intn(n/2 + 1), Value: i}
+				want = want.Set(entries[i].Key, i)
+			}
+
+			for _, workers := range []int{0, 1, 4, 8} {
+				m := NewMapOfParallel(hasher, entries, workers)
+				if m.Len() != want.Len() {
+					t.Fatalf("n=%d workers=%d: expected len %d, got %d", n, workers, want.Len(), m.Len())
+				} else if !DeepEqual(m, want) {
+					t.Fatalf("n=%d workers=%d: maps differ", n, workers)
+				}
+
+				// The map must remain usable for updates.
+				other := m
+				for _, e := range entries {
+					other = other.Delete(e.Key)
+				}
+				if other.Len() != 0 {
+					t.Fatalf("n=%d workers=%d: expected empty map after deletes, got %d", n, workers, other.Len())
+				}
+			}
+		}
+	}
+}
+
 func TestMap_LimitedHash(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping: short")
@@ -1450,9 +3993,10 @@ func (m *TMap) Validate() error {
 			return fmt.Errorf("builder key (%d) mismatch: immutable=%d, std=%d", k, v, m.std[k])
 		}
 	}
+	// Iterate the builder's map directly, as in TList.Validate.
 	if err := m.validateIterator(m.im.Iterator()); err != nil {
 		return fmt.Errorf("basic: %s", err)
-	} else if err := m.validateIterator(m.builder.Iterator()); err != nil {
+	} else if err := m.validateIterator(m.builder.m.Iterator()); err != nil {
 		return fmt.Errorf("builder: %s", err)
 	}
 	return nil
@@ -1543,6 +4087,23 @@ func BenchmarkMap_Iterator(b *testing.B) {
 			itr.Next()
 		}
 	})
+
+	b.Run("String", func(b *testing.B) {
+		m := NewMap[string, string](nil)
+		for _, k := range mapTestStringKeys(n) {
+			m = m.Set(k, k)
+		}
+		b.ReportAllocs()
+		b.ResetTimer()
+
+		itr := m.Iterator()
+		for i := 0; i < b.N; i++ {
+			if i%n == 0 {
+				itr.First()
+			}
+			itr.Next()
+		}
+	})
 }
 
 func BenchmarkMapBuilder_Set(b *testing.B) {
@@ -1677,7 +4238,7 @@ func TestInternalSortedMapLeafNode(t *testing.T) {
 		for _, i := range rand.Perm(32) {
 			var resized bool
 			var splitNode sortedMapNode[int, int]
-			node, splitNode = node.set(i, i*10, &cmpr, false, &resized)
+			node, splitNode = node.set(i, i*10, &cmpr, nil, &resized)
 			if !resized {
 				t.Fatal("expected resize")
 			} else if splitNode != nil {
@@ -1711,11 +4272,11 @@ func TestInternalSortedMapLeafNode(t *testing.T) {
 
 		for _, i := range rand.Perm(32) {
 			var resized bool
-			node, _ = node.set(i, i*2, &cmpr, false, &resized)
+			node, _ = node.set(i, i*2, &cmpr, nil, &resized)
 		}
 		for _, i := range rand.Perm(32) {
 			var resized bool
-			node, _ = node.set(i, i*3, &cmpr, false, &resized)
+			node, _ = node.set(i, i*3, &cmpr, nil, &resized)
 			if resized {
 				t.Fatal("expected no resize")
 			}
@@ -1735,12 +4296,12 @@ func TestInternalSortedMapLeafNode(t *testing.T) {
 		var node sortedMapNode[int, int] = &sortedMapLeafNode[int, int]{}
 		for i := 0; i < 32; i++ {
 			var resized bool
-			node, _ = node.set(i, i*10, &cmpr, false, &resized)
+			node, _ = node.set(i, i*10, &cmpr, nil, &resized)
 		}
 
 		// Add one more and expect split.
 		var resized bool
-		newNode, splitNode := node.set(32, 320, &cmpr, false, &resized)
+		newNode, splitNode := node.set(32, 320, &cmpr, nil, &resized)
 
 		// Verify node contents.
 		newLeafNode, ok := newNode.(*sortedMapLeafNode[int, int])
@@ -1783,7 +4344,7 @@ func TestInternalSortedMapBranchNode(t *testing.T) {
 		var cmpr defaultComparer[int]
 		leaf0 := &sortedMapLeafNode[int, int]{entries: []mapEntry[int, int]{{key: keys[0], value: keys[0] * 10}}}
 		leaf1 := &sortedMapLeafNode[int, int]{entries: []mapEntry[int, int]{{key: keys[1], value: keys[1] * 10}}}
-		var node sortedMapNode[int, int] = newSortedMapBranchNode[int, int](leaf0, leaf1)
+		var node sortedMapNode[int, int] = newSortedMapBranchNode[int, int](nil, leaf0, leaf1)
 
 		sort.Ints(keys)
 		for _, i := range rand.Perm(len(keys)) {
@@ -1791,7 +4352,7 @@ func TestInternalSortedMapBranchNode(t *testing.T) {
 
 			var resized bool
 			var splitNode sortedMapNode[int, int]
-			node, splitNode = node.set(key, key*10, &cmpr, false, &resized)
+			node, splitNode = node.set(key, key*10, &cmpr, nil, &resized)
 			if key == leaf0.entries[0].key || key == leaf1.entries[0].key {
 				if resized {
 					t.Fatalf("expected no resize: key=%d", key)
@@ -1830,11 +4391,11 @@ func TestInternalSortedMapBranchNode(t *testing.T) {
 			}
 			children[i] = leaf
 		}
-		var node sortedMapNode[int, int] = newSortedMapBranchNode(children...)
+		var node sortedMapNode[int, int] = newSortedMapBranchNode(nil, children...)
 
 		// Add one more and expect split.
 		var resized bool
-		newNode, splitNode := node.set((32 * 32), (32*32)*100, &cmpr, false, &resized)
+		newNode, splitNode := node.set((32 * 32), (32*32)*100, &cmpr, nil, &resized)
 
 		// Verify node contents.
 		var idx int
@@ -1968,32 +4529,241 @@ func TestSortedMap_Set(t *testing.T) {
 				m.Set(m.NewKey(rand), rand.Intn(10000))
 			}
 		}
-		if err := m.Validate(); err != nil {
-			t.Fatal(err)
+		if err := m.Validate(); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("BuilderSnapshot", func(t *testing.T) {
+		b := NewSortedMapBuilder[int, int](nil)
+		for i := 0; i < 1000; i++ {
+			b.Set(i, i)
+		}
+		snap := b.Snapshot()
+
+		for i := 0; i < 1000; i++ {
+			if i%2 == 0 {
+				b.Set(i, -i)
+			} else {
+				b.Delete(i)
+			}
+		}
+
+		// Nodes copied after the snapshot are owned by the builder again.
+		root := b.m.root
+		b.Set(0, 0)
+		if b.m.root != root {
+			t.Fatal("expected Set after snapshot copy to update in place")
+		}
+
+		m, err := b.TryMap()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if snap.Len() != 1000 {
+			t.Fatalf("unexpected snapshot len: %d", snap.Len())
+		}
+		for i := 0; i < 1000; i++ {
+			if v, ok := snap.Get(i); !ok || v != i {
+				t.Fatalf("snapshot Get(%d)=<%v,%v>", i, v, ok)
+			}
+		}
+		if m.Len() != 500 {
+			t.Fatalf("unexpected map len: %d", m.Len())
+		}
+
+		if _, err := b.TryMap(); err != ErrBuilderFinalized {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	// Mix appends past the maximum key with inserts, updates and deletes
+	// elsewhere, checking against a map built by inserting in random order.
+	RunRandom(t, "AppendMixed", func(t *testing.T, rand *rand.Rand) {
+		m := NewSortedMap[int, int](nil)
+		b := NewSortedMapBuilder[int, int](nil)
+		model := make(map[int]int)
+		var versions []*SortedMap[int, int]
+		var models []map[int]int
+		max := 0
+		for i := 0; i < 3000; i++ {
+			var k int
+			switch rand.Intn(10) {
+			case 0:
+				k = rand.Intn(max + 1)
+				m = m.Delete(k)
+				b.Delete(k)
+				delete(model, k)
+				continue
+			case 1, 2:
+				k = rand.Intn(max + 1)
+			default:
+				max += 1 + rand.Intn(3)
+				k = max
+			}
+			m = m.Set(k, i)
+			b.Set(k, i)
+			model[k] = i
+			if i%500 == 0 {
+				versions, models = append(versions, m), append(models, maps.Clone(model))
+			}
+		}
+
+		keys := slices.Sorted(maps.Keys(model))
+		want := NewSortedMap[int, int](nil)
+		for _, i := range rand.Perm(len(keys)) {
+			want = want.Set(keys[i], model[keys[i]])
+		}
+		for name, got := range map[string]*SortedMap[int, int]{"Set": m, "Builder": b.Map()} {
+			if !DeepEqual(got, want) {
+				t.Fatalf("%s: unexpected map", name)
+			}
+			var gotKeys []int
+			for itr := got.Iterator(); !itr.Done(); {
+				k, _, _ := itr.Next()
+				gotKeys = append(gotKeys, k)
+			}
+			if !slices.Equal(gotKeys, keys) {
+				t.Fatalf("%s: unexpected keys", name)
+			}
+		}
+		// Earlier versions must be unaffected by later appends.
+		for i, v := range versions {
+			if v.Len() != len(models[i]) {
+				t.Fatalf("version %d: changed length %d, want %d", i, v.Len(), len(models[i]))
+			}
+			for k, want := range models[i] {
+				if got, ok := v.Get(k); !ok || got != want {
+					t.Fatalf("version %d: Get(%d)=<%v,%v>, want %v", i, k, got, ok, want)
+				}
+			}
+		}
+	})
+
+	t.Run("BuilderIterator", func(t *testing.T) {
+		b := NewSortedMapBuilder[int, int](nil)
+		for i := 0; i < 1000; i++ {
+			b.Set(i, i)
+		}
+
+		// Mutate the builder while iterating.
+		var n int
+		for itr := b.Iterator(); !itr.Done(); n++ {
+			k, v, _ := itr.Next()
+			if k != n || v != n {
+				t.Fatalf("iterator entry %d=<%v,%v>", n, k, v)
+			}
+			b.Set(-1-n, 0)
+			b.Set(k, -k)
+			b.Delete(k + 1)
+		}
+		if n != 1000 {
+			t.Fatalf("unexpected iterator len: %d", n)
+		}
+
+		// The builder owns the nodes it copied while iterating.
+		root := b.m.root
+		b.Set(0, 0)
+		if b.m.root != root {
+			t.Fatal("expected Set after iteration to update in place")
+		}
+
+		m := b.Map()
+		if m.Len() != 2000 {
+			t.Fatalf("unexpected map len: %d", m.Len())
+		}
+		for k := 0; k < 1000; k++ {
+			if v, ok := m.Get(k); !ok || v != -k {
+				t.Fatalf("Get(%d)=<%v,%v>", k, v, ok)
+			}
+		}
+	})
+}
+
+// Ensure map can support overwrites as it expands.
+func TestSortedMap_Overwrite(t *testing.T) {
+	const n = 1000
+	m := NewSortedMap[int, int](nil)
+	for i := 0; i < n; i++ {
+		// Set original value.
+		m = m.Set(i, i)
+
+		// Overwrite every node.
+		for j := 0; j <= i; j++ {
+			m = m.Set(j, i*j)
+		}
+	}
+
+	// Verify all key/value pairs in map.
+	for i := 0; i < n; i++ {
+		if v, ok := m.Get(i); !ok || v != i*(n-1) {
+			t.Fatalf("Get(%d)=<%v,%v>", i, v, ok)
+		}
+	}
+}
+
+func TestSortedMap_DeleteRange(t *testing.T) {
+	RunRandom(t, "Model", func(t *testing.T, rand *rand.Rand) {
+		m := NewSortedMap[int, int](nil)
+		for i := rand.Intn(5000); i > 0; i-- {
+			k := rand.Intn(10000)
+			m = m.Set(k, -k)
+		}
+		want, repr := m, m.GoString()
+
+		lo := rand.Intn(11000) - 500
+		hi := lo + rand.Intn(6000)
+		for k := lo; k < hi; k++ {
+			want = want.Delete(k)
+		}
+		got := m.DeleteRange(lo, hi)
+		if got.Len() != want.Len() || !slices.Equal(sortedMapKeys(got), sortedMapKeys(want)) {
+			t.Fatalf("DeleteRange(%d, %d): expected %d keys, got %d", lo, hi, want.Len(), got.Len())
+		}
+		if m.GoString() != repr {
+			t.Fatal("original map modified")
+		}
+
+		// The result must remain usable.
+		for i := 0; i < 100; i++ {
+			k := rand.Intn(10000)
+			if rand.Intn(2) == 0 {
+				got, want = got.Set(k, k), want.Set(k, k)
+			} else {
+				got, want = got.Delete(k), want.Delete(k)
+			}
+		}
+		if !slices.Equal(sortedMapKeys(got), sortedMapKeys(want)) || got.Len() != want.Len() {
+			t.Fatalf("DeleteRange(%d, %d): result diverged after updates", lo, hi)
+		}
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		m := NewSortedMap[int, int](nil)
+		if m.DeleteRange(0, 10) != m {
+			t.Fatal("expected empty map to be returned")
+		}
+		for i := 0; i < 100; i++ {
+			m = m.Set(i*2, i)
+		}
+		if m.DeleteRange(5, 5) != m || m.DeleteRange(10, 5) != m || m.DeleteRange(11, 12) != m || m.DeleteRange(500, 600) != m {
+			t.Fatal("expected map to be returned for a range without keys")
+		}
+		if other := m.DeleteRange(-10, 1000); other.Len() != 0 || other.root != nil {
+			t.Fatalf("expected all keys to be removed, got %d", other.Len())
 		}
 	})
 }
 
-// Ensure map can support overwrites as it expands.
-func TestSortedMap_Overwrite(t *testing.T) {
-	const n = 1000
-	m := NewSortedMap[int, int](nil)
-	for i := 0; i < n; i++ {
-		// Set original value.
-		m = m.Set(i, i)
-
-		// Overwrite every node.
-		for j := 0; j <= i; j++ {
-			m = m.Set(j, i*j)
-		}
-	}
-
-	// Verify all key/value pairs in map.
-	for i := 0; i < n; i++ {
-		if v, ok := m.Get(i); !ok || v != i*(n-1) {
-			t.Fatalf("Get(%d)=<%v,%v>", i, v, ok)
-		}
-	}
+// sortedMapKeys returns the keys of m in order.
+func sortedMapKeys[K, V any](m *SortedMap[K, V]) []K {
+	var keys []K
+	m.Range(func(key K, _ V) bool {
+		keys = append(keys, key)
+		return true
+	})
+	return keys
 }
 
 func TestSortedMap_Delete(t *testing.T) {
@@ -2095,6 +4865,86 @@ func TestSortedMap_Delete(t *testing.T) {
 	})
 }
 
+func TestSortedMap_AscendAfter(t *testing.T) {
+	m := NewSortedMap[int, int](nil)
+	var want []MapEntry[int, int]
+	for i := 0; i < 10000; i++ {
+		m = m.Set(i*2, i)
+		want = append(want, MapEntry[int, int]{Key: i * 2, Value: i})
+	}
+
+	t.Run("Ascending", func(t *testing.T) {
+		var got []MapEntry[int, int]
+		for cursor, more := -1, true; more; {
+			var page []MapEntry[int, int]
+			page, cursor, more = m.AscendAfter(cursor, 7)
+			if len(page) != 7 && more {
+				t.Fatalf("unexpected page size %d with more remaining", len(page))
+			}
+			got = append(got, page...)
+		}
+		if !slices.Equal(got, want) {
+			t.Fatalf("unexpected entries: %d entries", len(got))
+		}
+	})
+
+	t.Run("Descending", func(t *testing.T) {
+		var got []MapEntry[int, int]
+		for cursor, more := math.MaxInt, true; more; {
+			var page []MapEntry[int, int]
+			page, cursor, more = m.DescendBefore(cursor, 7)
+			got = append(got, page...)
+		}
+		slices.Reverse(got)
+		if !slices.Equal(got, want) {
+			t.Fatalf("unexpected entries: %d entries", len(got))
+		}
+	})
+
+	t.Run("Cursor", func(t *testing.T) {
+		// Cursors between keys, on keys and beyond either end.
+		for _, tt := range []struct {
+			cursor, limit int
+			reverse       bool
+			keys          []int
+			next          int
+			more          bool
+		}{
+			{cursor: 4, limit: 2, keys: []int{6, 8}, next: 8, more: true},
+			{cursor: 5, limit: 2, keys: []int{6, 8}, next: 8, more: true},
+			{cursor: 19996, limit: 5, keys: []int{19998}, next: 19998},
+			{cursor: 19998, limit: 5, next: 19998},
+			{cursor: 3, limit: 0, next: 3, more: true},
+			{cursor: 4, limit: 2, reverse: true, keys: []int{2, 0}, next: 0},
+			{cursor: 5, limit: 1, reverse: true, keys: []int{4}, next: 4, more: true},
+			{cursor: 0, limit: 5, reverse: true, next: 0},
+			{cursor: 30000, limit: 1, reverse: true, keys: []int{19998}, next: 19998, more: true},
+		} {
+			page, next, more := m.AscendAfter(tt.cursor, tt.limit)
+			if tt.reverse {
+				page, next, more = m.DescendBefore(tt.cursor, tt.limit)
+			}
+			var keys []int
+			for _, e := range page {
+				keys = append(keys, e.Key)
+			}
+			if !slices.Equal(keys, tt.keys) || next != tt.next || more != tt.more {
+				t.Errorf("cursor=%d limit=%d reverse=%v: got %v, %d, %v", tt.cursor, tt.limit, tt.reverse, keys, next, more)
+			}
+		}
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		m := NewSortedMap[string, int](nil)
+		if page, next, more := m.AscendAfter("a", 10); len(page) != 0 || next != "a" || more {
+			t.Fatalf("unexpected page: %v, %q, %v", page, next, more)
+		}
+		if page, next, more := m.DescendBefore("a", 10); len(page) != 0 || next != "a" || more {
+			t.Fatalf("unexpected page: %v, %q, %v", page, next, more)
+		}
+	})
+}
+
 func TestSortedMap_Iterator(t *testing.T) {
 	t.Run("Empty", func(t *testing.T) {
 		t.Run("First", func(t *testing.T) {
@@ -2183,6 +5033,414 @@ func TestSortedMap_Iterator(t *testing.T) {
 	})
 }
 
+// iteratorModel is a reference cursor over a slice implementing the
+// documented iterator contract: Next and Prev return the element under the
+// cursor and then move it, and an iterator whose cursor has left the sequence
+// stays done until it is repositioned.
+type iteratorModel struct {
+	n   int
+	pos int
+}
+
+func (m *iteratorModel) done() bool { return m.pos < 0 || m.pos >= m.n }
+
+// step returns the position under the cursor, or -1 if done, and moves the
+// cursor by delta.
+func (m *iteratorModel) step(delta int) int {
+	if m.done() {
+		return -1
+	}
+	pos := m.pos
+	m.pos += delta
+	return pos
+}
+
+func TestListIterator_Bidirectional(t *testing.T) {
+	newList := func(rand *rand.Rand) (*List[int], []int) {
+		var l *List[int]
+		switch rand.Intn(4) {
+		case 0: // slice-backed
+			l = NewList[int]()
+			for i := rand.Intn(listSliceThreshold + 1); i > 0; i-- {
+				l = l.Append(rand.Int())
+			}
+		case 1: // trie-backed
+			l = NewList[int]()
+			for i := listSliceThreshold + 1 + rand.Intn(2000); i > 0; i-- {
+				l = l.Append(rand.Int())
+			}
+		case 2: // trie-backed with a non-zero origin
+			l = NewList[int]()
+			for i := 100 + rand.Intn(2000); i > 0; i-- {
+				l = l.Prepend(rand.Int())
+			}
+		default: // sliced trie
+			l = NewList[int]()
+			for i := 2000; i > 0; i-- {
+				l = l.Append(rand.Int())
+			}
+			start := rand.Intn(1000)
+			l = l.Slice(start, start+rand.Intn(1000))
+		}
+		return l, slices.Collect(listValues(l))
+	}
+
+	RunRandom(t, "Random", func(t *testing.T, rand *rand.Rand) {
+		l, std := newList(rand)
+		itr := l.Iterator()
+		model := &iteratorModel{n: len(std)}
+		for i := 0; i < 500; i++ {
+			var op string
+			var want int
+			switch r := rand.Intn(20); {
+			case r < 8:
+				op, want = "Next", model.step(1)
+				if got, v := itr.Next(); got != want || (want >= 0 && v != std[want]) {
+					t.Fatalf("%d. Next()=<%d,%d>, expected index %d", i, got, v, want)
+				}
+				continue
+			case r < 16:
+				op, want = "Prev", model.step(-1)
+				if got, v := itr.Prev(); got != want || (want >= 0 && v != std[want]) {
+					t.Fatalf("%d. Prev()=<%d,%d>, expected index %d", i, got, v, want)
+				}
+				continue
+			case r < 18 && len(std) > 0:
+				op, model.pos = "Seek", rand.Intn(len(std))
+				itr.Seek(model.pos)
+			case r == 18:
+				op = "First"
+				if itr.First(); len(std) > 0 {
+					model.pos = 0
+				}
+			default:
+				op = "Last"
+				if itr.Last(); len(std) > 0 {
+					model.pos = len(std) - 1
+				}
+			}
+			if itr.Done() != model.done() {
+				t.Fatalf("%d. Done()=%v after %s, expected %v", i, itr.Done(), op, model.done())
+			}
+		}
+	})
+}
+
+func TestSortedMapIterator_Bidirectional(t *testing.T) {
+	RunRandom(t, "Random", func(t *testing.T, rand *rand.Rand) {
+		m := NewSortedMap[int, int](nil)
+		for i := rand.Intn(3000); i > 0; i-- {
+			m = m.Set(rand.Intn(10000)*2, i)
+		}
+		var keys []int
+		m.Range(func(key int, value int) bool {
+			keys = append(keys, key)
+			return true
+		})
+
+		itr := m.Iterator()
+		model := &iteratorModel{n: len(keys)}
+		for i := 0; i < 500; i++ {
+			var op string
+			switch r := rand.Intn(20); {
+			case r < 8:
+				want := model.step(1)
+				if k, _, ok := itr.Next(); ok != (want >= 0) || (ok && k != keys[want]) {
+					t.Fatalf("%d. Next()=<%d,%v>, expected index %d", i, k, ok, want)
+				}
+				continue
+			case r < 16:
+				want := model.step(-1)
+				if k, _, ok := itr.Prev(); ok != (want >= 0) || (ok && k != keys[want]) {
+					t.Fatalf("%d. Prev()=<%d,%v>, expected index %d", i, k, ok, want)
+				}
+				continue
+			case r < 18:
+				// Seek to present and absent keys, including past the end.
+				key := rand.Intn(20010)
+				op, model.pos = "Seek", sort.SearchInts(keys, key)
+				itr.Seek(key)
+			case r == 18:
+				op, model.pos = "First", 0
+				itr.First()
+			default:
+				op, model.pos = "Last", len(keys)-1
+				itr.Last()
+			}
+			if itr.Done() != model.done() {
+				t.Fatalf("%d. Done()=%v after %s, expected %v", i, itr.Done(), op, model.done())
+			}
+		}
+	})
+}
+
+// testIteratorClone clones itr midway through want and checks that the clone
+// and the original advance independently from the same position.
+func testIteratorClone[I any, T comparable](t *testing.T, itr I, clone func(I) I, seq func(I) Seq[T], want []T) {
+	t.Helper()
+	mid := len(want) / 2
+	visited := seqValues(seqN(seq(itr), mid))
+	other := clone(itr)
+
+	// Advancing the clone first must not move the original.
+	first, ok := seq(other).Next()
+	if !ok || first != want[mid] {
+		t.Fatalf("clone Next()=<%v,%v>, expected %v", first, ok, want[mid])
+	}
+	visited = append(visited, seqValues(seq(itr))...)
+	if !slices.Equal(visited, want) {
+		t.Fatalf("original visited %v, expected %v", visited, want)
+	}
+
+	// Exhausting the original must not move the clone.
+	if rest := seqValues(seq(other)); !slices.Equal(rest, want[mid+1:]) {
+		t.Fatalf("clone visited %v, expected %v", rest, want[mid+1:])
+	}
+}
+
+// seqN limits s to its first n values.
+func seqN[T any](s Seq[T], n int) Seq[T] { return &limitedSeq[T]{s, n} }
+
+type limitedSeq[T any] struct {
+	s Seq[T]
+	n int
+}
+
+func (s *limitedSeq[T]) Next() (value T, ok bool) {
+	if s.n <= 0 {
+		return value, false
+	}
+	s.n--
+	return s.s.Next()
+}
+
+func TestIterator_Clone(t *testing.T) {
+	var want []int
+	for i := 0; i < 1000; i++ {
+		want = append(want, i)
+	}
+	list := NewList(want...)
+	m := NewMap[int, int](nil)
+	sm := NewSortedMap[int, int](nil)
+	for _, v := range want {
+		m, sm = m.Set(v, v), sm.Set(v, v)
+	}
+
+	t.Run("List", func(t *testing.T) {
+		for _, l := range []*List[int]{list, NewList(want[:20]...)} {
+			testIteratorClone(t, l.Iterator(), (*ListIterator[int]).Clone, (*ListIterator[int]).AsSeq, slices.Collect(listValues(l)))
+		}
+	})
+	t.Run("Map", func(t *testing.T) {
+		var order []int
+		m.Range(func(k, v int) bool { order = append(order, k); return true })
+		testIteratorClone(t, m.Iterator(), (*MapIterator[int, int]).Clone, mapKeySeq[*MapIterator[int, int]], order)
+	})
+	t.Run("SortedMap", func(t *testing.T) {
+		testIteratorClone(t, sm.Iterator(), (*SortedMapIterator[int, int]).Clone, mapKeySeq[*SortedMapIterator[int, int]], want)
+	})
+	t.Run("SortedMapReverse", func(t *testing.T) {
+		itr := sm.Iterator()
+		itr.Last()
+		reversed := slices.Clone(want)
+		slices.Reverse(reversed)
+		prev := func(itr *SortedMapIterator[int, int]) Seq[int] { return sortedMapPrevSeq[int, int]{itr} }
+		testIteratorClone(t, itr, (*SortedMapIterator[int, int]).Clone, prev, reversed)
+	})
+	t.Run("Set", func(t *testing.T) {
+		s := NewSet[int](nil, want...)
+		var order []int
+		for itr := s.Iterator(); !itr.Done(); {
+			v, _ := itr.Next()
+			order = append(order, v)
+		}
+		testIteratorClone(t, s.Iterator(), (*SetIterator[int]).Clone, func(itr *SetIterator[int]) Seq[int] { return itr }, order)
+	})
+	t.Run("SortedSet", func(t *testing.T) {
+		s := NewSortedSet[int](nil, want...)
+		testIteratorClone(t, s.Iterator(), (*SortedSetIterator[int]).Clone, func(itr *SortedSetIterator[int]) Seq[int] { return itr }, want)
+	})
+	t.Run("Queue", func(t *testing.T) {
+		q := NewQueue(want[:500]...)
+		for _, v := range want[500:] {
+			q = q.Enqueue(v)
+		}
+		testIteratorClone(t, q.Iterator(), (*QueueIterator[int]).Clone, (*QueueIterator[int]).AsSeq, want)
+	})
+	t.Run("BatchListBuilder", func(t *testing.T) {
+		b := NewBatchListBuilder[int](64)
+		for i := 399; i >= 0; i-- {
+			b.Prepend(want[i])
+		}
+		for _, v := range want[400:] {
+			b.Append(v)
+		}
+		testIteratorClone(t, b.Iterator(), (*BatchListIterator[int]).Clone, (*BatchListIterator[int]).AsSeq, want)
+	})
+}
+
+func TestIterator_Allocs(t *testing.T) {
+	const n = 5000
+	keys := mapTestStringKeys(n)
+
+	// mapIteratorAllocs returns the allocations made by a full iteration.
+	mapIteratorAllocs := func(itr interface {
+		First()
+		Done() bool
+	}, next func()) float64 {
+		return testing.AllocsPerRun(10, func() {
+			for itr.First(); !itr.Done(); {
+				next()
+			}
+		})
+	}
+
+	t.Run("Map", func(t *testing.T) {
+		collide := &mockHasher[int]{
+			hash:  func(k int) uint32 { return uint32(k % 100) },
+			equal: func(a, b int) bool { return a == b },
+		}
+		for _, size := range []int{5, n} {
+			ints, collided := NewMap[int, int](nil), NewMap[int, int](collide)
+			strs := NewMap[string, string](nil)
+			for i := 0; i < size; i++ {
+				ints = ints.Set(i, i)
+				collided = collided.Set(i, i)
+				strs = strs.Set(keys[i], keys[i])
+			}
+			itr, citr, sitr := ints.Iterator(), collided.Iterator(), strs.Iterator()
+			if a := mapIteratorAllocs(itr, func() { itr.Next() }); a != 0 {
+				t.Errorf("size=%d: int keys: %v allocs per iteration", size, a)
+			}
+			if a := mapIteratorAllocs(citr, func() { citr.Next() }); a != 0 {
+				t.Errorf("size=%d: colliding keys: %v allocs per iteration", size, a)
+			}
+			if a := mapIteratorAllocs(sitr, func() { sitr.Next() }); a != 0 {
+				t.Errorf("size=%d: string keys: %v allocs per iteration", size, a)
+			}
+		}
+	})
+
+	t.Run("SortedMap", func(t *testing.T) {
+		ints, strs := NewSortedMap[int, int](nil), NewSortedMap[string, string](nil)
+		for i := 0; i < n; i++ {
+			ints = ints.Set(i, i)
+			strs = strs.Set(keys[i], keys[i])
+		}
+		itr, sitr := ints.Iterator(), strs.Iterator()
+		if a := mapIteratorAllocs(itr, func() { itr.Next() }); a != 0 {
+			t.Errorf("int keys: %v allocs per iteration", a)
+		}
+		if a := mapIteratorAllocs(sitr, func() { sitr.Next() }); a != 0 {
+			t.Errorf("string keys: %v allocs per iteration", a)
+		}
+		if a := testing.AllocsPerRun(10, func() {
+			for sitr.Last(); !sitr.Done(); {
+				sitr.Prev()
+			}
+		}); a != 0 {
+			t.Errorf("string keys: %v allocs per reverse iteration", a)
+		}
+	})
+}
+
+// mapKeySeq adapts a map iterator to a Seq of its keys.
+func mapKeySeq[I Seq2[K, V], K, V any](itr I) Seq[K] { return mapKeys[K, V]{itr} }
+
+type mapKeys[K, V any] struct{ itr Seq2[K, V] }
+
+func (s mapKeys[K, V]) Next() (key K, ok bool) {
+	key, _, ok = s.itr.Next()
+	return key, ok
+}
+
+// sortedMapPrevSeq adapts a sorted map iterator to a Seq of keys in reverse.
+type sortedMapPrevSeq[K, V any] struct{ itr *SortedMapIterator[K, V] }
+
+func (s sortedMapPrevSeq[K, V]) Next() (key K, ok bool) {
+	key, _, ok = s.itr.Prev()
+	return key, ok
+}
+
+// sumReadonly is written against ReadonlyMap so it accepts maps, sorted maps
+// and their builders alike.
+func sumReadonly(m ReadonlyMap[string, int], keys ...string) (sum int, visited []string) {
+	for _, key := range keys {
+		v, _ := m.Get(key)
+		sum += v
+	}
+	m.Range(func(key string, value int) bool {
+		visited = append(visited, key)
+		return len(visited) < 3
+	})
+	return sum, visited
+}
+
+func TestReadonlyMap(t *testing.T) {
+	mb := NewMapBuilder[string, int](nil)
+	smb := NewSortedMapBuilder[string, int](nil)
+	for i, key := range []string{"d", "b", "a", "e", "c"} {
+		mb.Set(key, i)
+		smb.Set(key, i)
+	}
+	m, sm := mb.Snapshot(), smb.Snapshot()
+
+	for name, rm := range map[string]ReadonlyMap[string, int]{
+		"Map":              m,
+		"MapBuilder":       mb,
+		"SortedMap":        sm,
+		"SortedMapBuilder": smb,
+	} {
+		t.Run(name, func(t *testing.T) {
+			if rm.Len() != 5 {
+				t.Fatalf("unexpected length: %d", rm.Len())
+			}
+			sum, visited := sumReadonly(rm, "a", "e", "z")
+			if sum != 5 {
+				t.Fatalf("unexpected sum: %d", sum)
+			} else if len(visited) != 3 {
+				t.Fatalf("expected Range to stop after 3 entries, visited %v", visited)
+			}
+			if strings.HasPrefix(name, "Sorted") && fmt.Sprint(visited) != "[a b c]" {
+				t.Fatalf("expected sorted order, visited %v", visited)
+			}
+		})
+	}
+
+	t.Run("BuilderRangeInPlace", func(t *testing.T) {
+		// Range walks the builder's map without a snapshot, so nodes copied
+		// after one are still updated in place afterwards.
+		mb.Set("a", 10)
+		smb.Set("a", 10)
+		mroot, smroot := mb.m.root, smb.m.root
+		mb.Range(func(string, int) bool { return true })
+		smb.Range(func(string, int) bool { return true })
+		mb.Set("b", 10)
+		smb.Set("b", 10)
+		if mb.m.root != mroot || smb.m.root != smroot {
+			t.Fatal("expected Set after Range to update in place")
+		}
+		if v, _ := m.Get("b"); v != 1 {
+			t.Fatalf("snapshot modified: b=%d", v)
+		}
+	})
+
+	t.Run("Collect", func(t *testing.T) {
+		converted := CollectSortedMap(nil, m.Range)
+		var keys []string
+		converted.Range(func(key string, value int) bool {
+			keys = append(keys, key)
+			return true
+		})
+		if fmt.Sprint(keys) != "[a b c d e]" {
+			t.Fatalf("unexpected keys %v", keys)
+		}
+		if back := CollectMap(nil, sm.Range); back.Len() != 5 {
+			t.Fatalf("unexpected length: %d", back.Len())
+		}
+	})
+}
+
 func TestNewHasher(t *testing.T) {
 	t.Run("builtin", func(t *testing.T) {
 		t.Run("int", func(t *testing.T) { testNewHasher(t, int(100)) })
@@ -2222,6 +5480,171 @@ func testNewHasher[V cmp.Ordered](t *testing.T, v V) {
 	}
 }
 
+func TestFastStringHasher(t *testing.T) {
+	h := NewFastStringHasher()
+	if h.Hash("foo") != h.Hash("foo") || !h.Equal("foo", "foo") || h.Equal("foo", "bar") {
+		t.Fatal("unexpected hasher behavior")
+	}
+
+	t.Run("Map", func(t *testing.T) {
+		keys := mapTestStringKeys(10000)
+		m := NewMap[string, int](h)
+		for i, k := range keys {
+			m = m.Set(k, i)
+		}
+		for i, k := range keys {
+			if v, ok := m.Get(k); !ok || v != i {
+				t.Fatalf("Get(%q)=<%v,%v>, expected %d", k, v, ok, i)
+			}
+		}
+		for _, k := range keys[:5000] {
+			m = m.Delete(k)
+		}
+		if m.Len() != 5000 {
+			t.Fatalf("unexpected length: %d", m.Len())
+		}
+	})
+
+	// With well-distributed 32-bit hashes, n keys should put about n²/2³²
+	// keys in collision nodes.
+	t.Run("Distribution", func(t *testing.T) {
+		keys := mapTestStringKeys(200000)
+		for _, tt := range []struct {
+			name   string
+			hasher Hasher[string]
+		}{{"Default", NewHasher("")}, {"Fast", h}} {
+			m := NewMap[string, int](tt.hasher)
+			for i, k := range keys {
+				m = m.Set(k, i)
+			}
+			collided := mapCollidedKeys(m.root)
+			t.Logf("%s: %d keys in collision nodes", tt.name, collided)
+			if tt.name == "Fast" && collided > 100 {
+				t.Fatalf("expected few collisions, got %d", collided)
+			}
+		}
+	})
+}
+
+func TestHasherFunc(t *testing.T) {
+	type key struct{ a, b int }
+	hashers := map[string]Hasher[key]{
+		"Good": ComparableHasherFunc(func(k key) uint32 { return uint32(k.a*31 + k.b) }),
+		// Poor hashes put many keys in collision nodes but must still work.
+		"Constant": ComparableHasherFunc(func(key) uint32 { return 0 }),
+		"Low":      HasherFunc(func(k key) uint32 { return uint32(k.a % 3) }, func(x, y key) bool { return x == y }),
+		"Seeded":   SeededHasher(ComparableHasherFunc(func(k key) uint32 { return uint32(k.a) }), 42),
+	}
+	for name, h := range hashers {
+		t.Run(name, func(t *testing.T) {
+			const n = 2000
+			m := NewMap[key, int](h)
+			mb := NewBatchMapBuilder[key, int](h, 64)
+			sb := NewBatchSetBuilder(h, 64)
+			s := NewSet(h)
+			for i := 0; i < n; i++ {
+				k := key{i, -i}
+				m = m.Set(k, i)
+				mb.Set(k, i)
+				sb.Add(k)
+				s = s.Add(k)
+			}
+			bm, bs := mb.Map(), sb.Set()
+			for i := 0; i < n; i++ {
+				k := key{i, -i}
+				if v, ok := m.Get(k); !ok || v != i {
+					t.Fatalf("Map.Get(%v)=<%d,%v>", k, v, ok)
+				} else if v, ok := bm.Get(k); !ok || v != i {
+					t.Fatalf("BatchMapBuilder: Get(%v)=<%d,%v>", k, v, ok)
+				} else if !s.Has(k) || !bs.Has(k) {
+					t.Fatalf("expected sets to contain %v", k)
+				}
+			}
+			if _, ok := m.Get(key{1, 1}); ok {
+				t.Fatal("unexpected key")
+			}
+			if m.Len() != n || bm.Len() != n || s.Len() != n || bs.Len() != n {
+				t.Fatalf("unexpected lengths: %d, %d, %d, %d", m.Len(), bm.Len(), s.Len(), bs.Len())
+			}
+
+			var count int
+			for itr := m.Iterator(); !itr.Done(); itr.Next() {
+				count++
+			}
+			for i := 0; i < n; i += 2 {
+				m = m.Delete(key{i, -i})
+			}
+			if count != n || m.Len() != n/2 {
+				t.Fatalf("unexpected iteration count %d or length %d", count, m.Len())
+			}
+			for i := 0; i < n; i++ {
+				if _, ok := m.Get(key{i, -i}); ok != (i%2 == 1) {
+					t.Fatalf("unexpected presence of key %d after deletes", i)
+				}
+			}
+		})
+	}
+
+	t.Run("Seed", func(t *testing.T) {
+		base := NewHasher(0)
+		a, b := SeededHasher(base, 1), SeededHasher(base, 2)
+		var differ int
+		for i := 0; i < 100; i++ {
+			if a.Hash(i) != a.Hash(i) {
+				t.Fatal("expected deterministic hash")
+			} else if a.Hash(i) != b.Hash(i) {
+				differ++
+			}
+		}
+		if differ < 90 || !a.Equal(3, 3) || a.Equal(3, 4) {
+			t.Fatalf("expected seeds to change hashes, %d of 100 differ", differ)
+		}
+	})
+
+	t.Run("Nil", func(t *testing.T) {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Fatal("expected panic")
+			}
+		}()
+		HasherFunc[int](nil, nil)
+	})
+}
+
+// mapTestStringKeys returns n distinct keys of 8 to 64 bytes resembling
+// identifiers, paths and addresses.
+func mapTestStringKeys(n int) []string {
+	patterns := []string{"user-%06d", "/var/lib/service/data/%d/index.db", "customer.%d@example.com", "tenant/%d/orders/2024-01-01T00:00:00Z/items/line-number"}
+	keys := make([]string, n)
+	for i := range keys {
+		keys[i] = fmt.Sprintf(patterns[i%len(patterns)], i)
+	}
+	return keys
+}
+
+// mapCollidedKeys returns the number of keys in hash collision nodes under n.
+func mapCollidedKeys[K, V any](n mapNode[K, V]) int {
+	switch n := n.(type) {
+	case *mapHashCollisionNode[K, V]:
+		return len(n.entries)
+	case *mapBitmapIndexedNode[K, V]:
+		var total int
+		for _, child := range n.nodes {
+			total += mapCollidedKeys(child)
+		}
+		return total
+	case *mapHashArrayNode[K, V]:
+		var total int
+		for _, child := range n.nodes {
+			if child != nil {
+				total += mapCollidedKeys(child)
+			}
+		}
+		return total
+	}
+	return 0
+}
+
 func TestNewComparer(t *testing.T) {
 	t.Run("builtin", func(t *testing.T) {
 		t.Run("int", func(t *testing.T) { testNewComparer(t, int(100), int(101)) })
@@ -2347,9 +5770,10 @@ func (m *TSortedMap) Validate() error {
 		return fmt.Errorf("basic: %s", err)
 	}
 
-	if err := m.validateForwardIterator(m.builder.Iterator()); err != nil {
+	// Iterate the builder's map directly, as in TList.Validate.
+	if err := m.validateForwardIterator(m.builder.m.Iterator()); err != nil {
 		return fmt.Errorf("basic: %s", err)
-	} else if err := m.validateBackwardIterator(m.builder.Iterator()); err != nil {
+	} else if err := m.validateBackwardIterator(m.builder.m.Iterator()); err != nil {
 		return fmt.Errorf("basic: %s", err)
 	}
 	return nil
@@ -2416,6 +5840,31 @@ func BenchmarkSortedMap_Delete(b *testing.B) {
 	}
 }
 
+func BenchmarkSortedMap_DeleteRange(b *testing.B) {
+	const n = 1_000_000
+	builder := NewSortedMapBuilder[int, int](nil)
+	for i := 0; i < n; i++ {
+		builder.Set(i, i)
+	}
+	m := builder.Map()
+
+	b.Run("DeleteRange", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			m.DeleteRange(400_000, 500_000)
+		}
+	})
+	b.Run("Loop", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			other := m
+			for k := 400_000; k < 500_000; k++ {
+				other = other.Delete(k)
+			}
+		}
+	})
+}
+
 func BenchmarkSortedMap_Iterator(b *testing.B) {
 	const n = 10000
 	m := NewSortedMap[int, int](nil)
@@ -2444,6 +5893,23 @@ func BenchmarkSortedMap_Iterator(b *testing.B) {
 			itr.Prev()
 		}
 	})
+
+	b.Run("String", func(b *testing.B) {
+		m := NewSortedMap[string, string](nil)
+		for _, k := range mapTestStringKeys(n) {
+			m = m.Set(k, k)
+		}
+		b.ReportAllocs()
+		b.ResetTimer()
+
+		itr := m.Iterator()
+		for i := 0; i < b.N; i++ {
+			if i%n == 0 {
+				itr.First()
+			}
+			itr.Next()
+		}
+	})
 }
 
 func BenchmarkSortedMapBuilder_Set(b *testing.B) {
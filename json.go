@@ -0,0 +1,241 @@
+package immutable
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// jsonKeyKind describes how a key type is converted to or from a JSON object
+// key. The rules follow those encoding/json uses for Go map keys.
+type jsonKeyKind int
+
+const (
+	jsonKeyUnsupported jsonKeyKind = iota
+	jsonKeyString                  // string kinds, used directly
+	jsonKeyText                    // encoding.TextMarshaler or TextUnmarshaler
+	jsonKeyInt                     // signed integer kinds, base 10
+	jsonKeyUint                    // unsigned integer kinds, base 10
+)
+
+var (
+	textMarshalerType   = reflect.TypeFor[encoding.TextMarshaler]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+// jsonKeyCodec holds the key conversions for a single key type.
+type jsonKeyCodec struct {
+	encode jsonKeyKind
+	decode jsonKeyKind
+}
+
+// jsonKeyCodecs caches a *jsonKeyCodec per reflect.Type so reflection is only
+// used once per key type rather than once per entry.
+var jsonKeyCodecs sync.Map
+
+// jsonKeyCodecFor returns the cached codec for t, computing it if necessary.
+func jsonKeyCodecFor(t reflect.Type) *jsonKeyCodec {
+	if c, ok := jsonKeyCodecs.Load(t); ok {
+		return c.(*jsonKeyCodec)
+	}
+
+	c := &jsonKeyCodec{}
+	numeric := jsonKeyUnsupported
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		numeric = jsonKeyInt
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		numeric = jsonKeyUint
+	}
+
+	// Text marshaling takes precedence over the key's kind, as in encoding/json.
+	switch {
+	case t.Implements(textMarshalerType):
+		c.encode = jsonKeyText
+	case t.Kind() == reflect.String:
+		c.encode = jsonKeyString
+	default:
+		c.encode = numeric
+	}
+	switch {
+	case reflect.PointerTo(t).Implements(textUnmarshalerType):
+		c.decode = jsonKeyText
+	case t.Kind() == reflect.String:
+		c.decode = jsonKeyString
+	default:
+		c.decode = numeric
+	}
+
+	actual, _ := jsonKeyCodecs.LoadOrStore(t, c)
+	return actual.(*jsonKeyCodec)
+}
+
+// encodeJSONKey returns the JSON object key for key.
+func encodeJSONKey[K any](kind jsonKeyKind, key K) (string, error) {
+	switch kind {
+	case jsonKeyString:
+		return reflect.ValueOf(&key).Elem().String(), nil
+	case jsonKeyText:
+		if v := reflect.ValueOf(&key).Elem(); (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) && v.IsNil() {
+			return "", nil
+		}
+		text, err := any(key).(encoding.TextMarshaler).MarshalText()
+		return string(text), err
+	case jsonKeyInt:
+		return strconv.FormatInt(reflect.ValueOf(&key).Elem().Int(), 10), nil
+	case jsonKeyUint:
+		return strconv.FormatUint(reflect.ValueOf(&key).Elem().Uint(), 10), nil
+	}
+	panic("immutable: unsupported JSON key kind")
+}
+
+// decodeJSONKey parses a JSON object key into a key of type K.
+func decodeJSONKey[K any](kind jsonKeyKind, s string) (key K, err error) {
+	v := reflect.ValueOf(&key).Elem()
+	switch kind {
+	case jsonKeyText:
+		err = any(&key).(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	case jsonKeyString:
+		v.SetString(s)
+	case jsonKeyInt:
+		n, perr := strconv.ParseInt(s, 10, 64)
+		if perr != nil || v.OverflowInt(n) {
+			return key, fmt.Errorf("invalid %s key %q", v.Type(), s)
+		}
+		v.SetInt(n)
+	case jsonKeyUint:
+		n, perr := strconv.ParseUint(s, 10, 64)
+		if perr != nil || v.OverflowUint(n) {
+			return key, fmt.Errorf("invalid %s key %q", v.Type(), s)
+		}
+		v.SetUint(n)
+	default:
+		panic("immutable: unsupported JSON key kind")
+	}
+	return key, err
+}
+
+// MarshalJSON implements json.Marshaler. The map is encoded as a JSON object
+// with keys converted the way encoding/json converts Go map keys:
+// encoding.TextMarshaler keys are marshaled, string keys are used directly and
+// integer keys are formatted in base 10. Object keys are sorted.
+func (m *Map[K, V]) MarshalJSON() ([]byte, error) {
+	if m == nil {
+		return []byte("null"), nil
+	}
+	t := reflect.TypeFor[K]()
+	codec := jsonKeyCodecFor(t)
+	if codec.encode == jsonKeyUnsupported {
+		return nil, fmt.Errorf("immutable.Map.MarshalJSON: unsupported key type %s", t)
+	}
+
+	type entry struct {
+		key   string
+		value V
+	}
+	entries := make([]entry, 0, m.Len())
+	itr := m.Iterator()
+	for !itr.Done() {
+		k, v, _ := itr.Next()
+		key, err := encodeJSONKey(codec.encode, k)
+		if err != nil {
+			return nil, fmt.Errorf("immutable.Map.MarshalJSON: %w", err)
+		}
+		entries = append(entries, entry{key: key, value: v})
+	}
+	slices.SortFunc(entries, func(a, b entry) int { return strings.Compare(a.key, b.key) })
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, e := range entries {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(e.key)
+		buf.Write(key)
+		buf.WriteByte(':')
+		value, err := json.Marshal(e.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler, replacing the contents of the map
+// with the decoded JSON object. Object keys are converted back the way
+// encoding/json converts Go map keys: encoding.TextUnmarshaler keys are
+// unmarshaled, string keys are used directly and integer keys are parsed in
+// base 10. Unmarshaling null leaves the map unchanged.
+//
+// Unlike other Map methods this modifies the receiver, so it should only be
+// used on a map that has not yet been shared. The map's hasher is retained;
+// key types without a built-in hasher must be unmarshaled into a map created
+// by NewMap with a hasher.
+func (m *Map[K, V]) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	t := reflect.TypeFor[K]()
+	codec := jsonKeyCodecFor(t)
+	if codec.decode == jsonKeyUnsupported {
+		return fmt.Errorf("immutable.Map.UnmarshalJSON: unsupported key type %s", t)
+	}
+
+	var raw map[string]V
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if m.hasher == nil && len(raw) > 0 && !hasDefaultHasher(t) {
+		return fmt.Errorf("immutable.Map.UnmarshalJSON: no built-in hasher for key type %s", t)
+	}
+
+	b := NewMapBuilder[K, V](m.hasher)
+	for s, v := range raw {
+		key, err := decodeJSONKey[K](codec.decode, s)
+		if err != nil {
+			return fmt.Errorf("immutable.Map.UnmarshalJSON: %w", err)
+		}
+		b.Set(key, v)
+	}
+	*m = *b.Map()
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler. The list is encoded as a JSON array
+// of its values in order.
+func (l *List[T]) MarshalJSON() ([]byte, error) {
+	if l == nil {
+		return []byte("null"), nil
+	}
+	values := make([]T, 0, l.Len())
+	itr := l.Iterator()
+	for !itr.Done() {
+		_, v := itr.Next()
+		values = append(values, v)
+	}
+	return json.Marshal(values)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, replacing the contents of the
+// list with the decoded JSON array. Unmarshaling null leaves the list
+// unchanged. Like Map.UnmarshalJSON, it modifies the receiver.
+func (l *List[T]) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	var values []T
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+	*l = *NewList[T]().appendSlice(values, new(owner))
+	return nil
+}
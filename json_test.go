@@ -0,0 +1,197 @@
+package immutable
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// jsonPoint is a struct key type that round-trips through encoding.TextMarshaler.
+type jsonPoint struct{ X, Y int }
+
+func (p jsonPoint) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("%d:%d", p.X, p.Y)), nil
+}
+
+func (p *jsonPoint) UnmarshalText(text []byte) error {
+	_, err := fmt.Sscanf(string(text), "%d:%d", &p.X, &p.Y)
+	return err
+}
+
+type jsonPointHasher struct{}
+
+func (jsonPointHasher) Hash(p jsonPoint) uint32   { return uint32(p.X*31 + p.Y) }
+func (jsonPointHasher) Equal(a, b jsonPoint) bool { return a == b }
+
+// jsonID is a string type whose text form differs from its string value.
+type jsonID string
+
+func (id jsonID) MarshalText() ([]byte, error) { return []byte("id-" + id), nil }
+
+func (id *jsonID) UnmarshalText(text []byte) error {
+	s, ok := strings.CutPrefix(string(text), "id-")
+	if !ok {
+		return fmt.Errorf("invalid id %q", text)
+	}
+	*id = jsonID(s)
+	return nil
+}
+
+type jsonLevel uint8
+
+func TestMap_JSON(t *testing.T) {
+	t.Run("StringKeys", func(t *testing.T) {
+		m := NewMap[string, int](nil).Set("b", 2).Set("a", 1).Set("<c>", 3)
+		data, err := json.Marshal(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Keys are sorted and escaped the same way as for Go maps.
+		want, _ := json.Marshal(map[string]int{"a": 1, "b": 2, "<c>": 3})
+		if string(data) != string(want) {
+			t.Fatalf("expected %s, got %s", want, data)
+		}
+
+		var other Map[string, int]
+		if err := json.Unmarshal(data, &other); err != nil {
+			t.Fatal(err)
+		}
+		if got := maps.Collect(mapAll(&other)); !maps.Equal(got, map[string]int{"a": 1, "b": 2, "<c>": 3}) {
+			t.Fatalf("unexpected map: %v", got)
+		}
+	})
+
+	t.Run("IntegerKeys", func(t *testing.T) {
+		m := NewMap[int, string](nil).Set(-5, "neg").Set(10, "ten")
+		data, err := json.Marshal(m)
+		if err != nil {
+			t.Fatal(err)
+		} else if string(data) != `{"-5":"neg","10":"ten"}` {
+			t.Fatalf("unexpected json: %s", data)
+		}
+		other := NewMap[int, string](nil)
+		if err := json.Unmarshal(data, other); err != nil {
+			t.Fatal(err)
+		}
+		if v, ok := other.Get(-5); !ok || v != "neg" || other.Len() != 2 {
+			t.Fatalf("unexpected map: len=%d", other.Len())
+		}
+
+		var levels Map[jsonLevel, bool]
+		if err := json.Unmarshal([]byte(`{"256":true}`), &levels); err == nil {
+			t.Fatal("expected overflow error")
+		}
+	})
+
+	t.Run("TextMarshalerKeys", func(t *testing.T) {
+		m := NewMap[jsonPoint, string](jsonPointHasher{})
+		for i := 0; i < 100; i++ {
+			m = m.Set(jsonPoint{i, -i}, fmt.Sprint(i))
+		}
+		data, err := json.Marshal(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		other := NewMap[jsonPoint, string](jsonPointHasher{})
+		if err := json.Unmarshal(data, other); err != nil {
+			t.Fatal(err)
+		}
+		if got, want := maps.Collect(mapAll(other)), maps.Collect(mapAll(m)); !maps.Equal(got, want) {
+			t.Fatalf("round trip mismatch: %v", got)
+		}
+
+		// Without a hasher the key type cannot be used.
+		var unhashed Map[jsonPoint, string]
+		if err := json.Unmarshal(data, &unhashed); err == nil || !strings.Contains(err.Error(), "jsonPoint") {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("TextMarshalerStringKeys", func(t *testing.T) {
+		// As with Go maps, text marshaling takes precedence over the string kind.
+		m := NewMap[jsonID, int](nil).Set("x", 1)
+		data, err := json.Marshal(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, _ := json.Marshal(map[jsonID]int{"x": 1})
+		if string(data) != string(want) {
+			t.Fatalf("expected %s, got %s", want, data)
+		}
+
+		var other Map[jsonID, int]
+		if err := json.Unmarshal(data, &other); err != nil {
+			t.Fatal(err)
+		}
+		if v, ok := other.Get("x"); !ok || v != 1 || other.Len() != 1 {
+			t.Fatalf("Get(x)=<%v,%v>", v, ok)
+		}
+	})
+
+	t.Run("UnsupportedKeys", func(t *testing.T) {
+		m := NewMap[float64, int](nil)
+		if _, err := json.Marshal(m); err == nil || !strings.Contains(err.Error(), "unsupported key type float64") {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := json.Unmarshal([]byte(`{"1.5":1}`), m); err == nil || !strings.Contains(err.Error(), "unsupported key type float64") {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("Nested", func(t *testing.T) {
+		type doc struct {
+			Tags *Map[string, []int] `json:"tags"`
+		}
+		in := doc{Tags: NewMap[string, []int](nil).Set("a", []int{1, 2})}
+		data, err := json.Marshal(in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var out doc
+		if err := json.Unmarshal(data, &out); err != nil {
+			t.Fatal(err)
+		}
+		if v, ok := out.Tags.Get("a"); !ok || len(v) != 2 || v[1] != 2 {
+			t.Fatalf("Get(a)=<%v,%v>", v, ok)
+		}
+		if err := json.Unmarshal([]byte(`{"tags":null}`), &out); err != nil || out.Tags != nil {
+			t.Fatalf("unexpected null decode: %v, %v", err, out.Tags)
+		}
+	})
+}
+
+func TestList_JSON(t *testing.T) {
+	for _, n := range []int{0, 3, 100} {
+		l := NewList[int]()
+		for i := 0; i < n; i++ {
+			l = l.Append(i * i)
+		}
+		data, err := json.Marshal(l)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, _ := json.Marshal(slices.Collect(listValues(l)))
+		if n > 0 && string(data) != string(want) {
+			t.Fatalf("n=%d: expected %s, got %s", n, want, data)
+		} else if n == 0 && string(data) != "[]" {
+			t.Fatalf("unexpected json: %s", data)
+		}
+
+		var other List[int]
+		if err := json.Unmarshal(data, &other); err != nil {
+			t.Fatal(err)
+		}
+		if got := slices.Collect(listValues(&other)); other.Len() != n || (n > 0 && !slices.Equal(got, slices.Collect(listValues(l)))) {
+			t.Fatalf("n=%d: round trip mismatch: %v", n, got)
+		}
+	}
+
+	var l *List[string]
+	if data, err := json.Marshal(l); err != nil || string(data) != "null" {
+		t.Fatalf("unexpected nil list json: %s, %v", data, err)
+	}
+}
@@ -2,8 +2,13 @@ package immutable
 
 import (
 	"fmt"
+	"iter"
 	"math/bits"
 	"reflect"
+	"runtime"
+	"slices"
+	"sync"
+	"sync/atomic"
 )
 
 const (
@@ -18,21 +23,23 @@ const (
 // For smaller lists (under listSliceThreshold elements), it uses a slice internally
 // for better performance, and will transparently switch to a trie for larger lists.
 type List[T any] struct {
-	root   listNode[T] // root node
-	origin int         // offset to zero index element
-	size   int         // total number of elements in use
+	root   listNode[T]      // root node
+	origin int              // offset to zero index element
+	size   int              // total number of elements in use, including the tail
+	tail   *listLeafNode[T] // last leaf, held outside the trie until full; see append
 }
 
 // NewList returns a new empty instance of List.
 func NewList[T any](values ...T) *List[T] {
 	if len(values) > listSliceThreshold {
+		o := new(owner)
 		l := &List[T]{
-			root:   &listLeafNode[T]{},
+			root:   &listLeafNode[T]{owner: o},
 			origin: 0,
 			size:   0,
 		}
 		for _, value := range values {
-			l = l.append(value, true)
+			l = l.append(value, o)
 		}
 		return l
 	}
@@ -46,6 +53,214 @@ func NewList[T any](values ...T) *List[T] {
 	}
 }
 
+// NewListFromSliceParallel returns a list containing values, building its
+// trie with up to workers goroutines. If workers is zero or less,
+// runtime.GOMAXPROCS(0) is used. The input is split into chunks whose sizes
+// are powers of the node size, so each goroutine fills complete subtrees that
+// are then joined under a common root. Lists too small to benefit are built on
+// the calling goroutine. The result is equal to NewList(values...).
+func NewListFromSliceParallel[T any](values []T, workers int) *List[T] {
+	if len(values) <= listSliceThreshold {
+		return NewList(values...)
+	}
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	// Find the height of the root, matching the lists built by appending, then
+	// of the subtrees to build concurrently: the tallest that still gives every
+	// worker a few to build.
+	var depth uint
+	for 1<<(depth*listNodeBits) < len(values) {
+		depth++
+	}
+	chunkDepth := depth
+	for chunkDepth > 1 && len(values)>>((chunkDepth+1)*listNodeBits) < 4*workers {
+		chunkDepth--
+	}
+	o := new(owner)
+	if workers == 1 || chunkDepth >= depth {
+		root := newListNode[T](depth, o).setRange(0, values, o)
+		return &List[T]{root: root, size: len(values)}
+	}
+
+	span := 1 << ((chunkDepth + 1) * listNodeBits)
+	chunks := make([]listNode[T], (len(values)+span-1)/span)
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	for w := 0; w < min(workers, len(chunks)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := int(next.Add(1) - 1); i < len(chunks); i = int(next.Add(1) - 1) {
+				start := i * span
+				chunks[i] = newListNode[T](chunkDepth, o).setRange(start, values[start:min(start+span, len(values))], o)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Hang each subtree from the branches above it.
+	root := &listBranchNode[T]{d: depth, owner: o}
+	for i, chunk := range chunks {
+		index := i * span
+		n := root
+		for n.d > chunkDepth+1 {
+			idx := (index >> (n.d * listNodeBits)) & listNodeMask
+			if n.children[idx] == nil {
+				n.children[idx] = &listBranchNode[T]{d: n.d - 1, owner: o}
+			}
+			n = n.children[idx].(*listBranchNode[T])
+		}
+		n.children[(index>>(n.d*listNodeBits))&listNodeMask] = chunk
+	}
+	return &List[T]{root: root, size: len(values)}
+}
+
+// MapList returns a new list containing the result of fn applied to each
+// element of l, in order.
+func MapList[T, U any](l *List[T], fn func(T) U) *List[U] {
+	return MapListIndexed(l, func(_ int, v T) U { return fn(v) })
+}
+
+// MapListIndexed is like MapList but also passes each element's index to fn.
+// The result is built a leaf at a time, so it allocates once per 32 elements
+// rather than once per element.
+func MapListIndexed[T, U any](l *List[T], fn func(int, T) U) *List[U] {
+	other, o := NewList[U](), new(owner)
+	buf := make([]U, 0, listNodeSize)
+	var index int
+	listChunks(l, 0, l.size, func(chunk []T) bool {
+		buf = buf[:0]
+		for _, v := range chunk {
+			buf = append(buf, fn(index, v))
+			index++
+		}
+		other = other.appendSlice(buf, o)
+		return true
+	})
+	return other
+}
+
+// Pair holds two values of possibly different types, as produced by ZipLists.
+type Pair[A, B any] struct {
+	First  A
+	Second B
+}
+
+// ZipLists returns a list pairing the elements of a and b by position. The
+// result has the length of the shorter list; extra elements of the longer one
+// are ignored.
+func ZipLists[A, B any](a *List[A], b *List[B]) *List[Pair[A, B]] {
+	other, o := NewList[Pair[A, B]](), new(owner)
+	buf := make([]Pair[A, B], 0, listNodeSize)
+	itrA, itrB := a.Iterator(), b.Iterator()
+	for !itrA.Done() && !itrB.Done() {
+		_, va := itrA.Next()
+		_, vb := itrB.Next()
+		if buf = append(buf, Pair[A, B]{First: va, Second: vb}); len(buf) == listNodeSize {
+			other, buf = other.appendSlice(buf, o), buf[:0]
+		}
+	}
+	return other.appendSlice(buf, o)
+}
+
+// UnzipList splits a list of pairs into a list of their first values and a
+// list of their second values.
+func UnzipList[A, B any](l *List[Pair[A, B]]) (*List[A], *List[B]) {
+	return MapList(l, func(p Pair[A, B]) A { return p.First }),
+		MapList(l, func(p Pair[A, B]) B { return p.Second })
+}
+
+// FlattenLists returns a list of the elements of each list in l, in order.
+// Nil lists are treated as empty. Full leaves of the inner lists that fall on
+// a leaf boundary of the result are shared rather than copied.
+func FlattenLists[T any](l *List[*List[T]]) *List[T] {
+	var leaves []listNode[T]
+	var size, n int
+	var leaf *listLeafNode[T]
+	add := func(shared *listLeafNode[T], chunk []T) bool {
+		if n == 0 && shared != nil && len(chunk) == listNodeSize {
+			leaves, size = append(leaves, shared), size+listNodeSize
+			return true
+		}
+		for len(chunk) > 0 {
+			if leaf == nil {
+				leaf = &listLeafNode[T]{}
+			}
+			k := copy(leaf.children[n:], chunk)
+			chunk, n, size = chunk[k:], n+k, size+k
+			if n == listNodeSize {
+				leaf.occupied = ^uint32(0)
+				leaves, leaf, n = append(leaves, leaf), nil, 0
+			}
+		}
+		return true
+	}
+	listChunks(l, 0, l.size, func(chunk []*List[T]) bool {
+		for _, inner := range chunk {
+			if inner != nil {
+				listLeaves(inner, 0, inner.size, add)
+			}
+		}
+		return true
+	})
+	if n > 0 {
+		leaf.occupied = (uint32(1) << n) - 1
+		leaves = append(leaves, leaf)
+	}
+	return newListFromLeaves(leaves, size)
+}
+
+// ReduceList folds the elements of l from first to last, calling fn with the
+// accumulated value and each element in turn and returning the final
+// accumulator, or init for an empty list. The leaves are walked directly, so
+// there is no per-element lookup. fn must not modify l.
+func ReduceList[T, A any](l *List[T], init A, fn func(A, T) A) A {
+	acc := init
+	listChunks(l, 0, l.size, func(chunk []T) bool {
+		for _, v := range chunk {
+			acc = fn(acc, v)
+		}
+		return true
+	})
+	return acc
+}
+
+// ReduceListRight is like ReduceList but folds the elements from last to
+// first.
+func ReduceListRight[T, A any](l *List[T], init A, fn func(A, T) A) A {
+	acc := init
+	listChunksReverse(l, 0, l.size, func(chunk []T) bool {
+		for i := len(chunk) - 1; i >= 0; i-- {
+			acc = fn(acc, chunk[i])
+		}
+		return true
+	})
+	return acc
+}
+
+// NewListRepeat returns a list of n copies of value. Since nodes are never
+// modified once shared, a single full leaf, and each full branch above it, is
+// referenced from every slot it fills. If n is zero or less, the list is empty.
+func NewListRepeat[T any](value T, n int) *List[T] {
+	n = max(n, 0)
+	full := &listLeafNode[T]{occupied: ^uint32(0)}
+	for i := range full.children {
+		full.children[i] = value
+	}
+	leaves := make([]listNode[T], n/listNodeSize, n/listNodeSize+1)
+	for i := range leaves {
+		leaves[i] = full
+	}
+	if rem := n % listNodeSize; rem > 0 {
+		last := &listLeafNode[T]{occupied: (uint32(1) << rem) - 1}
+		copy(last.children[:rem], full.children[:])
+		leaves = append(leaves, last)
+	}
+	return newListFromLeaves(leaves, n)
+}
+
 // clone returns a copy of the list.
 func (l *List[T]) clone() *List[T] {
 	other := *l
@@ -58,54 +273,107 @@ func (l *List[T]) Len() int { return l.size }
 // cap returns the total number of possible elements for the current depth.
 func (l *List[T]) cap() int { return 1 << (l.root.depth() * listNodeBits) }
 
+// tailLen returns the number of elements held in the tail.
+func (l *List[T]) tailLen() int {
+	if l.tail == nil {
+		return 0
+	}
+	return bits.Len32(l.tail.occupied)
+}
+
+// trie returns a list equal to l whose elements are all held in the trie,
+// pushing the tail into it if there is one. Operations other than appending
+// and indexed reads and writes work on the trie alone and call this first.
+// A partially filled tail is copied, so later writes past its last element,
+// in place or not, leave l untouched.
+func (l *List[T]) trie() *List[T] {
+	if l.tail == nil {
+		return l
+	}
+	n := l.tailLen()
+	leaf := l.tail
+	if n < listNodeSize {
+		tmp := *leaf
+		tmp.owner = nil
+		leaf = &tmp
+	}
+	other := l.clone()
+	other.tail = nil
+	pos := l.origin + l.size - n
+	for other.root.depth() == 0 || pos >= other.cap() {
+		newRoot := &listBranchNode[T]{d: other.root.depth() + 1}
+		newRoot.children[0] = other.root
+		other.root = newRoot
+	}
+	other.root = listNodeSetLeaf(other.root, pos, leaf)
+	return other
+}
+
+// listNodeSetLeaf returns a copy of the branch n with leaf placed at the
+// leaf-aligned position pos, creating any missing branches on the way.
+func listNodeSetLeaf[T any](n listNode[T], pos int, leaf *listLeafNode[T]) listNode[T] {
+	branch := *n.(*listBranchNode[T])
+	branch.owner = nil
+	idx := (pos >> (branch.d * listNodeBits)) & listNodeMask
+	if branch.d == 1 {
+		branch.children[idx] = leaf
+		return &branch
+	}
+	child := branch.children[idx]
+	if child == nil {
+		child = &listBranchNode[T]{d: branch.d - 1}
+	}
+	branch.children[idx] = listNodeSetLeaf(child, pos, leaf)
+	return &branch
+}
+
 // Get returns the value at the given index. Similar to slices, this method will
 // panic if index is below zero or is greater than or equal to the list size.
 func (l *List[T]) Get(index int) T {
 	if index < 0 || index >= l.size {
 		panic(fmt.Sprintf("immutable.List.Get: index %d out of bounds", index))
 	}
-	if sliceNode, ok := l.root.(*listSliceNode[T]); ok {
-		return sliceNode.elements[index]
-	}
-	return l.root.get(l.origin + index)
+	return l.get(index)
 }
 
-// Contains returns true if the list contains the given value.
-// For comparable element types, this uses ==. For non-comparable types, it
-// falls back to reflect.DeepEqual. This method does not mutate the list and is
-// safe for concurrent use across goroutines.
-func (l *List[T]) Contains(value T) bool {
-	if l.size == 0 {
-		return false
-	}
-	// Equality function with fast path for comparable types.
-	eq := func(a, b T) bool {
-		ta := reflect.TypeOf(a)
-		if ta != nil && ta.Comparable() {
-			return any(a) == any(b)
-		}
-		return reflect.DeepEqual(a, b)
+// GetOK returns the value at the given index and true, or the zero value and
+// false if index is below zero or greater than or equal to the list size.
+func (l *List[T]) GetOK(index int) (T, bool) {
+	if index < 0 || index >= l.size {
+		var zero T
+		return zero, false
 	}
-	// Optimize for slice-backed lists.
+	return l.get(index), true
+}
+
+// get returns the value at an index known to be within bounds.
+func (l *List[T]) get(index int) T {
 	if sliceNode, ok := l.root.(*listSliceNode[T]); ok {
-		for i := 0; i < len(sliceNode.elements); i++ {
-			if eq(sliceNode.elements[i], value) {
-				return true
-			}
+		return sliceNode.elements[index]
+	} else if l.tail != nil {
+		if i := index - (l.size - l.tailLen()); i >= 0 {
+			return l.tail.children[i]
 		}
-		return false
 	}
-	// Fallback to iterator for trie-backed lists.
-	itr := l.Iterator()
-	for !itr.Done() {
-		_, v := itr.Next()
-		if eq(v, value) {
-			return true
+	// Shallow tries are walked directly rather than through the recursive
+	// descent. Lists grown by appending have a root of depth 2 from 33 elements.
+	pos := l.origin + index
+	if branch, ok := l.root.(*listBranchNode[T]); ok && branch.d <= 2 {
+		if branch.d == 2 {
+			branch = branch.children[(pos>>(2*listNodeBits))&listNodeMask].(*listBranchNode[T])
 		}
+		return branch.children[(pos>>listNodeBits)&listNodeMask].(*listLeafNode[T]).children[pos&listNodeMask]
 	}
-	return false
+	return l.root.get(pos)
 }
 
+// Contains returns true if the list contains the given value.
+// For comparable element types, this uses ==. For non-comparable types, it
+// falls back to reflect.DeepEqual. This method does not mutate the list and is
+// safe for concurrent use across goroutines. For comparable T, ListContains
+// avoids converting values to interfaces for the comparison.
+func (l *List[T]) Contains(value T) bool { return l.IndexOf(value) >= 0 }
+
 // ContainsFunc returns true if the list contains a value equal to the provided
 // value using the caller-supplied equality function.
 // The equality function should define equivalence for two values of type T and
@@ -116,95 +384,574 @@ func (l *List[T]) ContainsFunc(value T, equal func(a, b T) bool) bool {
 		return false
 	}
 	assert(equal != nil, "immutable.List.ContainsFunc: equal function must not be nil")
+	return l.IndexFunc(func(v T) bool { return equal(v, value) }) >= 0
+}
+
+// ListContains reports whether l contains value, comparing with ==. It walks
+// the leaves directly and does not allocate.
+func ListContains[T comparable](l *List[T], value T) bool {
+	return !listChunks(l, 0, l.size, func(chunk []T) bool {
+		for _, v := range chunk {
+			if v == value {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// IndexOf returns the index of the first value equal to value, or -1 if the
+// list does not contain it. Values are compared as in Contains.
+func (l *List[T]) IndexOf(value T) int {
+	eq := valuesEqualFunc[T]()
+	return l.IndexFunc(func(v T) bool { return eq(v, value) })
+}
+
+// IndexFunc returns the index of the first value for which pred returns true,
+// or -1 if there is none.
+func (l *List[T]) IndexFunc(pred func(T) bool) int {
 	if sliceNode, ok := l.root.(*listSliceNode[T]); ok {
 		for i := 0; i < len(sliceNode.elements); i++ {
-			if equal(sliceNode.elements[i], value) {
+			if pred(sliceNode.elements[i]) {
+				return i
+			}
+		}
+		return -1
+	}
+	index, i := -1, 0
+	listChunks(l, 0, l.size, func(chunk []T) bool {
+		for j, v := range chunk {
+			if pred(v) {
+				index = i + j
+				return false
+			}
+		}
+		i += len(chunk)
+		return true
+	})
+	return index
+}
+
+// LastIndexOf returns the index of the last value equal to value, or -1 if
+// the list does not contain it. Values are compared as in Contains.
+func (l *List[T]) LastIndexOf(value T) int {
+	eq := valuesEqualFunc[T]()
+	_, index, _ := l.FindLast(func(v T) bool { return eq(v, value) })
+	return index
+}
+
+// FindLast returns the last value for which pred returns true, along with its
+// index. The list is scanned from the end, stopping at the first match. If no
+// value matches, the zero value, -1 and false are returned.
+func (l *List[T]) FindLast(pred func(T) bool) (value T, index int, ok bool) {
+	index, end := -1, l.size
+	listChunksReverse(l, 0, l.size, func(chunk []T) bool {
+		end -= len(chunk)
+		for j := len(chunk) - 1; j >= 0; j-- {
+			if pred(chunk[j]) {
+				value, index, ok = chunk[j], end+j, true
+				return false
+			}
+		}
+		return true
+	})
+	return value, index, ok
+}
+
+// Count returns the number of values equal to value. Values are compared as
+// in Contains.
+func (l *List[T]) Count(value T) int {
+	eq := valuesEqualFunc[T]()
+	return l.CountFunc(func(v T) bool { return eq(v, value) })
+}
+
+// CountFunc returns the number of values for which pred returns true.
+func (l *List[T]) CountFunc(pred func(T) bool) int {
+	var n int
+	listChunks(l, 0, l.size, func(chunk []T) bool {
+		for _, v := range chunk {
+			if pred(v) {
+				n++
+			}
+		}
+		return true
+	})
+	return n
+}
+
+// Any reports whether pred returns true for at least one value. It stops at
+// the first such value and returns false for an empty list.
+func (l *List[T]) Any(pred func(T) bool) bool { return l.IndexFunc(pred) >= 0 }
+
+// Every reports whether pred returns true for every value. It stops at the
+// first value for which it does not and returns true for an empty list.
+func (l *List[T]) Every(pred func(T) bool) bool { return l.indexNot(pred) == l.size }
+
+// None reports whether pred returns false for every value. It stops at the
+// first value for which it does not and returns true for an empty list.
+func (l *List[T]) None(pred func(T) bool) bool { return !l.Any(pred) }
+
+// valuesEqualFunc returns valuesEqual for T, resolved ahead of time when T is
+// a comparable concrete type, so == is used without a reflect call per value.
+// Interface types are still checked per value.
+func valuesEqualFunc[T any]() func(a, b T) bool {
+	if typ := reflect.TypeFor[T](); typ.Kind() != reflect.Interface && typ.Comparable() {
+		return func(a, b T) bool { return any(a) == any(b) }
+	}
+	return valuesEqual[T]
+}
+
+// valuesEqual reports whether a and b are equal, using == for comparable
+// types and falling back to reflect.DeepEqual otherwise.
+func valuesEqual[T any](a, b T) bool {
+	ta := reflect.TypeOf(a)
+	if ta != nil && ta.Comparable() {
+		return any(a) == any(b)
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// RemoveAll returns a list without the values for which pred returns true,
+// keeping the order of the rest. If no values match, l itself is returned, so
+// callers can detect the no-op by comparing pointers. The values before the
+// first match keep sharing l's nodes.
+func (l *List[T]) RemoveAll(pred func(T) bool) *List[T] {
+	first, i := -1, 0
+	listChunks(l, 0, l.size, func(chunk []T) bool {
+		for j, v := range chunk {
+			if pred(v) {
+				first = i + j
+				return false
+			}
+		}
+		i += len(chunk)
+		return true
+	})
+	if first < 0 {
+		return l
+	}
+
+	var kept []T
+	listChunks(l, first+1, l.size, func(chunk []T) bool {
+		for _, v := range chunk {
+			if !pred(v) {
+				kept = append(kept, v)
+			}
+		}
+		return true
+	})
+	return l.Slice(0, first).appendSlice(kept, nil)
+}
+
+// RemoveValue returns a list without any values equal to value, compared as
+// in Contains. If value is not in the list, l itself is returned.
+func (l *List[T]) RemoveValue(value T) *List[T] {
+	eq := valuesEqualFunc[T]()
+	return l.RemoveAll(func(v T) bool { return eq(v, value) })
+}
+
+// Unique returns a list without repeated values, keeping the first occurrence
+// of each. Values are compared as in Contains: those of comparable types are
+// tracked in a map, and the rest are compared with reflect.DeepEqual against
+// earlier such values. If there are no repeats, l itself is returned.
+func (l *List[T]) Unique() *List[T] {
+	seen := make(map[any]struct{})
+	var others []T
+	return l.RemoveAll(func(v T) bool {
+		if typ := reflect.TypeOf(v); typ == nil || typ.Comparable() {
+			if _, ok := seen[any(v)]; ok {
+				return true
+			}
+			seen[any(v)] = struct{}{}
+			return false
+		}
+		for _, other := range others {
+			if reflect.DeepEqual(v, other) {
 				return true
 			}
 		}
+		others = append(others, v)
 		return false
-	}
-	itr := l.Iterator()
-	for !itr.Done() {
-		_, v := itr.Next()
-		if equal(v, value) {
+	})
+}
+
+// Compact returns a list in which each run of equal adjacent values is
+// replaced by its first value, like slices.Compact. Values are compared as in
+// Count. If no adjacent values are equal, l itself is returned.
+func (l *List[T]) Compact() *List[T] { return l.CompactFunc(valuesEqualFunc[T]()) }
+
+// CompactFunc is like Compact but compares values with eq, like
+// slices.CompactFunc. Each value is compared with the last value kept.
+func (l *List[T]) CompactFunc(eq func(a, b T) bool) *List[T] {
+	var prev T
+	first := true
+	return l.RemoveAll(func(v T) bool {
+		if !first && eq(prev, v) {
 			return true
 		}
-	}
-	return false
+		prev, first = v, false
+		return false
+	})
+}
+
+// UniqueListFunc returns a list without values whose key, as returned by key,
+// repeats that of an earlier value, keeping the first occurrence of each key.
+// If there are no repeats, l itself is returned.
+func UniqueListFunc[T any, K comparable](l *List[T], key func(T) K) *List[T] {
+	seen := make(map[K]struct{})
+	return l.RemoveAll(func(v T) bool {
+		k := key(v)
+		if _, ok := seen[k]; ok {
+			return true
+		}
+		seen[k] = struct{}{}
+		return false
+	})
 }
 
 // Set returns a new list with value set at index. Similar to slices, this
 // method will panic if index is below zero or if the index is greater than
 // or equal to the list size.
-func (l *List[T]) Set(index int, value T) *List[T] { return l.set(index, value, false) }
+func (l *List[T]) Set(index int, value T) *List[T] { return l.set(index, value, nil) }
 
-func (l *List[T]) set(index int, value T, mutable bool) *List[T] {
+func (l *List[T]) set(index int, value T, o *owner) *List[T] {
 	if index < 0 || index >= l.size {
 		panic(fmt.Sprintf("immutable.List.Set: index %d out of bounds", index))
 	}
 	// If it's a slice node, the logic is simple.
 	if sliceNode, ok := l.root.(*listSliceNode[T]); ok {
 		other := l
-		if !mutable {
+		if o == nil {
 			other = l.clone()
 		}
-		other.root = sliceNode.set(index, value, mutable)
+		other.root = sliceNode.set(index, value, o)
 		return other
 	}
 	// Otherwise, use the existing trie logic.
 	other := l
-	if !mutable {
+	if o == nil {
+		other = l.clone()
+	}
+	// The tail may be shared with other versions of the list, so it is always
+	// copied rather than updated in place.
+	if i := index - (l.size - l.tailLen()); l.tail != nil && i >= 0 {
+		other.tail = l.tail.set(i, value, nil).(*listLeafNode[T])
+		return other
+	}
+	pos := l.origin + index
+	if root, ok := l.root.(*listBranchNode[T]); ok && root.d <= 2 {
+		// As in Get, walk shallow tries directly, copying the path if shared.
+		if !o.owns(root.owner) {
+			tmp := *root
+			tmp.owner = o
+			root = &tmp
+		}
+		branch := root
+		if root.d == 2 {
+			idx := (pos >> (2 * listNodeBits)) & listNodeMask
+			branch = root.children[idx].(*listBranchNode[T])
+			if !o.owns(branch.owner) {
+				tmp := *branch
+				tmp.owner = o
+				branch = &tmp
+				root.children[idx] = branch
+			}
+		}
+		idx := (pos >> listNodeBits) & listNodeMask
+		leaf := branch.children[idx].(*listLeafNode[T])
+		if !o.owns(leaf.owner) {
+			tmp := *leaf
+			tmp.owner = o
+			leaf = &tmp
+			branch.children[idx] = leaf
+		}
+		leaf.children[pos&listNodeMask] = value
+		leaf.occupied |= 1 << (pos & listNodeMask)
+		other.root = root
+		return other
+	}
+	other.root = l.root.set(pos, value, o)
+	return other
+}
+
+// UpdateAt returns a new list with the value at index replaced by the result
+// of fn applied to it. The value is read and written in one descent of the
+// trie, rather than one for Get and another for Set. Similar to Set, this
+// method will panic if index is below zero or greater than or equal to the
+// list size.
+func (l *List[T]) UpdateAt(index int, fn func(old T) T) *List[T] { return l.updateAt(index, fn, nil) }
+
+func (l *List[T]) updateAt(index int, fn func(old T) T, o *owner) *List[T] {
+	if index < 0 || index >= l.size {
+		panic(fmt.Sprintf("immutable.List.UpdateAt: index %d out of bounds", index))
+	}
+	other := l
+	if o == nil {
 		other = l.clone()
 	}
-	other.root = l.root.set(l.origin+index, value, mutable)
+	if sliceNode, ok := l.root.(*listSliceNode[T]); ok {
+		other.root = sliceNode.set(index, fn(sliceNode.elements[index]), o)
+		return other
+	} else if i := index - (l.size - l.tailLen()); l.tail != nil && i >= 0 {
+		other.tail = listNodeUpdate(listNode[T](l.tail), i, fn, nil).(*listLeafNode[T])
+		return other
+	}
+	other.root = listNodeUpdate(l.root, l.origin+index, fn, o)
 	return other
 }
 
+// listNodeUpdate replaces the value at a position of a trie node with the
+// result of fn applied to it, copying the nodes on the path not owned by o.
+func listNodeUpdate[T any](n listNode[T], index int, fn func(old T) T, o *owner) listNode[T] {
+	switch n := n.(type) {
+	case *listBranchNode[T]:
+		other := n
+		if !o.owns(n.owner) {
+			tmp := *n
+			tmp.owner = o
+			other = &tmp
+		}
+		idx := (index >> (n.d * listNodeBits)) & listNodeMask
+		other.children[idx] = listNodeUpdate(n.children[idx], index, fn, o)
+		return other
+	case *listLeafNode[T]:
+		other := n
+		if !o.owns(n.owner) {
+			tmp := *n
+			tmp.owner = o
+			other = &tmp
+		}
+		other.children[index&listNodeMask] = fn(n.children[index&listNodeMask])
+		return other
+	}
+	return n
+}
+
+// Swap returns a new list with the elements at indexes i and j exchanged. The
+// nodes on both paths are copied once, rather than once per Set. If i equals
+// j, l itself is returned. This method will panic if either index is below
+// zero or greater than or equal to the list size.
+func (l *List[T]) Swap(i, j int) *List[T] { return l.swap(i, j, nil) }
+
+func (l *List[T]) swap(i, j int, o *owner) *List[T] {
+	for _, index := range [2]int{i, j} {
+		if index < 0 || index >= l.size {
+			panic(fmt.Sprintf("immutable.List.Swap: index %d out of bounds", index))
+		}
+	}
+	if i == j {
+		return l
+	}
+	if l.tail != nil && max(i, j) >= l.size-l.tailLen() {
+		l = l.trie()
+	}
+	other := l
+	if o == nil {
+		other = l.clone()
+	}
+	if sliceNode, ok := l.root.(*listSliceNode[T]); ok {
+		elements := sliceNode.elements
+		if !o.owns(sliceNode.owner) {
+			elements = make([]T, len(sliceNode.elements))
+			copy(elements, sliceNode.elements)
+			other.root = &listSliceNode[T]{elements: elements, owner: o}
+		}
+		elements[i], elements[j] = elements[j], elements[i]
+		return other
+	}
+	other.root = listNodeSetPair(l.root, l.origin+i, l.root.get(l.origin+j), l.origin+j, l.root.get(l.origin+i), o)
+	return other
+}
+
+// listNodeSetPair sets the values at two different positions of a trie node,
+// copying each node on the paths to them not owned by o at most once.
+func listNodeSetPair[T any](n listNode[T], i int, vi T, j int, vj T, o *owner) listNode[T] {
+	switch n := n.(type) {
+	case *listBranchNode[T]:
+		other := n
+		if !o.owns(n.owner) {
+			tmp := *n
+			tmp.owner = o
+			other = &tmp
+		}
+		idxI := (i >> (n.d * listNodeBits)) & listNodeMask
+		idxJ := (j >> (n.d * listNodeBits)) & listNodeMask
+		if idxI == idxJ {
+			other.children[idxI] = listNodeSetPair(n.children[idxI], i, vi, j, vj, o)
+		} else {
+			other.children[idxI] = n.children[idxI].set(i, vi, o)
+			other.children[idxJ] = n.children[idxJ].set(j, vj, o)
+		}
+		return other
+	case *listLeafNode[T]:
+		other := n
+		if !o.owns(n.owner) {
+			tmp := *n
+			tmp.owner = o
+			other = &tmp
+		}
+		other.children[i&listNodeMask] = vi
+		other.children[j&listNodeMask] = vj
+		return other
+	}
+	return n.set(i, vi, o).set(j, vj, o)
+}
+
 // Append returns a new list with value added to the end of the list.
-func (l *List[T]) Append(value T) *List[T] { return l.append(value, false) }
+func (l *List[T]) Append(value T) *List[T] { return l.append(value, nil) }
 
-func (l *List[T]) append(value T, mutable bool) *List[T] {
+func (l *List[T]) append(value T, o *owner) *List[T] {
 	// If it's a slice node and there's room, append to the slice.
 	if sliceNode, ok := l.root.(*listSliceNode[T]); ok {
 		if l.size < listSliceThreshold {
+			if o.owns(sliceNode.owner) {
+				// An owned slice node can grow in place, amortizing allocations.
+				sliceNode.elements = append(sliceNode.elements, value)
+				l.size++
+				return l
+			}
 			newElements := make([]T, l.size+1)
 			copy(newElements, sliceNode.elements)
 			newElements[l.size] = value
 			other := l
-			if !mutable {
+			if o == nil {
 				other = l.clone()
 			}
-			other.root = &listSliceNode[T]{elements: newElements}
+			other.root = &listSliceNode[T]{elements: newElements, owner: o}
 			other.size++
 			return other
 		}
-		// If we are at the threshold, we need to convert to a trie.
-		trieRoot := sliceNode.toTrie(true)
+		// If we are at the threshold, we need to convert to a trie. The new
+		// trie is unshared so the append can fill it in place.
+		f := fillOwner(o)
+		trieRoot := sliceNode.toTrie(f)
 		tempList := &List[T]{root: trieRoot, size: l.size, origin: 0}
-		return tempList.append(value, mutable)
+		return tempList.append(value, f)
 	}
 	// Standard trie-based append logic
+	if o == nil {
+		if other := l.appendTail(value); other != nil {
+			return other
+		}
+	}
 	other := l
-	if !mutable {
+	if o == nil {
 		other = l.clone()
 	}
 	// Expand list to the right if no slots remain.
 	if other.size+other.origin >= l.cap() {
-		newRoot := &listBranchNode[T]{d: other.root.depth() + 1}
+		newRoot := &listBranchNode[T]{d: other.root.depth() + 1, owner: o}
 		newRoot.children[0] = other.root
 		other.root = newRoot
 	}
 	// Increase size and set the last element to the new value.
 	other.size++
-	other.root = other.root.set(other.origin+other.size-1, value, mutable)
+	other.root = other.root.set(other.origin+other.size-1, value, o)
 	return other
 }
 
+// appendTail returns l with value added to its tail, or nil if the end of
+// the trie-backed list l is not on a leaf boundary and it has no tail.
+//
+// Rather than copying the path from the root to the last leaf on every
+// append, a list that ends on a leaf boundary starts a tail: a leaf held
+// outside the trie that later appends copy on their own. Only when the tail
+// is full is it pushed into the trie, so 31 of every 32 appends copy a single
+// leaf. Builders append in place and never start a tail.
+func (l *List[T]) appendTail(value T) *List[T] {
+	n := l.tailLen()
+	if l.tail == nil && (l.origin+l.size)&listNodeMask != 0 {
+		return nil
+	}
+	var other *List[T]
+	var tail listLeafNode[T]
+	if n == 0 || n == listNodeSize {
+		other = l.trie()
+		if other == l {
+			other = l.clone()
+		}
+		n = 0
+	} else {
+		other, tail = l.clone(), *l.tail
+	}
+	tail.children[n] = value
+	tail.occupied |= 1 << n
+	other.tail = &tail
+	other.size++
+	return other
+}
+
+// appendSlice returns a list with values added to the end of the list in order.
+// Slice-backed lists that stay under listSliceThreshold are extended with a
+// single allocation; otherwise values are packed into trie leaves a chunk at a
+// time rather than being appended one by one.
+func (l *List[T]) appendSlice(values []T, o *owner) *List[T] {
+	if len(values) == 0 {
+		return l
+	}
+	l = l.trie()
+	if sliceNode, ok := l.root.(*listSliceNode[T]); ok {
+		newLen := l.size + len(values)
+		if newLen <= listSliceThreshold {
+			newElements := make([]T, newLen)
+			copy(newElements, sliceNode.elements)
+			copy(newElements[l.size:], values)
+			other := l
+			if o == nil {
+				other = l.clone()
+			}
+			other.root = &listSliceNode[T]{elements: newElements, owner: o}
+			other.size = newLen
+			return other
+		}
+		// Converting to a trie allocates fresh nodes, so they can be filled in place.
+		f := fillOwner(o)
+		tempList := &List[T]{root: sliceNode.toTrie(f), size: l.size, origin: 0}
+		return tempList.appendSlice(values, f)
+	}
+	other := l
+	if o == nil {
+		other = l.clone()
+	}
+	// Expand list to the right until enough slots remain.
+	for other.origin+other.size+len(values) > other.cap() {
+		newRoot := &listBranchNode[T]{d: other.root.depth() + 1, owner: o}
+		newRoot.children[0] = other.root
+		other.root = newRoot
+	}
+	other.root = other.root.setRange(other.origin+other.size, values, o)
+	other.size += len(values)
+	return other
+}
+
+// Concat returns a new list with the elements of other appended after the
+// elements of l. Neither list is modified.
+//
+// The elements of the shorter list are copied in bulk, a leaf at a time, into
+// a copy of the longer list, which shares all of its untouched nodes with the
+// original.
+func (l *List[T]) Concat(other *List[T]) *List[T] {
+	if other.size == 0 {
+		return l
+	} else if l.size == 0 {
+		return other
+	}
+	if l.size < other.size {
+		return other.prependSlice(appendListRange(make([]T, 0, l.size), l, 0, l.size), nil)
+	}
+	return l.appendSlice(appendListRange(make([]T, 0, other.size), other, 0, other.size), nil)
+}
+
+// AppendSlice returns a new list with values added to the end of the list.
+// The values are copied in bulk, a leaf at a time, so each affected node is
+// copied once rather than once per value. If values is empty, l itself is
+// returned.
+func (l *List[T]) AppendSlice(values []T) *List[T] { return l.appendSlice(values, nil) }
+
 // Prepend returns a new list with value(s) added to the beginning of the list.
-func (l *List[T]) Prepend(value T) *List[T] { return l.prepend(value, false) }
+func (l *List[T]) Prepend(value T) *List[T] { return l.prepend(value, nil) }
 
-func (l *List[T]) prepend(value T, mutable bool) *List[T] {
+func (l *List[T]) prepend(value T, o *owner) *List[T] {
 	// If it's a slice node and there's room, prepend to the slice.
 	if sliceNode, ok := l.root.(*listSliceNode[T]); ok {
 		if l.size < listSliceThreshold {
@@ -212,35 +959,140 @@ func (l *List[T]) prepend(value T, mutable bool) *List[T] {
 			newElements[0] = value
 			copy(newElements[1:], sliceNode.elements)
 			other := l
-			if !mutable {
+			if o == nil {
 				other = l.clone()
 			}
-			other.root = &listSliceNode[T]{elements: newElements}
+			other.root = &listSliceNode[T]{elements: newElements, owner: o}
 			other.size++
 			return other
 		}
 		// If we are at the threshold, we need to convert to a trie.
-		trieRoot := sliceNode.toTrie(true)
+		f := fillOwner(o)
+		trieRoot := sliceNode.toTrie(f)
 		tempList := &List[T]{root: trieRoot, size: l.size, origin: 0}
-		return tempList.prepend(value, mutable)
+		return tempList.prepend(value, f)
 	}
 	// Standard trie-based prepend logic
+	l = l.trie()
+	other := l
+	if o == nil {
+		other = l.clone()
+	}
+	// Expand list to the left if no slots remain.
+	if other.origin == 0 {
+		newRoot := &listBranchNode[T]{d: other.root.depth() + 1, owner: o}
+		newRoot.children[listNodeSize-1] = other.root
+		other.root = newRoot
+		other.origin += (listNodeSize - 1) << (other.root.depth() * listNodeBits)
+	}
+	// Increase size and move origin back. Update first element to value.
+	other.size++
+	other.origin--
+	other.root = other.root.set(other.origin, value, o)
+	return other
+}
+
+// PrependSlice returns a new list with values added to the beginning of the
+// list in order, so values[0] ends up at index zero. As with AppendSlice, the
+// values are copied in bulk and each affected node is copied once. If values
+// is empty, l itself is returned.
+func (l *List[T]) PrependSlice(values []T) *List[T] { return l.prependSlice(values, nil) }
+
+// prependSlice returns a list with values added to the beginning of the list in
+// order, so values[0] ends up at index zero. Slice-backed lists are extended
+// with a single allocation; trie-backed lists expand leftward once and are then
+// filled a leaf at a time.
+func (l *List[T]) prependSlice(values []T, o *owner) *List[T] {
+	if len(values) == 0 {
+		return l
+	}
+	l = l.trie()
+	if sliceNode, ok := l.root.(*listSliceNode[T]); ok {
+		newLen := l.size + len(values)
+		if newLen <= listSliceThreshold {
+			newElements := make([]T, newLen)
+			copy(newElements, values)
+			copy(newElements[len(values):], sliceNode.elements)
+			other := l
+			if o == nil {
+				other = l.clone()
+			}
+			other.root = &listSliceNode[T]{elements: newElements, owner: o}
+			other.size = newLen
+			return other
+		}
+		if l.size == 0 {
+			return &List[T]{root: (&listSliceNode[T]{elements: values}).toTrie(o), size: len(values)}
+		}
+		// Converting to a trie allocates fresh nodes, so they can be filled in place.
+		f := fillOwner(o)
+		tempList := &List[T]{root: sliceNode.toTrie(f), size: l.size, origin: 0}
+		return tempList.prependSlice(values, f)
+	}
 	other := l
-	if !mutable {
+	if o == nil {
 		other = l.clone()
 	}
-	// Expand list to the left if no slots remain.
-	if other.origin == 0 {
-		newRoot := &listBranchNode[T]{d: other.root.depth() + 1}
-		newRoot.children[listNodeSize-1] = other.root
-		other.root = newRoot
-		other.origin += (listNodeSize - 1) << (other.root.depth() * listNodeBits)
+	// Expand list to the left until enough slots remain.
+	for other.origin < len(values) {
+		newRoot := &listBranchNode[T]{d: other.root.depth() + 1, owner: o}
+		newRoot.children[listNodeSize-1] = other.root
+		other.root = newRoot
+		other.origin += (listNodeSize - 1) << (other.root.depth() * listNodeBits)
+	}
+	other.origin -= len(values)
+	other.size += len(values)
+	other.root = other.root.setRange(other.origin, values, o)
+	return other
+}
+
+// InsertAt returns a new list with value inserted before index, shifting the
+// elements from index on to the right. Similar to slices, this method will
+// panic if index is below zero or greater than the list size.
+//
+// The elements on the shorter side of index are copied and the nodes on the
+// longer side are shared, so inserting near either end is cheap.
+func (l *List[T]) InsertAt(index int, value T) *List[T] { return l.insertAt(index, value, nil) }
+
+func (l *List[T]) insertAt(index int, value T, o *owner) *List[T] {
+	if index < 0 || index > l.size {
+		panic(fmt.Sprintf("immutable.List.InsertAt: index %d out of bounds", index))
+	}
+	// Copy the shorter side before slicing, which may update l in place.
+	if index <= l.size/2 {
+		values := append(appendListRange(make([]T, 0, index+1), l, 0, index), value)
+		return l.slice(index, l.size, o).prependSlice(values, o)
+	}
+	values := appendListRange(append(make([]T, 0, l.size-index+1), value), l, index, l.size)
+	return l.slice(0, index, o).appendSlice(values, o)
+}
+
+// RemoveAt returns a new list without the element at index, shifting the
+// elements after it to the left. Similar to slices, this method will panic if
+// index is below zero or greater than or equal to the list size. As with
+// InsertAt, only the elements on the shorter side of index are copied.
+func (l *List[T]) RemoveAt(index int) *List[T] { return l.removeAt(index, nil) }
+
+func (l *List[T]) removeAt(index int, o *owner) *List[T] {
+	if index < 0 || index >= l.size {
+		panic(fmt.Sprintf("immutable.List.RemoveAt: index %d out of bounds", index))
+	}
+	if index < l.size/2 {
+		values := appendListRange(make([]T, 0, index), l, 0, index)
+		return l.slice(index+1, l.size, o).prependSlice(values, o)
 	}
-	// Increase size and move origin back. Update first element to value.
-	other.size++
-	other.origin--
-	other.root = other.root.set(other.origin, value, mutable)
-	return other
+	values := appendListRange(make([]T, 0, l.size-index-1), l, index+1, l.size)
+	return l.slice(0, index, o).appendSlice(values, o)
+}
+
+// appendListRange appends the values at indexes start through end-1 of l to
+// dst and returns the extended slice.
+func appendListRange[T any](dst []T, l *List[T], start, end int) []T {
+	listChunks(l, start, end, func(chunk []T) bool {
+		dst = append(dst, chunk...)
+		return true
+	})
+	return dst
 }
 
 // Slice returns a new list of elements between start index and end index.
@@ -249,9 +1101,9 @@ func (l *List[T]) prepend(value T, mutable bool) *List[T] {
 // end.
 // Unlike Go slices, references to inaccessible elements will be automatically
 // removed so they can be garbage collected.
-func (l *List[T]) Slice(start, end int) *List[T] { return l.slice(start, end, false) }
+func (l *List[T]) Slice(start, end int) *List[T] { return l.slice(start, end, nil) }
 
-func (l *List[T]) slice(start, end int, mutable bool) *List[T] {
+func (l *List[T]) slice(start, end int, o *owner) *List[T] {
 	// Panics similar to Go slices.
 	if start < 0 || start > l.size {
 		panic(fmt.Sprintf("immutable.List.Slice: start index %d out of bounds", start))
@@ -267,11 +1119,18 @@ func (l *List[T]) slice(start, end int, mutable bool) *List[T] {
 	if sliceNode, ok := l.root.(*listSliceNode[T]); ok {
 		newElements := make([]T, end-start)
 		copy(newElements, sliceNode.elements[start:end])
-		return &List[T]{root: &listSliceNode[T]{elements: newElements}, size: end - start}
+		return &List[T]{root: &listSliceNode[T]{elements: newElements, owner: o}, size: end - start}
+	}
+	// Convert small results back to a slice node, the reverse of the upgrade in
+	// append, rather than keeping branches alive for a handful of elements.
+	if end-start <= listSliceThreshold {
+		newElements := appendListRange(make([]T, 0, end-start), l, start, end)
+		return &List[T]{root: &listSliceNode[T]{elements: newElements, owner: o}, size: end - start}
 	}
 	// Create copy, if immutable.
+	l = l.trie()
 	other := l
-	if !mutable {
+	if o == nil {
 		other = l.clone()
 	}
 	// Update origin/size.
@@ -289,11 +1148,370 @@ func (l *List[T]) slice(start, end int, mutable bool) *List[T] {
 		other.root = other.root.(*listBranchNode[T]).children[i]
 	}
 	// Ensure all references are removed before start & after end.
-	other.root = other.root.deleteBefore(other.origin, mutable)
-	other.root = other.root.deleteAfter(other.origin+other.size-1, mutable)
+	other.root = other.root.deleteBefore(other.origin, o)
+	other.root = other.root.deleteAfter(other.origin+other.size-1, o)
 	return other
 }
 
+// Compress returns a list equal to l in its most compact form: backed by a
+// slice if it has listSliceThreshold elements or fewer, otherwise a dense trie
+// of the least height starting at origin zero. A list sliced out of a much
+// larger one keeps the height and offsets of its ancestor, and a list that has
+// been prepended to and sliced repeatedly keeps growing its origin; compressing
+// such a list drops that skeleton so it can be garbage collected. The elements
+// are copied, so the result shares no nodes with l.
+func (l *List[T]) Compress() *List[T] {
+	if sliceNode, ok := l.root.(*listSliceNode[T]); ok && len(sliceNode.elements) == cap(sliceNode.elements) {
+		return l
+	}
+	leaves := make([]listNode[T], 0, (l.size+listNodeSize-1)/listNodeSize)
+	var leaf *listLeafNode[T]
+	var n int
+	listChunks(l, 0, l.size, func(chunk []T) bool {
+		for len(chunk) > 0 {
+			if leaf == nil {
+				leaf = &listLeafNode[T]{}
+				leaves = append(leaves, leaf)
+			}
+			k := copy(leaf.children[n:], chunk)
+			chunk, n = chunk[k:], n+k
+			leaf.occupied = uint32(1)<<n - 1
+			if n == listNodeSize {
+				leaf, n = nil, 0
+			}
+		}
+		return true
+	})
+	return newListFromLeaves(leaves, l.size)
+}
+
+// SplitAt returns the elements before index i and the elements from i on,
+// equal to l.Slice(0, i) and l.Slice(i, l.Len()). Both halves share all nodes
+// with l except those on the path to index i, which each half needs its own
+// copy of since one keeps the elements before i and the other those after.
+// This method will panic if i is below zero or greater than the list size.
+func (l *List[T]) SplitAt(i int) (*List[T], *List[T]) {
+	if i < 0 || i > l.size {
+		panic(fmt.Sprintf("immutable.List.SplitAt: index %d out of bounds", i))
+	}
+	return l.slice(0, i, nil), l.slice(i, l.size, nil)
+}
+
+// Rotate returns a list with the elements of l rotated left by n, so the
+// element at index n comes first. A negative n rotates right. n is taken
+// modulo the list size, and if the result equals l, l itself is returned.
+// Only the shorter of the two rotated parts is copied, as in Concat.
+func (l *List[T]) Rotate(n int) *List[T] {
+	if l.size == 0 {
+		return l
+	}
+	if n %= l.size; n < 0 {
+		n += l.size
+	}
+	if n == 0 {
+		return l
+	}
+	return l.slice(n, l.size, nil).Concat(l.slice(0, n, nil))
+}
+
+// Splice returns a list with the elements between start and end index
+// replaced by replacement. The elements before start and from end on keep
+// sharing l's nodes, except where the shorter side is copied by Concat.
+// Similar to Slice, this method will panic if start or end are below zero or
+// greater than the list size, or if start is greater than end.
+func (l *List[T]) Splice(start, end int, replacement ...T) *List[T] {
+	if start < 0 || start > l.size {
+		panic(fmt.Sprintf("immutable.List.Splice: start index %d out of bounds", start))
+	} else if end < 0 || end > l.size {
+		panic(fmt.Sprintf("immutable.List.Splice: end index %d out of bounds", end))
+	} else if start > end {
+		panic(fmt.Sprintf("immutable.List.Splice: invalid slice index: [%d:%d]", start, end))
+	}
+	if start == end && len(replacement) == 0 {
+		return l
+	}
+	return l.slice(0, start, nil).appendSlice(replacement, nil).Concat(l.slice(end, l.size, nil))
+}
+
+// Pop returns a list without the last element, along with that element. As
+// with Slice, the trie is contracted once its remaining elements fit under a
+// single branch. If the list is empty, it returns l, the zero value and false.
+func (l *List[T]) Pop() (*List[T], T, bool) {
+	if l.size == 0 {
+		var zero T
+		return l, zero, false
+	}
+	return l.slice(0, l.size-1, nil), l.Get(l.size - 1), true
+}
+
+// Shift returns a list without the first element, along with that element.
+// If the list is empty, it returns l, the zero value and false.
+func (l *List[T]) Shift() (*List[T], T, bool) {
+	if l.size == 0 {
+		var zero T
+		return l, zero, false
+	}
+	return l.slice(1, l.size, nil), l.Get(0), true
+}
+
+// Take returns a list of the first n elements of l. Unlike Slice, n is
+// clamped to the list size rather than causing a panic.
+func (l *List[T]) Take(n int) *List[T] { return l.slice(0, min(max(n, 0), l.size), nil) }
+
+// Drop returns a list without the first n elements of l. Unlike Slice, n is
+// clamped to the list size rather than causing a panic.
+func (l *List[T]) Drop(n int) *List[T] { return l.slice(min(max(n, 0), l.size), l.size, nil) }
+
+// TakeWhile returns the longest prefix of l whose elements all satisfy pred.
+func (l *List[T]) TakeWhile(pred func(T) bool) *List[T] {
+	return l.Take(l.indexNot(pred))
+}
+
+// DropWhile returns l without the longest prefix whose elements all satisfy
+// pred.
+func (l *List[T]) DropWhile(pred func(T) bool) *List[T] {
+	return l.Drop(l.indexNot(pred))
+}
+
+// indexNot returns the index of the first element not satisfying pred, or the
+// list size if they all do.
+func (l *List[T]) indexNot(pred func(T) bool) int {
+	if i := l.IndexFunc(func(v T) bool { return !pred(v) }); i >= 0 {
+		return i
+	}
+	return l.size
+}
+
+// listChunks calls fn with each run of the values at indexes start through
+// end-1 held contiguously in a node, in order, stopping if fn returns false.
+// Returns false if fn did.
+func listChunks[T any](l *List[T], start, end int, fn func(chunk []T) bool) bool {
+	return listLeaves(l, start, end, func(_ *listLeafNode[T], chunk []T) bool { return fn(chunk) })
+}
+
+// listLeaves is like listChunks but also passes fn the leaf holding each
+// chunk, or nil for a slice-backed list.
+func listLeaves[T any](l *List[T], start, end int, fn func(leaf *listLeafNode[T], chunk []T) bool) bool {
+	if start >= end {
+		return true
+	} else if sliceNode, ok := l.root.(*listSliceNode[T]); ok {
+		return fn(nil, sliceNode.elements[start:end])
+	}
+	trieSize := l.size - l.tailLen()
+	if start < trieSize && !listNodeLeaves(l.root, 0, l.origin+start, l.origin+min(end, trieSize), fn) {
+		return false
+	} else if end > trieSize {
+		return fn(l.tail, l.tail.children[max(start-trieSize, 0):end-trieSize])
+	}
+	return true
+}
+
+// listNodeChunks calls fn with the values at positions lo through hi-1 of a
+// trie node whose first position is base.
+func listNodeChunks[T any](n listNode[T], base, lo, hi int, fn func(chunk []T) bool) bool {
+	return listNodeLeaves(n, base, lo, hi, func(_ *listLeafNode[T], chunk []T) bool { return fn(chunk) })
+}
+
+// listNodeLeaves is like listNodeChunks but also passes fn the leaf holding
+// each chunk.
+func listNodeLeaves[T any](n listNode[T], base, lo, hi int, fn func(leaf *listLeafNode[T], chunk []T) bool) bool {
+	switch n := n.(type) {
+	case *listBranchNode[T]:
+		span := 1 << (n.d * listNodeBits)
+		for i := max(lo-base, 0) / span; i < listNodeSize && base+i*span < hi; i++ {
+			if !listNodeLeaves(n.children[i], base+i*span, lo, hi, fn) {
+				return false
+			}
+		}
+	case *listLeafNode[T]:
+		return fn(n, n.children[max(lo-base, 0):min(hi-base, listNodeSize)])
+	}
+	return true
+}
+
+// newListFromLeaves returns a list of size elements held in leaves, in order.
+// Every leaf but the last must be full. Leaves may be shared with other lists
+// or repeated, in which case a run of identical children shares one parent.
+func newListFromLeaves[T any](leaves []listNode[T], size int) *List[T] {
+	if size <= listSliceThreshold {
+		elements := make([]T, size)
+		if size > 0 {
+			copy(elements, leaves[0].(*listLeafNode[T]).children[:size])
+		}
+		return &List[T]{root: &listSliceNode[T]{elements: elements}, size: size}
+	}
+	nodes := leaves
+	for depth := uint(1); len(nodes) > 1; depth++ {
+		parents := make([]listNode[T], 0, (len(nodes)+listNodeSize-1)/listNodeSize)
+		for i := 0; i < len(nodes); i += listNodeSize {
+			children := nodes[i:min(i+listNodeSize, len(nodes))]
+			if i > 0 && slices.Equal(children, nodes[i-listNodeSize:i]) {
+				parents = append(parents, parents[len(parents)-1])
+				continue
+			}
+			parent := &listBranchNode[T]{d: depth}
+			copy(parent.children[:], children)
+			parents = append(parents, parent)
+		}
+		nodes = parents
+	}
+	return &List[T]{root: nodes[0], size: size}
+}
+
+// listChunksReverse is like listChunks but calls fn with the chunks from last
+// to first. The values within each chunk remain in list order.
+func listChunksReverse[T any](l *List[T], start, end int, fn func(chunk []T) bool) bool {
+	if start >= end {
+		return true
+	} else if sliceNode, ok := l.root.(*listSliceNode[T]); ok {
+		return fn(sliceNode.elements[start:end])
+	}
+	trieSize := l.size - l.tailLen()
+	if end > trieSize && !fn(l.tail.children[max(start-trieSize, 0):end-trieSize]) {
+		return false
+	} else if start < trieSize {
+		return listNodeChunksReverse(l.root, 0, l.origin+start, l.origin+min(end, trieSize), fn)
+	}
+	return true
+}
+
+func listNodeChunksReverse[T any](n listNode[T], base, lo, hi int, fn func(chunk []T) bool) bool {
+	switch n := n.(type) {
+	case *listBranchNode[T]:
+		span := 1 << (n.d * listNodeBits)
+		for i := min((hi-1-base)/span, listNodeSize-1); i >= 0 && base+(i+1)*span > lo; i-- {
+			if !listNodeChunksReverse(n.children[i], base+i*span, lo, hi, fn) {
+				return false
+			}
+		}
+	case *listLeafNode[T]:
+		return fn(n.children[max(lo-base, 0):min(hi-base, listNodeSize)])
+	}
+	return true
+}
+
+// lastLeaf returns the leaf holding the last element of a trie-backed list,
+// or nil for an empty or slice-backed list.
+func (l *List[T]) lastLeaf() *listLeafNode[T] {
+	if l.size == 0 {
+		return nil
+	} else if l.tail != nil {
+		return l.tail
+	}
+	pos := l.origin + l.size - 1
+	n := l.root
+	for {
+		switch node := n.(type) {
+		case *listBranchNode[T]:
+			n = node.children[(pos>>(node.d*listNodeBits))&listNodeMask]
+		case *listLeafNode[T]:
+			return node
+		default:
+			return nil
+		}
+	}
+}
+
+// All returns an iterator over the indexes and values of the list, in order.
+// The leaves are walked directly, without allocating a ListIterator.
+func (l *List[T]) All() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		var i int
+		listChunks(l, 0, l.size, func(chunk []T) bool {
+			for _, v := range chunk {
+				if !yield(i, v) {
+					return false
+				}
+				i++
+			}
+			return true
+		})
+	}
+}
+
+// Values returns an iterator over the values of the list, in order.
+func (l *List[T]) Values() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		listChunks(l, 0, l.size, func(chunk []T) bool {
+			for _, v := range chunk {
+				if !yield(v) {
+					return false
+				}
+			}
+			return true
+		})
+	}
+}
+
+// Backward returns an iterator over the indexes and values of the list, from
+// the last element to the first.
+func (l *List[T]) Backward() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) { l.EachReverse(yield) }
+}
+
+// EachReverse calls fn with the index and value of each element, from the
+// last to the first, until fn returns false. The leaves are walked directly
+// from the rightmost one, so unlike ranging with a ListIterator, nothing is
+// allocated.
+func (l *List[T]) EachReverse(fn func(index int, value T) bool) {
+	i := l.size - 1
+	listChunksReverse(l, 0, l.size, func(chunk []T) bool {
+		for j := len(chunk) - 1; j >= 0; j-- {
+			if !fn(i, chunk[j]) {
+				return false
+			}
+			i--
+		}
+		return true
+	})
+}
+
+// ForEachRange calls fn with the index and value of each element between
+// start and end index, in order, until fn returns false. It descends directly
+// to the leaf holding start and walks forward from there. Similar to Slice,
+// this method will panic if start or end are below zero or greater than the
+// list size, or if start is greater than end.
+func (l *List[T]) ForEachRange(start, end int, fn func(index int, value T) bool) {
+	if start < 0 || start > l.size {
+		panic(fmt.Sprintf("immutable.List.ForEachRange: start index %d out of bounds", start))
+	} else if end < 0 || end > l.size {
+		panic(fmt.Sprintf("immutable.List.ForEachRange: end index %d out of bounds", end))
+	} else if start > end {
+		panic(fmt.Sprintf("immutable.List.ForEachRange: invalid slice index: [%d:%d]", start, end))
+	}
+	i := start
+	listChunks(l, start, end, func(chunk []T) bool {
+		for _, v := range chunk {
+			if !fn(i, v) {
+				return false
+			}
+			i++
+		}
+		return true
+	})
+}
+
+// Windows returns an iterator over the overlapping sublists of l with size
+// elements, starting at each index in turn. Each window is a Slice of l. If
+// size is greater than the list size, there are no windows. This method will
+// panic if size is zero or less.
+func (l *List[T]) Windows(size int) iter.Seq[*List[T]] { return l.WindowsStep(size, 1) }
+
+// WindowsStep is like Windows but starts each window step elements after the
+// start of the previous one. This method will panic if size or step is zero
+// or less.
+func (l *List[T]) WindowsStep(size, step int) iter.Seq[*List[T]] {
+	assert(size > 0, "immutable.List.Windows: size must be positive")
+	assert(step > 0, "immutable.List.Windows: step must be positive")
+	return func(yield func(*List[T]) bool) {
+		for start := 0; start+size <= l.size; start += step {
+			if !yield(l.slice(start, start+size, nil)) {
+				return
+			}
+		}
+	}
+}
+
 // Iterator returns a new iterator for this list positioned at the first index.
 func (l *List[T]) Iterator() *ListIterator[T] {
 	itr := &ListIterator[T]{list: l}
@@ -301,21 +1519,87 @@ func (l *List[T]) Iterator() *ListIterator[T] {
 	return itr
 }
 
+// RangeParallel calls fn for every index and value of l, splitting the list
+// into up to workers ranges of consecutive indexes that are walked in their
+// own goroutines. If workers is zero or less, runtime.GOMAXPROCS(0) is used.
+// fn is called concurrently and must be safe for concurrent use; calls are in
+// no particular order. RangeParallel returns once all calls have returned.
+func (l *List[T]) RangeParallel(workers int, fn func(index int, value T)) {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	walk := func(start, end int) {
+		i := start
+		listChunks(l, start, end, func(chunk []T) bool {
+			for _, v := range chunk {
+				fn(i, v)
+				i++
+			}
+			return true
+		})
+	}
+	if workers == 1 || l.size <= listSliceThreshold {
+		walk(0, l.size)
+		return
+	}
+	parallelRange(l.size, workers, walk)
+}
+
 // ListBuilder represents an efficient builder for creating new Lists.
-type ListBuilder[T any] struct{ list *List[T] }
+type ListBuilder[T any] struct {
+	list  *List[T]
+	owner *owner           // owner of the nodes the builder may update in place
+	tail  *listLeafNode[T] // owned leaf holding the last element, if known
+}
 
 // NewListBuilder returns a new instance of ListBuilder.
-func NewListBuilder[T any]() *ListBuilder[T] { return &ListBuilder[T]{list: NewList[T]()} }
+func NewListBuilder[T any]() *ListBuilder[T] {
+	return &ListBuilder[T]{list: NewList[T](), owner: new(owner)}
+}
+
+// NewListBuilderFrom returns a new instance of ListBuilder holding the
+// elements of l. The builder shares l's nodes, so l is never modified: each
+// node is copied the first time an update touches it and updated in place
+// from then on.
+func NewListBuilderFrom[T any](l *List[T]) *ListBuilder[T] {
+	if l == nil {
+		return NewListBuilder[T]()
+	}
+	// Builders never keep a tail, so push l's into the trie first.
+	list := l.trie()
+	if list == l {
+		list = l.clone()
+	}
+	return &ListBuilder[T]{list: list, owner: new(owner)}
+}
 
 // List returns the current copy of the list.
 // The builder should not be used again after the list after this call.
 func (b *ListBuilder[T]) List() *List[T] {
 	assert(b.list != nil, "immutable.ListBuilder.List(): duplicate call to fetch list")
 	list := b.list
-	b.list = nil
+	b.list, b.tail = nil, nil
 	return list
 }
 
+// TryList is like List but returns ErrBuilderFinalized instead of panicking if
+// the list has already been fetched.
+func (b *ListBuilder[T]) TryList() (*List[T], error) {
+	if b.list == nil {
+		return nil, ErrBuilderFinalized
+	}
+	return b.List(), nil
+}
+
+// Snapshot returns the current state of the list without invalidating the
+// builder. Later updates copy each node still referenced by the snapshot the
+// first time they touch it, so the snapshot never observes them.
+func (b *ListBuilder[T]) Snapshot() *List[T] {
+	assert(b.list != nil, "immutable.ListBuilder: builder invalid after List() invocation")
+	b.owner, b.tail = new(owner), nil
+	return b.list.clone()
+}
+
 // Len returns the number of elements in the underlying list.
 func (b *ListBuilder[T]) Len() int {
 	assert(b.list != nil, "immutable.ListBuilder: builder invalid after List() invocation")
@@ -331,31 +1615,91 @@ func (b *ListBuilder[T]) Get(index int) T {
 // Set updates the value at the given index.
 func (b *ListBuilder[T]) Set(index int, value T) {
 	assert(b.list != nil, "immutable.ListBuilder: builder invalid after List() invocation")
-	b.list = b.list.set(index, value, true)
+	b.list = b.list.set(index, value, b.owner)
+}
+
+// UpdateAt replaces the value at index with the result of fn applied to it.
+func (b *ListBuilder[T]) UpdateAt(index int, fn func(old T) T) {
+	assert(b.list != nil, "immutable.ListBuilder: builder invalid after List() invocation")
+	b.list = b.list.updateAt(index, fn, b.owner)
+}
+
+// Swap exchanges the elements at indexes i and j.
+func (b *ListBuilder[T]) Swap(i, j int) {
+	assert(b.list != nil, "immutable.ListBuilder: builder invalid after List() invocation")
+	b.list = b.list.swap(i, j, b.owner)
 }
 
 // Append adds value to the end of the list.
 func (b *ListBuilder[T]) Append(value T) {
 	assert(b.list != nil, "immutable.ListBuilder: builder invalid after List() invocation")
-	b.list = b.list.append(value, true)
+	// Until the next leaf boundary, write straight into the owned last leaf
+	// rather than descending from the root.
+	if pos := b.list.origin + b.list.size; b.tail != nil && pos&listNodeMask != 0 {
+		b.tail.children[pos&listNodeMask] = value
+		b.tail.occupied |= 1 << (pos & listNodeMask)
+		b.list.size++
+		return
+	}
+	b.list = b.list.append(value, b.owner)
+	b.tail = b.ownedTail()
+}
+
+// AppendSlice adds values to the end of the list.
+func (b *ListBuilder[T]) AppendSlice(values []T) {
+	assert(b.list != nil, "immutable.ListBuilder: builder invalid after List() invocation")
+	b.list = b.list.appendSlice(values, b.owner)
+	b.tail = b.ownedTail()
+}
+
+// ownedTail returns the last leaf of the list if the builder owns it, or nil.
+func (b *ListBuilder[T]) ownedTail() *listLeafNode[T] {
+	if leaf := b.list.lastLeaf(); leaf != nil && b.owner.owns(leaf.owner) {
+		return leaf
+	}
+	return nil
 }
 
 // Prepend adds value to the beginning of the list.
 func (b *ListBuilder[T]) Prepend(value T) {
 	assert(b.list != nil, "immutable.ListBuilder: builder invalid after List() invocation")
-	b.list = b.list.prepend(value, true)
+	b.list = b.list.prepend(value, b.owner)
+	b.tail = nil
+}
+
+// PrependSlice adds values to the beginning of the list in order.
+func (b *ListBuilder[T]) PrependSlice(values []T) {
+	assert(b.list != nil, "immutable.ListBuilder: builder invalid after List() invocation")
+	b.list = b.list.prependSlice(values, b.owner)
+	b.tail = nil
+}
+
+// InsertAt inserts value before index, shifting the later elements right.
+func (b *ListBuilder[T]) InsertAt(index int, value T) {
+	assert(b.list != nil, "immutable.ListBuilder: builder invalid after List() invocation")
+	b.list = b.list.insertAt(index, value, b.owner)
+	b.tail = nil
+}
+
+// RemoveAt removes the element at index, shifting the later elements left.
+func (b *ListBuilder[T]) RemoveAt(index int) {
+	assert(b.list != nil, "immutable.ListBuilder: builder invalid after List() invocation")
+	b.list = b.list.removeAt(index, b.owner)
+	b.tail = nil
 }
 
 // Slice updates the list with a sublist of elements between start and end index.
 func (b *ListBuilder[T]) Slice(start, end int) {
 	assert(b.list != nil, "immutable.ListBuilder: builder invalid after List() invocation")
-	b.list = b.list.slice(start, end, true)
+	b.list = b.list.slice(start, end, b.owner)
+	b.tail = nil
 }
 
-// Iterator returns a new iterator for the underlying list.
+// Iterator returns a new iterator over a snapshot of the underlying list, so
+// the builder may be updated during iteration. As after Snapshot, each node
+// the iterator shares is copied by the next update that touches it.
 func (b *ListBuilder[T]) Iterator() *ListIterator[T] {
-	assert(b.list != nil, "immutable.ListBuilder: builder invalid after List() invocation")
-	return b.list.Iterator()
+	return b.Snapshot().Iterator()
 }
 
 // Contains returns true if the underlying list contains the given value.
@@ -370,7 +1714,44 @@ func (b *ListBuilder[T]) ContainsFunc(value T, equal func(a, b T) bool) bool {
 	return b.list.ContainsFunc(value, equal)
 }
 
+// IndexOf returns the index of the first value equal to value in the underlying list, or -1.
+func (b *ListBuilder[T]) IndexOf(value T) int {
+	assert(b.list != nil, "immutable.ListBuilder: builder invalid after List() invocation")
+	return b.list.IndexOf(value)
+}
+
+// IndexFunc returns the index of the first value in the underlying list matching pred, or -1.
+func (b *ListBuilder[T]) IndexFunc(pred func(T) bool) int {
+	assert(b.list != nil, "immutable.ListBuilder: builder invalid after List() invocation")
+	return b.list.IndexFunc(pred)
+}
+
+// Reset discards the contents of the builder. A builder that has been reset
+// is valid again after List() and can be reused.
+func (b *ListBuilder[T]) Reset() {
+	b.list = NewList[T]()
+	b.owner, b.tail = new(owner), nil
+}
+
+// TakeAndReset returns the list and resets the builder for reuse.
+func (b *ListBuilder[T]) TakeAndReset() *List[T] {
+	list := b.List()
+	b.Reset()
+	return list
+}
+
 // ListIterator represents an ordered iterator over a list.
+//
+// The iterator's cursor rests on an element rather than between elements.
+// Next and Prev both return the element under the cursor and then move the
+// cursor one position forward or backward. Mixing them therefore yields the
+// element under the cursor again: after Next returns index i, Prev returns
+// index i+1, and after Prev returns index i, Next returns index i-1.
+//
+// Once the cursor moves before the first or past the last element the
+// iterator is done, and Next and Prev return an index of -1 until the cursor
+// is repositioned with First, Last or Seek. Slice-backed and trie-backed lists
+// behave identically.
 type ListIterator[T any] struct {
 	list  *List[T]
 	index int
@@ -378,8 +1759,25 @@ type ListIterator[T any] struct {
 	depth int
 }
 
+// Done returns true if the cursor is outside the list.
 func (itr *ListIterator[T]) Done() bool { return itr.index < 0 || itr.index >= itr.list.Len() }
 
+// Index returns the index that the next call to Next or Prev returns, or -1
+// if the iterator is done. Seeking to it later restores the position.
+func (itr *ListIterator[T]) Index() int {
+	if itr.Done() {
+		return -1
+	}
+	return itr.index
+}
+
+// Clone returns an independent iterator at the same position. Moving either
+// iterator does not affect the other.
+func (itr *ListIterator[T]) Clone() *ListIterator[T] {
+	other := *itr
+	return &other
+}
+
 // First positions the iterator on the first index.
 func (itr *ListIterator[T]) First() {
 	if itr.list.Len() != 0 {
@@ -405,7 +1803,47 @@ func (itr *ListIterator[T]) Seek(index int) {
 	itr.seek(index)
 }
 
+// Peek returns the current index and its value without moving the iterator,
+// so they match what the next call to Next or Prev returns. If the iterator
+// is done, it returns -1, the zero value and false.
+func (itr *ListIterator[T]) Peek() (index int, value T, ok bool) {
+	if itr.Done() {
+		return -1, value, false
+	}
+	if sliceNode, ok := itr.list.root.(*listSliceNode[T]); ok {
+		return itr.index, sliceNode.elements[itr.index], true
+	}
+	elem := &itr.stack[itr.depth]
+	return itr.index, elem.node.(*listLeafNode[T]).children[elem.index], true
+}
+
+// Skip moves the iterator n positions forward, or backward if n is negative,
+// without visiting the elements in between. The stack is only unwound as far
+// as the closest node holding both positions. If the new position is outside
+// the list, the iterator is done and false is returned.
+func (itr *ListIterator[T]) Skip(n int) bool {
+	if itr.Done() {
+		return false
+	}
+	index := itr.index + n
+	if index < 0 || index >= itr.list.Len() {
+		itr.index = max(min(index, itr.list.Len()), -1)
+		return false
+	}
+	oldPos, newPos := itr.list.origin+itr.index, itr.list.origin+index
+	for ; itr.depth > 0; itr.depth-- {
+		shift := (itr.stack[itr.depth].node.depth() + 1) * listNodeBits
+		if oldPos>>shift == newPos>>shift {
+			break
+		}
+	}
+	itr.index = index
+	itr.seek(index)
+	return true
+}
+
 // Next returns the current index and its value & moves the iterator forward.
+// Returns an index of -1 if the iterator is done.
 func (itr *ListIterator[T]) Next() (index int, value T) {
 	var empty T
 	if itr.Done() {
@@ -431,6 +1869,7 @@ func (itr *ListIterator[T]) Next() (index int, value T) {
 }
 
 // Prev returns the current index and value and moves the iterator backward.
+// Returns an index of -1 if the iterator is done.
 func (itr *ListIterator[T]) Prev() (index int, value T) {
 	var empty T
 	if itr.Done() {
@@ -457,6 +1896,15 @@ func (itr *ListIterator[T]) Prev() (index int, value T) {
 func (itr *ListIterator[T]) seek(index int) {
 	if _, ok := itr.list.root.(*listSliceNode[T]); ok {
 		return
+	} else if tail := itr.list.tail; tail != nil {
+		// The tail sits alone at the bottom of the stack while the cursor is
+		// in it, and the root is put back once the cursor leaves it.
+		if i := index - (itr.list.size - itr.list.tailLen()); i >= 0 {
+			itr.stack[0], itr.depth = listIteratorElem[T]{node: tail, index: i}, 0
+			return
+		} else if itr.depth == 0 {
+			itr.stack[0].node = itr.list.root
+		}
 	}
 	for {
 		elem := &itr.stack[itr.depth]
@@ -489,25 +1937,43 @@ const (
 type listNode[T any] interface {
 	depth() uint
 	get(index int) T
-	set(index int, v T, mutable bool) listNode[T]
+	set(index int, v T, o *owner) listNode[T]
+	setRange(index int, values []T, o *owner) listNode[T]
 	containsBefore(index int) bool
 	containsAfter(index int) bool
-	deleteBefore(index int, mutable bool) listNode[T]
-	deleteAfter(index int, mutable bool) listNode[T]
+	deleteBefore(index int, o *owner) listNode[T]
+	deleteAfter(index int, o *owner) listNode[T]
+}
+
+// fillOwner returns o, or if o is nil the owner stamped on the nodes a
+// persistent operation allocates and fills itself, such as the trie a slice
+// node is converted to. Those nodes are updated in place only by the
+// operation that creates them, before the result is returned.
+func fillOwner(o *owner) *owner {
+	if o == nil {
+		return transient
+	}
+	return o
 }
 
-// newListNode returns a leaf node for depth zero, otherwise returns a branch node.
-func newListNode[T any](depth uint) listNode[T] {
+// transient is the owner of the nodes filled by persistent operations; see
+// fillOwner.
+var transient = new(owner)
+
+// newListNode returns a leaf node for depth zero, otherwise returns a branch
+// node. The node is stamped with o.
+func newListNode[T any](depth uint, o *owner) listNode[T] {
 	if depth == 0 {
-		return &listLeafNode[T]{}
+		return &listLeafNode[T]{owner: o}
 	}
-	return &listBranchNode[T]{d: depth}
+	return &listBranchNode[T]{d: depth, owner: o}
 }
 
 // listBranchNode represents a branch of a List tree at a given depth.
 type listBranchNode[T any] struct {
 	d        uint // depth
 	children [listNodeSize]listNode[T]
+	owner    *owner // owner allowed to update the node in place
 }
 
 func (n *listBranchNode[T]) depth() uint { return n.d }
@@ -517,20 +1983,52 @@ func (n *listBranchNode[T]) get(index int) T {
 	return n.children[idx].get(index)
 }
 
-func (n *listBranchNode[T]) set(index int, v T, mutable bool) listNode[T] {
+func (n *listBranchNode[T]) set(index int, v T, o *owner) listNode[T] {
 	idx := (index >> (n.d * listNodeBits)) & listNodeMask
-	child := n.children[idx]
-	if child == nil {
-		child = newListNode[T](n.depth() - 1)
+	var other *listBranchNode[T]
+	if o.owns(n.owner) {
+		other = n
+	} else {
+		tmp := *n
+		tmp.owner = o
+		other = &tmp
+	}
+	if child := n.children[idx]; child != nil {
+		other.children[idx] = child.set(index, v, o)
+	} else {
+		// Freshly allocated children are never shared so they can be filled in place.
+		f := fillOwner(o)
+		other.children[idx] = newListNode[T](n.d-1, f).set(index, v, f)
 	}
+	return other
+}
+
+// setRange writes values to consecutive indexes starting at index. The caller
+// must ensure the range fits within the node's capacity.
+func (n *listBranchNode[T]) setRange(index int, values []T, o *owner) listNode[T] {
 	var other *listBranchNode[T]
-	if mutable {
+	if o.owns(n.owner) {
 		other = n
 	} else {
 		tmp := *n
+		tmp.owner = o
 		other = &tmp
 	}
-	other.children[idx] = child.set(index, v, mutable)
+	shift := n.d * listNodeBits
+	span := 1 << shift
+	for len(values) > 0 {
+		idx := (index >> shift) & listNodeMask
+		k := min(len(values), span-(index&(span-1)))
+		if child := other.children[idx]; child != nil {
+			other.children[idx] = child.setRange(index, values[:k], o)
+		} else {
+			// Freshly allocated children are never shared so they can be filled in place.
+			f := fillOwner(o)
+			other.children[idx] = newListNode[T](n.d-1, f).setRange(index, values[:k], f)
+		}
+		index += k
+		values = values[k:]
+	}
 	return other
 }
 
@@ -560,44 +2058,44 @@ func (n *listBranchNode[T]) containsAfter(index int) bool {
 	return false
 }
 
-func (n *listBranchNode[T]) deleteBefore(index int, mutable bool) listNode[T] {
+func (n *listBranchNode[T]) deleteBefore(index int, o *owner) listNode[T] {
 	if !n.containsBefore(index) {
 		return n
 	}
 	idx := (index >> (n.d * listNodeBits)) & listNodeMask
 	var other *listBranchNode[T]
-	if mutable {
+	if o.owns(n.owner) {
 		other = n
 		for i := 0; i < idx; i++ {
 			n.children[i] = nil
 		}
 	} else {
-		other = &listBranchNode[T]{d: n.d}
+		other = &listBranchNode[T]{d: n.d, owner: o}
 		copy(other.children[idx:][:], n.children[idx:][:])
 	}
 	if other.children[idx] != nil {
-		other.children[idx] = other.children[idx].deleteBefore(index, mutable)
+		other.children[idx] = other.children[idx].deleteBefore(index, o)
 	}
 	return other
 }
 
-func (n *listBranchNode[T]) deleteAfter(index int, mutable bool) listNode[T] {
+func (n *listBranchNode[T]) deleteAfter(index int, o *owner) listNode[T] {
 	if !n.containsAfter(index) {
 		return n
 	}
 	idx := (index >> (n.d * listNodeBits)) & listNodeMask
 	var other *listBranchNode[T]
-	if mutable {
+	if o.owns(n.owner) {
 		other = n
 		for i := idx + 1; i < len(n.children); i++ {
 			n.children[i] = nil
 		}
 	} else {
-		other = &listBranchNode[T]{d: n.d}
+		other = &listBranchNode[T]{d: n.d, owner: o}
 		copy(other.children[:idx+1], n.children[:idx+1])
 	}
 	if other.children[idx] != nil {
-		other.children[idx] = other.children[idx].deleteAfter(index, mutable)
+		other.children[idx] = other.children[idx].deleteAfter(index, o)
 	}
 	return other
 }
@@ -606,19 +2104,21 @@ func (n *listBranchNode[T]) deleteAfter(index int, mutable bool) listNode[T] {
 type listLeafNode[T any] struct {
 	children [listNodeSize]T
 	occupied uint32 // bitset with ones at occupied positions, position 0 is the LSB
+	owner    *owner // owner allowed to update the node in place
 }
 
 func (n *listLeafNode[T]) depth() uint { return 0 }
 
 func (n *listLeafNode[T]) get(index int) T { return n.children[index&listNodeMask] }
 
-func (n *listLeafNode[T]) set(index int, v T, mutable bool) listNode[T] {
+func (n *listLeafNode[T]) set(index int, v T, o *owner) listNode[T] {
 	idx := index & listNodeMask
 	var other *listLeafNode[T]
-	if mutable {
+	if o.owns(n.owner) {
 		other = n
 	} else {
 		tmp := *n
+		tmp.owner = o
 		other = &tmp
 	}
 	other.children[idx] = v
@@ -626,6 +2126,21 @@ func (n *listLeafNode[T]) set(index int, v T, mutable bool) listNode[T] {
 	return other
 }
 
+func (n *listLeafNode[T]) setRange(index int, values []T, o *owner) listNode[T] {
+	idx := index & listNodeMask
+	var other *listLeafNode[T]
+	if o.owns(n.owner) {
+		other = n
+	} else {
+		tmp := *n
+		tmp.owner = o
+		other = &tmp
+	}
+	copy(other.children[idx:], values)
+	other.occupied |= uint32((uint64(1)<<len(values))-1) << idx
+	return other
+}
+
 func (n *listLeafNode[T]) containsBefore(index int) bool {
 	idx := index & listNodeMask
 	return bits.TrailingZeros32(n.occupied) < idx
@@ -637,40 +2152,40 @@ func (n *listLeafNode[T]) containsAfter(index int) bool {
 	return lastSetPos > idx
 }
 
-func (n *listLeafNode[T]) deleteBefore(index int, mutable bool) listNode[T] {
+func (n *listLeafNode[T]) deleteBefore(index int, o *owner) listNode[T] {
 	if !n.containsBefore(index) {
 		return n
 	}
 	idx := index & listNodeMask
 	var other *listLeafNode[T]
-	if mutable {
+	if o.owns(n.owner) {
 		other = n
 		var empty T
 		for i := 0; i < idx; i++ {
 			other.children[i] = empty
 		}
 	} else {
-		other = &listLeafNode[T]{occupied: n.occupied}
+		other = &listLeafNode[T]{occupied: n.occupied, owner: o}
 		copy(other.children[idx:][:], n.children[idx:][:])
 	}
 	other.occupied &= ^((1 << idx) - 1)
 	return other
 }
 
-func (n *listLeafNode[T]) deleteAfter(index int, mutable bool) listNode[T] {
+func (n *listLeafNode[T]) deleteAfter(index int, o *owner) listNode[T] {
 	if !n.containsAfter(index) {
 		return n
 	}
 	idx := index & listNodeMask
 	var other *listLeafNode[T]
-	if mutable {
+	if o.owns(n.owner) {
 		other = n
 		var empty T
 		for i := idx + 1; i < len(n.children); i++ {
 			other.children[i] = empty
 		}
 	} else {
-		other = &listLeafNode[T]{occupied: n.occupied}
+		other = &listLeafNode[T]{occupied: n.occupied, owner: o}
 		copy(other.children[:idx+1][:], n.children[:idx+1][:])
 	}
 	other.occupied &= (1 << (idx + 1)) - 1
@@ -678,32 +2193,47 @@ func (n *listLeafNode[T]) deleteAfter(index int, mutable bool) listNode[T] {
 }
 
 // A list node which is implemented as a slice. Used for small lists.
-type listSliceNode[T any] struct{ elements []T }
+type listSliceNode[T any] struct {
+	elements []T
+	owner    *owner // owner allowed to update the node in place
+}
 
 func (n *listSliceNode[T]) depth() uint     { return 0 }
 func (n *listSliceNode[T]) get(index int) T { return n.elements[index] }
 
-func (n *listSliceNode[T]) set(index int, v T, mutable bool) listNode[T] {
-	if mutable {
+func (n *listSliceNode[T]) set(index int, v T, o *owner) listNode[T] {
+	if o.owns(n.owner) {
 		n.elements[index] = v
 		return n
 	}
 	newElements := make([]T, len(n.elements))
 	copy(newElements, n.elements)
 	newElements[index] = v
-	return &listSliceNode[T]{elements: newElements}
+	return &listSliceNode[T]{elements: newElements, owner: o}
+}
+
+func (n *listSliceNode[T]) setRange(index int, values []T, o *owner) listNode[T] {
+	if o.owns(n.owner) {
+		copy(n.elements[index:], values)
+		return n
+	}
+	newElements := make([]T, len(n.elements))
+	copy(newElements, n.elements)
+	copy(newElements[index:], values)
+	return &listSliceNode[T]{elements: newElements, owner: o}
 }
 
-func (n *listSliceNode[T]) containsBefore(index int) bool                    { return true }
-func (n *listSliceNode[T]) containsAfter(index int) bool                     { return true }
-func (n *listSliceNode[T]) deleteBefore(index int, mutable bool) listNode[T] { return n }
-func (n *listSliceNode[T]) deleteAfter(index int, mutable bool) listNode[T]  { return n }
+func (n *listSliceNode[T]) containsBefore(index int) bool                { return true }
+func (n *listSliceNode[T]) containsAfter(index int) bool                 { return true }
+func (n *listSliceNode[T]) deleteBefore(index int, o *owner) listNode[T] { return n }
+func (n *listSliceNode[T]) deleteAfter(index int, o *owner) listNode[T]  { return n }
 
-// toTrie converts a listSliceNode to a trie-based structure.
-func (n *listSliceNode[T]) toTrie(mutable bool) listNode[T] {
+// toTrie converts a listSliceNode to a trie-based structure whose nodes are
+// stamped with o.
+func (n *listSliceNode[T]) toTrie(o *owner) listNode[T] {
 	numElements := len(n.elements)
 	if numElements == 0 {
-		return &listLeafNode[T]{}
+		return &listLeafNode[T]{owner: o}
 	}
 	var leaves []listNode[T]
 	for i := 0; i < numElements; i += listNodeSize {
@@ -712,7 +2242,7 @@ func (n *listSliceNode[T]) toTrie(mutable bool) listNode[T] {
 			end = numElements
 		}
 		chunk := n.elements[i:end]
-		leaf := &listLeafNode[T]{}
+		leaf := &listLeafNode[T]{owner: o}
 		copy(leaf.children[:], chunk)
 		leaf.occupied = (uint32(1) << len(chunk)) - 1
 		leaves = append(leaves, leaf)
@@ -727,7 +2257,7 @@ func (n *listSliceNode[T]) toTrie(mutable bool) listNode[T] {
 				end = len(nodes)
 			}
 			chunk := nodes[i:end]
-			parent := &listBranchNode[T]{d: depth}
+			parent := &listBranchNode[T]{d: depth, owner: o}
 			copy(parent.children[:], chunk)
 			parents = append(parents, parent)
 		}
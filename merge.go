@@ -0,0 +1,116 @@
+package immutable
+
+// Merge3State records which versions of a map contain a key during a
+// three-way merge.
+type Merge3State uint8
+
+const (
+	// Merge3InBase is set if the key is in the base map.
+	Merge3InBase Merge3State = 1 << iota
+	// Merge3InLeft is set if the key is in the left map.
+	Merge3InLeft
+	// Merge3InRight is set if the key is in the right map.
+	Merge3InRight
+)
+
+// Conflict describes a key that Merge3 could not merge. Values are the zero
+// value for versions whose State flag is unset.
+type Conflict[K, V any] struct {
+	Key   K
+	Base  V
+	Left  V
+	Right V
+	State Merge3State
+}
+
+// Merge3 merges left and right, two maps derived from base, into one.
+//
+// A key added, modified or deleted on only one side takes that side's entry,
+// and a key changed identically on both sides takes the common entry. Values
+// are compared with DeepEqual. Any other key is a conflict, which is passed to
+// resolve if it is not nil; resolve returns the merged value and true, or
+// false to leave the conflict unresolved. Unresolved conflicts keep base's
+// entry in the merged map and are returned, in no particular order.
+//
+// Only the subtrees in which left and right differ from base are visited, so
+// when both sides are derived from base with Set and Delete the cost is
+// proportional to the number of changes rather than the size of the maps.
+// This is also why the maps are taken as *Map rather than ReadonlyMap: the
+// shared subtrees can only be found by comparing the maps' trees.
+func Merge3[K, V any](base, left, right *Map[K, V], resolve func(key K, base, left, right V, state Merge3State) (V, bool)) (*Map[K, V], []Conflict[K, V]) {
+	var hasher Hasher[K]
+	for _, m := range []*Map[K, V]{base, left, right} {
+		if m != nil && m.hasher != nil {
+			hasher = m.hasher
+			break
+		}
+	}
+	if base == nil {
+		base = NewMap[K, V](hasher)
+	}
+	if left == nil {
+		left = NewMap[K, V](hasher)
+	}
+	if right == nil {
+		right = NewMap[K, V](hasher)
+	}
+
+	// Gather the keys in subtrees that either side does not share with base.
+	changed := NewMap[K, struct{}](hasher)
+	collect := func(key K, _ V) bool {
+		changed = changed.Set(key, struct{}{})
+		return true
+	}
+	for _, m := range []*Map[K, V]{left, right} {
+		mapUnsharedEntries(base.root, m.root, collect)
+		mapUnsharedEntries(m.root, base.root, collect)
+	}
+
+	// Left's changes are already in place, so only right's and the
+	// conflicts need to be applied to it.
+	other := left
+	var conflicts []Conflict[K, V]
+	itr := changed.Iterator()
+	for !itr.Done() {
+		key, _, _ := itr.Next()
+		b, bok := base.Get(key)
+		l, lok := left.Get(key)
+		r, rok := right.Get(key)
+		leftChanged := lok != bok || (lok && !DeepEqual(l, b))
+		rightChanged := rok != bok || (rok && !DeepEqual(r, b))
+		if !rightChanged || (lok == rok && (!lok || DeepEqual(l, r))) {
+			continue
+		} else if !leftChanged {
+			other = mapSetOrDelete(other, key, r, rok)
+			continue
+		}
+
+		var state Merge3State
+		if bok {
+			state |= Merge3InBase
+		}
+		if lok {
+			state |= Merge3InLeft
+		}
+		if rok {
+			state |= Merge3InRight
+		}
+		if resolve != nil {
+			if v, ok := resolve(key, b, l, r, state); ok {
+				other = other.Set(key, v)
+				continue
+			}
+		}
+		other = mapSetOrDelete(other, key, b, bok)
+		conflicts = append(conflicts, Conflict[K, V]{Key: key, Base: b, Left: l, Right: r, State: state})
+	}
+	return other, conflicts
+}
+
+// mapSetOrDelete returns m with key set to value if ok, or deleted otherwise.
+func mapSetOrDelete[K, V any](m *Map[K, V], key K, value V, ok bool) *Map[K, V] {
+	if ok {
+		return m.Set(key, value)
+	}
+	return m.Delete(key)
+}
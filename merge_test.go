@@ -0,0 +1,171 @@
+package immutable
+
+import "testing"
+
+// countingHasher wraps a hasher, counting calls to Hash.
+type countingHasher[K any] struct {
+	Hasher[K]
+	n *int
+}
+
+func (h countingHasher[K]) Hash(key K) uint32 {
+	*h.n++
+	return h.Hasher.Hash(key)
+}
+
+func TestMerge3(t *testing.T) {
+	// Each case gives the value of a key in the base, left and right maps and
+	// the expected merged value, where 0 means the key is absent.
+	cases := []struct {
+		base, left, right int
+		want              int
+		conflict          bool
+	}{
+		{0, 0, 0, 0, false},
+		{0, 0, 2, 2, false}, // added on right
+		{0, 2, 0, 2, false}, // added on left
+		{0, 2, 2, 2, false}, // added identically
+		{0, 2, 3, 0, true},  // added differently
+		{1, 1, 1, 1, false},
+		{1, 1, 2, 2, false}, // modified on right
+		{1, 1, 0, 0, false}, // deleted on right
+		{1, 2, 1, 2, false}, // modified on left
+		{1, 0, 1, 0, false}, // deleted on left
+		{1, 2, 2, 2, false}, // modified identically
+		{1, 0, 0, 0, false}, // deleted on both
+		{1, 2, 3, 1, true},  // modified differently
+		{1, 2, 0, 1, true},  // modified on left, deleted on right
+		{1, 0, 2, 1, true},  // deleted on left, modified on right
+	}
+
+	// Surround the cases with unchanged keys so the maps are tries.
+	const n = 1000
+	base := NewMap[int, int](nil)
+	for i := 0; i < n; i++ {
+		base = base.Set(n+i, i)
+	}
+	for i, c := range cases {
+		if c.base != 0 {
+			base = base.Set(i, c.base)
+		}
+	}
+	left, right := base, base
+	for i, c := range cases {
+		left = mapSetOrDelete(left, i, c.left, c.left != 0)
+		right = mapSetOrDelete(right, i, c.right, c.right != 0)
+	}
+
+	check := func(t *testing.T, merged *Map[int, int], want func(i int) int) {
+		t.Helper()
+		for i := range cases {
+			v, ok := merged.Get(i)
+			if w := want(i); w == 0 && ok {
+				t.Errorf("case %d: expected key to be absent, got %d", i, v)
+			} else if w != 0 && v != w {
+				t.Errorf("case %d: expected %d, got %d (ok=%v)", i, w, v, ok)
+			}
+		}
+		for i := 0; i < n; i++ {
+			if v, _ := merged.Get(n + i); v != i {
+				t.Fatalf("unchanged key %d: expected %d, got %d", n+i, i, v)
+			}
+		}
+	}
+
+	t.Run("Report", func(t *testing.T) {
+		merged, conflicts := Merge3(base, left, right, nil)
+		check(t, merged, func(i int) int { return cases[i].want })
+
+		var reported int
+		for _, c := range conflicts {
+			tc := cases[c.Key]
+			if !tc.conflict {
+				t.Errorf("case %d: unexpected conflict", c.Key)
+			} else if c.Base != tc.base || c.Left != tc.left || c.Right != tc.right {
+				t.Errorf("case %d: unexpected conflict values: %+v", c.Key, c)
+			}
+			reported++
+		}
+		var expected int
+		for _, c := range cases {
+			if c.conflict {
+				expected++
+			}
+		}
+		if reported != expected {
+			t.Fatalf("expected %d conflicts, got %d", expected, reported)
+		}
+	})
+
+	t.Run("Resolve", func(t *testing.T) {
+		merged, conflicts := Merge3(base, left, right, func(key, b, l, r int, state Merge3State) (int, bool) {
+			c := cases[key]
+			var want Merge3State
+			if c.base != 0 {
+				want |= Merge3InBase
+			}
+			if c.left != 0 {
+				want |= Merge3InLeft
+			}
+			if c.right != 0 {
+				want |= Merge3InRight
+			}
+			if state != want {
+				t.Errorf("case %d: expected state %03b, got %03b", key, want, state)
+			}
+			// Leave deletions unresolved.
+			return 10 * (l + r), state&Merge3InLeft != 0 && state&Merge3InRight != 0
+		})
+		check(t, merged, func(i int) int {
+			if c := cases[i]; c.conflict && c.left != 0 && c.right != 0 {
+				return 10 * (c.left + c.right)
+			}
+			return cases[i].want
+		})
+		if len(conflicts) != 2 {
+			t.Fatalf("expected deletion conflicts to be reported, got %+v", conflicts)
+		}
+	})
+
+	t.Run("Nil", func(t *testing.T) {
+		m := NewMap[string, int](nil).Set("a", 1)
+		merged, conflicts := Merge3(nil, m, nil, nil)
+		if v, ok := merged.Get("a"); !ok || v != 1 || len(conflicts) != 0 {
+			t.Fatalf("unexpected merge: %v %v %+v", v, ok, conflicts)
+		}
+		merged, _ = Merge3[string, int](nil, nil, nil, nil)
+		if merged.Len() != 0 {
+			t.Fatalf("unexpected length %d", merged.Len())
+		}
+	})
+
+	t.Run("SharedSubtrees", func(t *testing.T) {
+		var hashes int
+		base := NewMap[int, int](countingHasher[int]{NewHasher(0), &hashes})
+		for i := 0; i < 100000; i++ {
+			base = base.Set(i, i)
+		}
+		left := base.Set(1, -1).Delete(2)
+		right := base.Set(3, -3).Set(-4, 4)
+
+		hashes = 0
+		merged, conflicts := Merge3(base, left, right, nil)
+		if len(conflicts) != 0 {
+			t.Fatalf("unexpected conflicts: %+v", conflicts)
+		}
+		for k, want := range map[int]int{1: -1, 3: -3, -4: 4, 5: 5} {
+			if v, _ := merged.Get(k); v != want {
+				t.Fatalf("key %d: expected %d, got %d", k, want, v)
+			}
+		}
+		if _, ok := merged.Get(2); ok {
+			t.Fatal("expected deleted key to be absent")
+		}
+		if hashes > 1000 {
+			t.Fatalf("expected unchanged subtrees to be skipped, hashed %d keys", hashes)
+		}
+		if merged.Len() != 100000 {
+			t.Fatalf("unexpected length %d", merged.Len())
+		}
+	})
+}
@@ -16,21 +16,16 @@ type Queue[T any] struct {
 // NewQueue returns a new queue containing the provided values in order.
 // Values are placed on the front for optimal subsequent dequeues.
 func NewQueue[T any](values ...T) *Queue[T] {
-	if len(values) == 0 {
-		return &Queue[T]{front: NewList[T](), back: NewList[T](), size: 0}
-	}
-	return &Queue[T]{front: NewList(values...), back: NewList[T](), size: len(values)}
+	return NewQueueOf(values)
 }
 
 // NewQueueOf returns a new queue containing the provided slice of values in order.
+// The slice is not retained; NewList already makes the single defensive copy.
 func NewQueueOf[T any](values []T) *Queue[T] {
 	if len(values) == 0 {
 		return &Queue[T]{front: NewList[T](), back: NewList[T](), size: 0}
 	}
-	// Copy values to avoid aliasing.
-	buf := make([]T, len(values))
-	copy(buf, values)
-	return &Queue[T]{front: NewList(buf...), back: NewList[T](), size: len(buf)}
+	return &Queue[T]{front: NewList(values...), back: NewList[T](), size: len(values)}
 }
 
 // Len returns the total number of elements in the queue.
@@ -84,8 +79,7 @@ func (q *Queue[T]) Dequeue() (next *Queue[T], value T, ok bool) {
 
 	// If front has elements, pop from there.
 	if q.front != nil && q.front.Len() > 0 {
-		v := q.front.Get(0)
-		newFront := q.front.Slice(1, q.front.Len())
+		newFront, v, _ := q.front.Shift()
 		nextQ := &Queue[T]{front: newFront, back: q.back, size: q.size - 1}
 		nextQ = nextQ.normalize()
 		return nextQ, v, true
@@ -95,8 +89,7 @@ func (q *Queue[T]) Dequeue() (next *Queue[T], value T, ok bool) {
 	if q.back != nil && q.back.Len() > 0 {
 		// After normalization, front will be non-empty.
 		norm := q.normalize()
-		v := norm.front.Get(0)
-		newFront := norm.front.Slice(1, norm.front.Len())
+		newFront, v, _ := norm.front.Shift()
 		nextQ := &Queue[T]{front: newFront, back: norm.back, size: q.size - 1}
 		return nextQ, v, true
 	}
@@ -164,6 +157,13 @@ func (itr *QueueIterator[T]) Done() bool {
 	return itr.stage == -1
 }
 
+// Clone returns an independent iterator at the same position. Moving either
+// iterator does not affect the other.
+func (itr *QueueIterator[T]) Clone() *QueueIterator[T] {
+	other := *itr
+	return &other
+}
+
 // First positions the iterator at the first element.
 func (itr *QueueIterator[T]) First() {
 	if itr.q == nil || itr.q.size == 0 {
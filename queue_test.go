@@ -78,3 +78,35 @@ func TestQueueNormalizeBoundary(t *testing.T) {
 		t.Fatalf("expected empty after full drain")
 	}
 }
+
+func TestNewQueueOfNoAliasing(t *testing.T) {
+	for _, n := range []int{5, 1000} {
+		values := make([]int, n)
+		for i := range values {
+			values[i] = i
+		}
+		q := NewQueueOf(values)
+		for i := range values {
+			values[i] = -1
+		}
+		itr := q.Iterator()
+		for !itr.Done() {
+			idx, v, _ := itr.Next()
+			if v != idx {
+				t.Fatalf("n=%d: expected %d at index %d, got %d", n, idx, idx, v)
+			}
+		}
+	}
+}
+
+func BenchmarkNewQueueOf(b *testing.B) {
+	values := make([]int, 1_000_000)
+	for i := range values {
+		values[i] = i
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = NewQueueOf(values)
+	}
+}
@@ -0,0 +1,68 @@
+package immutable
+
+import "sync/atomic"
+
+// Ref is an atomically updated reference to a value, intended for publishing
+// successive versions of a collection such as a *Map or *List: one or more
+// writers install new versions while any number of readers load the current
+// one without locking. The zero value holds the zero value of T. A Ref must
+// not be copied after first use.
+type Ref[T any] struct {
+	p atomic.Pointer[T]
+}
+
+// NewRef returns a new reference holding v.
+func NewRef[T any](v T) *Ref[T] {
+	r := &Ref[T]{}
+	r.p.Store(&v)
+	return r
+}
+
+// deref returns the value p points to, or the zero value if p is nil.
+func deref[T any](p *T) T {
+	if p == nil {
+		var empty T
+		return empty
+	}
+	return *p
+}
+
+// Load returns the current value.
+func (r *Ref[T]) Load() T { return deref(r.p.Load()) }
+
+// Store sets the current value to v.
+func (r *Ref[T]) Store(v T) { r.p.Store(&v) }
+
+// Swap sets the current value to v and returns the previous value.
+func (r *Ref[T]) Swap(v T) T { return deref(r.p.Swap(&v)) }
+
+// CompareAndSwap sets the current value to new if it is equal to old, and
+// reports whether it did. Values are compared with ==, so CompareAndSwap
+// panics if T's dynamic type is not comparable. Collections are compared by
+// pointer, not content.
+func (r *Ref[T]) CompareAndSwap(old, new T) bool {
+	for {
+		p := r.p.Load()
+		if any(deref(p)) != any(old) {
+			return false
+		} else if r.p.CompareAndSwap(p, &new) {
+			return true
+		}
+	}
+}
+
+// Update sets the current value to fn applied to it and returns the result.
+// If another writer changes the value first, fn is called again with the new
+// value, so fn must be free of side effects. Because the collections are
+// immutable, this gives lock-free read-modify-write:
+//
+//	ref.Update(func(m *Map[string, int]) *Map[string, int] { return m.Set("a", 1) })
+func (r *Ref[T]) Update(fn func(T) T) T {
+	for {
+		p := r.p.Load()
+		next := fn(deref(p))
+		if r.p.CompareAndSwap(p, &next) {
+			return next
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package immutable
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestRef(t *testing.T) {
+	t.Run("Zero", func(t *testing.T) {
+		var r Ref[*List[int]]
+		if r.Load() != nil {
+			t.Fatal("expected nil")
+		}
+		l := NewList(1)
+		if !r.CompareAndSwap(nil, l) {
+			t.Fatal("expected swap from zero value")
+		} else if r.Load() != l {
+			t.Fatal("unexpected value")
+		}
+	})
+
+	t.Run("Swap", func(t *testing.T) {
+		a, b := NewMap[int, int](nil), NewMap[int, int](nil).Set(1, 1)
+		r := NewRef(a)
+		if r.Load() != a {
+			t.Fatal("unexpected initial value")
+		}
+		if prev := r.Swap(b); prev != a {
+			t.Fatal("expected previous value")
+		}
+		if r.CompareAndSwap(a, a) {
+			t.Fatal("expected swap to fail")
+		} else if !r.CompareAndSwap(b, a) {
+			t.Fatal("expected swap to succeed")
+		}
+		r.Store(b)
+		if r.Load() != b {
+			t.Fatal("unexpected stored value")
+		}
+	})
+
+	t.Run("NotComparable", func(t *testing.T) {
+		var r string
+		func() {
+			defer func() { r = recover().(error).Error() }()
+			NewRef([]int{1}).CompareAndSwap([]int{1}, nil)
+		}()
+		if r != "runtime error: comparing uncomparable type []int" {
+			t.Fatalf("unexpected panic: %q", r)
+		}
+	})
+
+	t.Run("ConcurrentUpdate", func(t *testing.T) {
+		const workers, keys, n = 8, 16, 1024
+		r := NewRef(NewMap[int, int](nil))
+
+		var wg sync.WaitGroup
+		for w := 0; w < workers; w++ {
+			wg.Add(1)
+			go func(w int) {
+				defer wg.Done()
+				for i := 0; i < n; i++ {
+					key := (w + i) % keys
+					r.Update(func(m *Map[int, int]) *Map[int, int] {
+						v, _ := m.Get(key)
+						return m.Set(key, v+1)
+					})
+					// Readers always see a complete version.
+					if m := r.Load(); m.Len() > keys {
+						t.Errorf("unexpected length %d", m.Len())
+					}
+				}
+			}(w)
+		}
+		wg.Wait()
+
+		m := r.Load()
+		var sum int
+		itr := m.Iterator()
+		for !itr.Done() {
+			_, v, _ := itr.Next()
+			sum += v
+		}
+		if sum != workers*n {
+			t.Fatalf("expected sum %d, got %d", workers*n, sum)
+		}
+		for key := 0; key < keys; key++ {
+			if v, _ := m.Get(key); v != workers*n/keys {
+				t.Fatalf("key %d: expected %d, got %d", key, workers*n/keys, v)
+			}
+		}
+	})
+}
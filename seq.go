@@ -0,0 +1,101 @@
+package immutable
+
+// Seq is implemented by iterators that yield one value per step, so that
+// algorithms can be written once for every collection in the package. Next
+// returns the next value and true, or the zero value and false once the
+// iterator is exhausted.
+//
+// SetIterator and SortedSetIterator implement Seq directly. ListIterator,
+// QueueIterator and BatchListIterator provide one through their AsSeq methods.
+type Seq[T any] interface {
+	Next() (value T, ok bool)
+}
+
+// Seq2 is implemented by iterators that yield a key/value pair per step. Next
+// returns the next pair and true, or zero values and false once the iterator is
+// exhausted.
+//
+// MapIterator and SortedMapIterator implement Seq2 directly. ListIterator,
+// QueueIterator and BatchListIterator provide one, keyed by index, through
+// their AsSeq2 methods.
+type Seq2[K, V any] interface {
+	Next() (key K, value V, ok bool)
+}
+
+var (
+	_ Seq[int]          = (*SetIterator[int])(nil)
+	_ Seq[int]          = (*SortedSetIterator[int])(nil)
+	_ Seq2[string, int] = (*MapIterator[string, int])(nil)
+	_ Seq2[string, int] = (*SortedMapIterator[string, int])(nil)
+)
+
+// indexedIterator is implemented by the list iterators, whose Next returns an
+// index of -1 rather than an ok flag once exhausted.
+type indexedIterator[T any] interface {
+	Done() bool
+	Next() (index int, value T)
+}
+
+// indexedSeq adapts an indexedIterator to Seq.
+type indexedSeq[T any] struct{ itr indexedIterator[T] }
+
+func (s indexedSeq[T]) Next() (value T, ok bool) {
+	if s.itr.Done() {
+		return value, false
+	}
+	_, value = s.itr.Next()
+	return value, true
+}
+
+// indexedSeq2 adapts an indexedIterator to Seq2.
+type indexedSeq2[T any] struct{ itr indexedIterator[T] }
+
+func (s indexedSeq2[T]) Next() (index int, value T, ok bool) {
+	if s.itr.Done() {
+		return 0, value, false
+	}
+	index, value = s.itr.Next()
+	return index, value, true
+}
+
+// AsSeq returns a Seq yielding the iterator's remaining values. The Seq
+// shares the iterator's position, so advancing one advances the other.
+func (itr *ListIterator[T]) AsSeq() Seq[T] { return indexedSeq[T]{itr} }
+
+// AsSeq2 returns a Seq2 yielding the iterator's remaining indexes and values.
+// The Seq2 shares the iterator's position, so advancing one advances the other.
+func (itr *ListIterator[T]) AsSeq2() Seq2[int, T] { return indexedSeq2[T]{itr} }
+
+// AsSeq returns a Seq yielding the iterator's remaining values. The Seq
+// shares the iterator's position, so advancing one advances the other.
+func (itr *BatchListIterator[T]) AsSeq() Seq[T] { return indexedSeq[T]{itr} }
+
+// AsSeq2 returns a Seq2 yielding the iterator's remaining indexes and values.
+// The Seq2 shares the iterator's position, so advancing one advances the other.
+func (itr *BatchListIterator[T]) AsSeq2() Seq2[int, T] { return indexedSeq2[T]{itr} }
+
+// queueSeq adapts a QueueIterator to Seq.
+type queueSeq[T any] struct{ itr *QueueIterator[T] }
+
+func (s queueSeq[T]) Next() (value T, ok bool) {
+	_, value, ok = s.itr.Next()
+	return value, ok
+}
+
+// queueSeq2 adapts a QueueIterator to Seq2.
+type queueSeq2[T any] struct{ itr *QueueIterator[T] }
+
+func (s queueSeq2[T]) Next() (index int, value T, ok bool) {
+	if index, value, ok = s.itr.Next(); !ok {
+		return 0, value, false
+	}
+	return index, value, true
+}
+
+// AsSeq returns a Seq yielding the iterator's remaining values. The Seq
+// shares the iterator's position, so advancing one advances the other.
+func (itr *QueueIterator[T]) AsSeq() Seq[T] { return queueSeq[T]{itr} }
+
+// AsSeq2 returns a Seq2 yielding the iterator's remaining indexes and values.
+// The Seq2 shares the iterator's position, so advancing one advances the other.
+func (itr *QueueIterator[T]) AsSeq2() Seq2[int, T] { return queueSeq2[T]{itr} }
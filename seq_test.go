@@ -0,0 +1,90 @@
+package immutable
+
+import (
+	"slices"
+	"testing"
+)
+
+// seqValues and seq2Keys are written once against the Seq interfaces and used
+// with every collection below.
+func seqValues[T any](s Seq[T]) []T {
+	var values []T
+	for v, ok := s.Next(); ok; v, ok = s.Next() {
+		values = append(values, v)
+	}
+	return values
+}
+
+func seq2Keys[K, V any](s Seq2[K, V]) []K {
+	var keys []K
+	for k, _, ok := s.Next(); ok; k, _, ok = s.Next() {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func TestSeq(t *testing.T) {
+	want := []int{1, 2, 3, 4}
+
+	t.Run("Values", func(t *testing.T) {
+		batch := NewBatchListBuilder[int](2)
+		batch.Prepend(1)
+		batch.Append(2)
+		batch.Append(3)
+		batch.Append(4)
+
+		for name, seq := range map[string]Seq[int]{
+			"List":             NewList(want...).Iterator().AsSeq(),
+			"Queue":            NewQueue(1, 2).Enqueue(3).Enqueue(4).Iterator().AsSeq(),
+			"BatchListBuilder": batch.Iterator().AsSeq(),
+			"SortedSet":        NewSortedSet[int](nil, 4, 3, 2, 1).Iterator(),
+		} {
+			if got := seqValues(seq); !slices.Equal(got, want) {
+				t.Errorf("%s: expected %v, got %v", name, want, got)
+			}
+		}
+
+		got := seqValues[int](NewSet[int](nil, want...).Iterator())
+		slices.Sort(got)
+		if !slices.Equal(got, want) {
+			t.Errorf("Set: expected %v, got %v", want, got)
+		}
+	})
+
+	t.Run("Pairs", func(t *testing.T) {
+		for name, seq := range map[string]Seq2[int, int]{
+			"List":      NewList(5, 6, 7, 8).Iterator().AsSeq2(),
+			"Queue":     NewQueue(5, 6).Enqueue(7).Enqueue(8).Iterator().AsSeq2(),
+			"SortedMap": NewSortedMap[int, int](nil).Set(2, 0).Set(0, 0).Set(3, 0).Set(1, 0).Iterator(),
+		} {
+			if got := seq2Keys(seq); !slices.Equal(got, []int{0, 1, 2, 3}) {
+				t.Errorf("%s: unexpected keys %v", name, got)
+			}
+		}
+
+		got := seq2Keys[int, int](NewMap[int, int](nil).Set(3, 0).Set(1, 0).Iterator())
+		slices.Sort(got)
+		if !slices.Equal(got, []int{1, 3}) {
+			t.Errorf("Map: unexpected keys %v", got)
+		}
+	})
+
+	t.Run("SharedPosition", func(t *testing.T) {
+		itr := NewList(want...).Iterator()
+		itr.Next()
+		seq := itr.AsSeq()
+		if v, ok := seq.Next(); !ok || v != 2 {
+			t.Fatalf("Next()=<%v,%v>", v, ok)
+		}
+		if i, v := itr.Next(); i != 2 || v != 3 {
+			t.Fatalf("Next()=<%v,%v>", i, v)
+		}
+		seq.Next()
+		if v, ok := seq.Next(); ok || v != 0 {
+			t.Fatalf("expected exhausted seq, got <%v,%v>", v, ok)
+		}
+		if i, v, ok := itr.AsSeq2().Next(); ok || i != 0 || v != 0 {
+			t.Fatalf("expected exhausted seq, got <%v,%v,%v>", i, v, ok)
+		}
+	})
+}
@@ -71,6 +71,73 @@ func BenchmarkSet_Operations(b *testing.B) {
 	}
 }
 
+// Benchmark Set algebra on a set and a version derived from it by 10 changes,
+// and on an unrelated copy with the same values, which shares no nodes.
+func BenchmarkSet_Algebra(b *testing.B) {
+	const size = 1_000_000
+	builder := NewSetBuilder[int](nil)
+	for i := 0; i < size; i++ {
+		builder.Set(i)
+	}
+	a := builder.Snapshot()
+	derived := a
+	for i := 0; i < 5; i++ {
+		derived = derived.Add(size + i).Delete(i * 1000)
+	}
+	unrelated := NewSet[int](nil, derived.Items()...)
+
+	for _, other := range []struct {
+		name string
+		s    Set[int]
+	}{{"Derived", derived}, {"Unrelated", unrelated}} {
+		b.Run("Union/"+other.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_ = a.Union(other.s)
+			}
+		})
+		b.Run("Intersection/"+other.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_ = a.Intersection(other.s)
+			}
+		})
+		b.Run("Difference/"+other.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_ = a.Difference(other.s)
+			}
+		})
+	}
+}
+
+// Benchmark trimming the oldest 90% of a sorted set, structurally and one
+// value at a time.
+func BenchmarkSortedSet_DeleteBefore(b *testing.B) {
+	const size = 1_000_000
+	builder := NewSortedSetBuilder[int](nil)
+	for i := 0; i < size; i++ {
+		builder.Set(i)
+	}
+	s := builder.SortedSet()
+
+	b.Run("DeleteBefore", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = s.DeleteBefore(size * 9 / 10)
+		}
+	})
+	b.Run("Loop", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			other := s
+			for v := 0; v < size*9/10; v++ {
+				other = other.Delete(v)
+			}
+		}
+	})
+}
+
 // Benchmark SortedSet operations
 func BenchmarkSortedSet_Operations(b *testing.B) {
 	sizes := []int{100, 1000, 10000}
@@ -14,9 +14,9 @@ type Set[T any] struct {
 // Default hasher implementations only exist for int, string, and byte slice types.
 // NewSet can also take some initial values as varargs.
 func NewSet[T any](hasher Hasher[T], values ...T) Set[T] {
-	m := NewMap[T, struct{}](hasher)
+	m, o := NewMap[T, struct{}](hasher), new(owner)
 	for _, value := range values {
-		m = m.set(value, struct{}{}, true)
+		m = m.set(value, struct{}{}, o)
 	}
 	return Set[T]{m}
 }
@@ -55,6 +55,77 @@ func (s Set[T]) Items() []T {
 	return r
 }
 
+// Union returns a set of the values in either s or other.
+//
+// Like Intersection and Difference, Union skips the subtrees the two sets
+// share, so when one set is derived from the other with Add and Delete the
+// cost is proportional to the number of changes rather than the size of the
+// sets. The sets are expected to use equivalent hashers.
+func (s Set[T]) Union(other Set[T]) Set[T] {
+	// Add the values of the smaller set to the larger.
+	dst, src := s.m, other.m
+	if mapLen(dst) < mapLen(src) {
+		dst, src = src, dst
+	}
+	if mapLen(src) == 0 {
+		return Set[T]{dst}
+	}
+	mapUnsharedEntries(src.root, dst.root, func(key T, _ struct{}) bool {
+		if _, ok := dst.Get(key); !ok {
+			dst = dst.Set(key, struct{}{})
+		}
+		return true
+	})
+	return Set[T]{dst}
+}
+
+// Intersection returns a set of the values in both s and other.
+func (s Set[T]) Intersection(other Set[T]) Set[T] {
+	// Remove the values of the smaller set missing from the larger. Values in
+	// shared subtrees are in both.
+	dst, src := s.m, other.m
+	if mapLen(dst) > mapLen(src) {
+		dst, src = src, dst
+	}
+	if mapLen(dst) == 0 || mapLen(src) == 0 {
+		return NewSet(setHasher(s))
+	}
+	result := dst
+	mapUnsharedEntries(dst.root, src.root, func(key T, _ struct{}) bool {
+		if _, ok := src.Get(key); !ok {
+			result = result.Delete(key)
+		}
+		return true
+	})
+	return Set[T]{result}
+}
+
+// Difference returns a set of the values in s that are not in other.
+func (s Set[T]) Difference(other Set[T]) Set[T] {
+	if mapLen(other.m) == 0 {
+		return s
+	}
+	// Values in shared subtrees are in other, so only the rest are candidates.
+	result, o := NewMap[T, struct{}](setHasher(s)), new(owner)
+	if mapLen(s.m) != 0 {
+		mapUnsharedEntries(s.m.root, other.m.root, func(key T, _ struct{}) bool {
+			if _, ok := other.m.Get(key); !ok {
+				result = result.set(key, struct{}{}, o)
+			}
+			return true
+		})
+	}
+	return Set[T]{result}
+}
+
+// setHasher returns the hasher of s, which may be nil.
+func setHasher[T any](s Set[T]) Hasher[T] {
+	if s.m == nil {
+		return nil
+	}
+	return s.m.hasher
+}
+
 // Iterator returns a new iterator for this set positioned at the first value.
 func (s Set[T]) Iterator() *SetIterator[T] {
 	itr := &SetIterator[T]{mi: s.m.Iterator()}
@@ -73,6 +144,12 @@ func (itr *SetIterator[T]) Done() bool {
 	return itr.mi.Done()
 }
 
+// Clone returns an independent iterator at the same position. Moving either
+// iterator does not affect the other.
+func (itr *SetIterator[T]) Clone() *SetIterator[T] {
+	return &SetIterator[T]{mi: itr.mi.Clone()}
+}
+
 // First moves the iterator to the first value.
 func (itr *SetIterator[T]) First() {
 	itr.mi.First()
@@ -85,29 +162,48 @@ func (itr *SetIterator[T]) Next() (val T, ok bool) {
 }
 
 type SetBuilder[T any] struct {
-	s Set[T]
+	s     Set[T]
+	owner *owner // owner of the nodes the builder may update in place
 }
 
 func NewSetBuilder[T any](hasher Hasher[T]) *SetBuilder[T] {
-	return &SetBuilder[T]{s: NewSet(hasher)}
+	return &SetBuilder[T]{s: NewSet(hasher), owner: new(owner)}
+}
+
+// NewSetBuilderFrom returns a new instance of SetBuilder holding the values of
+// s. The builder shares s's nodes, copying each once on the first update that
+// touches it, so s is never modified.
+func NewSetBuilderFrom[T any](s *Set[T]) *SetBuilder[T] {
+	if s == nil || s.m == nil {
+		return NewSetBuilder[T](nil)
+	}
+	return &SetBuilder[T]{s: Set[T]{s.m.clone()}, owner: new(owner)}
 }
 
-func (s SetBuilder[T]) Set(val T) {
-	s.s.m = s.s.m.set(val, struct{}{}, true)
+func (s *SetBuilder[T]) Set(val T) {
+	s.s.m = s.s.m.set(val, struct{}{}, s.owner)
 }
 
-func (s SetBuilder[T]) Delete(val T) {
-	s.s.m = s.s.m.delete(val, true)
+func (s *SetBuilder[T]) Delete(val T) {
+	s.s.m = s.s.m.delete(val, s.owner)
 }
 
-func (s SetBuilder[T]) Has(val T) bool {
+func (s *SetBuilder[T]) Has(val T) bool {
 	return s.s.Has(val)
 }
 
-func (s SetBuilder[T]) Len() int {
+func (s *SetBuilder[T]) Len() int {
 	return s.s.Len()
 }
 
+// Snapshot returns the current state of the set without invalidating the
+// builder. As with MapBuilder.Snapshot, shared nodes are copied once on the
+// next update that touches them.
+func (s *SetBuilder[T]) Snapshot() Set[T] {
+	s.owner = new(owner)
+	return Set[T]{s.s.m.clone()}
+}
+
 type SortedSet[T any] struct {
 	m *SortedMap[T, struct{}]
 }
@@ -119,9 +215,9 @@ type SortedSet[T any] struct {
 // exist for int, string, and byte slice keys.
 // NewSortedSet can also take some initial values as varargs.
 func NewSortedSet[T any](comparer Comparer[T], values ...T) SortedSet[T] {
-	m := NewSortedMap[T, struct{}](comparer)
+	m, o := NewSortedMap[T, struct{}](comparer), new(owner)
 	for _, value := range values {
-		m = m.set(value, struct{}{}, true)
+		m = m.set(value, struct{}{}, o)
 	}
 	return SortedSet[T]{m}
 }
@@ -160,6 +256,57 @@ func (s SortedSet[T]) Items() []T {
 	return r
 }
 
+// DeleteRange returns a set without the values from lo up to but not including
+// hi, removing whole subtrees at once; see SortedMap.DeleteRange. If no values
+// are in the range, s is returned.
+func (s SortedSet[T]) DeleteRange(lo, hi T) SortedSet[T] {
+	return SortedSet[T]{s.m.DeleteRange(lo, hi)}
+}
+
+// DeleteBefore returns a set without the values before v. If there are none,
+// s is returned.
+func (s SortedSet[T]) DeleteBefore(v T) SortedSet[T] {
+	if s.m.root == nil {
+		return s
+	}
+	return s.DeleteRange(s.m.root.minKey(), v)
+}
+
+// DeleteAfter returns a set without the values after v. If there are none, s
+// is returned.
+func (s SortedSet[T]) DeleteAfter(v T) SortedSet[T] {
+	itr := SortedMapIterator[T, struct{}]{m: s.m}
+	itr.Seek(v)
+	if !itr.Done() && s.m.comparer.Compare(itr.peek(), v) == 0 {
+		itr.Next()
+	}
+	if itr.Done() {
+		return s
+	}
+	return SortedSet[T]{s.m.deleteRange(itr.peek(), nil)}
+}
+
+// AscendAfter returns up to limit values after cursor, in order, for
+// paginating over the set. It also returns the cursor for the next page and
+// whether any values remain after it; see SortedMap.AscendAfter.
+func (s SortedSet[T]) AscendAfter(cursor T, limit int) (values []T, next T, more bool) {
+	values = make([]T, 0, max(min(limit, s.Len()), 0))
+	next, more = s.m.page(cursor, limit, false, func(value T, _ struct{}) {
+		values = append(values, value)
+	})
+	return values, next, more
+}
+
+// DescendBefore is like AscendAfter but returns values before cursor, in
+// reverse order.
+func (s SortedSet[T]) DescendBefore(cursor T, limit int) (values []T, next T, more bool) {
+	values = make([]T, 0, max(min(limit, s.Len()), 0))
+	next, more = s.m.page(cursor, limit, true, func(value T, _ struct{}) {
+		values = append(values, value)
+	})
+	return values, next, more
+}
+
 // Iterator returns a new iterator for this set positioned at the first value.
 func (s SortedSet[T]) Iterator() *SortedSetIterator[T] {
 	itr := &SortedSetIterator[T]{mi: s.m.Iterator()}
@@ -168,7 +315,8 @@ func (s SortedSet[T]) Iterator() *SortedSetIterator[T] {
 }
 
 // SortedSetIterator represents an iterator over a sorted set.
-// Iteration can occur in natural or reverse order based on use of Next() or Prev().
+// Iteration can occur in natural or reverse order based on use of Next() or Prev(),
+// which follow the cursor discipline described for SortedMapIterator.
 type SortedSetIterator[T any] struct {
 	mi *SortedMapIterator[T, struct{}]
 }
@@ -178,6 +326,12 @@ func (itr *SortedSetIterator[T]) Done() bool {
 	return itr.mi.Done()
 }
 
+// Clone returns an independent iterator at the same position. Moving either
+// iterator does not affect the other.
+func (itr *SortedSetIterator[T]) Clone() *SortedSetIterator[T] {
+	return &SortedSetIterator[T]{mi: itr.mi.Clone()}
+}
+
 // First moves the iterator to the first value.
 func (itr *SortedSetIterator[T]) First() {
 	itr.mi.First()
@@ -209,20 +363,54 @@ func (itr *SortedSetIterator[T]) Seek(val T) {
 }
 
 type SortedSetBuilder[T any] struct {
-	s *SortedSet[T]
+	s     *SortedSet[T]
+	owner *owner // owner of the nodes the builder may update in place
 }
 
 func NewSortedSetBuilder[T any](comparer Comparer[T]) *SortedSetBuilder[T] {
 	s := NewSortedSet(comparer)
-	return &SortedSetBuilder[T]{s: &s}
+	return &SortedSetBuilder[T]{s: &s, owner: new(owner)}
+}
+
+// NewSortedSetBuilderFrom returns a new instance of SortedSetBuilder holding
+// the values of ss. The builder shares ss's nodes, copying each once on the
+// first update that touches it, so ss is never modified.
+func NewSortedSetBuilderFrom[T any](ss *SortedSet[T]) *SortedSetBuilder[T] {
+	if ss == nil || ss.m == nil {
+		return NewSortedSetBuilder[T](nil)
+	}
+	return &SortedSetBuilder[T]{s: &SortedSet[T]{ss.m.clone()}, owner: new(owner)}
 }
 
 func (s SortedSetBuilder[T]) Set(val T) {
-	s.s.m = s.s.m.set(val, struct{}{}, true)
+	s.s.m = s.s.m.set(val, struct{}{}, s.owner)
+}
+
+// AddSlice adds all values to the set. Values that are in ascending order and
+// greater than every value already in the set are bulk loaded onto the tree,
+// unless the builder was seeded with an existing set.
+func (s SortedSetBuilder[T]) AddSlice(values []T) {
+	entries := make([]mapEntry[T, struct{}], len(values))
+	for i, val := range values {
+		entries[i].key = val
+	}
+	if s.s.m.appendSorted(entries, s.owner) {
+		return
+	}
+	for _, val := range values {
+		s.s.m = s.s.m.set(val, struct{}{}, s.owner)
+	}
 }
 
 func (s SortedSetBuilder[T]) Delete(val T) {
-	s.s.m = s.s.m.delete(val, true)
+	s.s.m = s.s.m.delete(val, s.owner)
+}
+
+// DeleteSlice removes all values from the set.
+func (s SortedSetBuilder[T]) DeleteSlice(values []T) {
+	for _, val := range values {
+		s.s.m = s.s.m.delete(val, s.owner)
+	}
 }
 
 func (s SortedSetBuilder[T]) Has(val T) bool {
@@ -1,6 +1,10 @@
 package immutable
 
 import (
+	"fmt"
+	"maps"
+	"math/rand"
+	"slices"
 	"testing"
 )
 
@@ -124,3 +128,403 @@ func TestSortedSetBuilder(t *testing.T) {
 		t.Fatalf("Third item incorrectly sorted")
 	}
 }
+
+func TestSortedSetBuilder_Slice(t *testing.T) {
+	b := NewSortedSetBuilder[int](nil)
+	model := NewSortedSet[int](nil)
+	next := 0
+	for round := 0; round < 50; round++ {
+		var values []int
+		switch round % 3 {
+		case 0: // ascending past the current maximum: bulk loaded
+			for i := 0; i < 40; i++ {
+				values = append(values, next)
+				next += 1 + i%3
+			}
+		case 1: // unordered, overlapping existing values
+			for i := 0; i < 40; i++ {
+				values = append(values, (i*7919+round*31)%(next+1))
+			}
+		case 2: // ascending but overlapping: falls back to single inserts
+			for i := 0; i < 40; i++ {
+				values = append(values, next-40+i)
+			}
+		}
+		b.AddSlice(values)
+		for _, v := range values {
+			model = model.Add(v)
+		}
+
+		var deletes []int
+		for i := 0; i < 15; i++ {
+			deletes = append(deletes, (i*104729+round*17)%(next+1))
+		}
+		b.DeleteSlice(deletes)
+		for _, v := range deletes {
+			model = model.Delete(v)
+		}
+
+		if b.Len() != model.Len() {
+			t.Fatalf("round %d: expected length %d, got %d", round, model.Len(), b.Len())
+		}
+	}
+
+	items, want := b.SortedSet().Items(), model.Items()
+	if len(items) != len(want) {
+		t.Fatalf("expected %d items, got %d", len(want), len(items))
+	}
+	for i := range want {
+		if items[i] != want[i] {
+			t.Fatalf("expected item %d to be %d, got %d", i, want[i], items[i])
+		}
+	}
+}
+
+// TestBuilderFrom seeds set and sorted map builders with existing collections,
+// mutates them heavily and checks the seeds are unchanged.
+func TestBuilderFrom(t *testing.T) {
+	RunRandom(t, "SetBuilder", func(t *testing.T, rand *rand.Rand) {
+		seed := NewSet[int](nil)
+		model := make(map[int]struct{})
+		for i := rand.Intn(2000); i > 0; i-- {
+			v := rand.Intn(1000)
+			seed, model[v] = seed.Add(v), struct{}{}
+		}
+		items, repr := seed.Items(), seed.GoString()
+
+		b := NewSetBuilderFrom(&seed)
+		for i := 0; i < 1000; i++ {
+			if v := rand.Intn(1000); rand.Intn(2) == 0 {
+				b.Set(v)
+				model[v] = struct{}{}
+			} else {
+				b.Delete(v)
+				delete(model, v)
+			}
+			if i == 500 {
+				b.Snapshot()
+			}
+		}
+
+		got := b.Snapshot().Items()
+		slices.Sort(got)
+		if want := slices.Sorted(maps.Keys(model)); !slices.Equal(got, want) {
+			t.Fatalf("unexpected set: %v, want %v", got, want)
+		}
+		if !slices.Equal(seed.Items(), items) || seed.GoString() != repr {
+			t.Fatalf("seed modified: %s", seed.GoString())
+		}
+	})
+
+	RunRandom(t, "SortedSetBuilder", func(t *testing.T, rand *rand.Rand) {
+		seed := NewSortedSet[int](nil)
+		model := make(map[int]struct{})
+		for i := rand.Intn(2000); i > 0; i-- {
+			v := rand.Intn(1000)
+			seed, model[v] = seed.Add(v), struct{}{}
+		}
+		items, repr := seed.Items(), seed.GoString()
+
+		b := NewSortedSetBuilderFrom(&seed)
+		for i := 0; i < 100; i++ {
+			values := make([]int, rand.Intn(20))
+			for j := range values {
+				values[j] = 1000 + i*20 + j
+			}
+			switch rand.Intn(3) {
+			case 0:
+				b.AddSlice(values)
+				for _, v := range values {
+					model[v] = struct{}{}
+				}
+			case 1:
+				v := rand.Intn(1000)
+				b.Set(v)
+				model[v] = struct{}{}
+			case 2:
+				v := rand.Intn(1000)
+				b.Delete(v)
+				delete(model, v)
+			}
+		}
+
+		if got, want := b.SortedSet().Items(), slices.Sorted(maps.Keys(model)); !slices.Equal(got, want) {
+			t.Fatalf("unexpected set: %v, want %v", got, want)
+		}
+		if !slices.Equal(seed.Items(), items) || seed.GoString() != repr {
+			t.Fatalf("seed modified: %s", seed.GoString())
+		}
+	})
+
+	RunRandom(t, "SortedMapBuilder", func(t *testing.T, rand *rand.Rand) {
+		seed := NewSortedMap[int, int](nil)
+		model := make(map[int]int)
+		for i := rand.Intn(2000); i > 0; i-- {
+			k := rand.Intn(1000)
+			seed, model[k] = seed.Set(k, i), i
+		}
+		entries := func(m *SortedMap[int, int]) (a [][2]int) {
+			m.Range(func(k, v int) bool {
+				a = append(a, [2]int{k, v})
+				return true
+			})
+			return a
+		}
+		before, repr := entries(seed), seed.GoString()
+
+		b := NewSortedMapBuilderFrom(seed)
+		for i := 0; i < 1000; i++ {
+			if k := rand.Intn(1000); rand.Intn(2) == 0 {
+				b.Set(k, -i)
+				model[k] = -i
+			} else {
+				b.Delete(k)
+				delete(model, k)
+			}
+		}
+
+		m := b.Map()
+		if m.Len() != len(model) {
+			t.Fatalf("unexpected len %d, want %d", m.Len(), len(model))
+		}
+		for k, want := range model {
+			if v, ok := m.Get(k); !ok || v != want {
+				t.Fatalf("key %d: expected %d, got %d", k, want, v)
+			}
+		}
+		if !slices.Equal(entries(seed), before) || seed.GoString() != repr {
+			t.Fatalf("seed modified: %s", seed.GoString())
+		}
+	})
+
+	// Seeded builders copy each shared node once and then own it.
+	t.Run("InPlace", func(t *testing.T) {
+		seed := NewSet[int](nil)
+		sortedSeed := NewSortedSet[int](nil)
+		for i := 0; i < 1000; i++ {
+			seed, sortedSeed = seed.Add(i), sortedSeed.Add(i)
+		}
+
+		sb := NewSetBuilderFrom(&seed)
+		sb.Set(1000)
+		root := sb.s.m.root
+		if sb.Set(1001); sb.s.m.root != root {
+			t.Fatal("SetBuilder: expected second Set to update in place")
+		}
+
+		ssb := NewSortedSetBuilderFrom(&sortedSeed)
+		ssb.Delete(0)
+		sortedRoot := ssb.s.m.root
+		if ssb.Delete(1); ssb.s.m.root != sortedRoot {
+			t.Fatal("SortedSetBuilder: expected second Delete to update in place")
+		}
+
+		lb := NewListBuilderFrom(NewList(1, 2, 3))
+		lb.Set(0, 0)
+		listRoot := lb.list.root
+		if lb.Set(1, 0); lb.list.root != listRoot {
+			t.Fatal("ListBuilder: expected second Set to update in place")
+		}
+
+		if seed.Len() != 1000 || seed.Has(1000) || sortedSeed.Len() != 1000 || !sortedSeed.Has(0) {
+			t.Fatal("seed modified")
+		}
+	})
+
+	// AddSlice bulk loads ascending values onto a seeded builder, which fills
+	// leaves completely rather than splitting them.
+	t.Run("SortedSetAddSliceBulk", func(t *testing.T) {
+		seed := NewSortedSet[int](nil, 0)
+		b := NewSortedSetBuilderFrom(&seed)
+		values := make([]int, 4*sortedMapNodeSize)
+		for i := range values {
+			values[i] = i + 1
+		}
+		b.AddSlice(values)
+
+		root, ok := b.s.m.root.(*sortedMapBranchNode[int, struct{}])
+		if !ok {
+			t.Fatalf("unexpected root %T", b.s.m.root)
+		}
+		for _, elem := range root.elems[:len(root.elems)-1] {
+			if n := len(elem.node.(*sortedMapLeafNode[int, struct{}]).entries); n != sortedMapNodeSize {
+				t.Fatalf("expected full leaves, got a leaf of %d", n)
+			}
+		}
+		if got := b.SortedSet(); got.Len() != len(values)+1 || seed.Len() != 1 {
+			t.Fatalf("unexpected lengths %d and %d", got.Len(), seed.Len())
+		}
+	})
+}
+
+func TestSetAlgebra(t *testing.T) {
+	check := func(t *testing.T, a, b Set[int], ma, mb map[int]struct{}) {
+		t.Helper()
+		union, intersection, difference := maps.Clone(ma), make(map[int]struct{}), make(map[int]struct{})
+		maps.Copy(union, mb)
+		for v := range ma {
+			if _, ok := mb[v]; ok {
+				intersection[v] = struct{}{}
+			} else {
+				difference[v] = struct{}{}
+			}
+		}
+		for _, tt := range []struct {
+			name string
+			got  Set[int]
+			want map[int]struct{}
+		}{
+			{"Union", a.Union(b), union},
+			{"Intersection", a.Intersection(b), intersection},
+			{"Difference", a.Difference(b), difference},
+		} {
+			got := tt.got.Items()
+			slices.Sort(got)
+			if want := slices.Sorted(maps.Keys(tt.want)); !slices.Equal(got, want) {
+				t.Fatalf("%s: expected %v, got %v", tt.name, want, got)
+			}
+		}
+	}
+
+	RunRandom(t, "Unrelated", func(t *testing.T, rand *rand.Rand) {
+		a, b := NewSet[int](nil), NewSet[int](nil)
+		ma, mb := make(map[int]struct{}), make(map[int]struct{})
+		for i := rand.Intn(1000); i > 0; i-- {
+			v := rand.Intn(1000)
+			a, ma[v] = a.Add(v), struct{}{}
+		}
+		for i := rand.Intn(1000); i > 0; i-- {
+			v := rand.Intn(1000)
+			b, mb[v] = b.Add(v), struct{}{}
+		}
+		check(t, a, b, ma, mb)
+		check(t, b, a, mb, ma)
+	})
+
+	RunRandom(t, "Derived", func(t *testing.T, rand *rand.Rand) {
+		a := NewSet[int](nil)
+		ma := make(map[int]struct{})
+		for i := rand.Intn(5000); i > 0; i-- {
+			v := rand.Intn(10000)
+			a, ma[v] = a.Add(v), struct{}{}
+		}
+		b, mb := a, maps.Clone(ma)
+		for i := rand.Intn(20); i > 0; i-- {
+			if v := rand.Intn(10000); rand.Intn(2) == 0 {
+				b, mb[v] = b.Add(v), struct{}{}
+			} else {
+				b = b.Delete(v)
+				delete(mb, v)
+			}
+		}
+		check(t, a, b, ma, mb)
+		check(t, b, a, mb, ma)
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		a := NewSet[int](nil, 1, 2)
+		for _, empty := range []Set[int]{{}, NewSet[int](nil)} {
+			if n := a.Union(empty).Len() + empty.Union(a).Len(); n != 4 {
+				t.Fatalf("unexpected union lengths: %d", n)
+			}
+			if n := a.Intersection(empty).Len() + empty.Intersection(a).Len(); n != 0 {
+				t.Fatalf("unexpected intersection lengths: %d", n)
+			}
+			if a.Difference(empty).Len() != 2 || empty.Difference(a).Len() != 0 {
+				t.Fatal("unexpected difference")
+			}
+		}
+	})
+
+	t.Run("SharedSubtrees", func(t *testing.T) {
+		var hashes int
+		a := NewSet[int](countingHasher[int]{NewHasher(0), &hashes})
+		for i := 0; i < 100000; i++ {
+			a = a.Add(i)
+		}
+		b := a.Add(-1).Add(-2).Delete(5).Delete(6)
+
+		hashes = 0
+		union, intersection, difference := a.Union(b), a.Intersection(b), a.Difference(b)
+		if hashes > 1000 {
+			t.Fatalf("expected shared subtrees to be skipped, hashed %d values", hashes)
+		}
+		if union.Len() != 100002 || intersection.Len() != 99998 || difference.Len() != 2 {
+			t.Fatalf("unexpected lengths: %d, %d, %d", union.Len(), intersection.Len(), difference.Len())
+		}
+		if !difference.Has(5) || !difference.Has(6) || intersection.Has(-1) || !union.Has(-2) {
+			t.Fatal("unexpected values")
+		}
+	})
+}
+
+func TestSortedSet_AscendAfter(t *testing.T) {
+	var want []string
+	for i := 0; i < 10000; i++ {
+		want = append(want, fmt.Sprintf("%05d", i))
+	}
+	s := NewSortedSet[string](nil, want...)
+
+	var got []string
+	for cursor, more := "", true; more; {
+		var page []string
+		page, cursor, more = s.AscendAfter(cursor, 7)
+		got = append(got, page...)
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("unexpected ascending values: %d values", len(got))
+	}
+
+	got = nil
+	for cursor, more := "~", true; more; {
+		var page []string
+		page, cursor, more = s.DescendBefore(cursor, 7)
+		got = append(got, page...)
+	}
+	slices.Reverse(got)
+	if !slices.Equal(got, want) {
+		t.Fatalf("unexpected descending values: %d values", len(got))
+	}
+}
+
+func TestSortedSet_DeleteRange(t *testing.T) {
+	// Values added in order fill leaves of sortedMapNodeSize, so multiples of
+	// it fall on leaf boundaries.
+	const n = 5000
+	var values []int
+	for i := 0; i < n; i++ {
+		values = append(values, i*2)
+	}
+	b := NewSortedSetBuilder[int](nil)
+	b.AddSlice(values)
+	s := b.SortedSet()
+	items := s.Items()
+
+	check := func(t *testing.T, name string, got SortedSet[int], keep func(v int) bool) {
+		t.Helper()
+		want := slices.DeleteFunc(slices.Clone(items), func(v int) bool { return !keep(v) })
+		if vs := got.Items(); !slices.Equal(vs, want) || got.Len() != len(want) {
+			t.Fatalf("%s: expected %d values, got %d", name, len(want), got.Len())
+		}
+		if len(want) == n && got.m != s.m {
+			t.Fatalf("%s: expected the receiver when nothing is removed", name)
+		}
+		if !slices.Equal(s.Items(), items) {
+			t.Fatalf("%s: original modified", name)
+		}
+	}
+
+	bounds := []int{-10, 0, 1, 2 * sortedMapNodeSize, 2*sortedMapNodeSize + 1, 2 * 7 * sortedMapNodeSize, 5001, 2 * (n - 1), 2*n + 10}
+	for _, lo := range bounds {
+		check(t, fmt.Sprintf("DeleteBefore(%d)", lo), s.DeleteBefore(lo), func(v int) bool { return v >= lo })
+		check(t, fmt.Sprintf("DeleteAfter(%d)", lo), s.DeleteAfter(lo), func(v int) bool { return v <= lo })
+		for _, hi := range bounds {
+			check(t, fmt.Sprintf("DeleteRange(%d, %d)", lo, hi), s.DeleteRange(lo, hi), func(v int) bool { return v < lo || v >= hi })
+		}
+	}
+
+	empty := NewSortedSet[int](nil)
+	if empty.DeleteBefore(1).Len() != 0 || empty.DeleteAfter(1).Len() != 0 || empty.DeleteRange(0, 1).Len() != 0 {
+		t.Fatal("unexpected values in empty set")
+	}
+}
@@ -0,0 +1,126 @@
+package immutable
+
+import "unsafe"
+
+// nodeWalker is implemented by collections whose nodes can be enumerated for
+// the structural sharing diagnostics, currently *Map and *List.
+type nodeWalker interface {
+	// walkNodes calls fn once for every node reachable from the collection's
+	// root with the node's address and approximate size in bytes.
+	walkNodes(fn func(node unsafe.Pointer, size uintptr))
+
+	// headerSize returns the size of the collection's own struct.
+	headerSize() uintptr
+}
+
+// SharingStats reports how many nodes two versions of a collection have in
+// common. It is returned by SharedSize.
+type SharingStats struct {
+	SharedNodes  int // nodes reachable from both collections
+	UniqueNodesA int // nodes reachable only from the first collection
+	UniqueNodesB int // nodes reachable only from the second collection
+
+	// SharedBytes approximates the memory held by the shared nodes.
+	SharedBytes uintptr
+}
+
+// SharedSize compares two collections of the same type, typically an original
+// and a version derived from it, and reports how many of their internal nodes
+// are shared. Nodes are compared by identity, so only structure shared through
+// persistence is counted; equal but separately built collections share
+// nothing. It accepts *Map and *List values.
+//
+// SharedSize is a diagnostic: it visits every node of both collections and
+// byte counts are estimates, as described for MemoryFootprint.
+func SharedSize[C nodeWalker](a, b C) SharingStats {
+	nodes := make(map[unsafe.Pointer]struct{})
+	a.walkNodes(func(node unsafe.Pointer, size uintptr) {
+		nodes[node] = struct{}{}
+	})
+
+	var stats SharingStats
+	seen := make(map[unsafe.Pointer]struct{})
+	b.walkNodes(func(node unsafe.Pointer, size uintptr) {
+		if _, ok := seen[node]; ok {
+			return
+		}
+		seen[node] = struct{}{}
+		if _, ok := nodes[node]; ok {
+			stats.SharedNodes++
+			stats.SharedBytes += size
+		} else {
+			stats.UniqueNodesB++
+		}
+	})
+	stats.UniqueNodesA = len(nodes) - stats.SharedNodes
+	return stats
+}
+
+// MemoryFootprint estimates the number of bytes used by a collection's own
+// structure. It accepts *Map and *List values.
+//
+// The estimate covers the collection header, every node struct and the
+// backing arrays of node slices, sized with unsafe.Sizeof. Memory referenced
+// from keys and values, such as string contents, is not included.
+func MemoryFootprint(c nodeWalker) uintptr {
+	size := c.headerSize()
+	c.walkNodes(func(node unsafe.Pointer, n uintptr) { size += n })
+	return size
+}
+
+func (m *Map[K, V]) headerSize() uintptr { return unsafe.Sizeof(*m) }
+
+func (m *Map[K, V]) walkNodes(fn func(node unsafe.Pointer, size uintptr)) {
+	if m != nil {
+		walkMapNodes(m.root, fn)
+	}
+}
+
+// walkMapNodes calls fn for n and each of its descendants.
+func walkMapNodes[K, V any](n mapNode[K, V], fn func(node unsafe.Pointer, size uintptr)) {
+	switch n := n.(type) {
+	case *mapArrayNode[K, V]:
+		fn(unsafe.Pointer(n), unsafe.Sizeof(*n)+uintptr(cap(n.entries))*unsafe.Sizeof(mapEntry[K, V]{}))
+	case *mapBitmapIndexedNode[K, V]:
+		fn(unsafe.Pointer(n), unsafe.Sizeof(*n)+uintptr(cap(n.nodes))*unsafe.Sizeof(mapNode[K, V](nil)))
+		for _, child := range n.nodes {
+			walkMapNodes(child, fn)
+		}
+	case *mapHashArrayNode[K, V]:
+		fn(unsafe.Pointer(n), unsafe.Sizeof(*n))
+		for _, child := range n.nodes {
+			walkMapNodes(child, fn)
+		}
+	case *mapValueNode[K, V]:
+		fn(unsafe.Pointer(n), unsafe.Sizeof(*n))
+	case *mapHashCollisionNode[K, V]:
+		fn(unsafe.Pointer(n), unsafe.Sizeof(*n)+uintptr(cap(n.entries))*unsafe.Sizeof(mapEntry[K, V]{}))
+	}
+}
+
+func (l *List[T]) headerSize() uintptr { return unsafe.Sizeof(*l) }
+
+func (l *List[T]) walkNodes(fn func(node unsafe.Pointer, size uintptr)) {
+	if l != nil {
+		walkListNodes(l.root, fn)
+		if l.tail != nil {
+			walkListNodes[T](l.tail, fn)
+		}
+	}
+}
+
+// walkListNodes calls fn for n and each of its descendants.
+func walkListNodes[T any](n listNode[T], fn func(node unsafe.Pointer, size uintptr)) {
+	switch n := n.(type) {
+	case *listBranchNode[T]:
+		fn(unsafe.Pointer(n), unsafe.Sizeof(*n))
+		for _, child := range n.children {
+			walkListNodes(child, fn)
+		}
+	case *listLeafNode[T]:
+		fn(unsafe.Pointer(n), unsafe.Sizeof(*n))
+	case *listSliceNode[T]:
+		var zero T
+		fn(unsafe.Pointer(n), unsafe.Sizeof(*n)+uintptr(cap(n.elements))*unsafe.Sizeof(zero))
+	}
+}
@@ -0,0 +1,102 @@
+package immutable
+
+import (
+	"fmt"
+	"testing"
+	"unsafe"
+)
+
+func TestSharedSize(t *testing.T) {
+	t.Run("Map", func(t *testing.T) {
+		a := NewMap[int, int](nil)
+		for i := 0; i < 10000; i++ {
+			a = a.Set(i, i)
+		}
+		if stats := SharedSize(a, a); stats.UniqueNodesA != 0 || stats.UniqueNodesB != 0 || stats.SharedNodes == 0 {
+			t.Fatalf("unexpected self stats: %+v", stats)
+		} else if stats.SharedBytes+a.headerSize() != MemoryFootprint(a) {
+			t.Fatalf("expected shared bytes %d to match footprint %d", stats.SharedBytes, MemoryFootprint(a))
+		}
+
+		b := a.Set(10000, 0).Delete(5)
+		stats := SharedSize(a, b)
+		if stats.UniqueNodesA == 0 || stats.UniqueNodesB == 0 || stats.SharedNodes < 10*(stats.UniqueNodesA+stats.UniqueNodesB) {
+			t.Fatalf("unexpected stats: %+v", stats)
+		}
+
+		// Separately built maps share nothing, even when equal.
+		c := NewMap[int, int](nil)
+		for i := 0; i < 10000; i++ {
+			c = c.Set(i, i)
+		}
+		if stats := SharedSize(a, c); stats.SharedNodes != 0 || stats.SharedBytes != 0 || stats.UniqueNodesB == 0 {
+			t.Fatalf("unexpected stats: %+v", stats)
+		}
+
+		var empty *Map[int, int]
+		if stats := SharedSize(empty, a); stats.SharedNodes != 0 || stats.UniqueNodesA != 0 || stats.UniqueNodesB == 0 {
+			t.Fatalf("unexpected stats: %+v", stats)
+		}
+	})
+
+	t.Run("List", func(t *testing.T) {
+		a := NewList[int]()
+		for i := 0; i < 5000; i++ {
+			a = a.Append(i)
+		}
+		b := a.Set(100, -1)
+		stats := SharedSize(a, b)
+		if stats.UniqueNodesA != stats.UniqueNodesB || stats.UniqueNodesA != int(a.root.depth())+1 {
+			t.Fatalf("expected one modified path, got %+v", stats)
+		}
+
+		small := NewList(1, 2, 3)
+		if stats := SharedSize(small, small.Append(4)); stats.SharedNodes != 0 || stats.UniqueNodesA != 1 || stats.UniqueNodesB != 1 {
+			t.Fatalf("unexpected stats: %+v", stats)
+		}
+	})
+}
+
+func TestMemoryFootprint(t *testing.T) {
+	if got, want := MemoryFootprint(NewList[int64]()), unsafe.Sizeof(List[int64]{})+unsafe.Sizeof(listSliceNode[int64]{}); got != want {
+		t.Fatalf("expected %d, got %d", want, got)
+	}
+	if got, want := MemoryFootprint(NewList[int64](1, 2)), unsafe.Sizeof(List[int64]{})+unsafe.Sizeof(listSliceNode[int64]{})+16; got != want {
+		t.Fatalf("expected %d, got %d", want, got)
+	}
+
+	// A larger list costs at least as much as its elements.
+	l := NewList[int64]()
+	for i := 0; i < 1000; i++ {
+		l = l.Append(int64(i))
+	}
+	if got := MemoryFootprint(l); got < 8000 || got > 16000 {
+		t.Fatalf("unexpected footprint %d", got)
+	}
+
+	var m *Map[string, int]
+	if got := MemoryFootprint(m); got != unsafe.Sizeof(Map[string, int]{}) {
+		t.Fatalf("unexpected nil map footprint %d", got)
+	}
+}
+
+func ExampleSharedSize() {
+	b := NewMapBuilder[int, int](nil)
+	for i := 0; i < 1000000; i++ {
+		b.Set(i, i)
+	}
+	m := b.Map()
+
+	edited := m
+	for i := 0; i < 10; i++ {
+		edited = edited.Set(i*1000, -i)
+	}
+
+	stats := SharedSize(m, edited)
+	total := stats.SharedNodes + stats.UniqueNodesB
+	fmt.Println("shared > 99%:", float64(stats.SharedNodes)/float64(total) > 0.99)
+	fmt.Println("shared bytes > 99%:", float64(stats.SharedBytes)/float64(MemoryFootprint(edited)) > 0.99)
+	// Output:
+	// shared > 99%: true
+	// shared bytes > 99%: true
+}
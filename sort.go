@@ -0,0 +1,105 @@
+package immutable
+
+import (
+	"cmp"
+	"math/rand"
+	"slices"
+)
+
+// SortStableFunc returns a list of l's values sorted by cmp, which returns a
+// negative number when a < b, a positive number when a > b and zero when they
+// are equal. Equal values keep their order in l. If l is already sorted it is
+// returned unchanged.
+func (l *List[T]) SortStableFunc(cmp func(a, b T) int) *List[T] {
+	if l.IsSortedFunc(cmp) {
+		return l
+	}
+	values := appendListRange(make([]T, 0, l.size), l, 0, l.size)
+	slices.SortStableFunc(values, cmp)
+	return NewListFromSliceParallel(values, 1)
+}
+
+// IsSortedFunc reports whether l's values are sorted by cmp, as defined for
+// SortStableFunc. It stops at the first value out of order.
+func (l *List[T]) IsSortedFunc(cmp func(a, b T) int) bool {
+	var prev T
+	first := true
+	return listChunks(l, 0, l.size, func(chunk []T) bool {
+		if !first && cmp(chunk[0], prev) < 0 {
+			return false
+		}
+		first, prev = false, chunk[len(chunk)-1]
+		return slices.IsSortedFunc(chunk, cmp)
+	})
+}
+
+// Shuffle returns a list of l's values in a random order, permuted with the
+// Fisher-Yates shuffle using r so results can be reproduced. If r is nil, the
+// math/rand package's default source is used.
+func (l *List[T]) Shuffle(r *rand.Rand) *List[T] {
+	values := appendListRange(make([]T, 0, l.size), l, 0, l.size)
+	swap := func(i, j int) { values[i], values[j] = values[j], values[i] }
+	if r != nil {
+		r.Shuffle(len(values), swap)
+	} else {
+		rand.Shuffle(len(values), swap)
+	}
+	return NewListFromSliceParallel(values, 1)
+}
+
+// SortList returns a list of l's values in ascending order. See
+// SortStableFunc.
+func SortList[T cmp.Ordered](l *List[T]) *List[T] { return l.SortStableFunc(cmp.Compare[T]) }
+
+// IsSortedList reports whether l's values are in ascending order.
+func IsSortedList[T cmp.Ordered](l *List[T]) bool { return l.IsSortedFunc(cmp.Compare[T]) }
+
+// CompareLists compares the values of a and b in order, like slices.Compare.
+// The result is the comparison of the first pair of values that differ or, if
+// one list is a prefix of the other, -1 when a is shorter and +1 when b is. It
+// is 0 if the lists are equal. A nil list is treated as empty.
+func CompareLists[T cmp.Ordered](a, b *List[T]) int { return CompareListsFunc(a, b, cmp.Compare[T]) }
+
+// CompareListsFunc is like CompareLists but compares each pair of values with
+// compare, like slices.CompareFunc. Lists sharing the same trie, such as a list
+// and itself, are equal without any comparisons.
+func CompareListsFunc[T, U any](a *List[T], b *List[U], compare func(T, U) int) int {
+	n, m := listLen(a), listLen(b)
+	if n == 0 || m == 0 {
+		return cmp.Compare(n, m)
+	} else if n == m && a.origin == b.origin && any(a.root) == any(b.root) && any(a.tail) == any(b.tail) {
+		return 0
+	}
+	itrA, itrB := a.Iterator(), b.Iterator()
+	for !itrA.Done() && !itrB.Done() {
+		_, va := itrA.Next()
+		_, vb := itrB.Next()
+		if c := compare(va, vb); c != 0 {
+			return c
+		}
+	}
+	return cmp.Compare(n, m)
+}
+
+// BinarySearchList searches for target in a list sorted in ascending order
+// and returns the index at which target is found, or would be inserted to
+// keep the list sorted, and whether it was found. See slices.BinarySearch.
+func BinarySearchList[T cmp.Ordered](l *List[T], target T) (int, bool) {
+	return BinarySearchListFunc(l, target, cmp.Compare[T])
+}
+
+// BinarySearchListFunc is like BinarySearchList but uses cmp, which compares a
+// list value with target as in slices.BinarySearchFunc. The list must be
+// sorted in the order cmp defines.
+func BinarySearchListFunc[T, E any](l *List[T], target E, cmp func(T, E) int) (int, bool) {
+	// Each probe is a Get, which walks shallow tries without recursion.
+	lo, hi := 0, l.size
+	for lo < hi {
+		if mid := int(uint(lo+hi) >> 1); cmp(l.Get(mid), target) < 0 {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+	return lo, lo < l.size && cmp(l.Get(lo), target) == 0
+}
@@ -0,0 +1,220 @@
+package immutable
+
+import (
+	"cmp"
+	"fmt"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestList_SortStableFunc(t *testing.T) {
+	type record struct {
+		key, payload int
+	}
+	byKey := func(a, b record) int { return cmp.Compare(a.key, b.key) }
+
+	RunRandom(t, "Stable", func(t *testing.T, rand *rand.Rand) {
+		// Build the list by appending and prepending so the trie has an origin.
+		n := rand.Intn(5000)
+		values := make([]record, n)
+		for i := range values {
+			values[i] = record{key: rand.Intn(20), payload: i}
+		}
+		l := NewList[record]()
+		for i := n/2 - 1; i >= 0; i-- {
+			l = l.Prepend(values[i])
+		}
+		for _, v := range values[n/2:] {
+			l = l.Append(v)
+		}
+		repr := l.GoString()
+
+		sorted := l.SortStableFunc(byKey)
+		got := slices.Collect(listValues(sorted))
+		for i := 1; i < len(got); i++ {
+			if got[i-1].key > got[i].key || (got[i-1].key == got[i].key && got[i-1].payload > got[i].payload) {
+				t.Fatalf("values %d and %d out of order: %v, %v", i-1, i, got[i-1], got[i])
+			}
+		}
+		if len(got) != n || l.GoString() != repr {
+			t.Fatalf("unexpected length %d, or original modified", len(got))
+		}
+		if !sorted.IsSortedFunc(byKey) || (n > 1 && l.IsSortedFunc(byKey) != slices.IsSortedFunc(values, byKey)) {
+			t.Fatal("unexpected IsSortedFunc result")
+		}
+		if sorted.SortStableFunc(byKey) != sorted {
+			t.Fatal("expected sorted list to be returned unchanged")
+		}
+	})
+
+	t.Run("Slice", func(t *testing.T) {
+		l := NewList[int]()
+		for i := 0; i < 1000; i++ {
+			l = l.Append(i)
+		}
+		if !IsSortedList(l.Slice(37, 900)) {
+			t.Fatal("expected sliced list to be sorted")
+		}
+		l = l.Set(500, -1)
+		if IsSortedList(l) || !IsSortedList(l.Slice(501, 1000)) || !IsSortedList(l.Slice(0, 500)) {
+			t.Fatal("unexpected IsSortedList result")
+		}
+		if got := slices.Collect(listValues(SortList(l.Slice(400, 600)))); got[0] != -1 || !slices.IsSorted(got) || len(got) != 200 {
+			t.Fatalf("unexpected sorted list: %v", got)
+		}
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		if l := NewList[int](); !IsSortedList(l) || SortList(l).Len() != 0 {
+			t.Fatal("unexpected empty list result")
+		}
+	})
+}
+
+func TestList_Shuffle(t *testing.T) {
+	l := NewList[int]()
+	for i := 0; i < 1000; i++ {
+		l = l.Append(i)
+	}
+	repr := l.GoString()
+
+	a, b := l.Shuffle(rand.New(rand.NewSource(1))), l.Shuffle(rand.New(rand.NewSource(1)))
+	if !slices.Equal(slices.Collect(listValues(a)), slices.Collect(listValues(b))) {
+		t.Fatal("expected the same source to give the same order")
+	}
+	if IsSortedList(a) || !slices.Equal(slices.Collect(listValues(SortList(a))), slices.Collect(listValues(l))) {
+		t.Fatal("expected a permutation of the list")
+	}
+	if c := l.Shuffle(nil); c.Len() != l.Len() || IsSortedList(c) {
+		t.Fatal("unexpected shuffle with the default source")
+	}
+	if a = a.Append(-1).Prepend(-2); a.Get(0) != -2 || a.Get(1001) != -1 {
+		t.Fatal("unexpected append or prepend")
+	}
+	if l.GoString() != repr {
+		t.Fatal("original modified")
+	}
+	if got := NewList[int]().Shuffle(nil); got.Len() != 0 {
+		t.Fatalf("unexpected empty shuffle: %v", got)
+	}
+}
+
+func TestList_IsSortedFunc(t *testing.T) {
+	// The comparison stops at the first inversion.
+	l := NewList[int]()
+	for i := 0; i < 10000; i++ {
+		l = l.Append(i)
+	}
+	l = l.Set(100, -1)
+	var calls int
+	if l.IsSortedFunc(func(a, b int) int { calls++; return cmp.Compare(a, b) }) {
+		t.Fatal("expected list to be unsorted")
+	}
+	if calls > 200 {
+		t.Fatalf("expected early exit, compared %d times", calls)
+	}
+}
+
+func TestBinarySearchList(t *testing.T) {
+	RunRandom(t, "Random", func(t *testing.T, rand *rand.Rand) {
+		values := make([]int, rand.Intn(3000))
+		for i := range values {
+			values[i] = rand.Intn(2 * len(values))
+		}
+		slices.Sort(values)
+		l := NewList[int]()
+		for i := len(values)/2 - 1; i >= 0; i-- {
+			l = l.Prepend(values[i])
+		}
+		for _, v := range values[len(values)/2:] {
+			l = l.Append(v)
+		}
+
+		for i := 0; i < 100; i++ {
+			target := rand.Intn(2*len(values)+2) - 1
+			wantIndex, wantFound := slices.BinarySearch(values, target)
+			if index, found := BinarySearchList(l, target); index != wantIndex || found != wantFound {
+				t.Fatalf("target %d: got (%d, %v), expected (%d, %v)", target, index, found, wantIndex, wantFound)
+			}
+		}
+	})
+
+	t.Run("Func", func(t *testing.T) {
+		type entry struct {
+			key   string
+			value int
+		}
+		l := NewList(entry{"a", 1}, entry{"c", 2}, entry{"e", 3})
+		byKey := func(e entry, key string) int { return cmp.Compare(e.key, key) }
+		if index, found := BinarySearchListFunc(l, "c", byKey); index != 1 || !found {
+			t.Fatalf("unexpected result (%d, %v)", index, found)
+		}
+		if index, found := BinarySearchListFunc(l, "d", byKey); index != 2 || found {
+			t.Fatalf("unexpected result (%d, %v)", index, found)
+		}
+		if index, found := BinarySearchListFunc(NewList[entry](), "a", byKey); index != 0 || found {
+			t.Fatalf("unexpected result (%d, %v)", index, found)
+		}
+	})
+}
+
+func TestCompareLists(t *testing.T) {
+	newList := func(values ...int) *List[int] {
+		l := NewList[int]()
+		for _, v := range values {
+			l = l.Append(v)
+		}
+		return l
+	}
+	long := make([]int, 1000)
+	for i := range long {
+		long[i] = i
+	}
+	longer := append(slices.Clone(long), 0)
+	changed := slices.Clone(long)
+	changed[900] = -1
+
+	for _, tt := range []struct {
+		a, b []int
+	}{
+		{nil, nil},
+		{nil, []int{1}},
+		{[]int{1, 2}, []int{1, 2}},
+		{[]int{1, 2}, []int{1, 3}},
+		{[]int{2}, []int{1, 3}},
+		{long, long},
+		{long, longer},
+		{long, changed},
+	} {
+		want := slices.Compare(tt.a, tt.b)
+		if got := CompareLists(newList(tt.a...), newList(tt.b...)); got != want {
+			t.Fatalf("%v vs %v: expected %d, got %d", len(tt.a), len(tt.b), want, got)
+		}
+		if got := CompareLists(newList(tt.b...), newList(tt.a...)); got != -want {
+			t.Fatalf("%v vs %v: expected %d, got %d", len(tt.b), len(tt.a), -want, got)
+		}
+	}
+
+	t.Run("Nil", func(t *testing.T) {
+		if CompareLists(nil, NewList[int]()) != 0 || CompareLists(nil, NewList(1)) != -1 {
+			t.Fatal("expected a nil list to compare as empty")
+		}
+	})
+
+	t.Run("SharedRoot", func(t *testing.T) {
+		l := newList(long...)
+		var calls int
+		if CompareListsFunc(l, l, func(a, b int) int { calls++; return cmp.Compare(a, b) }) != 0 || calls != 0 {
+			t.Fatalf("expected no comparisons, got %d", calls)
+		}
+	})
+
+	t.Run("Func", func(t *testing.T) {
+		a, b := NewList(1, 2, 3), NewList("1", "2", "4")
+		byString := func(a int, b string) int { return cmp.Compare(fmt.Sprint(a), b) }
+		if got := CompareListsFunc(a, b, byString); got != -1 {
+			t.Fatalf("unexpected result %d", got)
+		}
+	})
+}
@@ -0,0 +1,76 @@
+package immutable
+
+import (
+	"database/sql/driver"
+	"fmt"
+)
+
+// scanJSON returns the JSON document held by a database value accepted by
+// Scan. A nil value or a JSON null reports ok as false.
+func scanJSON(src any) (data []byte, ok bool, err error) {
+	switch src := src.(type) {
+	case nil:
+		return nil, false, nil
+	case []byte:
+		return src, string(src) != "null", nil
+	case string:
+		return []byte(src), src != "null", nil
+	}
+	return nil, false, fmt.Errorf("unsupported source type %T", src)
+}
+
+// Value implements driver.Valuer, storing the map as a JSON object so it can
+// be written to JSON columns such as Postgres jsonb. A nil map is stored as
+// NULL.
+func (m *Map[K, V]) Value() (driver.Value, error) {
+	if m == nil {
+		return nil, nil
+	}
+	data, err := m.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// Scan implements sql.Scanner, replacing the contents of the map with a JSON
+// object read from the database. The source may be []byte or string; NULL
+// produces an empty map. Like UnmarshalJSON, it modifies the receiver and
+// retains its hasher.
+func (m *Map[K, V]) Scan(src any) error {
+	data, ok, err := scanJSON(src)
+	if err != nil {
+		return fmt.Errorf("immutable.Map.Scan: %w", err)
+	} else if !ok {
+		*m = *NewMap[K, V](m.hasher)
+		return nil
+	}
+	return m.UnmarshalJSON(data)
+}
+
+// Value implements driver.Valuer, storing the list as a JSON array. A nil
+// list is stored as NULL.
+func (l *List[T]) Value() (driver.Value, error) {
+	if l == nil {
+		return nil, nil
+	}
+	data, err := l.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// Scan implements sql.Scanner, replacing the contents of the list with a JSON
+// array read from the database. The source may be []byte or string; NULL
+// produces an empty list. Like UnmarshalJSON, it modifies the receiver.
+func (l *List[T]) Scan(src any) error {
+	data, ok, err := scanJSON(src)
+	if err != nil {
+		return fmt.Errorf("immutable.List.Scan: %w", err)
+	} else if !ok {
+		*l = *NewList[T]()
+		return nil
+	}
+	return l.UnmarshalJSON(data)
+}
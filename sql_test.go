@@ -0,0 +1,96 @@
+package immutable
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"slices"
+	"strings"
+	"testing"
+)
+
+var (
+	_ driver.Valuer = (*Map[string, int])(nil)
+	_ sql.Scanner   = (*Map[string, int])(nil)
+	_ driver.Valuer = (*List[int])(nil)
+	_ sql.Scanner   = (*List[int])(nil)
+)
+
+func TestSQL(t *testing.T) {
+	t.Run("Map", func(t *testing.T) {
+		m := NewMap[string, []int](nil).Set("a", []int{1}).Set("b", []int{2, 3})
+		v, err := m.Value()
+		if err != nil {
+			t.Fatal(err)
+		} else if !driver.IsValue(v) {
+			t.Fatalf("invalid driver value %T", v)
+		}
+
+		// Drivers may hand back either []byte or string.
+		for _, src := range []any{v, string(v.([]byte))} {
+			var other Map[string, []int]
+			if err := other.Scan(src); err != nil {
+				t.Fatal(err)
+			}
+			if got, ok := other.Get("b"); !ok || !slices.Equal(got, []int{2, 3}) || other.Len() != 2 {
+				t.Fatalf("Get(b)=<%v,%v>", got, ok)
+			}
+		}
+
+		other := NewMap[string, []int](nil).Set("x", nil)
+		if err := other.Scan(nil); err != nil || other.Len() != 0 {
+			t.Fatalf("unexpected NULL scan: %v, len=%d", err, other.Len())
+		}
+		if err := other.Scan(42); err == nil || !strings.Contains(err.Error(), "unsupported source type int") {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if v, err := (*Map[string, int])(nil).Value(); v != nil || err != nil {
+			t.Fatalf("unexpected nil map value: %v, %v", v, err)
+		}
+	})
+
+	t.Run("MapHasher", func(t *testing.T) {
+		var m Map[jsonPoint, bool]
+		if err := m.Scan(`{"1:2":true}`); err == nil {
+			t.Fatal("expected hasher error")
+		}
+		other := NewMap[jsonPoint, bool](jsonPointHasher{})
+		if err := other.Scan(`{"1:2":true}`); err != nil {
+			t.Fatal(err)
+		} else if _, ok := other.Get(jsonPoint{1, 2}); !ok {
+			t.Fatal("expected key 1:2")
+		}
+		if err := other.Scan(nil); err != nil || other.Len() != 0 {
+			t.Fatalf("unexpected NULL scan: %v, len=%d", err, other.Len())
+		}
+		// The hasher is retained after scanning NULL.
+		if _, ok := other.Set(jsonPoint{3, 4}, true).Get(jsonPoint{3, 4}); !ok {
+			t.Fatal("expected key 3:4")
+		}
+	})
+
+	t.Run("List", func(t *testing.T) {
+		l := NewList[string]()
+		for i := 0; i < 100; i++ {
+			l = l.Append(strings.Repeat("x", i%5))
+		}
+		v, err := l.Value()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var other List[string]
+		if err := other.Scan(v); err != nil {
+			t.Fatal(err)
+		}
+		if got, want := slices.Collect(listValues(&other)), slices.Collect(listValues(l)); !slices.Equal(got, want) {
+			t.Fatalf("round trip mismatch: %v", got)
+		}
+		for _, src := range []any{nil, "null"} {
+			if err := other.Scan(src); err != nil || other.Len() != 0 {
+				t.Fatalf("unexpected scan of %v: %v, len=%d", src, err, other.Len())
+			}
+		}
+		if err := other.Scan("{}"); err == nil {
+			t.Fatal("expected error for JSON object")
+		}
+	})
+}